@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+// Periodic maintenance jobs, meant to be run from a scheduler:
+//
+//	go run ./cmd/jobs rollup-product-views
+//	go run ./cmd/jobs notify-back-in-stock
+//	go run ./cmd/jobs send-booking-reminders
+//	go run ./cmd/jobs purge-trash
+//	go run ./cmd/jobs rollup-store-analytics
+//	go run ./cmd/jobs notify-reopened-stores
+//	go run ./cmd/jobs send-follow-digests
+//	go run ./cmd/jobs rollup-seller-scorecards
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: Error loading .env file: %v", err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatal("Please specify a job to run")
+	}
+
+	ctx := context.Background()
+	var err error
+
+	switch os.Args[1] {
+	case "rollup-product-views":
+		err = rollupProductViews(ctx)
+	case "notify-back-in-stock":
+		err = notifyBackInStock(ctx)
+	case "send-booking-reminders":
+		err = sendBookingReminders(ctx)
+	case "purge-trash":
+		err = purgeTrash(ctx)
+	case "rollup-store-analytics":
+		err = rollupStoreAnalytics(ctx)
+	case "notify-reopened-stores":
+		err = notifyReopenedStores(ctx)
+	case "send-follow-digests":
+		err = sendFollowDigests(ctx)
+	case "rollup-seller-scorecards":
+		err = rollupSellerScorecards(ctx)
+	default:
+		log.Fatalf("Unknown job %q", os.Args[1])
+	}
+
+	if err != nil {
+		log.Fatalf("Job %s failed: %v", os.Args[1], err)
+	}
+	fmt.Printf("Successfully ran %s\n", os.Args[1])
+}
+
+// rollupProductViews re-aggregates yesterday's and today's view events so the daily
+// table stays correct even if an incremental update was lost.
+func rollupProductViews(ctx context.Context) error {
+	svc := product.NewService(product.NewRepository())
+	now := time.Now().UTC()
+	for _, day := range []time.Time{now.Add(-24 * time.Hour), now} {
+		if err := svc.RollupProductViews(ctx, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyBackInStock sends the next batch of back in stock alerts for every
+// restocked product that still has people waiting.
+func notifyBackInStock(ctx context.Context) error {
+	svc := product.NewService(product.NewRepository())
+	ids, err := svc.GetRestockedProductIDs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		notified, err := svc.NotifyRestock(ctx, id)
+		if err != nil {
+			log.Printf("Failed to notify subscribers of product %d: %v", id, err)
+			continue
+		}
+		if notified > 0 {
+			log.Printf("Notified %d subscribers that product %d is back in stock", notified, id)
+		}
+	}
+	return nil
+}
+
+// sendBookingReminders reminds customers and providers of bookings starting
+// within the next day. Run it at least hourly.
+func sendBookingReminders(ctx context.Context) error {
+	svc := booking.NewService(booking.NewRepository())
+	sent, err := svc.SendReminders(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Sent reminders for %d bookings", sent)
+	return nil
+}
+
+// purgeTrash permanently deletes products and stores that have been in the
+// trash for longer than the retention window.
+func purgeTrash(ctx context.Context) error {
+	before := time.Now().Add(-product.TrashRetention)
+	products, err := product.NewService(product.NewRepository()).PurgeTrashedProducts(ctx, before)
+	if err != nil {
+		return err
+	}
+	stores, err := store.NewService(store.NewRepository()).PurgeTrashedStores(ctx, before)
+	if err != nil {
+		return err
+	}
+	log.Printf("Purged %d products and %d stores from the trash", products, stores)
+	return nil
+}
+
+// rollupStoreAnalytics rebuilds yesterday's and today's store analytics from
+// the orders and view events. Run it at least hourly to keep dashboards fresh.
+func rollupStoreAnalytics(ctx context.Context) error {
+	svc := store.NewService(store.NewRepository())
+	now := time.Now().UTC()
+	for _, day := range []time.Time{now.Add(-24 * time.Hour), now} {
+		if err := svc.RollupStoreAnalytics(ctx, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyReopenedStores tells followers about stores back from a holiday or
+// vacation. Run it every few minutes so followers hear soon after reopening.
+func notifyReopenedStores(ctx context.Context) error {
+	reopened, err := store.NewService(store.NewRepository()).NotifyReopenedStores(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Notified followers of %d reopened stores", reopened)
+	return nil
+}
+
+// sendFollowDigests emails opted-in users what their followed stores posted
+// since their last digest. Run it once a day.
+func sendFollowDigests(ctx context.Context) error {
+	sent, err := store.NewService(store.NewRepository()).SendFollowDigests(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Sent %d follow digests", sent)
+	return nil
+}
+
+// rollupSellerScorecards rescores every store from its recent orders,
+// disputes, chat replies and reviews. Run it once a day.
+func rollupSellerScorecards(ctx context.Context) error {
+	scored, err := store.NewService(store.NewRepository()).RollupStoreScorecards(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Scored %d stores", scored)
+	return nil
+}
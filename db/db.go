@@ -2,9 +2,15 @@ package db
 
 import (
 	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
 	"github.com/samstringzz/alutamarket-backend/internals/cart"
+	"github.com/samstringzz/alutamarket-backend/internals/download"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
+	"github.com/samstringzz/alutamarket-backend/internals/media"
 	"github.com/samstringzz/alutamarket-backend/internals/messages"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
 	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/review"
 	"github.com/samstringzz/alutamarket-backend/internals/skynet"
 	"github.com/samstringzz/alutamarket-backend/internals/store"
 	"github.com/samstringzz/alutamarket-backend/internals/user"
@@ -24,10 +30,18 @@ func Migrate() *gorm.DB {
 		&product.Product{},
 		&cart.Cart{},
 		&product.Category{},
-		&product.SubCategory{}, // Added SubCategory
+		&product.CategoryAttribute{},
+		&product.ProductSlugHistory{},
+		&product.BundleItem{},
+		&product.PricingRule{},
+		&product.SaleCampaign{},
+		&product.SaleItem{},
+		&booking.Availability{},
+		&booking.Booking{},
 		&messages.Chat{},
 		&product.HandledProduct{},
-		&store.Review{},
+		&review.Review{},
+		&review.HelpfulVote{},
 		&messages.Message{},
 		&store.Order{},
 		&store.Downloads{},
@@ -36,6 +50,38 @@ func Migrate() *gorm.DB {
 		&store.DVAAccount{},
 		&store.DVACustomer{},
 		&store.DVABank{},
+		&product.ProductView{},
+		&product.ProductViewDaily{},
+		&store.StoreSalesDaily{},
+		&store.StoreProductSalesDaily{},
+		&store.StoreCustomerDaily{},
+		&store.StoreVisitorDaily{},
+		&store.StoreMember{},
+		&kyc.Verification{},
+		&store.StoreHours{},
+		&store.StoreClosure{},
+		&store.StoreAnnouncement{},
+		&store.StoreCustomerTags{},
+		&store.StoreCustomerNote{},
+		&store.StorePromoCampaign{},
+		&store.StoreOrderEvent{},
+		&store.OrderDispute{},
+		&store.StoreScorecard{},
+		&store.StorePolicy{},
+		&store.StoreCloseout{},
+		&store.StoreHandleHistory{},
+		&store.QRScan{},
+		&product.ProductModeration{},
+		&product.ProductPriceHistory{},
+		&product.StockSubscription{},
+		&product.ProductQuestion{},
+		&product.ProductAnswer{},
+		&product.QAVote{},
+		&notification.Notification{},
+		&media.Image{},
+		&media.ImageRendition{},
+		&download.Event{},
+		&download.LinkUse{},
 	); err != nil {
 		panic("Failed to migrate database: " + err.Error())
 	}
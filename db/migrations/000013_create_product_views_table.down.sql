@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS views INTEGER[] DEFAULT '{}';
+DROP TABLE IF EXISTS product_view_daily;
+DROP TABLE IF EXISTS product_views;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS product_views (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL DEFAULT 0, -- 0 for anonymous viewers
+    session_id VARCHAR(100) NOT NULL DEFAULT '',
+    source VARCHAR(50) NOT NULL DEFAULT 'direct',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_views_product_id ON product_views(product_id);
+CREATE INDEX idx_product_views_user_id ON product_views(user_id);
+CREATE INDEX idx_product_views_session_id ON product_views(session_id);
+CREATE INDEX idx_product_views_created_at ON product_views(created_at);
+
+CREATE TABLE IF NOT EXISTS product_view_daily (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    unique_viewers INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, day)
+);
+
+-- Carry the legacy per-product viewer arrays over as events, then drop them
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'products' AND column_name = 'views'
+    ) THEN
+        INSERT INTO product_views (product_id, user_id, source, created_at)
+        SELECT p.id, v.user_id, 'legacy', p.updated_at
+        FROM products p, unnest(p.views) AS v(user_id)
+        WHERE p.views IS NOT NULL;
+
+        ALTER TABLE products DROP COLUMN views;
+    END IF;
+END $$;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_store_sku;
+DROP INDEX IF EXISTS idx_products_sku;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,5 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_products_sku ON products(sku);
+-- A SKU identifies one product within a store; products without a SKU are exempt
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_store_sku ON products(store, sku) WHERE sku <> '';
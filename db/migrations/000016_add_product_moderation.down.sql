@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS product_moderations;
+ALTER TABLE stores DROP COLUMN IF EXISTS trusted;
+DROP INDEX IF EXISTS idx_products_moderation_status;
+ALTER TABLE products DROP COLUMN IF EXISTS moderation_reason;
+ALTER TABLE products DROP COLUMN IF EXISTS moderation_status;
@@ -0,0 +1,24 @@
+-- Existing products stay live; new and edited ones are set to pending by the application
+ALTER TABLE products ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS moderation_reason TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_products_moderation_status ON products(moderation_status);
+
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS trusted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS product_moderations (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    store VARCHAR(255) NOT NULL DEFAULT '',
+    trigger VARCHAR(20) NOT NULL DEFAULT '',
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason TEXT NOT NULL DEFAULT '',
+    reviewer_id INTEGER NOT NULL DEFAULT 0,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_moderations_product_id ON product_moderations(product_id);
+CREATE INDEX idx_product_moderations_store ON product_moderations(store);
+CREATE INDEX idx_product_moderations_status ON product_moderations(status);
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS price_drop_email;
+ALTER TABLE handled_products DROP COLUMN IF EXISTS alerted_price;
+ALTER TABLE handled_products DROP COLUMN IF EXISTS saved_price;
+DROP TABLE IF EXISTS product_price_history;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS product_price_history (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) NOT NULL DEFAULT 0,
+    discount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_price_history_product_id ON product_price_history(product_id);
+CREATE INDEX idx_product_price_history_created_at ON product_price_history(created_at);
+
+-- Start every product's history at its current price
+INSERT INTO product_price_history (product_id, price, discount)
+SELECT id, price, discount FROM products;
+
+ALTER TABLE handled_products ADD COLUMN IF NOT EXISTS saved_price DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE handled_products ADD COLUMN IF NOT EXISTS alerted_price DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Items saved before price tracking are compared against today's price
+UPDATE handled_products hp
+SET saved_price = p.price - p.discount
+FROM products p
+WHERE p.id = hp.product_id;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS price_drop_email BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS stock_subscriptions (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    variant VARCHAR(255) NOT NULL DEFAULT '', -- "name:value", empty for the whole product
+    user_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_subscriptions_product_id ON stock_subscriptions(product_id);
+CREATE INDEX idx_stock_subscriptions_user_id ON stock_subscriptions(user_id);
+CREATE INDEX idx_stock_subscriptions_status ON stock_subscriptions(status);
+CREATE INDEX idx_stock_subscriptions_created_at ON stock_subscriptions(created_at);
+-- One place in the queue per user and product variant
+CREATE UNIQUE INDEX idx_stock_subscriptions_waiting ON stock_subscriptions(product_id, variant, user_id) WHERE status = 'waiting';
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS product_questions (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    upvotes INTEGER NOT NULL DEFAULT 0,
+    answer_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_questions_product_id ON product_questions(product_id);
+CREATE INDEX idx_product_questions_user_id ON product_questions(user_id);
+CREATE INDEX idx_product_questions_status ON product_questions(status);
+
+CREATE TABLE IF NOT EXISTS product_answers (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES product_questions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    author VARCHAR(255) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    is_seller BOOLEAN NOT NULL DEFAULT FALSE,
+    verified_buyer BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'published',
+    upvotes INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_answers_question_id ON product_answers(question_id);
+CREATE INDEX idx_product_answers_user_id ON product_answers(user_id);
+CREATE INDEX idx_product_answers_status ON product_answers(status);
+
+CREATE TABLE IF NOT EXISTS qa_votes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    kind VARCHAR(20) NOT NULL, -- question/answer
+    target_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_qa_votes_user_target ON qa_votes(user_id, kind, target_id);
@@ -0,0 +1,26 @@
+ALTER TABLE stores
+    DROP COLUMN IF EXISTS rating_average,
+    DROP COLUMN IF EXISTS review_count;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS rating_average,
+    DROP COLUMN IF EXISTS review_count;
+
+DROP TABLE IF EXISTS review_helpful_votes;
+
+DROP INDEX IF EXISTS idx_reviews_buyer_id;
+DROP INDEX IF EXISTS idx_reviews_store_id;
+DROP INDEX IF EXISTS idx_reviews_product_id;
+DROP INDEX IF EXISTS idx_reviews_order_product;
+
+-- Legacy product reviews are not restored to the products table
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reviews JSONB;
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS reviews TEXT;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS buyer TEXT;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS verified,
+    DROP COLUMN IF EXISTS helpful_count,
+    DROP COLUMN IF EXISTS seller_replied_at,
+    DROP COLUMN IF EXISTS seller_reply,
+    DROP COLUMN IF EXISTS photos;
@@ -0,0 +1,106 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL DEFAULT 0,
+    product_id INTEGER NOT NULL DEFAULT 0,
+    order_id VARCHAR(100) NOT NULL DEFAULT '',
+    seller_id INTEGER NOT NULL DEFAULT 0,
+    rating NUMERIC(3,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS buyer_id INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS nickname VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS avatar TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS message TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS photos TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS seller_reply TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS seller_replied_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS helpful_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Store reviews kept the buyer's details as a JSON blob
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'reviews' AND column_name = 'buyer'
+    ) THEN
+        UPDATE reviews SET
+            nickname = COALESCE(buyer::jsonb->>'nickname', ''),
+            avatar = COALESCE(buyer::jsonb->>'avatar', ''),
+            message = COALESCE(NULLIF(message, ''), buyer::jsonb->>'comment', '')
+        WHERE buyer IS NOT NULL AND buyer <> '' AND buyer <> 'null';
+
+        ALTER TABLE reviews DROP COLUMN buyer;
+    END IF;
+END $$;
+
+-- Product reviews were stored as a JSON array on the product row. They never
+-- referenced an order, so they are carried over as unverified reviews.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'products' AND column_name = 'reviews'
+    ) THEN
+        INSERT INTO reviews (product_id, store_id, seller_id, nickname, avatar, message, rating, verified, created_at, updated_at)
+        SELECT p.id, COALESCE(s.id, 0), COALESCE(s.user_id, 0),
+               COALESCE(r->>'username', ''), COALESCE(r->>'image', ''), COALESCE(r->>'message', ''),
+               COALESCE((r->>'rating')::NUMERIC, 0), FALSE, p.updated_at, p.updated_at
+        FROM products p
+        LEFT JOIN stores s ON s.name = p.store
+        CROSS JOIN LATERAL jsonb_array_elements(p.reviews::jsonb) AS r
+        WHERE p.reviews IS NOT NULL AND jsonb_typeof(p.reviews::jsonb) = 'array';
+
+        ALTER TABLE products DROP COLUMN reviews;
+    END IF;
+END $$;
+
+ALTER TABLE handled_products DROP COLUMN IF EXISTS reviews;
+ALTER TABLE stores DROP COLUMN IF EXISTS reviews;
+
+-- Each delivered order line can be reviewed once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reviews_order_product ON reviews(order_id, product_id) WHERE verified AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_reviews_product_id ON reviews(product_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_store_id ON reviews(store_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_buyer_id ON reviews(buyer_id);
+
+CREATE TABLE IF NOT EXISTS review_helpful_votes (
+    id SERIAL PRIMARY KEY,
+    review_id INTEGER NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_review_helpful_votes_review_user ON review_helpful_votes(review_id, user_id);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS review_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE stores
+    ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS review_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE products p SET
+    rating_average = agg.average,
+    review_count = agg.total
+FROM (
+    SELECT product_id, AVG(rating) AS average, COUNT(*) AS total
+    FROM reviews WHERE deleted_at IS NULL
+    GROUP BY product_id
+) agg
+WHERE agg.product_id = p.id;
+
+UPDATE stores s SET
+    rating_average = agg.average,
+    review_count = agg.total
+FROM (
+    SELECT store_id, AVG(rating) AS average, COUNT(*) AS total
+    FROM reviews WHERE deleted_at IS NULL
+    GROUP BY store_id
+) agg
+WHERE agg.store_id = s.id;
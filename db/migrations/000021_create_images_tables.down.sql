@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS image_renditions;
+DROP TABLE IF EXISTS images;
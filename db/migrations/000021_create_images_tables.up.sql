@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS images (
+    id SERIAL PRIMARY KEY,
+    owner_type VARCHAR(20) NOT NULL, -- product or store
+    owner_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    uploaded_by INTEGER NOT NULL DEFAULT 0,
+    mime_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    original_url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_images_owner ON images(owner_type, owner_id);
+CREATE INDEX idx_images_deleted_at ON images(deleted_at);
+
+CREATE TABLE IF NOT EXISTS image_renditions (
+    id SERIAL PRIMARY KEY,
+    image_id INTEGER NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+    size VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    url TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    bytes INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_image_renditions_image_id ON image_renditions(image_id);
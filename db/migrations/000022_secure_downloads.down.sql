@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS download_events;
+
+ALTER TABLE products DROP COLUMN IF EXISTS watermark;
+
+ALTER TABLE downloads
+    DROP COLUMN IF EXISTS max_downloads,
+    DROP COLUMN IF EXISTS download_count,
+    DROP COLUMN IF EXISTS product_id;
@@ -0,0 +1,21 @@
+ALTER TABLE downloads
+    ADD COLUMN IF NOT EXISTS product_id INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS download_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_downloads INTEGER NOT NULL DEFAULT 5;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS watermark BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS download_events (
+    id SERIAL PRIMARY KEY,
+    download_id VARCHAR(100) NOT NULL,
+    user_id VARCHAR(100) NOT NULL DEFAULT '',
+    action VARCHAR(20) NOT NULL, -- issued, served or denied
+    reason TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_download_events_download_id ON download_events(download_id);
+CREATE INDEX idx_download_events_user_id ON download_events(user_id);
+CREATE INDEX idx_download_events_created_at ON download_events(created_at);
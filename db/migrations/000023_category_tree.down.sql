@@ -0,0 +1,23 @@
+DROP INDEX IF EXISTS idx_products_attributes;
+DROP INDEX IF EXISTS idx_products_category_id;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS attributes,
+    DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS category_attributes;
+
+-- Subcategories are still in their old table; only the copies are removed.
+-- Categories nested deeper than one level are lost.
+DELETE FROM categories WHERE parent_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_categories_path;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP INDEX IF EXISTS idx_categories_parent_name;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS path,
+    DROP COLUMN IF EXISTS parent_id;
+
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
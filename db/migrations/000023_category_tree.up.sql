@@ -0,0 +1,76 @@
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS type VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES categories(id),
+    ADD COLUMN IF NOT EXISTS path VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Names only need to be unique among siblings now; slugs stay globally unique
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name
+    ON categories (COALESCE(parent_id, 0), LOWER(name)) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_path ON categories(path text_pattern_ops);
+
+UPDATE categories SET path = id::TEXT, depth = 0 WHERE parent_id IS NULL;
+
+-- Subcategories become child categories. Depending on how the database was
+-- set up they live in sub_categories (GORM) or subcategories (migration 3).
+DO $$
+DECLARE
+    legacy TEXT;
+BEGIN
+    FOREACH legacy IN ARRAY ARRAY['sub_categories', 'subcategories'] LOOP
+        IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = legacy) THEN
+            EXECUTE format(
+                'INSERT INTO categories (name, slug, type, parent_id, depth, created_at, updated_at)
+                 SELECT s.name, c.slug || ''-'' || s.slug, c.type, c.id, 1, s.created_at, s.updated_at
+                 FROM %I s
+                 JOIN categories c ON c.id = s.category_id
+                 WHERE NOT EXISTS (
+                     SELECT 1 FROM categories e
+                     WHERE e.parent_id = c.id AND LOWER(e.name) = LOWER(s.name)
+                 )
+                 ON CONFLICT DO NOTHING', legacy);
+        END IF;
+    END LOOP;
+END $$;
+
+UPDATE categories SET path = parent_id::TEXT || '/' || id::TEXT WHERE depth = 1 AND path = '';
+
+CREATE TABLE IF NOT EXISTS category_attributes (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    label VARCHAR(255) NOT NULL DEFAULT '',
+    type VARCHAR(20) NOT NULL DEFAULT 'text',
+    options TEXT[],
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    facet BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_attributes_name ON category_attributes(category_id, name);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS category_id INTEGER,
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes);
+
+-- Link existing products by the names they were saved with, preferring the subcategory
+UPDATE products p SET category_id = child.id
+FROM categories child
+JOIN categories root ON root.id = child.parent_id
+WHERE p.category_id IS NULL
+    AND LOWER(root.name) = LOWER(p.category)
+    AND LOWER(child.name) = LOWER(p.subcategory);
+
+UPDATE products p SET category_id = root.id
+FROM categories root
+WHERE p.category_id IS NULL
+    AND root.parent_id IS NULL
+    AND LOWER(root.name) = LOWER(p.category);
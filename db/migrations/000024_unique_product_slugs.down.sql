@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_slug_history;
+DROP INDEX IF EXISTS idx_products_store_slug;
@@ -0,0 +1,26 @@
+-- Clean up slugs generated before they had to be unique: collapse repeated
+-- dashes, fill in empty ones and number duplicates within a store.
+UPDATE products
+SET slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(slug), '-{2,}', '-', 'g')), ''), 'product');
+
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY store, slug ORDER BY id) AS n
+    FROM products
+)
+UPDATE products p
+SET slug = p.slug || '-' || p.id
+FROM ranked
+WHERE ranked.id = p.id AND ranked.n > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_store_slug ON products(store, slug);
+
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    store VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_slug_history_store_slug ON product_slug_history(store, slug);
+CREATE INDEX IF NOT EXISTS idx_product_slug_history_product_id ON product_slug_history(product_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS product_pricing_rules;
+DROP TABLE IF EXISTS product_bundle_items;
+ALTER TABLE products DROP COLUMN IF EXISTS is_bundle;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sale_items;
+DROP TABLE IF EXISTS sale_campaigns;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS sale_campaigns (
+    id SERIAL PRIMARY KEY,
+    store VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_campaigns_store ON sale_campaigns(store);
+CREATE INDEX IF NOT EXISTS idx_sale_campaigns_starts_at ON sale_campaigns(starts_at);
+CREATE INDEX IF NOT EXISTS idx_sale_campaigns_ends_at ON sale_campaigns(ends_at);
+
+CREATE TABLE IF NOT EXISTS sale_items (
+    id SERIAL PRIMARY KEY,
+    campaign_id INTEGER NOT NULL REFERENCES sale_campaigns(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sale_price NUMERIC(10,2) NOT NULL DEFAULT 0,
+    percent_off NUMERIC(5,2) NOT NULL DEFAULT 0,
+    unit_cap INTEGER NOT NULL DEFAULT 0 CHECK (unit_cap >= 0),
+    units_sold INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sale_items_campaign_product ON sale_items(campaign_id, product_id);
+CREATE INDEX IF NOT EXISTS idx_sale_items_product_id ON sale_items(product_id);
@@ -0,0 +1,51 @@
+-- Products take on the kind of their category, so services can be told apart
+UPDATE products p SET type = c.type
+FROM categories c
+WHERE c.id = p.category_id AND c.type IS NOT NULL AND c.type <> '';
+
+CREATE TABLE IF NOT EXISTS service_availability (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    store VARCHAR(255) NOT NULL,
+    hours JSONB NOT NULL DEFAULT '[]',
+    slot_minutes INTEGER NOT NULL CHECK (slot_minutes > 0),
+    capacity INTEGER NOT NULL DEFAULT 1 CHECK (capacity > 0),
+    min_notice_hours INTEGER NOT NULL DEFAULT 0,
+    max_advance_days INTEGER NOT NULL DEFAULT 30,
+    cancel_before_hours INTEGER NOT NULL DEFAULT 0,
+    reschedule_before_hours INTEGER NOT NULL DEFAULT 0,
+    max_reschedules INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_availability_product_id ON service_availability(product_id);
+CREATE INDEX IF NOT EXISTS idx_service_availability_store ON service_availability(store);
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_name VARCHAR(255) NOT NULL DEFAULT '',
+    store VARCHAR(255) NOT NULL,
+    user_id INTEGER NOT NULL,
+    order_uuid VARCHAR(255) NOT NULL DEFAULT '',
+    seats INTEGER NOT NULL DEFAULT 1 CHECK (seats > 0),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    hold_expires_at TIMESTAMP WITH TIME ZONE,
+    reschedules INTEGER NOT NULL DEFAULT 0,
+    reminder_sent_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    cancelled_by VARCHAR(20) NOT NULL DEFAULT '',
+    cancel_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_product_id ON bookings(product_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_store ON bookings(store);
+CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON bookings(user_id);
+CREATE INDEX IF NOT EXISTS idx_bookings_order_uuid ON bookings(order_uuid);
+CREATE INDEX IF NOT EXISTS idx_bookings_starts_at ON bookings(starts_at);
+CREATE INDEX IF NOT EXISTS idx_bookings_status ON bookings(status);
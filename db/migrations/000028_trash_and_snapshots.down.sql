@@ -0,0 +1,6 @@
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_product_id_fkey FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS product_thumbnail,
+    DROP COLUMN IF EXISTS product_name;
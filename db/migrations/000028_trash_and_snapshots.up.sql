@@ -0,0 +1,12 @@
+-- Reviews keep the product as it was ordered, so they survive the product being purged
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS product_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS product_thumbnail TEXT NOT NULL DEFAULT '';
+
+UPDATE reviews r SET product_name = p.name, product_thumbnail = COALESCE(p.thumbnail, '')
+FROM products p
+WHERE p.id = r.product_id AND r.product_name = '';
+
+-- Bookings and payout accounts are records; purging a product or store must not remove them
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_product_id_fkey;
+ALTER TABLE IF EXISTS paystack_dva_accounts DROP CONSTRAINT IF EXISTS paystack_dva_accounts_store_id_fkey;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS store_visitor_daily;
+DROP TABLE IF EXISTS store_customer_daily;
+DROP TABLE IF EXISTS store_product_sales_daily;
+DROP TABLE IF EXISTS store_sales_daily;
@@ -0,0 +1,77 @@
+CREATE TABLE IF NOT EXISTS store_sales_daily (
+    store VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    revenue NUMERIC(14,2) NOT NULL DEFAULT 0,
+    orders INTEGER NOT NULL DEFAULT 0,
+    units INTEGER NOT NULL DEFAULT 0,
+    customers INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (store, day)
+);
+
+CREATE TABLE IF NOT EXISTS store_product_sales_daily (
+    store VARCHAR(255) NOT NULL,
+    product_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    product_name VARCHAR(255) NOT NULL DEFAULT '',
+    revenue NUMERIC(14,2) NOT NULL DEFAULT 0,
+    units INTEGER NOT NULL DEFAULT 0,
+    orders INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (store, product_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS store_customer_daily (
+    store VARCHAR(255) NOT NULL,
+    user_id VARCHAR(50) NOT NULL,
+    day DATE NOT NULL,
+    orders INTEGER NOT NULL DEFAULT 0,
+    revenue NUMERIC(14,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (store, user_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS store_visitor_daily (
+    store VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0,
+    unique_visitors INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (store, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_sales_daily_day ON store_sales_daily(day);
+CREATE INDEX IF NOT EXISTS idx_store_product_sales_daily_day ON store_product_sales_daily(day);
+CREATE INDEX IF NOT EXISTS idx_store_customer_daily_day ON store_customer_daily(day);
+CREATE INDEX IF NOT EXISTS idx_store_visitor_daily_day ON store_visitor_daily(day);
+
+-- Backfill from existing orders and view events; the rollup job keeps recent days current
+CREATE TEMP TABLE paid_order_lines AS
+SELECT o.uuid, o.user_id, (o.created_at AT TIME ZONE 'UTC')::date AS day, l->>'store' AS store,
+    (l->>'id')::int AS product_id, l->>'name' AS product_name,
+    COALESCE((l->>'quantity')::int, 0) AS units,
+    (COALESCE((l->>'price')::numeric, 0) - COALESCE((l->>'discount')::numeric, 0)) * COALESCE((l->>'quantity')::int, 0)
+        - COALESCE((l->>'deal_savings')::numeric, 0) AS revenue
+FROM orders o, jsonb_array_elements(o.products::jsonb) AS l
+WHERE o.trans_status = 'paid' AND o.status <> 'canceled' AND o.deleted_at IS NULL;
+
+INSERT INTO store_sales_daily (store, day, revenue, orders, units, customers)
+SELECT store, day, SUM(revenue), COUNT(DISTINCT uuid), SUM(units), COUNT(DISTINCT user_id)
+FROM paid_order_lines GROUP BY store, day
+ON CONFLICT DO NOTHING;
+
+INSERT INTO store_product_sales_daily (store, product_id, day, product_name, revenue, units, orders)
+SELECT store, product_id, day, MAX(product_name), SUM(revenue), SUM(units), COUNT(DISTINCT uuid)
+FROM paid_order_lines GROUP BY store, product_id, day
+ON CONFLICT DO NOTHING;
+
+INSERT INTO store_customer_daily (store, user_id, day, orders, revenue)
+SELECT store, user_id, day, COUNT(DISTINCT uuid), SUM(revenue)
+FROM paid_order_lines GROUP BY store, user_id, day
+ON CONFLICT DO NOTHING;
+
+DROP TABLE paid_order_lines;
+
+INSERT INTO store_visitor_daily (store, day, views, unique_visitors)
+SELECT p.store, (v.created_at AT TIME ZONE 'UTC')::date, COUNT(*),
+    COUNT(DISTINCT CASE WHEN v.user_id <> 0 THEN 'u' || v.user_id::text ELSE 's' || v.session_id END)
+FROM product_views v
+JOIN products p ON p.id = v.product_id
+GROUP BY p.store, (v.created_at AT TIME ZONE 'UTC')::date
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS store_members (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL DEFAULT 0, -- 0 until the invite is accepted
+    role VARCHAR(30) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(30) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'invited',
+    invited_by INTEGER NOT NULL DEFAULT 0,
+    invite_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_members_store_id ON store_members(store_id);
+CREATE INDEX IF NOT EXISTS idx_store_members_user_id ON store_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_store_members_email ON store_members(email);
+CREATE INDEX IF NOT EXISTS idx_store_members_phone ON store_members(phone);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_store_members_store_user ON store_members(store_id, user_id) WHERE user_id <> 0;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS kyc_verifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE,
+    tier VARCHAR(20) NOT NULL DEFAULT 'unverified',
+    phone_verified_at TIMESTAMP WITH TIME ZONE,
+    id_type VARCHAR(10) NOT NULL DEFAULT '',
+    id_number_last4 VARCHAR(4) NOT NULL DEFAULT '', -- the full ID number is never stored
+    first_name VARCHAR(100) NOT NULL DEFAULT '',
+    last_name VARCHAR(100) NOT NULL DEFAULT '',
+    document_url TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT '', -- empty until an ID is submitted
+    provider VARCHAR(50) NOT NULL DEFAULT '',
+    provider_ref VARCHAR(255) NOT NULL DEFAULT '',
+    rejection_reason TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by INTEGER NOT NULL DEFAULT 0,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_status ON kyc_verifications(status);
+
+-- Users who already confirmed their phone OTP start on the phone tier
+INSERT INTO kyc_verifications (user_id, tier, phone_verified_at)
+SELECT id, 'phone_verified', CURRENT_TIMESTAMP FROM users WHERE active = true
+ON CONFLICT (user_id) DO NOTHING;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS store_closures;
+DROP TABLE IF EXISTS store_hours;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS store_hours (
+    store_id INTEGER PRIMARY KEY REFERENCES stores(id) ON DELETE CASCADE,
+    hours JSONB NOT NULL DEFAULT '[]', -- no windows means always open
+    away_message TEXT NOT NULL DEFAULT '',
+    accept_later_orders BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS store_closures (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL, -- holiday or vacation
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    reopen_notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_closures_store_id ON store_closures(store_id);
+CREATE INDEX IF NOT EXISTS idx_store_closures_ends_at ON store_closures(ends_at);
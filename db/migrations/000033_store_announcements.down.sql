@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS follow_digest_sent_at;
+ALTER TABLE users DROP COLUMN IF EXISTS follow_digest_email;
+DROP INDEX IF EXISTS idx_products_store_created_at;
+DROP TABLE IF EXISTS store_announcements;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS store_announcements (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    title VARCHAR(120) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    image TEXT NOT NULL DEFAULT '',
+    posted_by INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_announcements_store_id ON store_announcements(store_id);
+CREATE INDEX IF NOT EXISTS idx_store_announcements_created_at ON store_announcements(created_at);
+
+-- Feed pages read new listings by store, newest first
+CREATE INDEX IF NOT EXISTS idx_products_store_created_at ON products(store, created_at DESC);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS follow_digest_email BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS follow_digest_sent_at TIMESTAMP WITH TIME ZONE;
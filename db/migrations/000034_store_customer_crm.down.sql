@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS store_promo_campaigns;
+DROP TABLE IF EXISTS store_customer_notes;
+DROP TABLE IF EXISTS store_customer_tags;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS store_customer_tags (
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (store_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_customer_tags_tags ON store_customer_tags USING GIN (tags);
+
+CREATE TABLE IF NOT EXISTS store_customer_notes (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    author_id INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_customer_notes_store_id ON store_customer_notes(store_id);
+CREATE INDEX IF NOT EXISTS idx_store_customer_notes_user_id ON store_customer_notes(user_id);
+
+CREATE TABLE IF NOT EXISTS store_promo_campaigns (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    segment VARCHAR(30) NOT NULL DEFAULT '',
+    tag VARCHAR(30) NOT NULL DEFAULT '',
+    title VARCHAR(120) NOT NULL,
+    message TEXT NOT NULL,
+    product_id INTEGER NOT NULL DEFAULT 0,
+    email BOOLEAN NOT NULL DEFAULT FALSE,
+    recipients INTEGER NOT NULL DEFAULT 0,
+    sent_by INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_promo_campaigns_store_id ON store_promo_campaigns(store_id);
+CREATE INDEX IF NOT EXISTS idx_store_promo_campaigns_created_at ON store_promo_campaigns(created_at);
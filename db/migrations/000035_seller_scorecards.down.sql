@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS store_scorecards;
+DROP TABLE IF EXISTS order_disputes;
+DROP TABLE IF EXISTS store_order_events;
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS store_order_events (
+    id SERIAL PRIMARY KEY,
+    order_uuid VARCHAR(255) NOT NULL,
+    store VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_order_events_order_uuid ON store_order_events(order_uuid);
+CREATE INDEX IF NOT EXISTS idx_store_order_events_store ON store_order_events(store);
+
+CREATE TABLE IF NOT EXISTS order_disputes (
+    id SERIAL PRIMARY KEY,
+    order_uuid VARCHAR(255) NOT NULL,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    buyer_id INTEGER NOT NULL,
+    reason VARCHAR(30) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    resolution TEXT NOT NULL DEFAULT '',
+    resolved_by INTEGER NOT NULL DEFAULT 0,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_disputes_order_uuid ON order_disputes(order_uuid);
+CREATE INDEX IF NOT EXISTS idx_order_disputes_store_id ON order_disputes(store_id);
+CREATE INDEX IF NOT EXISTS idx_order_disputes_buyer_id ON order_disputes(buyer_id);
+CREATE INDEX IF NOT EXISTS idx_order_disputes_status ON order_disputes(status);
+CREATE INDEX IF NOT EXISTS idx_order_disputes_created_at ON order_disputes(created_at);
+
+CREATE TABLE IF NOT EXISTS store_scorecards (
+    store_id INTEGER PRIMARY KEY REFERENCES stores(id) ON DELETE CASCADE,
+    orders INTEGER NOT NULL DEFAULT 0,
+    avg_ship_hours DOUBLE PRECISION,
+    cancellation_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    dispute_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    avg_response_minutes DOUBLE PRECISION,
+    rating DOUBLE PRECISION,
+    reviews INTEGER NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL DEFAULT 50,
+    badge VARCHAR(20) NOT NULL DEFAULT 'new',
+    computed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS store_policies (
+    store_id INTEGER PRIMARY KEY REFERENCES stores(id) ON DELETE CASCADE,
+    pickup BOOLEAN NOT NULL DEFAULT FALSE,
+    pickup_location TEXT NOT NULL DEFAULT '',
+    campus_delivery BOOLEAN NOT NULL DEFAULT TRUE,
+    nationwide_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    processing_days INTEGER NOT NULL DEFAULT 0,
+    min_order_value NUMERIC(12,2) NOT NULL DEFAULT 0,
+    returns_accepted BOOLEAN NOT NULL DEFAULT FALSE,
+    return_window_days INTEGER NOT NULL DEFAULT 0,
+    return_policy TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS store_closeouts (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL UNIQUE REFERENCES stores(id) ON DELETE CASCADE,
+    store_name VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    was_in_maintenance BOOLEAN NOT NULL DEFAULT FALSE,
+    requested_by INTEGER NOT NULL DEFAULT 0,
+    closed_by INTEGER NOT NULL DEFAULT 0,
+    products_archived INTEGER NOT NULL DEFAULT 0,
+    final_payout NUMERIC(12,2) NOT NULL DEFAULT 0,
+    retain_until TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    reopened_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_closeouts_status ON store_closeouts(status);
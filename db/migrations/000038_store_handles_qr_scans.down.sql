@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS qr_scans;
+DROP TABLE IF EXISTS store_handle_history;
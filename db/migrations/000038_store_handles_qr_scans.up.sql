@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS store_handle_history (
+    id SERIAL PRIMARY KEY,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    handle VARCHAR(30) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_handle_history_store_id ON store_handle_history(store_id);
+
+CREATE TABLE IF NOT EXISTS qr_scans (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    target_id INTEGER NOT NULL,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_qr_scans_store_id ON qr_scans(store_id);
+CREATE INDEX IF NOT EXISTS idx_qr_scans_created_at ON qr_scans(created_at);
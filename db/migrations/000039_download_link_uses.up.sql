@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS download_link_uses (
+    id SERIAL PRIMARY KEY,
+    download_id VARCHAR(100) NOT NULL,
+    signature VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_download_link_uses_link ON download_link_uses(download_id, signature);
+CREATE INDEX IF NOT EXISTS idx_download_link_uses_created_at ON download_link_uses(created_at);
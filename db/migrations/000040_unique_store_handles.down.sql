@@ -0,0 +1,2 @@
+-- Cleaned up links are kept; only the uniqueness goes
+DROP INDEX IF EXISTS idx_stores_link_lower;
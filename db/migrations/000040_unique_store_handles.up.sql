@@ -0,0 +1,32 @@
+-- Store links were free-form before handles had rules. Each one is turned
+-- into a valid handle before handles are made unique regardless of case.
+CREATE TEMP TABLE store_link_cleanup AS
+SELECT id,
+       TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(TRIM(COALESCE(link, ''))), '[^a-z0-9]+', '-', 'g')) AS handle
+FROM stores;
+
+UPDATE store_link_cleanup
+SET handle = TRIM(BOTH '-' FROM LEFT(handle, 30))
+WHERE LENGTH(handle) > 30;
+
+UPDATE store_link_cleanup
+SET handle = 'store-' || id
+WHERE LENGTH(handle) < 3;
+
+-- Stores sharing a handle with an older store get their ID appended
+UPDATE store_link_cleanup c
+SET handle = TRIM(BOTH '-' FROM LEFT(c.handle, 29 - LENGTH(c.id::text))) || '-' || c.id
+WHERE EXISTS (SELECT 1 FROM store_link_cleanup o WHERE o.handle = c.handle AND o.id < c.id);
+
+UPDATE store_link_cleanup c
+SET handle = 'store-' || c.id
+WHERE EXISTS (SELECT 1 FROM store_link_cleanup o WHERE o.handle = c.handle AND o.id < c.id);
+
+UPDATE stores s
+SET link = c.handle
+FROM store_link_cleanup c
+WHERE s.id = c.id AND s.link IS DISTINCT FROM c.handle;
+
+DROP TABLE store_link_cleanup;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stores_link_lower ON stores (LOWER(link));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_qr_scans_visitor_previous;
+ALTER TABLE qr_scans DROP COLUMN IF EXISTS previous_id;
+ALTER TABLE qr_scans DROP COLUMN IF EXISTS visitor;
@@ -0,0 +1,13 @@
+ALTER TABLE qr_scans ADD COLUMN IF NOT EXISTS visitor VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE qr_scans ADD COLUMN IF NOT EXISTS previous_id INTEGER NOT NULL DEFAULT 0;
+
+-- Earlier scans have no visitor; link each to the scan of its code before it
+UPDATE qr_scans q
+SET previous_id = c.previous_id
+FROM (
+    SELECT id, COALESCE(LAG(id) OVER (PARTITION BY kind, target_id, visitor ORDER BY created_at, id), 0) AS previous_id
+    FROM qr_scans
+) c
+WHERE c.id = q.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_qr_scans_visitor_previous ON qr_scans(kind, target_id, visitor, previous_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_product_views_viewer_previous;
+ALTER TABLE product_views DROP COLUMN IF EXISTS previous_id;
+ALTER TABLE product_views DROP COLUMN IF EXISTS viewer;
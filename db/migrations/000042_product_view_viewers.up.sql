@@ -0,0 +1,17 @@
+ALTER TABLE product_views ADD COLUMN IF NOT EXISTS viewer VARCHAR(110);
+ALTER TABLE product_views ADD COLUMN IF NOT EXISTS previous_id INTEGER NOT NULL DEFAULT 0;
+
+UPDATE product_views
+SET viewer = CASE WHEN user_id <> 0 THEN 'u' || user_id::text ELSE 's' || session_id END
+WHERE viewer IS NULL;
+
+-- Link each earlier view to the viewer's view of the product before it
+UPDATE product_views v
+SET previous_id = c.previous_id
+FROM (
+    SELECT id, COALESCE(LAG(id) OVER (PARTITION BY product_id, viewer ORDER BY created_at, id), 0) AS previous_id
+    FROM product_views
+) c
+WHERE c.id = v.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_views_viewer_previous ON product_views(product_id, viewer, previous_id);
@@ -0,0 +1,22 @@
+-- Ratings count verified reviews only; legacy reviews stay listed
+UPDATE products p SET
+    rating_average = COALESCE(agg.average, 0),
+    review_count = COALESCE(agg.total, 0)
+FROM (
+    SELECT p2.id, AVG(r.rating) AS average, COUNT(r.id) AS total
+    FROM products p2
+    LEFT JOIN reviews r ON r.product_id = p2.id AND r.verified AND r.deleted_at IS NULL
+    GROUP BY p2.id
+) agg
+WHERE agg.id = p.id;
+
+UPDATE stores s SET
+    rating_average = COALESCE(agg.average, 0),
+    review_count = COALESCE(agg.total, 0)
+FROM (
+    SELECT s2.id, AVG(r.rating) AS average, COUNT(r.id) AS total
+    FROM stores s2
+    LEFT JOIN reviews r ON r.store_id = s2.id AND r.verified AND r.deleted_at IS NULL
+    GROUP BY s2.id
+) agg
+WHERE agg.id = s.id;
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func productSalesToModel(sales []*store.ProductSales) []*model.ProductSalesStat {
+	result := make([]*model.ProductSalesStat, 0, len(sales))
+	for _, s := range sales {
+		result = append(result, &model.ProductSalesStat{
+			ProductID:   int(s.ProductID),
+			ProductName: s.ProductName,
+			Revenue:     s.Revenue,
+			Units:       s.Units,
+			Orders:      s.Orders,
+		})
+	}
+	return result
+}
+
+func storeAnalyticsToModel(a *store.StoreAnalytics) *model.StoreAnalytics {
+	series := make([]*model.StoreAnalyticsBucket, 0, len(a.Series))
+	for _, b := range a.Series {
+		bucket := &model.StoreAnalyticsBucket{
+			Start:          b.Start,
+			Revenue:        b.Revenue,
+			Orders:         b.Orders,
+			Units:          b.Units,
+			Views:          b.Views,
+			UniqueVisitors: b.UniqueVisitors,
+		}
+		if b.UniqueVisitors > 0 {
+			bucket.ConversionRate = float64(b.Orders) / float64(b.UniqueVisitors)
+		}
+		series = append(series, bucket)
+	}
+
+	return &model.StoreAnalytics{
+		From:               a.From,
+		To:                 a.To,
+		Granularity:        a.Granularity,
+		Revenue:            a.Revenue,
+		Orders:             a.Orders,
+		Units:              a.Units,
+		AverageOrderValue:  a.AverageOrderValue,
+		Views:              a.Views,
+		UniqueVisitors:     a.UniqueVisitors,
+		ConversionRate:     a.ConversionRate,
+		Customers:          a.Customers,
+		RepeatCustomers:    a.RepeatCustomers,
+		RepeatCustomerRate: a.RepeatCustomerRate,
+		Series:             series,
+		TopByRevenue:       productSalesToModel(a.TopByRevenue),
+		TopByUnits:         productSalesToModel(a.TopByUnits),
+		TrafficSources:     trafficSourcesToModel(a.TrafficSources),
+		QRScans:            a.QRScans,
+	}
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+// requireAdmin checks that the caller is an admin and returns their user ID.
+func (r *Resolver) requireAdmin(ctx context.Context) (uint32, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := r.UserHandler.GetUser(ctx, strconv.Itoa(int(userID)))
+	if err != nil {
+		return 0, err
+	}
+	if user.Usertype != "admin" {
+		return 0, fmt.Errorf("unauthorized: only admin can perform this action")
+	}
+	return userID, nil
+}
+
+// requireStoreOwner loads a store and checks that the caller owns it.
+func (r *Resolver) requireStoreOwner(ctx context.Context, storeID uint32) (*store.Store, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeObj, err := store.NewRepository().GetStore(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if storeObj.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: only the store owner can perform this action")
+	}
+	return storeObj, nil
+}
+
+// requireStorePermission loads a store and checks that the caller's role in it
+// grants perm.
+func (r *Resolver) requireStorePermission(ctx context.Context, storeID uint32, perm string) (*store.Store, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeRepo := store.NewRepository()
+	storeObj, err := storeRepo.GetStore(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := storeRepo.GetStoreRole(ctx, storeObj, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !store.RoleAllows(role, perm) {
+		return nil, fmt.Errorf("unauthorized: your role in this store does not allow this action")
+	}
+	return storeObj, nil
+}
+
+// canInStore reports whether the caller's role in the named store grants perm.
+func (r *Resolver) canInStore(ctx context.Context, storeName string, perm string) bool {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	storeRepo := store.NewRepository()
+	storeObj, err := storeRepo.GetStoreByName(ctx, storeName)
+	if err != nil {
+		return false
+	}
+	role, err := storeRepo.GetStoreRole(ctx, storeObj, userID)
+	return err == nil && store.RoleAllows(role, perm)
+}
+
+// managesProducts reports whether the caller may manage the named store's products.
+func (r *Resolver) managesProducts(ctx context.Context, storeName string) bool {
+	return r.canInStore(ctx, storeName, store.PermProducts)
+}
+
+// requireProductManager loads a product and checks that the caller may manage
+// the products of the store selling it.
+func (r *Resolver) requireProductManager(ctx context.Context, productID uint32) (*product.Product, error) {
+	p, err := r.ProductHandler.GetProduct(ctx, productID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !r.canInStore(ctx, p.Store, store.PermProducts) {
+		return nil, fmt.Errorf("unauthorized: your role in this store does not allow this action")
+	}
+	return p, nil
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+func availabilityToModel(a *booking.Availability) *model.ServiceAvailability {
+	hours := make([]*model.WeeklyHours, 0, len(a.Hours))
+	for _, h := range a.Hours {
+		hours = append(hours, &model.WeeklyHours{Weekday: int(h.Weekday), Opens: h.Opens, Closes: h.Closes})
+	}
+	return &model.ServiceAvailability{
+		ProductID:             int(a.ProductID),
+		Hours:                 hours,
+		SlotMinutes:           a.SlotMinutes,
+		Capacity:              a.Capacity,
+		MinNoticeHours:        a.MinNoticeHours,
+		MaxAdvanceDays:        a.MaxAdvanceDays,
+		CancelBeforeHours:     a.CancelBeforeHours,
+		RescheduleBeforeHours: a.RescheduleBeforeHours,
+		MaxReschedules:        a.MaxReschedules,
+	}
+}
+
+func bookingToModel(b *booking.Booking) *model.Booking {
+	item := &model.Booking{
+		ID:          int(b.ID),
+		ProductID:   int(b.ProductID),
+		ProductName: b.ProductName,
+		Store:       b.Store,
+		UserID:      int(b.UserID),
+		OrderUUID:   b.OrderUUID,
+		Seats:       b.Seats,
+		StartsAt:    b.StartsAt,
+		EndsAt:      b.EndsAt,
+		Status:      b.Status,
+		Reschedules: b.Reschedules,
+		CreatedAt:   b.CreatedAt,
+	}
+	if b.Status == booking.StatusCancelled {
+		item.CancelledBy = &b.CancelledBy
+		item.CancelReason = &b.CancelReason
+	}
+	return item
+}
+
+// bookingActor loads a booking and works out whether the caller changes it as
+// its customer or as the provider running the store.
+func (r *Resolver) bookingActor(ctx context.Context, id uint32) (*booking.Booking, bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	b, err := booking.NewHandler(booking.NewService(booking.NewRepository())).GetBooking(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if b.UserID == userID {
+		return b, false, nil
+	}
+	if r.canInStore(ctx, b.Store, store.PermOrders) {
+		return b, true, nil
+	}
+	return nil, false, fmt.Errorf("unauthorized: only the customer or the provider can change this booking")
+}
+
+// slotTime normalises a client supplied slot start so it matches generated slots.
+func slotTime(t time.Time) time.Time {
+	return t.Truncate(time.Minute)
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+func categoryToModel(c *product.Category) *model.Category {
+	item := &model.Category{
+		ID:            strconv.FormatUint(uint64(c.ID), 10),
+		Name:          c.Name,
+		Slug:          c.Slug,
+		Type:          c.Type,
+		Depth:         c.Depth,
+		Path:          c.Path,
+		Children:      make([]*model.Category, 0, len(c.Children)),
+		Attributes:    make([]*model.CategoryAttribute, 0, len(c.Attributes)),
+		Subcategories: make([]*model.SubCategory, 0, len(c.SubCategories)),
+	}
+	if c.ParentID != nil {
+		parentID := int(*c.ParentID)
+		item.ParentID = &parentID
+	}
+	for _, child := range c.Children {
+		item.Children = append(item.Children, categoryToModel(child))
+	}
+	for _, a := range c.Attributes {
+		options := []string(a.Options)
+		if options == nil {
+			options = []string{}
+		}
+		item.Attributes = append(item.Attributes, &model.CategoryAttribute{
+			Name:      a.Name,
+			Label:     a.Label,
+			Type:      a.Type,
+			Options:   options,
+			Required:  a.Required,
+			Facet:     a.Facet,
+			Inherited: a.CategoryID != uint32(c.ID),
+		})
+	}
+	for _, sub := range c.SubCategories {
+		item.Subcategories = append(item.Subcategories, &model.SubCategory{
+			Name:     sub.Name,
+			Slug:     sub.Slug,
+			Category: int(sub.CategoryID),
+		})
+	}
+	return item
+}
+
+// attributeInputMap turns attribute inputs into the values product requests
+// carry. A nil input means the attributes were not sent.
+func attributeInputMap(input []*model.ProductAttributeInput) map[string]string {
+	if input == nil {
+		return nil
+	}
+	values := make(map[string]string, len(input))
+	for _, a := range input {
+		values[a.Name] = a.Value
+	}
+	return values
+}
+
+func productAttributesToModel(attributes product.ProductAttributes) []*model.ProductAttribute {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	items := make([]*model.ProductAttribute, 0, len(names))
+	for _, name := range names {
+		items = append(items, &model.ProductAttribute{Name: name, Value: attributes[name]})
+	}
+	return items
+}
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func storeCustomerToModel(c *store.CustomerProfile) *model.StoreCustomerProfile {
+	tags := []string(c.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return &model.StoreCustomerProfile{
+		UserID:        int(c.UserID),
+		Name:          c.Name,
+		Email:         c.Email,
+		Phone:         c.Phone,
+		Orders:        c.Orders,
+		LifetimeSpend: c.LifetimeSpend,
+		FirstPurchase: c.FirstPurchase,
+		LastPurchase:  c.LastPurchase,
+		Tags:          tags,
+		Segments:      c.Segments,
+	}
+}
+
+func storeCustomerNoteToModel(n *store.StoreCustomerNote) *model.StoreCustomerNote {
+	return &model.StoreCustomerNote{
+		ID:        int(n.ID),
+		StoreID:   int(n.StoreID),
+		UserID:    int(n.UserID),
+		Body:      n.Body,
+		AuthorID:  int(n.AuthorID),
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+func storePromoCampaignToModel(c *store.StorePromoCampaign) *model.StorePromoCampaign {
+	item := &model.StorePromoCampaign{
+		ID:         int(c.ID),
+		StoreID:    int(c.StoreID),
+		Title:      c.Title,
+		Message:    c.Message,
+		Email:      c.Email,
+		Recipients: c.Recipients,
+		CreatedAt:  c.CreatedAt,
+	}
+	if c.Segment != "" {
+		item.Segment = &c.Segment
+	}
+	if c.Tag != "" {
+		item.Tag = &c.Tag
+	}
+	if c.ProductID != 0 {
+		productID := int(c.ProductID)
+		item.ProductID = &productID
+	}
+	return item
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+func pricingRuleToModel(rule *product.PricingRule) *model.PricingRule {
+	return &model.PricingRule{
+		ID:           int(rule.ID),
+		Type:         rule.Type,
+		MinQuantity:  rule.MinQuantity,
+		UnitPrice:    rule.UnitPrice,
+		BuyQuantity:  rule.BuyQuantity,
+		FreeQuantity: rule.FreeQuantity,
+		Label:        rule.Label,
+	}
+}
+
+func appliedDealToModel(deal *product.AppliedDeal) *model.AppliedDeal {
+	if deal == nil {
+		return nil
+	}
+	return &model.AppliedDeal{
+		Label:     deal.Label,
+		FreeUnits: deal.FreeUnits,
+		Savings:   deal.Savings,
+		SaleUnits: deal.SaleUnits,
+		EndsAt:    deal.EndsAt,
+	}
+}
+
+// secondsUntil is what sale countdowns show, never below zero.
+func secondsUntil(t time.Time) int {
+	return max(int(time.Until(t).Seconds()), 0)
+}
+
+// remainingToModel maps the uncapped -1 to null.
+func remainingToModel(remaining int) *int {
+	if remaining < 0 {
+		return nil
+	}
+	return &remaining
+}
+
+func saleCampaignToModel(c *product.SaleCampaign) *model.SaleCampaign {
+	now := time.Now()
+	status := c.Status(now)
+	item := &model.SaleCampaign{
+		ID:        int(c.ID),
+		Store:     c.Store,
+		Name:      c.Name,
+		Status:    status,
+		StartsAt:  c.StartsAt,
+		EndsAt:    c.EndsAt,
+		Items:     make([]*model.SaleItem, 0, len(c.Items)),
+		CreatedAt: c.CreatedAt,
+	}
+	switch status {
+	case product.SaleScheduled:
+		item.SecondsLeft = secondsUntil(c.StartsAt)
+	case product.SaleLive:
+		item.SecondsLeft = secondsUntil(c.EndsAt)
+	}
+	for _, saleItem := range c.Items {
+		entry := &model.SaleItem{
+			ID:         int(saleItem.ID),
+			ProductID:  int(saleItem.ProductID),
+			SalePrice:  saleItem.SalePrice,
+			PercentOff: saleItem.PercentOff,
+			UnitCap:    saleItem.UnitCap,
+			UnitsSold:  saleItem.UnitsSold,
+			Remaining:  remainingToModel(saleItem.Remaining()),
+		}
+		if p := saleItem.Product; p != nil {
+			entry.ProductName = p.Name
+			entry.Thumbnail = p.Thumbnail
+			entry.RegularPrice = p.EffectivePrice()
+			entry.SalePrice = saleItem.UnitPrice(p)
+		}
+		item.Items = append(item.Items, entry)
+	}
+	return item
+}
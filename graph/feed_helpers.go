@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func storeAnnouncementToModel(a *store.StoreAnnouncement) *model.StoreAnnouncement {
+	item := &model.StoreAnnouncement{
+		ID:        int(a.ID),
+		StoreID:   int(a.StoreID),
+		Title:     a.Title,
+		Body:      a.Body,
+		CreatedAt: a.CreatedAt,
+	}
+	if a.Store != nil {
+		item.Store = a.Store.Name
+	}
+	if a.Image != "" {
+		item.Image = &a.Image
+	}
+	return item
+}
+
+func feedItemToModel(f *store.FeedItem) *model.FeedItem {
+	item := &model.FeedItem{
+		Cursor:    f.Cursor(),
+		Kind:      f.Kind,
+		Store:     f.Store,
+		CreatedAt: f.CreatedAt,
+	}
+	if f.Product != nil {
+		item.Product = productToModel(f.Product)
+		item.Price = &f.Price
+	}
+	if f.Kind == store.FeedPriceDrop {
+		item.PreviousPrice = &f.PreviousPrice
+	}
+	if f.Announcement != nil {
+		item.Announcement = storeAnnouncementToModel(f.Announcement)
+	}
+	return item
+}
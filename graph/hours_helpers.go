@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func storeHoursToModel(h *store.StoreHours) *model.StoreHours {
+	hours := make([]*model.WeeklyHours, 0, len(h.Hours))
+	for _, w := range h.Hours {
+		hours = append(hours, &model.WeeklyHours{Weekday: int(w.Weekday), Opens: w.Opens, Closes: w.Closes})
+	}
+	item := &model.StoreHours{
+		StoreID:           int(h.StoreID),
+		Hours:             hours,
+		AcceptLaterOrders: h.AcceptLaterOrders,
+	}
+	if h.AwayMessage != "" {
+		item.AwayMessage = &h.AwayMessage
+	}
+	return item
+}
+
+func storeClosureToModel(c *store.StoreClosure) *model.StoreClosure {
+	item := &model.StoreClosure{
+		ID:        int(c.ID),
+		StoreID:   int(c.StoreID),
+		Kind:      c.Kind,
+		StartsAt:  c.StartsAt,
+		EndsAt:    c.EndsAt,
+		CreatedAt: c.CreatedAt,
+	}
+	if c.Message != "" {
+		item.Message = &c.Message
+	}
+	return item
+}
+
+func storeAvailabilityToModel(a *store.StoreAvailability) *model.StoreAvailability {
+	item := &model.StoreAvailability{
+		Store:         a.Store,
+		Open:          a.Open,
+		ReopensAt:     a.ReopensAt,
+		AcceptsOrders: a.AcceptsOrders,
+	}
+	if a.Reason != "" {
+		item.Reason = &a.Reason
+	}
+	if a.Message != "" {
+		item.Message = &a.Message
+	}
+	return item
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
+)
+
+func identityVerificationToModel(v *kyc.Verification) *model.IdentityVerification {
+	item := &model.IdentityVerification{
+		ID:                int(v.ID),
+		UserID:            int(v.UserID),
+		IDType:            v.IDType,
+		IDNumberLast4:     v.IDNumberLast4,
+		FirstName:         v.FirstName,
+		LastName:          v.LastName,
+		DocumentURL:       v.DocumentURL,
+		Status:            v.Status,
+		Provider:          v.Provider,
+		ProviderReference: v.ProviderRef,
+		SubmittedAt:       v.SubmittedAt,
+		ReviewedAt:        v.ReviewedAt,
+	}
+	if v.RejectionReason != "" {
+		item.RejectionReason = &v.RejectionReason
+	}
+	return item
+}
+
+func verificationStatusToModel(s *kyc.Status) *model.VerificationStatus {
+	limits := &model.VerificationLimits{}
+	if s.Limits.MonthlySales > 0 {
+		limits.MonthlySales = &s.Limits.MonthlySales
+	}
+	if s.Limits.SingleWithdrawal > 0 {
+		limits.SingleWithdrawal = &s.Limits.SingleWithdrawal
+	}
+	if s.Limits.Listings > 0 {
+		limits.Listings = &s.Limits.Listings
+	}
+	item := &model.VerificationStatus{
+		Tier:         s.Tier,
+		Limits:       limits,
+		MonthlySales: s.MonthlySales,
+		Listings:     s.Listings,
+	}
+	// The record exists from phone verification on, but an ID may not have
+	// been submitted yet
+	if s.Verification != nil && s.Verification.Status != "" {
+		item.Identity = identityVerificationToModel(s.Verification)
+	}
+	return item
+}
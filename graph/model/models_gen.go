@@ -23,6 +23,15 @@ type Account struct {
 	Assigned      bool         `json:"assigned"`
 }
 
+type ActiveSale struct {
+	CampaignID  int       `json:"campaignId"`
+	Name        string    `json:"name"`
+	SalePrice   float64   `json:"salePrice"`
+	EndsAt      time.Time `json:"endsAt"`
+	SecondsLeft int       `json:"secondsLeft"`
+	Remaining   *int      `json:"remaining,omitempty"`
+}
+
 type AdminWithdrawal struct {
 	ID            string  `json:"id"`
 	SellerName    string  `json:"sellerName"`
@@ -35,12 +44,57 @@ type AdminWithdrawal struct {
 	StoreID       string  `json:"storeID"`
 }
 
+type AppliedDeal struct {
+	Label     string     `json:"label"`
+	FreeUnits int        `json:"freeUnits"`
+	Savings   float64    `json:"savings"`
+	SaleUnits int        `json:"saleUnits"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+}
+
 type Bank struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	Slug string `json:"slug"`
 }
 
+type Booking struct {
+	ID           int       `json:"id"`
+	ProductID    int       `json:"productId"`
+	ProductName  string    `json:"productName"`
+	Store        string    `json:"store"`
+	UserID       int       `json:"userId"`
+	OrderUUID    string    `json:"orderUuid"`
+	Seats        int       `json:"seats"`
+	StartsAt     time.Time `json:"startsAt"`
+	EndsAt       time.Time `json:"endsAt"`
+	Status       string    `json:"status"`
+	Reschedules  int       `json:"reschedules"`
+	CancelledBy  *string   `json:"cancelledBy,omitempty"`
+	CancelReason *string   `json:"cancelReason,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type BookingSlot struct {
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Capacity  int       `json:"capacity"`
+	Available int       `json:"available"`
+}
+
+type BundleItem struct {
+	ProductID int    `json:"productId"`
+	Name      string `json:"name"`
+	Thumbnail string `json:"thumbnail"`
+	Quantity  int    `json:"quantity"`
+	Available int    `json:"available"`
+}
+
+type BundleItemInput struct {
+	ProductID int `json:"productId"`
+	Quantity  int `json:"quantity"`
+}
+
 type BundleVariation struct {
 	VariationCode   string `json:"variationCode"`
 	Name            string `json:"name"`
@@ -57,18 +111,45 @@ type Cart struct {
 }
 
 type CartItem struct {
-	Product  *Product `json:"product"`
-	Quantity int      `json:"quantity"`
+	Product   *Product     `json:"product"`
+	Quantity  int          `json:"quantity"`
+	Subtotal  float64      `json:"subtotal"`
+	Deal      *AppliedDeal `json:"deal,omitempty"`
+	SlotStart *time.Time   `json:"slotStart,omitempty"`
 }
 
 type Category struct {
-	ID            string         `json:"id"`
-	Name          string         `json:"name"`
-	Slug          string         `json:"slug"`
-	Description   *string        `json:"description,omitempty"`
-	Image         *string        `json:"image,omitempty"`
-	Type          string         `json:"type"`
-	Subcategories []*SubCategory `json:"subcategories,omitempty"`
+	ID            string               `json:"id"`
+	Name          string               `json:"name"`
+	Slug          string               `json:"slug"`
+	Description   *string              `json:"description,omitempty"`
+	Image         *string              `json:"image,omitempty"`
+	Type          string               `json:"type"`
+	ParentID      *int                 `json:"parentId,omitempty"`
+	Depth         int                  `json:"depth"`
+	Path          string               `json:"path"`
+	Children      []*Category          `json:"children"`
+	Attributes    []*CategoryAttribute `json:"attributes"`
+	Subcategories []*SubCategory       `json:"subcategories,omitempty"`
+}
+
+type CategoryAttribute struct {
+	Name      string   `json:"name"`
+	Label     string   `json:"label"`
+	Type      string   `json:"type"`
+	Options   []string `json:"options"`
+	Required  bool     `json:"required"`
+	Facet     bool     `json:"facet"`
+	Inherited bool     `json:"inherited"`
+}
+
+type CategoryAttributeInput struct {
+	Name     string   `json:"name"`
+	Label    *string  `json:"label,omitempty"`
+	Type     *string  `json:"type,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Required *bool    `json:"required,omitempty"`
+	Facet    *bool    `json:"facet,omitempty"`
 }
 
 type Chat struct {
@@ -155,17 +236,36 @@ type DepositTransaction struct {
 	Description string    `json:"description"`
 }
 
+type DownloadEvent struct {
+	ID         int       `json:"id"`
+	DownloadID string    `json:"downloadId"`
+	UserID     string    `json:"userId"`
+	Action     string    `json:"action"`
+	Reason     *string   `json:"reason,omitempty"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type DownloadLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Remaining int       `json:"remaining"`
+}
+
 type Downloads struct {
-	ID        string     `json:"id"`
-	Thumbnail string     `json:"thumbnail"`
-	Name      string     `json:"name"`
-	Price     float64    `json:"price"`
-	Discount  int        `json:"discount"`
-	UUID      string     `json:"UUID"`
-	File      string     `json:"file"`
-	Users     []*string  `json:"users"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ID            string     `json:"id"`
+	Thumbnail     string     `json:"thumbnail"`
+	Name          string     `json:"name"`
+	Price         float64    `json:"price"`
+	Discount      int        `json:"discount"`
+	UUID          string     `json:"UUID"`
+	File          string     `json:"file"`
+	Users         []*string  `json:"users"`
+	DownloadCount int        `json:"download_count"`
+	MaxDownloads  int        `json:"max_downloads"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
 }
 
 type EmailSubscriptionResponse struct {
@@ -173,6 +273,43 @@ type EmailSubscriptionResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
+type Facet struct {
+	Name   string        `json:"name"`
+	Label  string        `json:"label"`
+	Values []*FacetValue `json:"values"`
+}
+
+type FacetFilterInput struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type FeedItem struct {
+	Cursor        string             `json:"cursor"`
+	Kind          string             `json:"kind"`
+	Store         string             `json:"store"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	Product       *Product           `json:"product,omitempty"`
+	PreviousPrice *float64           `json:"previousPrice,omitempty"`
+	Price         *float64           `json:"price,omitempty"`
+	Announcement  *StoreAnnouncement `json:"announcement,omitempty"`
+}
+
+type FollowerFeed struct {
+	Items      []*FeedItem `json:"items"`
+	NextCursor *string     `json:"nextCursor,omitempty"`
+}
+
+type FulfilmentChoiceInput struct {
+	Store  string `json:"store"`
+	Method string `json:"method"`
+}
+
 type HandledProducts struct {
 	UserID           int      `json:"userId"`
 	ProductID        int      `json:"productId"`
@@ -184,6 +321,40 @@ type HandledProducts struct {
 	ProductQuantity  *int     `json:"productQuantity,omitempty"`
 }
 
+type IdentityVerification struct {
+	ID                int        `json:"id"`
+	UserID            int        `json:"userId"`
+	IDType            string     `json:"idType"`
+	IDNumberLast4     string     `json:"idNumberLast4"`
+	FirstName         string     `json:"firstName"`
+	LastName          string     `json:"lastName"`
+	DocumentURL       string     `json:"documentUrl"`
+	Status            string     `json:"status"`
+	Provider          string     `json:"provider"`
+	ProviderReference string     `json:"providerReference"`
+	RejectionReason   *string    `json:"rejectionReason,omitempty"`
+	SubmittedAt       *time.Time `json:"submittedAt,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewedAt,omitempty"`
+}
+
+type IdentityVerificationInput struct {
+	IDType      string  `json:"idType"`
+	IDNumber    string  `json:"idNumber"`
+	FirstName   string  `json:"firstName"`
+	LastName    string  `json:"lastName"`
+	DateOfBirth *string `json:"dateOfBirth,omitempty"`
+	DocumentURL string  `json:"documentUrl"`
+}
+
+type ImageRendition struct {
+	Size   string `json:"size"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Bytes  int    `json:"bytes"`
+}
+
 type Invoice struct {
 	Customer        *InvoiceCustomer `json:"customer"`
 	DueDate         string           `json:"due_date"`
@@ -247,6 +418,18 @@ type LoginRes struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type MediaImage struct {
+	ID          int               `json:"id"`
+	OwnerType   string            `json:"ownerType"`
+	OwnerID     int               `json:"ownerId"`
+	Role        string            `json:"role"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	OriginalURL string            `json:"originalUrl"`
+	Renditions  []*ImageRendition `json:"renditions"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
 type Message struct {
 	ID        string  `json:"id"`
 	ChatID    string  `json:"chat_id"`
@@ -283,10 +466,11 @@ type MessageUserInput struct {
 }
 
 type ModifyCartItemInput struct {
-	ProductID   *string `json:"productId,omitempty"`
-	ProductName *string `json:"productName,omitempty"`
-	Quantity    int     `json:"quantity"`
-	User        int     `json:"user"`
+	ProductID   *string    `json:"productId,omitempty"`
+	ProductName *string    `json:"productName,omitempty"`
+	Quantity    int        `json:"quantity"`
+	User        int        `json:"user"`
+	SlotStart   *time.Time `json:"slotStart,omitempty"`
 }
 
 type Mutation struct {
@@ -297,6 +481,8 @@ type NewCategory struct {
 	Slug        string  `json:"slug"`
 	Description *string `json:"description,omitempty"`
 	Image       *string `json:"image,omitempty"`
+	Type        *string `json:"type,omitempty"`
+	ParentID    *int    `json:"parentId,omitempty"`
 }
 
 type NewReview struct {
@@ -342,6 +528,16 @@ type NewVerifyOtp struct {
 	Attempts int     `json:"attempts"`
 }
 
+type Notification struct {
+	ID        int       `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Link      *string   `json:"link,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type Order struct {
 	ID              int              `json:"id"`
 	Customer        string           `json:"customer"`
@@ -363,18 +559,39 @@ type Order struct {
 	CreatedAt       *time.Time       `json:"createdAt,omitempty"`
 }
 
+type OrderDispute struct {
+	ID         int        `json:"id"`
+	OrderID    string     `json:"orderId"`
+	StoreID    int        `json:"storeId"`
+	BuyerID    int        `json:"buyerId"`
+	Reason     string     `json:"reason"`
+	Details    *string    `json:"details,omitempty"`
+	Status     string     `json:"status"`
+	Resolution *string    `json:"resolution,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type OrderDisputeInput struct {
+	OrderID string  `json:"orderId"`
+	StoreID int     `json:"storeId"`
+	Reason  string  `json:"reason"`
+	Details *string `json:"details,omitempty"`
+}
+
 type PaymentData struct {
-	StoresID       []int           `json:"storesID,omitempty"`
-	Status         *string         `json:"status,omitempty"`
-	Product        []*ProductInput `json:"product,omitempty"`
-	UserID         string          `json:"userID"`
-	Customer       *CustomerInput  `json:"customer,omitempty"`
-	TrtRef         *string         `json:"trtRef,omitempty"`
-	Amount         *float64        `json:"amount,omitempty"`
-	UUID           *string         `json:"UUID,omitempty"`
-	PaymentGateway *string         `json:"paymentGateway,omitempty"`
-	CreatedAt      *time.Time      `json:"createdAt,omitempty"`
-	UpdatedAt      *time.Time      `json:"updatedAt,omitempty"`
+	StoresID       []int                    `json:"storesID,omitempty"`
+	Fulfilment     []*FulfilmentChoiceInput `json:"fulfilment,omitempty"`
+	Status         *string                  `json:"status,omitempty"`
+	Product        []*ProductInput          `json:"product,omitempty"`
+	UserID         string                   `json:"userID"`
+	Customer       *CustomerInput           `json:"customer,omitempty"`
+	TrtRef         *string                  `json:"trtRef,omitempty"`
+	Amount         *float64                 `json:"amount,omitempty"`
+	UUID           *string                  `json:"UUID,omitempty"`
+	PaymentGateway *string                  `json:"paymentGateway,omitempty"`
+	CreatedAt      *time.Time               `json:"createdAt,omitempty"`
+	UpdatedAt      *time.Time               `json:"updatedAt,omitempty"`
 }
 
 type PaymentDetails struct {
@@ -436,44 +653,123 @@ type PaystackDVAResponse struct {
 	Data    *PaystackDVAData `json:"data"`
 }
 
+type PriceHistoryEntry struct {
+	Price          float64   `json:"price"`
+	Discount       float64   `json:"discount"`
+	EffectivePrice float64   `json:"effectivePrice"`
+	ChangedAt      time.Time `json:"changedAt"`
+}
+
+type PricingRule struct {
+	ID           int     `json:"id"`
+	Type         string  `json:"type"`
+	MinQuantity  int     `json:"minQuantity"`
+	UnitPrice    float64 `json:"unitPrice"`
+	BuyQuantity  int     `json:"buyQuantity"`
+	FreeQuantity int     `json:"freeQuantity"`
+	Label        string  `json:"label"`
+}
+
+type PricingRuleInput struct {
+	Type         string   `json:"type"`
+	MinQuantity  *int     `json:"minQuantity,omitempty"`
+	UnitPrice    *float64 `json:"unitPrice,omitempty"`
+	BuyQuantity  *int     `json:"buyQuantity,omitempty"`
+	FreeQuantity *int     `json:"freeQuantity,omitempty"`
+	Label        *string  `json:"label,omitempty"`
+}
+
 type Product struct {
-	ID              int        `json:"id"`
-	Name            string     `json:"name"`
-	Price           float64    `json:"price"`
-	Description     string     `json:"description"`
-	Discount        float64    `json:"discount"`
-	Image           []string   `json:"image,omitempty"`
-	Slug            string     `json:"slug"`
-	Quantity        int        `json:"quantity"`
-	Status          bool       `json:"status"`
-	Thumbnail       string     `json:"thumbnail"`
-	Store           string     `json:"store"`
-	Category        string     `json:"category"`
-	Subcategory     string     `json:"subcategory"`
-	AlwaysAvailable *bool      `json:"alwaysAvailable,omitempty"`
-	Type            *string    `json:"type,omitempty"`
-	File            *string    `json:"file,omitempty"`
-	UnitsSold       int        `json:"unitsSold"`
-	CreatedAt       *time.Time `json:"createdAt,omitempty"`
+	ID               int                  `json:"id"`
+	Name             string               `json:"name"`
+	Price            float64              `json:"price"`
+	Description      string               `json:"description"`
+	Discount         float64              `json:"discount"`
+	Image            []string             `json:"image,omitempty"`
+	Slug             string               `json:"slug"`
+	Sku              *string              `json:"sku,omitempty"`
+	Quantity         int                  `json:"quantity"`
+	Status           bool                 `json:"status"`
+	Thumbnail        string               `json:"thumbnail"`
+	Store            string               `json:"store"`
+	Category         string               `json:"category"`
+	Subcategory      string               `json:"subcategory"`
+	CategoryID       *int                 `json:"categoryId,omitempty"`
+	Attributes       []*ProductAttribute  `json:"attributes"`
+	AlwaysAvailable  *bool                `json:"alwaysAvailable,omitempty"`
+	Type             *string              `json:"type,omitempty"`
+	File             *string              `json:"file,omitempty"`
+	UnitsSold        int                  `json:"unitsSold"`
+	CreatedAt        *time.Time           `json:"createdAt,omitempty"`
+	ModerationStatus *string              `json:"moderationStatus,omitempty"`
+	ModerationReason *string              `json:"moderationReason,omitempty"`
+	RatingAverage    float64              `json:"ratingAverage"`
+	ReviewCount      int                  `json:"reviewCount"`
+	PriceHistory     []*PriceHistoryEntry `json:"priceHistory"`
+	Questions        []*ProductQuestion   `json:"questions"`
+	IsBundle         bool                 `json:"isBundle"`
+	BundleItems      []*BundleItem        `json:"bundleItems"`
+	PricingRules     []*PricingRule       `json:"pricingRules"`
+	Sale             *ActiveSale          `json:"sale,omitempty"`
+	Availability     *ServiceAvailability `json:"availability,omitempty"`
+	StorePolicy      *StorePolicy         `json:"storePolicy,omitempty"`
+}
+
+type ProductAnswer struct {
+	ID            int       `json:"id"`
+	QuestionID    int       `json:"questionId"`
+	Author        string    `json:"author"`
+	Body          string    `json:"body"`
+	IsSeller      bool      `json:"isSeller"`
+	VerifiedBuyer bool      `json:"verifiedBuyer"`
+	Status        string    `json:"status"`
+	Upvotes       int       `json:"upvotes"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type ProductAttribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ProductAttributeInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
 type ProductInput struct {
-	Name            string        `json:"name"`
-	ID              *string       `json:"id,omitempty"`
-	Description     string        `json:"description"`
-	File            string        `json:"file"`
-	Price           float64       `json:"price"`
-	Discount        float64       `json:"discount"`
-	Thumbnail       string        `json:"thumbnail"`
-	Image           []string      `json:"image"`
-	Quantity        int           `json:"quantity"`
-	Variant         []*NewVariant `json:"variant,omitempty"`
-	Review          []*NewReview  `json:"review,omitempty"`
-	Store           string        `json:"store"`
-	Status          bool          `json:"status"`
-	Category        int           `json:"category"`
-	Subcategory     string        `json:"subcategory"`
-	AlwaysAvailable bool          `json:"always_available"`
+	Name            string                   `json:"name"`
+	ID              *string                  `json:"id,omitempty"`
+	Description     string                   `json:"description"`
+	File            string                   `json:"file"`
+	Price           float64                  `json:"price"`
+	Discount        float64                  `json:"discount"`
+	Thumbnail       string                   `json:"thumbnail"`
+	Image           []string                 `json:"image"`
+	Quantity        int                      `json:"quantity"`
+	Variant         []*NewVariant            `json:"variant,omitempty"`
+	Review          []*NewReview             `json:"review,omitempty"`
+	Store           string                   `json:"store"`
+	Status          bool                     `json:"status"`
+	Category        int                      `json:"category"`
+	Subcategory     *string                  `json:"subcategory,omitempty"`
+	Attributes      []*ProductAttributeInput `json:"attributes,omitempty"`
+	AlwaysAvailable bool                     `json:"always_available"`
+	Sku             *string                  `json:"sku,omitempty"`
+}
+
+type ProductModeration struct {
+	ID         int        `json:"id"`
+	ProductID  int        `json:"productId"`
+	Product    *Product   `json:"product,omitempty"`
+	Store      string     `json:"store"`
+	Trigger    string     `json:"trigger"`
+	Flags      []string   `json:"flags"`
+	Status     string     `json:"status"`
+	Reason     *string    `json:"reason,omitempty"`
+	ReviewerID *int       `json:"reviewerId,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
 }
 
 type ProductPaginationData struct {
@@ -485,6 +781,37 @@ type ProductPaginationData struct {
 	PrevPage    int        `json:"prev_page"`
 }
 
+type ProductQAQueue struct {
+	Questions []*ProductQuestion `json:"questions"`
+	Answers   []*ProductAnswer   `json:"answers"`
+}
+
+type ProductQuestion struct {
+	ID          int              `json:"id"`
+	ProductID   int              `json:"productId"`
+	Author      string           `json:"author"`
+	Body        string           `json:"body"`
+	Status      string           `json:"status"`
+	Upvotes     int              `json:"upvotes"`
+	AnswerCount int              `json:"answerCount"`
+	Answers     []*ProductAnswer `json:"answers"`
+	CreatedAt   time.Time        `json:"createdAt"`
+}
+
+type ProductSalesStat struct {
+	ProductID   int     `json:"productId"`
+	ProductName string  `json:"productName"`
+	Revenue     float64 `json:"revenue"`
+	Units       int     `json:"units"`
+	Orders      int     `json:"orders"`
+}
+
+type ProductViewStat struct {
+	Day           time.Time `json:"day"`
+	Views         int       `json:"views"`
+	UniqueViewers int       `json:"uniqueViewers"`
+}
+
 type PurchasedOrder struct {
 	CartID          int               `json:"cart_id"`
 	Coupon          string            `json:"coupon"`
@@ -503,20 +830,38 @@ type PurchasedOrder struct {
 	CreatedAt       time.Time         `json:"created_at"`
 }
 
+type QRCode struct {
+	Kind    string `json:"kind"`
+	ID      int    `json:"id"`
+	ScanURL string `json:"scanUrl"`
+	PageURL string `json:"pageUrl"`
+	PngURL  string `json:"pngUrl"`
+	SVGURL  string `json:"svgUrl"`
+}
+
 type Query struct {
 }
 
 type Review struct {
-	StoreID   int          `json:"store_id"`
-	ProductID int          `json:"product_id"`
-	OrderID   string       `json:"order_id"`
-	Buyer     *ReviewBuyer `json:"buyer,omitempty"`
-	SellerID  int          `json:"seller_id"`
-	Message   *string      `json:"message,omitempty"`
-	Username  string       `json:"username"`
-	Rating    float64      `json:"rating"`
-	CreatedAt *time.Time   `json:"created_at,omitempty"`
-	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+	ID               int          `json:"id"`
+	StoreID          int          `json:"store_id"`
+	ProductID        int          `json:"product_id"`
+	ProductName      string       `json:"product_name"`
+	ProductThumbnail string       `json:"product_thumbnail"`
+	OrderID          string       `json:"order_id"`
+	Buyer            *ReviewBuyer `json:"buyer,omitempty"`
+	BuyerID          int          `json:"buyer_id"`
+	SellerID         int          `json:"seller_id"`
+	Message          *string      `json:"message,omitempty"`
+	Username         string       `json:"username"`
+	Rating           float64      `json:"rating"`
+	Photos           []string     `json:"photos"`
+	SellerReply      *string      `json:"seller_reply,omitempty"`
+	SellerRepliedAt  *time.Time   `json:"seller_replied_at,omitempty"`
+	HelpfulCount     int          `json:"helpful_count"`
+	Verified         bool         `json:"verified"`
+	CreatedAt        *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt        *time.Time   `json:"updated_at,omitempty"`
 }
 
 type ReviewBuyer struct {
@@ -532,13 +877,15 @@ type ReviewBuyerInput struct {
 }
 
 type ReviewInput struct {
-	StoreID   int               `json:"store_id"`
+	StoreID   *int              `json:"store_id,omitempty"`
 	ProductID int               `json:"product_id"`
 	OrderID   string            `json:"order_id"`
 	Buyer     *ReviewBuyerInput `json:"buyer,omitempty"`
-	SellerID  int               `json:"seller_id"`
-	BuyerID   int               `json:"buyer_id"`
+	SellerID  *int              `json:"seller_id,omitempty"`
+	BuyerID   *int              `json:"buyer_id,omitempty"`
 	Rating    float64           `json:"rating"`
+	Message   *string           `json:"message,omitempty"`
+	Photos    []string          `json:"photos,omitempty"`
 	CreatedAt *time.Time        `json:"created_at,omitempty"`
 	UpdatedAt *time.Time        `json:"updated_at,omitempty"`
 }
@@ -549,6 +896,93 @@ type ReviewProduct struct {
 	Comment  string `json:"comment"`
 }
 
+type SaleCampaign struct {
+	ID          int         `json:"id"`
+	Store       string      `json:"store"`
+	Name        string      `json:"name"`
+	Status      string      `json:"status"`
+	StartsAt    time.Time   `json:"startsAt"`
+	EndsAt      time.Time   `json:"endsAt"`
+	SecondsLeft int         `json:"secondsLeft"`
+	Items       []*SaleItem `json:"items"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+type SaleCampaignInput struct {
+	StoreID  int              `json:"storeId"`
+	Name     string           `json:"name"`
+	StartsAt time.Time        `json:"startsAt"`
+	EndsAt   time.Time        `json:"endsAt"`
+	Items    []*SaleItemInput `json:"items"`
+}
+
+type SaleDeal struct {
+	Product      *Product  `json:"product"`
+	CampaignID   int       `json:"campaignId"`
+	CampaignName string    `json:"campaignName"`
+	SalePrice    float64   `json:"salePrice"`
+	RegularPrice float64   `json:"regularPrice"`
+	EndsAt       time.Time `json:"endsAt"`
+	SecondsLeft  int       `json:"secondsLeft"`
+	Remaining    *int      `json:"remaining,omitempty"`
+}
+
+type SaleItem struct {
+	ID           int     `json:"id"`
+	ProductID    int     `json:"productId"`
+	ProductName  string  `json:"productName"`
+	Thumbnail    string  `json:"thumbnail"`
+	RegularPrice float64 `json:"regularPrice"`
+	SalePrice    float64 `json:"salePrice"`
+	PercentOff   float64 `json:"percentOff"`
+	UnitCap      int     `json:"unitCap"`
+	UnitsSold    int     `json:"unitsSold"`
+	Remaining    *int    `json:"remaining,omitempty"`
+}
+
+type SaleItemInput struct {
+	ProductID  int      `json:"productId"`
+	SalePrice  *float64 `json:"salePrice,omitempty"`
+	PercentOff *float64 `json:"percentOff,omitempty"`
+	UnitCap    *int     `json:"unitCap,omitempty"`
+}
+
+type SellerScorecard struct {
+	Badge              string     `json:"badge"`
+	Score              float64    `json:"score"`
+	Orders             int        `json:"orders"`
+	AvgShipHours       *float64   `json:"avgShipHours,omitempty"`
+	CancellationRate   float64    `json:"cancellationRate"`
+	DisputeRate        float64    `json:"disputeRate"`
+	AvgResponseMinutes *float64   `json:"avgResponseMinutes,omitempty"`
+	Rating             *float64   `json:"rating,omitempty"`
+	Reviews            int        `json:"reviews"`
+	ComputedAt         *time.Time `json:"computedAt,omitempty"`
+}
+
+type ServiceAvailability struct {
+	ProductID             int            `json:"productId"`
+	Hours                 []*WeeklyHours `json:"hours"`
+	SlotMinutes           int            `json:"slotMinutes"`
+	Capacity              int            `json:"capacity"`
+	MinNoticeHours        int            `json:"minNoticeHours"`
+	MaxAdvanceDays        int            `json:"maxAdvanceDays"`
+	CancelBeforeHours     int            `json:"cancelBeforeHours"`
+	RescheduleBeforeHours int            `json:"rescheduleBeforeHours"`
+	MaxReschedules        int            `json:"maxReschedules"`
+}
+
+type ServiceAvailabilityInput struct {
+	Hours                 []*WeeklyHoursInput `json:"hours"`
+	SlotMinutes           int                 `json:"slotMinutes"`
+	Capacity              *int                `json:"capacity,omitempty"`
+	MinNoticeHours        *int                `json:"minNoticeHours,omitempty"`
+	MaxAdvanceDays        *int                `json:"maxAdvanceDays,omitempty"`
+	CancelBeforeHours     *int                `json:"cancelBeforeHours,omitempty"`
+	RescheduleBeforeHours *int                `json:"rescheduleBeforeHours,omitempty"`
+	MaxReschedules        *int                `json:"maxReschedules,omitempty"`
+}
+
 type Skynet struct {
 	ID            string  `json:"id"`
 	UserID        *string `json:"user_id,omitempty"`
@@ -597,6 +1031,13 @@ type SplitConfig struct {
 	Subaccount string `json:"Subaccount"`
 }
 
+type StaffInviteInput struct {
+	StoreID int     `json:"storeId"`
+	Email   *string `json:"email,omitempty"`
+	Phone   *string `json:"phone,omitempty"`
+	Role    string  `json:"role"`
+}
+
 type Store struct {
 	ID                 string             `json:"id"`
 	Link               string             `json:"link"`
@@ -618,6 +1059,122 @@ type Store struct {
 	Visitors           []string           `json:"visitors"`
 	Accounts           []*WithdrawAccount `json:"accounts,omitempty"`
 	MaintenanceMode    bool               `json:"maintenance_mode"`
+	RatingAverage      float64            `json:"rating_average"`
+	ReviewCount        int                `json:"review_count"`
+	ActiveSales        []*SaleCampaign    `json:"activeSales"`
+	VerificationTier   string             `json:"verificationTier"`
+	Verified           bool               `json:"verified"`
+	Availability       *StoreAvailability `json:"availability"`
+	Scorecard          *SellerScorecard   `json:"scorecard"`
+	Policy             *StorePolicy       `json:"policy"`
+}
+
+type StoreAnalytics struct {
+	From               time.Time               `json:"from"`
+	To                 time.Time               `json:"to"`
+	Granularity        string                  `json:"granularity"`
+	Revenue            float64                 `json:"revenue"`
+	Orders             int                     `json:"orders"`
+	Units              int                     `json:"units"`
+	AverageOrderValue  float64                 `json:"averageOrderValue"`
+	Views              int                     `json:"views"`
+	UniqueVisitors     int                     `json:"uniqueVisitors"`
+	ConversionRate     float64                 `json:"conversionRate"`
+	Customers          int                     `json:"customers"`
+	RepeatCustomers    int                     `json:"repeatCustomers"`
+	RepeatCustomerRate float64                 `json:"repeatCustomerRate"`
+	Series             []*StoreAnalyticsBucket `json:"series"`
+	TopByRevenue       []*ProductSalesStat     `json:"topByRevenue"`
+	TopByUnits         []*ProductSalesStat     `json:"topByUnits"`
+	TrafficSources     []*TrafficSource        `json:"trafficSources"`
+	QRScans            int                     `json:"qrScans"`
+}
+
+type StoreAnalyticsBucket struct {
+	Start          time.Time `json:"start"`
+	Revenue        float64   `json:"revenue"`
+	Orders         int       `json:"orders"`
+	Units          int       `json:"units"`
+	Views          int       `json:"views"`
+	UniqueVisitors int       `json:"uniqueVisitors"`
+	ConversionRate float64   `json:"conversionRate"`
+}
+
+type StoreAnnouncement struct {
+	ID        int       `json:"id"`
+	StoreID   int       `json:"storeId"`
+	Store     string    `json:"store"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Image     *string   `json:"image,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type StoreAnnouncementInput struct {
+	StoreID int     `json:"storeId"`
+	Title   string  `json:"title"`
+	Body    string  `json:"body"`
+	Image   *string `json:"image,omitempty"`
+}
+
+type StoreAvailability struct {
+	Store         string     `json:"store"`
+	Open          bool       `json:"open"`
+	Reason        *string    `json:"reason,omitempty"`
+	Message       *string    `json:"message,omitempty"`
+	ReopensAt     *time.Time `json:"reopensAt,omitempty"`
+	AcceptsOrders bool       `json:"acceptsOrders"`
+}
+
+type StoreCheckoutCheck struct {
+	Store    string       `json:"store"`
+	Method   string       `json:"method"`
+	Subtotal float64      `json:"subtotal"`
+	Policy   *StorePolicy `json:"policy"`
+	Problem  *string      `json:"problem,omitempty"`
+}
+
+type StoreCloseout struct {
+	StoreID          int        `json:"storeId"`
+	StoreName        string     `json:"storeName"`
+	Status           string     `json:"status"`
+	Reason           *string    `json:"reason,omitempty"`
+	ProductsArchived int        `json:"productsArchived"`
+	FinalPayout      float64    `json:"finalPayout"`
+	RetainUntil      *time.Time `json:"retainUntil,omitempty"`
+	ClosedAt         *time.Time `json:"closedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+type StoreCloseoutChecklist struct {
+	StoreID            int            `json:"storeId"`
+	CanClose           bool           `json:"canClose"`
+	Blockers           []string       `json:"blockers"`
+	OpenOrders         []string       `json:"openOrders"`
+	UpcomingBookings   int            `json:"upcomingBookings"`
+	PendingEarnings    float64        `json:"pendingEarnings"`
+	PendingWithdrawals int            `json:"pendingWithdrawals"`
+	OpenDisputes       int            `json:"openDisputes"`
+	Balance            float64        `json:"balance"`
+	Closeout           *StoreCloseout `json:"closeout,omitempty"`
+}
+
+type StoreClosure struct {
+	ID        int       `json:"id"`
+	StoreID   int       `json:"storeId"`
+	Kind      string    `json:"kind"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Message   *string   `json:"message,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type StoreClosureInput struct {
+	StoreID  int       `json:"storeId"`
+	Kind     string    `json:"kind"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	Message  *string   `json:"message,omitempty"`
 }
 
 type StoreCustomer struct {
@@ -626,6 +1183,42 @@ type StoreCustomer struct {
 	Address string `json:"address"`
 }
 
+type StoreCustomerFilter struct {
+	Segment *string `json:"segment,omitempty"`
+	Tag     *string `json:"tag,omitempty"`
+	Search  *string `json:"search,omitempty"`
+	Sort    *string `json:"sort,omitempty"`
+	Limit   *int    `json:"limit,omitempty"`
+	Offset  *int    `json:"offset,omitempty"`
+}
+
+type StoreCustomerList struct {
+	Total     int                     `json:"total"`
+	Customers []*StoreCustomerProfile `json:"customers"`
+}
+
+type StoreCustomerNote struct {
+	ID        int       `json:"id"`
+	StoreID   int       `json:"storeId"`
+	UserID    int       `json:"userId"`
+	Body      string    `json:"body"`
+	AuthorID  int       `json:"authorId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type StoreCustomerProfile struct {
+	UserID        int       `json:"userId"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Phone         string    `json:"phone"`
+	Orders        int       `json:"orders"`
+	LifetimeSpend float64   `json:"lifetimeSpend"`
+	FirstPurchase time.Time `json:"firstPurchase"`
+	LastPurchase  time.Time `json:"lastPurchase"`
+	Tags          []string  `json:"tags"`
+	Segments      []string  `json:"segments"`
+}
+
 type StoreEarnings struct {
 	ID              string    `json:"id"`
 	StoreID         int       `json:"storeId"`
@@ -657,6 +1250,25 @@ type StoreFollowerInput struct {
 	Action        string `json:"action"`
 }
 
+type StoreHandleCheck struct {
+	Handle    string  `json:"handle"`
+	Available bool    `json:"available"`
+	Reason    *string `json:"reason,omitempty"`
+}
+
+type StoreHours struct {
+	StoreID           int            `json:"storeId"`
+	Hours             []*WeeklyHours `json:"hours"`
+	AwayMessage       *string        `json:"awayMessage,omitempty"`
+	AcceptLaterOrders bool           `json:"acceptLaterOrders"`
+}
+
+type StoreHoursInput struct {
+	Hours             []*WeeklyHoursInput `json:"hours"`
+	AwayMessage       *string             `json:"awayMessage,omitempty"`
+	AcceptLaterOrders *bool               `json:"acceptLaterOrders,omitempty"`
+}
+
 type StoreInput struct {
 	ID                 *string `json:"id,omitempty"`
 	Link               string  `json:"link"`
@@ -673,6 +1285,20 @@ type StoreInput struct {
 	Background         *string `json:"background,omitempty"`
 }
 
+type StoreMember struct {
+	ID              int        `json:"id"`
+	StoreID         int        `json:"storeId"`
+	StoreName       string     `json:"storeName"`
+	UserID          *int       `json:"userId,omitempty"`
+	Role            string     `json:"role"`
+	Email           *string    `json:"email,omitempty"`
+	Phone           *string    `json:"phone,omitempty"`
+	Status          string     `json:"status"`
+	InviteExpiresAt time.Time  `json:"inviteExpiresAt"`
+	AcceptedAt      *time.Time `json:"acceptedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
 type StoreOrder struct {
 	StoreID   string         `json:"store_id"`
 	Product   []*Product     `json:"product,omitempty"`
@@ -698,6 +1324,29 @@ type StorePaginationData struct {
 	Total       int      `json:"total"`
 }
 
+type StorePolicy struct {
+	StoreID           int      `json:"storeId"`
+	FulfilmentMethods []string `json:"fulfilmentMethods"`
+	PickupLocation    *string  `json:"pickupLocation,omitempty"`
+	ProcessingDays    int      `json:"processingDays"`
+	MinOrderValue     float64  `json:"minOrderValue"`
+	ReturnsAccepted   bool     `json:"returnsAccepted"`
+	ReturnWindowDays  *int     `json:"returnWindowDays,omitempty"`
+	ReturnPolicy      *string  `json:"returnPolicy,omitempty"`
+}
+
+type StorePolicyInput struct {
+	Pickup             bool    `json:"pickup"`
+	PickupLocation     *string `json:"pickupLocation,omitempty"`
+	CampusDelivery     bool    `json:"campusDelivery"`
+	NationwideShipping bool    `json:"nationwideShipping"`
+	ProcessingDays     int     `json:"processingDays"`
+	MinOrderValue      float64 `json:"minOrderValue"`
+	ReturnsAccepted    bool    `json:"returnsAccepted"`
+	ReturnWindowDays   *int    `json:"returnWindowDays,omitempty"`
+	ReturnPolicy       *string `json:"returnPolicy,omitempty"`
+}
+
 type StoreProductInput struct {
 	Name      string  `json:"name"`
 	Thumbnail string  `json:"thumbnail"`
@@ -706,6 +1355,29 @@ type StoreProductInput struct {
 	ID        *string `json:"id,omitempty"`
 }
 
+type StorePromoCampaign struct {
+	ID         int       `json:"id"`
+	StoreID    int       `json:"storeId"`
+	Segment    *string   `json:"segment,omitempty"`
+	Tag        *string   `json:"tag,omitempty"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	ProductID  *int      `json:"productId,omitempty"`
+	Email      bool      `json:"email"`
+	Recipients int       `json:"recipients"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type StorePromoInput struct {
+	StoreID   int     `json:"storeId"`
+	Segment   *string `json:"segment,omitempty"`
+	Tag       *string `json:"tag,omitempty"`
+	Title     string  `json:"title"`
+	Message   string  `json:"message"`
+	ProductID *int    `json:"productId,omitempty"`
+	Email     *bool   `json:"email,omitempty"`
+}
+
 type StoreTransactions struct {
 	Deposits    []*DepositTransaction    `json:"deposits"`
 	Withdrawals []*WithdrawalTransaction `json:"withdrawals"`
@@ -736,12 +1408,23 @@ type SubscriptionBundle struct {
 }
 
 type TrackedProduct struct {
-	ID        int     `json:"id"`
-	Name      string  `json:"name"`
-	Thumbnail string  `json:"thumbnail"`
-	Price     float64 `json:"price"`
-	Discount  float64 `json:"discount"`
-	Status    string  `json:"status"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Thumbnail   string     `json:"thumbnail"`
+	Price       float64    `json:"price"`
+	Discount    float64    `json:"discount"`
+	Status      string     `json:"status"`
+	DealLabel   *string    `json:"dealLabel,omitempty"`
+	DealSavings *float64   `json:"dealSavings,omitempty"`
+	BookingID   *int       `json:"bookingId,omitempty"`
+	SlotStart   *time.Time `json:"slotStart,omitempty"`
+	ShipsAfter  *time.Time `json:"shipsAfter,omitempty"`
+	Fulfilment  *string    `json:"fulfilment,omitempty"`
+}
+
+type TrafficSource struct {
+	Source string `json:"source"`
+	Views  int    `json:"views"`
 }
 
 type Transaction struct {
@@ -764,23 +1447,40 @@ type TransactionInput struct {
 	Category string   `json:"category"`
 }
 
+type TrashedProduct struct {
+	Product   *Product  `json:"product"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+type TrashedStore struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Thumbnail    string    `json:"thumbnail"`
+	DeletedAt    time.Time `json:"deletedAt"`
+	PurgeAt      time.Time `json:"purgeAt"`
+	ProductCount int       `json:"productCount"`
+}
+
 type UpdateProductInput struct {
-	Name            *string       `json:"name,omitempty"`
-	ID              string        `json:"id"`
-	Description     *string       `json:"description,omitempty"`
-	File            *string       `json:"file,omitempty"`
-	Price           *float64      `json:"price,omitempty"`
-	Discount        *float64      `json:"discount,omitempty"`
-	Thumbnail       *string       `json:"thumbnail,omitempty"`
-	Image           []string      `json:"image,omitempty"`
-	Quantity        *int          `json:"quantity,omitempty"`
-	Variant         []*NewVariant `json:"variant,omitempty"`
-	Review          []*NewReview  `json:"review,omitempty"`
-	Store           *string       `json:"store,omitempty"`
-	Status          *bool         `json:"status,omitempty"`
-	Category        *int          `json:"category,omitempty"`
-	Subcategory     *string       `json:"subcategory,omitempty"`
-	AlwaysAvailable *bool         `json:"always_available,omitempty"`
+	Name            *string                  `json:"name,omitempty"`
+	ID              string                   `json:"id"`
+	Description     *string                  `json:"description,omitempty"`
+	File            *string                  `json:"file,omitempty"`
+	Price           *float64                 `json:"price,omitempty"`
+	Discount        *float64                 `json:"discount,omitempty"`
+	Thumbnail       *string                  `json:"thumbnail,omitempty"`
+	Image           []string                 `json:"image,omitempty"`
+	Quantity        *int                     `json:"quantity,omitempty"`
+	Variant         []*NewVariant            `json:"variant,omitempty"`
+	Review          []*NewReview             `json:"review,omitempty"`
+	Store           *string                  `json:"store,omitempty"`
+	Status          *bool                    `json:"status,omitempty"`
+	Category        *int                     `json:"category,omitempty"`
+	Subcategory     *string                  `json:"subcategory,omitempty"`
+	Attributes      []*ProductAttributeInput `json:"attributes,omitempty"`
+	AlwaysAvailable *bool                    `json:"always_available,omitempty"`
+	Sku             *string                  `json:"sku,omitempty"`
 }
 
 type UpdateStoreInput struct {
@@ -871,12 +1571,38 @@ type VariantValue struct {
 	Images []string `json:"images,omitempty"`
 }
 
+type VerificationLimits struct {
+	MonthlySales     *float64 `json:"monthlySales,omitempty"`
+	SingleWithdrawal *float64 `json:"singleWithdrawal,omitempty"`
+	Listings         *int     `json:"listings,omitempty"`
+}
+
+type VerificationStatus struct {
+	Tier         string                `json:"tier"`
+	Limits       *VerificationLimits   `json:"limits"`
+	MonthlySales float64               `json:"monthlySales"`
+	Listings     int                   `json:"listings"`
+	Identity     *IdentityVerification `json:"identity,omitempty"`
+}
+
 type VerifyOtp struct {
 	Phone string  `json:"phone"`
 	Code  string  `json:"code"`
 	Email *string `json:"email,omitempty"`
 }
 
+type WeeklyHours struct {
+	Weekday int    `json:"weekday"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
+type WeeklyHoursInput struct {
+	Weekday int    `json:"weekday"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
 type WithdrawalAccount struct {
 	BankName      string  `json:"bankName"`
 	BankCode      string  `json:"bankCode"`
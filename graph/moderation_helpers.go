@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+// notifyModerationDecision tells the owner of a product's store that an admin
+// approved or rejected it. Failures are logged since the decision is already saved.
+func (r *Resolver) notifyModerationDecision(ctx context.Context, p *product.Product) {
+	storeObj, err := store.NewRepository().GetStoreByName(ctx, p.Store)
+	if err != nil {
+		log.Printf("moderation: failed to find store %q for product %d: %v", p.Store, p.ID, err)
+		return
+	}
+
+	n := &notification.Notification{
+		UserID: storeObj.UserID,
+		Type:   notification.TypeProductModeration,
+		Link:   fmt.Sprintf("/product/%d", p.ID),
+	}
+	if p.ModerationStatus == product.ModerationApproved {
+		n.Title = "Your product has been approved"
+		n.Message = fmt.Sprintf("%s is now live on your store.", p.Name)
+	} else {
+		n.Title = "Your product was not approved"
+		n.Message = fmt.Sprintf("%s was rejected: %s", p.Name, p.ModerationReason)
+	}
+
+	notificationHandler := notification.NewHandler(notification.NewService(notification.NewRepository()))
+	if _, err := notificationHandler.Notify(ctx, n, true); err != nil {
+		log.Printf("moderation: failed to notify seller of product %d: %v", p.ID, err)
+	}
+}
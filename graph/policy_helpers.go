@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/cart"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func storePolicyToModel(p *store.StorePolicy) *model.StorePolicy {
+	item := &model.StorePolicy{
+		StoreID:           int(p.StoreID),
+		FulfilmentMethods: p.Methods(),
+		ProcessingDays:    p.ProcessingDays,
+		MinOrderValue:     p.MinOrderValue,
+		ReturnsAccepted:   p.ReturnsAccepted,
+	}
+	if p.PickupLocation != "" {
+		item.PickupLocation = &p.PickupLocation
+	}
+	if p.ReturnsAccepted {
+		item.ReturnWindowDays = &p.ReturnWindowDays
+	}
+	if p.ReturnPolicy != "" {
+		item.ReturnPolicy = &p.ReturnPolicy
+	}
+	return item
+}
+
+func policyCheckToModel(c *cart.PolicyCheck) *model.StoreCheckoutCheck {
+	item := &model.StoreCheckoutCheck{
+		Store:    c.Store,
+		Method:   c.Method,
+		Subtotal: c.Subtotal,
+		Policy:   storePolicyToModel(c.Policy),
+	}
+	if c.Problem != "" {
+		item.Problem = &c.Problem
+	}
+	return item
+}
+
+// fulfilmentChoices maps each store name to the fulfilment method picked for it.
+func fulfilmentChoices(input []*model.FulfilmentChoiceInput) map[string]string {
+	choices := make(map[string]string, len(input))
+	for _, choice := range input {
+		choices[choice.Store] = choice.Method
+	}
+	return choices
+}
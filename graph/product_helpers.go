@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+// productToModel converts a product for the single product views.
+func productToModel(p *product.Product) *model.Product {
+	categoryID := int(p.CategoryID)
+	return &model.Product{
+		ID:               int(p.ID),
+		RatingAverage:    p.RatingAverage,
+		ReviewCount:      p.ReviewCount,
+		Name:             p.Name,
+		Slug:             p.Slug,
+		Description:      p.Description,
+		Type:             &p.Type,
+		Price:            p.Price,
+		Discount:         p.Discount,
+		Status:           p.Status,
+		Quantity:         p.Quantity,
+		Thumbnail:        p.Thumbnail,
+		Image:            p.Images,
+		File:             &p.File,
+		Store:            p.Store,
+		Category:         p.Category,
+		Subcategory:      p.Subcategory,
+		CategoryID:       &categoryID,
+		AlwaysAvailable:  &p.AlwaysAvailbale,
+		IsBundle:         p.IsBundle,
+		UnitsSold:        p.UnitsSold,
+		Sku:              &p.SKU,
+		ModerationStatus: &p.ModerationStatus,
+		ModerationReason: &p.ModerationReason,
+	}
+}
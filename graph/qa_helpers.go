@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+func productAnswerToModel(a *product.ProductAnswer) *model.ProductAnswer {
+	return &model.ProductAnswer{
+		ID:            int(a.ID),
+		QuestionID:    int(a.QuestionID),
+		Author:        a.Author,
+		Body:          a.Body,
+		IsSeller:      a.IsSeller,
+		VerifiedBuyer: a.VerifiedBuyer,
+		Status:        a.Status,
+		Upvotes:       a.Upvotes,
+		CreatedAt:     a.CreatedAt,
+	}
+}
+
+func productQuestionToModel(q *product.ProductQuestion) *model.ProductQuestion {
+	answers := make([]*model.ProductAnswer, 0, len(q.Answers))
+	for _, a := range q.Answers {
+		answers = append(answers, productAnswerToModel(a))
+	}
+	return &model.ProductQuestion{
+		ID:          int(q.ID),
+		ProductID:   int(q.ProductID),
+		Author:      q.Author,
+		Body:        q.Body,
+		Status:      q.Status,
+		Upvotes:     q.Upvotes,
+		AnswerCount: q.AnswerCount,
+		Answers:     answers,
+		CreatedAt:   q.CreatedAt,
+	}
+}
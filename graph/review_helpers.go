@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/review"
+)
+
+func reviewToModel(rv *review.Review) *model.Review {
+	item := &model.Review{
+		ID:               int(rv.ID),
+		StoreID:          int(rv.StoreID),
+		ProductID:        int(rv.ProductID),
+		ProductName:      rv.ProductName,
+		ProductThumbnail: rv.ProductThumbnail,
+		OrderID:          rv.OrderID,
+		BuyerID:          int(rv.BuyerID),
+		SellerID:         int(rv.SellerID),
+		Message:          &rv.Message,
+		Username:         rv.Nickname,
+		Rating:           rv.Rating,
+		Photos:           rv.Photos,
+		Buyer: &model.ReviewBuyer{
+			Nickname: rv.Nickname,
+			Avatar:   rv.Avatar,
+			Comment:  rv.Message,
+		},
+		SellerRepliedAt: rv.SellerRepliedAt,
+		HelpfulCount:    rv.HelpfulCount,
+		Verified:        rv.Verified,
+		CreatedAt:       &rv.CreatedAt,
+		UpdatedAt:       &rv.UpdatedAt,
+	}
+	if item.Photos == nil {
+		item.Photos = []string{}
+	}
+	if rv.SellerReply != "" {
+		item.SellerReply = &rv.SellerReply
+	}
+	return item
+}
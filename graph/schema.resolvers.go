@@ -18,11 +18,17 @@ import (
 	"strings"
 	"time"
 
-	"github.com/samstringzz/alutamarket-backend/database"
 	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/admin"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
 	"github.com/samstringzz/alutamarket-backend/internals/cart"
+	"github.com/samstringzz/alutamarket-backend/internals/download"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
+	"github.com/samstringzz/alutamarket-backend/internals/media"
 	"github.com/samstringzz/alutamarket-backend/internals/messages"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
 	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/review"
 	"github.com/samstringzz/alutamarket-backend/internals/shared"
 	"github.com/samstringzz/alutamarket-backend/internals/store"
 	"github.com/samstringzz/alutamarket-backend/internals/subscriber"
@@ -165,6 +171,24 @@ func (r *mutationResolver) UpdateOrderStatus(ctx context.Context, orderUUID stri
 		return nil, fmt.Errorf("failed to get order details: %v", err)
 	}
 
+	// The buyer, admins and staff handling orders for one of the order's stores can update it
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed := order.UserID == strconv.Itoa(int(userID))
+	for _, storeName := range order.StoresID {
+		if allowed {
+			break
+		}
+		allowed = r.canInStore(ctx, storeName, store.PermOrders)
+	}
+	if !allowed {
+		if _, err := r.requireAdmin(ctx); err != nil {
+			return nil, fmt.Errorf("unauthorized: you cannot update this order")
+		}
+	}
+
 	// Update the order status
 	err = storeHandler.UpdateOrderStatus(ctx, orderUUID, status, "paid")
 	if err != nil {
@@ -463,68 +487,33 @@ func (r *mutationResolver) AddHandledProduct(ctx context.Context, userID int, pr
 
 // AddReview is the resolver for the addReview field.
 func (r *mutationResolver) AddReview(ctx context.Context, input model.ReviewInput) (*model.Review, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("database connection failed")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
-
-	// Create the review in the database
-	review := &model.Review{
-		StoreID:   input.StoreID,
-		ProductID: input.ProductID,
+	rv := &review.Review{
 		OrderID:   input.OrderID,
-		SellerID:  input.SellerID,
+		ProductID: uint32(input.ProductID),
+		BuyerID:   userID,
 		Rating:    input.Rating,
-		CreatedAt: &now,
-		UpdatedAt: &now,
+		Photos:    input.Photos,
 	}
-
 	if input.Buyer != nil {
-		review.Buyer = &model.ReviewBuyer{
-			Nickname: input.Buyer.Nickname,
-			Avatar:   input.Buyer.Avatar,
-			Comment:  input.Buyer.Comment,
-		}
-		// Set the message from buyer's comment
-		review.Message = &input.Buyer.Comment
-	}
-
-	// Insert the review into the database
-	result := db.Table("reviews").Create(&struct {
-		StoreID   int       `json:"store_id"`
-		ProductID int       `json:"product_id"`
-		OrderID   string    `json:"order_id"`
-		SellerID  int       `json:"seller_id"`
-		BuyerID   int       `json:"buyer_id"`
-		Rating    float64   `json:"rating"`
-		Message   string    `json:"message"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Nickname  string    `json:"nickname"`
-		Avatar    string    `json:"avatar"`
-		Username  string    `json:"username"`
-	}{
-		StoreID:   input.StoreID,
-		ProductID: input.ProductID,
-		OrderID:   input.OrderID,
-		SellerID:  input.SellerID,
-		BuyerID:   input.BuyerID,
-		Rating:    input.Rating,
-		Message:   input.Buyer.Comment,
-		CreatedAt: *review.CreatedAt,
-		UpdatedAt: *review.UpdatedAt,
-		Nickname:  input.Buyer.Nickname,
-		Avatar:    input.Buyer.Avatar,
-		Username:  input.Buyer.Nickname,
-	})
-
-	if result.Error != nil {
-		return nil, fmt.Errorf("failed to create review: %v", result.Error)
+		rv.Nickname = input.Buyer.Nickname
+		rv.Avatar = input.Buyer.Avatar
+		rv.Message = input.Buyer.Comment
+	}
+	if input.Message != nil {
+		rv.Message = *input.Message
 	}
 
-	return review, nil
+	reviewHandler := review.NewHandler(review.NewService(review.NewRepository()))
+	rv, err = reviewHandler.CreateReview(ctx, rv)
+	if err != nil {
+		return nil, err
+	}
+	return reviewToModel(rv), nil
 }
 
 // CheckStoreName is the resolver for the checkStoreName field.
@@ -674,18 +663,88 @@ func (r *mutationResolver) RemoveHandledProduct(ctx context.Context, prd int, ty
 
 // CreateCategory is the resolver for the createCategory field.
 func (r *mutationResolver) CreateCategory(ctx context.Context, input model.NewCategory) (*model.Category, error) {
-	panic(fmt.Errorf("not implemented: CreateCategory - createCategory"))
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	req := &product.Category{Name: input.Name}
+	if input.Type != nil {
+		req.Type = *input.Type
+	}
+	if input.ParentID != nil {
+		parentID := uint32(*input.ParentID)
+		req.ParentID = &parentID
+	}
+	category, err := r.ProductHandler.CreateCategory(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return categoryToModel(category), nil
 }
 
 // CreateSubCategory is the resolver for the createSubCategory field.
 func (r *mutationResolver) CreateSubCategory(ctx context.Context, input model.NewSubCategory) (*model.SubCategory, error) {
-	panic(fmt.Errorf("not implemented: CreateSubCategory - createSubCategory"))
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	parent, err := r.ProductHandler.CreateSubCategory(ctx, product.SubCategory{
+		Name:       input.Name,
+		CategoryID: uint32(input.Category),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range parent.SubCategories {
+		if strings.EqualFold(sub.Name, strings.TrimSpace(input.Name)) {
+			return &model.SubCategory{Name: sub.Name, Slug: sub.Slug, Category: int(sub.CategoryID)}, nil
+		}
+	}
+	return nil, fmt.Errorf("subcategory was not created")
+}
+
+// SetCategoryAttributes is the resolver for the setCategoryAttributes field.
+func (r *mutationResolver) SetCategoryAttributes(ctx context.Context, categoryID int, attributes []*model.CategoryAttributeInput) (*model.Category, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	schema := make([]product.CategoryAttribute, 0, len(attributes))
+	for _, a := range attributes {
+		attribute := product.CategoryAttribute{
+			Name:    a.Name,
+			Options: a.Options,
+		}
+		if a.Label != nil {
+			attribute.Label = *a.Label
+		}
+		if a.Type != nil {
+			attribute.Type = *a.Type
+		}
+		if a.Required != nil {
+			attribute.Required = *a.Required
+		}
+		if a.Facet != nil {
+			attribute.Facet = *a.Facet
+		}
+		schema = append(schema, attribute)
+	}
+
+	category, err := r.ProductHandler.SetCategoryAttributes(ctx, uint32(categoryID), schema)
+	if err != nil {
+		return nil, err
+	}
+	return categoryToModel(category), nil
 }
 
 // CreateProduct is the resolver for the createProduct field.
 func (r *mutationResolver) CreateProduct(ctx context.Context, input model.ProductInput) (*model.Product, error) {
+	if !r.managesProducts(ctx, input.Store) {
+		return nil, fmt.Errorf("unauthorized: your role in this store does not allow this action")
+	}
+
 	// Add debug logging
-	fmt.Printf("Creating product with category ID: %d, subcategory: %s\n", input.Category, input.Subcategory)
+	fmt.Printf("Creating product with category ID: %d\n", input.Category)
 	// Convert input to internal product structure
 	newProduct := &product.NewProduct{
 		Name:            input.Name,
@@ -698,10 +757,16 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, input model.Produc
 		Quantity:        input.Quantity,
 		File:            input.File,
 		Store:           input.Store,
-		CategoryID:      uint8(input.Category),
-		SubCategoryName: input.Subcategory,
+		CategoryID:      uint32(input.Category),
+		Attributes:      attributeInputMap(input.Attributes),
 		AlwaysAvailbale: input.AlwaysAvailable,
 	}
+	if input.Subcategory != nil {
+		newProduct.SubCategoryName = *input.Subcategory
+	}
+	if input.Sku != nil {
+		newProduct.SKU = *input.Sku
+	}
 
 	// Handle variant conversion if present
 	if input.Variant != nil {
@@ -737,20 +802,22 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, input model.Produc
 
 	// Convert to GraphQL model
 	return &model.Product{
-		ID:              int(createdProduct.ID),
-		Name:            createdProduct.Name,
-		Description:     createdProduct.Description,
-		Price:           createdProduct.Price,
-		Discount:        createdProduct.Discount,
-		Status:          createdProduct.Status,
-		Quantity:        createdProduct.Quantity,
-		Thumbnail:       createdProduct.Thumbnail,
-		Image:           createdProduct.Images,
-		File:            &createdProduct.File,
-		Store:           createdProduct.Store,
-		Category:        createdProduct.Category,
-		Subcategory:     createdProduct.Subcategory,
-		AlwaysAvailable: &createdProduct.AlwaysAvailbale,
+		ID:               int(createdProduct.ID),
+		Name:             createdProduct.Name,
+		Description:      createdProduct.Description,
+		Price:            createdProduct.Price,
+		Discount:         createdProduct.Discount,
+		Status:           createdProduct.Status,
+		Quantity:         createdProduct.Quantity,
+		Thumbnail:        createdProduct.Thumbnail,
+		Image:            createdProduct.Images,
+		File:             &createdProduct.File,
+		Store:            createdProduct.Store,
+		Category:         createdProduct.Category,
+		Subcategory:      createdProduct.Subcategory,
+		AlwaysAvailable:  &createdProduct.AlwaysAvailbale,
+		Sku:              &createdProduct.SKU,
+		ModerationStatus: &createdProduct.ModerationStatus,
 	}, nil
 }
 
@@ -763,6 +830,9 @@ func (r *mutationResolver) UpdateProduct(ctx context.Context, input *model.Updat
 	if err != nil {
 		return nil, fmt.Errorf("invalid product ID: %v", err)
 	}
+	if _, err := r.requireProductManager(ctx, uint32(productID)); err != nil {
+		return nil, err
+	}
 
 	// Create update request with only the ID initially
 	updateReq := &product.NewProduct{
@@ -792,14 +862,18 @@ func (r *mutationResolver) UpdateProduct(ctx context.Context, input *model.Updat
 		updateReq.Thumbnail = *input.Thumbnail
 	}
 	if input.Category != nil {
-		updateReq.CategoryID = uint8(*input.Category)
+		updateReq.CategoryID = uint32(*input.Category)
 	}
+	updateReq.Attributes = attributeInputMap(input.Attributes)
 	if input.Subcategory != nil {
 		updateReq.SubCategoryName = *input.Subcategory
 	}
 	if input.AlwaysAvailable != nil {
 		updateReq.AlwaysAvailbale = *input.AlwaysAvailable
 	}
+	if input.Sku != nil {
+		updateReq.SKU = *input.Sku
+	}
 
 	// Call the product handler to update the product
 	updatedProduct, err := productHandler.UpdateProduct(ctx, updateReq)
@@ -809,17 +883,19 @@ func (r *mutationResolver) UpdateProduct(ctx context.Context, input *model.Updat
 
 	// Return the updated product data
 	return &model.Product{
-		ID:              int(updatedProduct.ID),
-		Name:            updatedProduct.Name,
-		Price:           updatedProduct.Price,
-		Description:     updatedProduct.Description,
-		Discount:        updatedProduct.Discount,
-		Quantity:        updatedProduct.Quantity,
-		Status:          updatedProduct.Status,
-		Thumbnail:       updatedProduct.Thumbnail,
-		Category:        updatedProduct.Category,
-		Subcategory:     updatedProduct.Subcategory,
-		AlwaysAvailable: &updatedProduct.AlwaysAvailbale,
+		ID:               int(updatedProduct.ID),
+		Name:             updatedProduct.Name,
+		Price:            updatedProduct.Price,
+		Description:      updatedProduct.Description,
+		Discount:         updatedProduct.Discount,
+		Quantity:         updatedProduct.Quantity,
+		Status:           updatedProduct.Status,
+		Thumbnail:        updatedProduct.Thumbnail,
+		Category:         updatedProduct.Category,
+		Subcategory:      updatedProduct.Subcategory,
+		AlwaysAvailable:  &updatedProduct.AlwaysAvailbale,
+		Sku:              &updatedProduct.SKU,
+		ModerationStatus: &updatedProduct.ModerationStatus,
 	}, nil
 }
 
@@ -831,17 +907,23 @@ func (r *mutationResolver) ToggleStoreFollowStatus(ctx context.Context, user int
 // DeleteProduct is the resolver for the deleteProduct field.
 func (r *mutationResolver) DeleteProduct(ctx context.Context, productID int) (*model.Product, error) {
 	// Get product before deletion to return its data
-	product, err := r.ProductHandler.GetProduct(ctx, uint32(productID), 0)
+	product, err := r.requireProductManager(ctx, uint32(productID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch product: %v", err)
+		return nil, err
 	}
 
-	// Delete the product
+	// Move the product to the trash
 	err = r.ProductHandler.DeleteProduct(ctx, uint32(productID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete product: %v", err)
 	}
 
+	// Shoppers can no longer check it out
+	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+	if _, err := cartHandler.RemoveProductFromCarts(ctx, product.ID); err != nil {
+		log.Printf("Failed to remove deleted product %d from carts: %v", product.ID, err)
+	}
+
 	// Return the deleted product data
 	return &model.Product{
 		ID:              int(product.ID),
@@ -874,6 +956,10 @@ func (r *mutationResolver) ModifyCart(ctx context.Context, input model.ModifyCar
 		},
 		Quantity: input.Quantity,
 	}
+	if input.SlotStart != nil {
+		slot := slotTime(*input.SlotStart)
+		cartItem.SlotStart = &slot
+	}
 
 	// Set product ID if provided
 	if input.ProductID != nil {
@@ -908,8 +994,12 @@ func (r *mutationResolver) ModifyCart(ctx context.Context, input model.ModifyCar
 				Thumbnail: item.Product.Thumbnail,
 				Image:     item.Product.Images,
 				Store:     item.Product.Store,
+				IsBundle:  item.Product.IsBundle,
 			},
-			Quantity: item.Quantity,
+			Quantity:  item.Quantity,
+			Subtotal:  item.Subtotal,
+			Deal:      appliedDealToModel(item.Deal),
+			SlotStart: item.SlotStart,
 		}
 		cartItems = append(cartItems, cartItem)
 	}
@@ -958,8 +1048,12 @@ func (r *mutationResolver) RemoveAllCart(ctx context.Context, cartID int) (*mode
 				Thumbnail: item.Product.Thumbnail,
 				Image:     item.Product.Images,
 				Store:     item.Product.Store,
+				IsBundle:  item.Product.IsBundle,
 			},
-			Quantity: item.Quantity,
+			Quantity:  item.Quantity,
+			Subtotal:  item.Subtotal,
+			Deal:      appliedDealToModel(item.Deal),
+			SlotStart: item.SlotStart,
 		}
 		cartItems = append(cartItems, cartItem)
 	}
@@ -1049,6 +1143,20 @@ func (r *mutationResolver) UpdateStore(ctx context.Context, input *model.UpdateS
 		return nil, fmt.Errorf("invalid store ID: %v", err)
 	}
 
+	// Recording a visit is open to everyone; anything else needs a store role,
+	// and the payout account can only be changed by the owner
+	if input.Account != nil {
+		if _, err := r.requireStoreOwner(ctx, uint32(id)); err != nil {
+			return nil, err
+		}
+	} else if input.Name != nil || input.Link != nil || input.Description != nil || input.Address != nil ||
+		input.Phone != nil || input.Email != nil || input.Thumbnail != nil || input.Background != nil ||
+		input.HasPhysicalAddress != nil || input.Status != nil || input.MaintenanceMode != nil {
+		if _, err := r.requireStorePermission(ctx, uint32(id), store.PermSettings); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create store update struct
 	updateStore := &store.UpdateStore{
 		ID: uint32(id),
@@ -1229,7 +1337,8 @@ func (r *mutationResolver) DeleteStore(ctx context.Context, id int) (*model.Stor
 		return nil, err
 	}
 
-	// Check if user is admin or store owner
+	// Check if user is admin or store owner; deliberately owner-only, as no
+	// staff role may delete the store
 	user, err := r.UserHandler.GetUser(ctx, strconv.Itoa(int(userID)))
 	if err != nil {
 		return nil, err
@@ -1239,12 +1348,17 @@ func (r *mutationResolver) DeleteStore(ctx context.Context, id int) (*model.Stor
 		return nil, fmt.Errorf("unauthorized: only store owner or admin can delete store")
 	}
 
-	// Delete the store
-	err = storeHandler.DeleteStore(ctx, uint32(id))
+	// Closes the store, which fails while it still has anything to settle
+	_, err = storeHandler.CloseStore(ctx, uint32(id), userID)
 	if err != nil {
 		return nil, err
 	}
 
+	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+	if _, err := cartHandler.RemoveStoreFromCarts(ctx, storeObj.Name); err != nil {
+		log.Printf("Failed to remove deleted store %s from carts: %v", storeObj.Name, err)
+	}
+
 	// Return the deleted store
 	return &model.Store{
 		ID:                 strconv.Itoa(int(storeObj.ID)),
@@ -1300,13 +1414,14 @@ func (r *mutationResolver) InitializePayment(ctx context.Context, input model.Pa
 
 	// Create order with required fields
 	order := store.Order{
-		UserID:         input.UserID,
-		UUID:           *input.UUID,
-		PaymentGateway: *input.PaymentGateway,
-		Amount:         amountStr,
-		Status:         "pending",
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		UserID:            input.UserID,
+		UUID:              *input.UUID,
+		PaymentGateway:    *input.PaymentGateway,
+		Amount:            amountStr,
+		Status:            "pending",
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		FulfilmentChoices: fulfilmentChoices(input.Fulfilment),
 	}
 
 	// Initialize payment
@@ -1467,13 +1582,10 @@ func (r *mutationResolver) WithdrawFund(ctx context.Context, input model.FundInp
 	// Get store repository
 	storeRepo := store.NewRepository()
 
-	// Get store and validate user
-	storeObj, err := storeRepo.GetStore(ctx, uint32(input.StoreID))
+	// Only the owner's role grants withdrawals
+	storeObj, err := r.requireStorePermission(ctx, uint32(input.StoreID), store.PermWithdrawals)
 	if err != nil {
-		return false, fmt.Errorf("store not found: %w", err)
-	}
-	if storeObj.UserID != uint32(input.UserID) {
-		return false, fmt.Errorf("user not authorized for this store")
+		return false, err
 	}
 
 	// Check if store has sufficient balance in wallet
@@ -1682,1133 +1794,1308 @@ func (r *mutationResolver) ProcessStoreWithdrawal(ctx context.Context, id string
 	return true, nil
 }
 
-// Users is the resolver for the users field.
-func (r *queryResolver) Users(ctx context.Context, limit *int, offset *int) ([]*model.User, error) {
-	userHandler := user.NewHandler(user.NewService(user.NewRepository()))
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	// Get all users
-	users, err := userHandler.GetUsers(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch users: %v", err)
+// RecordProductView is the resolver for the recordProductView field.
+func (r *mutationResolver) RecordProductView(ctx context.Context, productID int, sessionID *string, source *string) (bool, error) {
+	view := &product.ProductView{
+		ProductID: uint32(productID),
 	}
 
-	// Handle pagination if offset and limit are provided
-	start := 0
-	if offset != nil {
-		start = *offset
+	// Views are recorded for anonymous visitors too, so a missing token is not an error
+	if userID, err := utils.GetUserIDFromContext(ctx); err == nil {
+		view.UserID = userID
 	}
-
-	end := len(users)
-	if limit != nil {
-		if start+*limit < end {
-			end = start + *limit
-		}
+	if sessionID != nil {
+		view.SessionID = *sessionID
+	}
+	if source != nil {
+		view.Source = *source
 	}
 
-	// Apply pagination
-	if start < len(users) {
-		users = users[start:end]
-	} else {
-		users = []*user.User{}
+	return r.ProductHandler.RecordProductView(ctx, view)
+}
+
+// ApproveProduct is the resolver for the approveProduct field.
+func (r *mutationResolver) ApproveProduct(ctx context.Context, productID int) (*model.Product, error) {
+	reviewerID, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	var userResponses []*model.User
-	for _, u := range users {
-		active := true
-		if u.Active != nil {
-			active = *u.Active
-		}
+	adminHandler := admin.NewHandler(admin.NewService(admin.NewRepository()))
+	p, err := adminHandler.ApproveProduct(ctx, uint32(productID), reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve product: %v", err)
+	}
+	r.notifyModerationDecision(ctx, p)
 
-		// Convert string to pointer for optional fields
-		var dobPtr, genderPtr, avatarPtr *string
-		if u.Dob != "" {
-			dobPtr = &u.Dob
+	// Price drops and restocks made while the product was in the queue are alerted now that it is live
+	go func(id uint32) {
+		if _, err := r.ProductHandler.NotifyPriceDrops(context.Background(), id); err != nil {
+			log.Printf("failed to send price drop alerts for product %d: %v", id, err)
 		}
-		if u.Gender != "" {
-			genderPtr = &u.Gender
-		}
-		if u.Avatar != "" {
-			avatarPtr = &u.Avatar
-		}
-
-		userResponse := &model.User{
-			ID:       strconv.FormatInt(int64(u.ID), 10),
-			UUID:     u.UUID,
-			Fullname: u.Fullname,
-			Email:    u.Email,
-			Phone:    u.Phone,
-			Campus:   u.Campus,
-			Usertype: u.Usertype,
-			Active:   active,
-			Avatar:   avatarPtr,
-			Dob:      dobPtr,
-			Gender:   genderPtr,
-			Online:   u.Online,
+		if _, err := r.ProductHandler.NotifyRestock(context.Background(), id); err != nil {
+			log.Printf("failed to send back in stock alerts for product %d: %v", id, err)
 		}
+	}(p.ID)
 
-		// If user is a seller, fetch their store
-		if u.Usertype == "seller" {
-			// Get the store for this seller
-			userStore, err := storeHandler.GetStore(ctx, u.ID)
-			if err == nil && userStore != nil {
-				// Convert followers to GraphQL model
-				var followers []*model.StoreFollower
-				for _, f := range userStore.Followers {
-					followers = append(followers, &model.StoreFollower{
-						FollowerID:    int(f.FollowerID),
-						FollowerName:  f.FollowerName,
-						StoreID:       int(f.StoreID),
-						FollowerImage: f.FollowerImage,
-					})
-				}
+	return &model.Product{
+		ID:               int(p.ID),
+		Name:             p.Name,
+		Slug:             p.Slug,
+		Description:      p.Description,
+		Price:            p.Price,
+		Discount:         p.Discount,
+		Status:           p.Status,
+		Quantity:         p.Quantity,
+		Thumbnail:        p.Thumbnail,
+		Image:            p.Images,
+		Store:            p.Store,
+		Category:         p.Category,
+		Subcategory:      p.Subcategory,
+		UnitsSold:        p.UnitsSold,
+		ModerationStatus: &p.ModerationStatus,
+	}, nil
+}
 
-				storeModel := &model.Store{
-					ID:                 strconv.Itoa(int(userStore.ID)),
-					Name:               userStore.Name,
-					Link:               userStore.Link,
-					Description:        userStore.Description,
-					Address:            userStore.Address,
-					Phone:              userStore.Phone,
-					HasPhysicalAddress: userStore.HasPhysicalAddress,
-					Thumbnail:          userStore.Thumbnail,
-					Background:         userStore.Background,
-					Status:             userStore.Status,
-					User:               int(userStore.UserID),
-					Wallet:             float64(userStore.Wallet),
-					Followers:          followers,
-				}
-				userResponse.Stores = []*model.Store{storeModel}
-			}
-		}
+// RejectProduct is the resolver for the rejectProduct field.
+func (r *mutationResolver) RejectProduct(ctx context.Context, productID int, reason string) (*model.Product, error) {
+	reviewerID, err := r.requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		userResponses = append(userResponses, userResponse)
+	adminHandler := admin.NewHandler(admin.NewService(admin.NewRepository()))
+	p, err := adminHandler.RejectProduct(ctx, uint32(productID), reviewerID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject product: %v", err)
 	}
+	r.notifyModerationDecision(ctx, p)
 
-	return userResponses, nil
+	return &model.Product{
+		ID:               int(p.ID),
+		Name:             p.Name,
+		Slug:             p.Slug,
+		Description:      p.Description,
+		Price:            p.Price,
+		Discount:         p.Discount,
+		Status:           p.Status,
+		Quantity:         p.Quantity,
+		Thumbnail:        p.Thumbnail,
+		Image:            p.Images,
+		Store:            p.Store,
+		Category:         p.Category,
+		Subcategory:      p.Subcategory,
+		UnitsSold:        p.UnitsSold,
+		ModerationStatus: &p.ModerationStatus,
+		ModerationReason: &p.ModerationReason,
+	}, nil
 }
 
-// User is the resolver for the User field.
-func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
-	result, err := r.UserHandler.GetUser(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %v", err)
+// SetStoreTrusted is the resolver for the setStoreTrusted field.
+func (r *mutationResolver) SetStoreTrusted(ctx context.Context, storeID int, trusted bool) (bool, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return false, err
 	}
 
-	if result == nil {
-		return nil, fmt.Errorf("user not found")
+	adminHandler := admin.NewHandler(admin.NewService(admin.NewRepository()))
+	if err := adminHandler.SetStoreTrusted(ctx, uint32(storeID), trusted); err != nil {
+		return false, fmt.Errorf("failed to update store: %v", err)
 	}
+	return true, nil
+}
 
-	// Convert repository user to GraphQL model with proper type handling
-	user := &model.User{
-		ID:       strconv.FormatUint(uint64(result.ID), 10),
-		UUID:     result.UUID,
-		Email:    result.Email,
-		Fullname: result.Fullname,
-		Phone:    result.Phone,
-		Campus:   result.Campus,
-		Usertype: result.Usertype,
-		Online:   result.Online,
-		Active:   *result.Active,
-		Twofa:    *result.Twofa,
+// MarkNotificationRead is the resolver for the markNotificationRead field.
+func (r *mutationResolver) MarkNotificationRead(ctx context.Context, id int) (bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
 	}
 
-	// Handle optional string fields
-	if result.Avatar != "" {
-		user.Avatar = &result.Avatar
-	}
-	if result.Gender != "" {
-		user.Gender = &result.Gender
-	}
-	if result.Dob != "" {
-		user.Dob = &result.Dob
+	notificationHandler := notification.NewHandler(notification.NewService(notification.NewRepository()))
+	if err := notificationHandler.MarkRead(ctx, userID, uint32(id)); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	// Handle tokens
-	if result.AccessToken != "" {
-		user.AccessToken = &result.AccessToken
-	}
-	if result.RefreshToken != "" {
-		user.RefreshToken = &result.RefreshToken
+// SetPriceDropEmail is the resolver for the setPriceDropEmail field.
+func (r *mutationResolver) SetPriceDropEmail(ctx context.Context, enabled bool) (bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
 	}
 
-	// Handle PaymentDetails if they exist
-	if result.PaymentDetails.Name != "" {
-		user.PaymentDetails = &model.PaymentDetails{
-			Name:    result.PaymentDetails.Name,
-			Phone:   result.PaymentDetails.Phone,
-			Address: result.PaymentDetails.Address,
-			Info:    result.PaymentDetails.Info,
-		}
+	if err := r.DB.Model(&user.User{}).Where("id = ?", userID).Update("price_drop_email", enabled).Error; err != nil {
+		return false, fmt.Errorf("failed to update price drop alerts: %v", err)
 	}
-
-	return user, nil
+	return enabled, nil
 }
 
-// Categories is the resolver for the Categories field.
-func (r *queryResolver) Categories(ctx context.Context) ([]*model.Category, error) {
-	categories, err := r.ProductHandler.GetCategories(ctx)
+// NotifyWhenAvailable is the resolver for the notifyWhenAvailable field.
+func (r *mutationResolver) NotifyWhenAvailable(ctx context.Context, productID int, variant *string) (bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch categories: %v", err)
+		return false, err
 	}
 
-	var result []*model.Category
-	for _, cat := range categories {
-		// Convert subcategories
-		subCategories := make([]*model.SubCategory, 0)
-		for _, sub := range cat.SubCategories {
-			subCategories = append(subCategories, &model.SubCategory{
-				Name:     sub.Name,
-				Slug:     sub.Slug,
-				Category: int(sub.CategoryID),
-			})
-		}
-
-		result = append(result, &model.Category{
-			ID:            strconv.FormatUint(uint64(cat.ID), 10),
-			Name:          cat.Name,
-			Slug:          cat.Slug,
-			Type:          cat.Type,
-			Subcategories: subCategories,
-		})
+	sub := &product.StockSubscription{
+		ProductID: uint32(productID),
+		UserID:    userID,
 	}
-	return result, nil
+	if variant != nil {
+		sub.Variant = *variant
+	}
+	if _, err := r.ProductHandler.SubscribeToRestock(ctx, sub); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// FollowedStores is the resolver for the FollowedStores field.
-func (r *queryResolver) FollowedStores(ctx context.Context, userID int) ([]*model.Store, error) {
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	// Convert int to uint32
-	userIDUint := uint32(userID)
-
-	// Get followed stores
-	stores, err := storeHandler.GetFollowedStores(ctx, userIDUint)
+// CancelNotifyWhenAvailable is the resolver for the cancelNotifyWhenAvailable field.
+func (r *mutationResolver) CancelNotifyWhenAvailable(ctx context.Context, productID int, variant *string) (bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch followed stores: %v", err)
+		return false, err
 	}
 
-	// Convert to GraphQL model
-	var modelStores []*model.Store
-	for _, s := range stores {
-		if s == nil {
-			continue
-		}
+	variantValue := ""
+	if variant != nil {
+		variantValue = *variant
+	}
+	if err := r.ProductHandler.CancelRestockSubscription(ctx, userID, uint32(productID), variantValue); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-		// Convert followers
-		var followers []*model.StoreFollower
-		for _, f := range s.Followers {
-			followers = append(followers, &model.StoreFollower{
-				FollowerID:    int(f.FollowerID),
-				FollowerName:  f.FollowerName,
-				StoreID:       int(f.StoreID),
-				FollowerImage: f.FollowerImage,
-			})
-		}
+// AskProductQuestion is the resolver for the askProductQuestion field.
+func (r *mutationResolver) AskProductQuestion(ctx context.Context, productID int, body string) (*model.ProductQuestion, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		// Convert products
-		var products []*model.Product
-		for _, p := range s.Products {
-			products = append(products, &model.Product{
-				ID:          int(p.ID),
-				Name:        p.Name,
-				Price:       p.Price,
-				Description: p.Description,
-				Discount:    p.Discount,
-				Image:       p.Images,
-				Slug:        p.Slug,
-				Quantity:    p.Quantity,
-				Status:      p.Status,
-				Thumbnail:   p.Thumbnail,
-				Store:       p.Store,
-				Category:    p.Category,
-				Subcategory: p.Subcategory,
-			})
-		}
+	q, err := r.ProductHandler.AskQuestion(ctx, &product.ProductQuestion{
+		ProductID: uint32(productID),
+		UserID:    userID,
+		Body:      body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productQuestionToModel(q), nil
+}
 
-		modelStore := &model.Store{
-			ID:                 strconv.Itoa(int(s.ID)),
-			Link:               s.Link,
-			Name:               s.Name,
-			User:               int(s.UserID),
-			Description:        s.Description,
-			Thumbnail:          s.Thumbnail,
-			Phone:              s.Phone,
-			Background:         s.Background,
-			Address:            s.Address,
-			Status:             s.Status,
-			HasPhysicalAddress: s.HasPhysicalAddress,
-			Wallet:             float64(s.Wallet),
-			Visitors:           s.Visitors,
-			Followers:          followers,
-			Product:            products,
-		}
-		modelStores = append(modelStores, modelStore)
+// AnswerProductQuestion is the resolver for the answerProductQuestion field.
+func (r *mutationResolver) AnswerProductQuestion(ctx context.Context, questionID int, body string) (*model.ProductAnswer, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return modelStores, nil
+	a, err := r.ProductHandler.AnswerQuestion(ctx, &product.ProductAnswer{
+		QuestionID: uint32(questionID),
+		UserID:     userID,
+		Body:       body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return productAnswerToModel(a), nil
 }
 
-// SellerOrders is the resolver for the SellerOrders field.
-func (r *queryResolver) SellerOrders(ctx context.Context, storeName string) ([]*model.Order, error) {
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	orders, err := storeHandler.GetOrdersByStore(ctx, storeName)
+// UpvoteProductQuestion is the resolver for the upvoteProductQuestion field.
+func (r *mutationResolver) UpvoteProductQuestion(ctx context.Context, id int) (int, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch orders: %v", err)
+		return 0, err
 	}
+	return r.ProductHandler.UpvoteQA(ctx, userID, product.QAKindQuestion, uint32(id))
+}
 
-	var result []*model.Order
-	for _, order := range orders {
-		// Verify transaction status with Paystack if needed
-		if order.PaymentGateway == "paystack" && (order.Status == "" || order.Status == "not completed" || order.TransStatus == "not paid") {
-			verificationResponse, err := utils.VerifyPaystackTransaction(order.UUID)
-			if err == nil && verificationResponse.Status {
-				if verificationResponse.Data.Status == "success" {
-					// Update to pending if the order is new/empty status or not completed
-					err = storeHandler.UpdateOrderStatus(ctx, order.UUID, "pending", "paid")
-					if err == nil {
-						order.Status = "pending"
-						order.TransStatus = "paid"
-					}
-				}
-			}
-		}
+// UpvoteProductAnswer is the resolver for the upvoteProductAnswer field.
+func (r *mutationResolver) UpvoteProductAnswer(ctx context.Context, id int) (int, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return r.ProductHandler.UpvoteQA(ctx, userID, product.QAKindAnswer, uint32(id))
+}
 
-		// Convert products
-		var products []*model.Product
-		for _, p := range order.Products {
-			products = append(products, &model.Product{
-				ID:        int(p.ID),
-				Name:      p.Name,
-				Price:     p.Price,
-				Thumbnail: p.Thumbnail,
-				Status:    p.Status == "active",
-			})
-		}
+// ModerateProductQuestion is the resolver for the moderateProductQuestion field.
+func (r *mutationResolver) ModerateProductQuestion(ctx context.Context, id int, approve bool) (bool, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return false, err
+	}
+	if err := r.ProductHandler.ModerateQA(ctx, product.QAKindQuestion, uint32(id), approve); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-		// Convert delivery details
-		var deliveryDetails *model.DeliveryDetails
-		if order.DeliveryDetails != nil {
-			deliveryDetails = &model.DeliveryDetails{
-				Method:  order.DeliveryDetails.Method,
-				Address: order.DeliveryDetails.Address,
-				Fee:     order.DeliveryDetails.Fee,
-			}
-		}
+// ModerateProductAnswer is the resolver for the moderateProductAnswer field.
+func (r *mutationResolver) ModerateProductAnswer(ctx context.Context, id int, approve bool) (bool, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return false, err
+	}
+	if err := r.ProductHandler.ModerateQA(ctx, product.QAKindAnswer, uint32(id), approve); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-		result = append(result, &model.Order{
-			CartID:          int(order.CartID),
-			UUID:            order.UUID,
-			Amount:          order.Amount,
-			Status:          order.Status,
-			PaymentGateway:  order.PaymentGateway,
-			PaymentMethod:   order.PaymentMethod,
-			TransRef:        order.TransRef,
-			TransStatus:     order.TransStatus,
-			Products:        products,
-			DeliveryDetails: deliveryDetails,
-			TextRef:         &order.TransRef,
-			CreatedAt:       &order.CreatedAt,
-		})
+// ReplyToReview is the resolver for the replyToReview field.
+func (r *mutationResolver) ReplyToReview(ctx context.Context, id int, reply string) (*model.Review, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	reviewHandler := review.NewHandler(review.NewService(review.NewRepository()))
+	rv, err := reviewHandler.ReplyToReview(ctx, uint32(id), userID, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reviewToModel(rv), nil
 }
 
-// Category is the resolver for the Category field.
-func (r *queryResolver) Category(ctx context.Context, id int) (*model.Category, error) {
-	categoryID := uint32(id)
-	category, err := r.ProductHandler.GetCategory(ctx, categoryID)
+// MarkReviewHelpful is the resolver for the markReviewHelpful field.
+func (r *mutationResolver) MarkReviewHelpful(ctx context.Context, id int) (int, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch category: %v", err)
+		return 0, err
 	}
 
-	if category == nil {
-		return nil, fmt.Errorf("category not found")
-	}
+	reviewHandler := review.NewHandler(review.NewService(review.NewRepository()))
+	return reviewHandler.MarkHelpful(ctx, uint32(id), userID)
+}
 
-	// Convert SubCategories to the correct type
-	subCategories := make([]*model.SubCategory, 0)
-	for _, sub := range category.SubCategories {
-		subCategories = append(subCategories, &model.SubCategory{
-			Name:     sub.Name,
-			Slug:     sub.Slug,
-			Category: int(sub.CategoryID),
-		})
+// RequestDownloadLink is the resolver for the requestDownloadLink field.
+func (r *mutationResolver) RequestDownloadLink(ctx context.Context, downloadID string) (*model.DownloadLink, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return &model.Category{
-		ID:            strconv.FormatUint(uint64(category.ID), 10),
-		Name:          category.Name,
-		Slug:          category.Slug,
-		Type:          category.Type,
-		Subcategories: subCategories,
+	downloadHandler := download.NewHandler(download.NewService(download.NewRepository()))
+	link, err := downloadHandler.IssueLink(ctx, downloadID, strconv.Itoa(int(userID)), "", "")
+	if err != nil {
+		return nil, err
+	}
+	return &model.DownloadLink{
+		URL:       link.URL,
+		ExpiresAt: link.ExpiresAt,
+		Remaining: link.Remaining,
 	}, nil
 }
 
-// SubCategory is the resolver for the SubCategory field.
-func (r *queryResolver) SubCategory(ctx context.Context, id string) (*model.SubCategory, error) {
-	panic(fmt.Errorf("not implemented: SubCategory - SubCategory"))
+// SetDownloadWatermark is the resolver for the setDownloadWatermark field.
+func (r *mutationResolver) SetDownloadWatermark(ctx context.Context, productID int, enabled bool) (bool, error) {
+	p, err := r.requireProductManager(ctx, uint32(productID))
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.DB.Model(&product.Product{}).Where("id = ?", p.ID).Update("watermark", enabled).Error; err != nil {
+		return false, fmt.Errorf("failed to update product: %v", err)
+	}
+	return true, nil
 }
 
-// Products is the resolver for the Products field.
-func (r *queryResolver) Products(ctx context.Context, store *string, categorySlug *string, limit *int, offset *int) (*model.ProductPaginationData, error) {
-	fmt.Printf("Received Arguments - limit: %v, offset: %v, store: %v\n", limit, offset, store)
+// SetBundleItems is the resolver for the setBundleItems field.
+func (r *mutationResolver) SetBundleItems(ctx context.Context, productID int, items []*model.BundleItemInput) (*model.Product, error) {
+	if _, err := r.requireProductManager(ctx, uint32(productID)); err != nil {
+		return nil, err
+	}
 
-	// Convert nullable parameters
-	limitValue := 12
-	if limit != nil {
-		limitValue = *limit
+	bundleItems := make([]*product.BundleItem, 0, len(items))
+	for _, item := range items {
+		bundleItems = append(bundleItems, &product.BundleItem{
+			ComponentID: uint32(item.ProductID),
+			Quantity:    item.Quantity,
+		})
 	}
+	bundle, err := r.ProductHandler.SetBundleItems(ctx, uint32(productID), bundleItems)
+	if err != nil {
+		return nil, err
+	}
+	return productToModel(bundle), nil
+}
 
-	offsetValue := 0
-	if offset != nil {
-		offsetValue = *offset
+// SetPricingRules is the resolver for the setPricingRules field.
+func (r *mutationResolver) SetPricingRules(ctx context.Context, productID int, rules []*model.PricingRuleInput) ([]*model.PricingRule, error) {
+	if _, err := r.requireProductManager(ctx, uint32(productID)); err != nil {
+		return nil, err
 	}
 
-	storeValue := ""
-	if store != nil {
-		storeValue = *store
+	pricingRules := make([]*product.PricingRule, 0, len(rules))
+	for _, in := range rules {
+		rule := &product.PricingRule{Type: in.Type}
+		if in.MinQuantity != nil {
+			rule.MinQuantity = *in.MinQuantity
+		}
+		if in.UnitPrice != nil {
+			rule.UnitPrice = *in.UnitPrice
+		}
+		if in.BuyQuantity != nil {
+			rule.BuyQuantity = *in.BuyQuantity
+		}
+		if in.FreeQuantity != nil {
+			rule.FreeQuantity = *in.FreeQuantity
+		}
+		if in.Label != nil {
+			rule.Label = *in.Label
+		}
+		pricingRules = append(pricingRules, rule)
 	}
 
-	var products []*product.Product
-	var total int
-	var err error
+	saved, err := r.ProductHandler.SetPricingRules(ctx, uint32(productID), pricingRules)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.PricingRule, 0, len(saved))
+	for _, rule := range saved {
+		result = append(result, pricingRuleToModel(rule))
+	}
+	return result, nil
+}
 
-	if store != nil && strings.Contains(*store, "-") {
-		// This is a search query
-		searchQuery := strings.TrimSuffix(*store, "-")
-		products, err = r.ProductHandler.SearchProducts(ctx, searchQuery)
-		if err != nil {
-			return nil, fmt.Errorf("failed to search products: %v", err)
+// CreateSaleCampaign is the resolver for the createSaleCampaign field.
+func (r *mutationResolver) CreateSaleCampaign(ctx context.Context, input model.SaleCampaignInput) (*model.SaleCampaign, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(input.StoreID), store.PermProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign := &product.SaleCampaign{
+		Store:    storeObj.Name,
+		Name:     input.Name,
+		StartsAt: input.StartsAt,
+		EndsAt:   input.EndsAt,
+		Items:    make([]*product.SaleItem, 0, len(input.Items)),
+	}
+	for _, in := range input.Items {
+		item := &product.SaleItem{ProductID: uint32(in.ProductID)}
+		if in.SalePrice != nil {
+			item.SalePrice = *in.SalePrice
 		}
-		total = len(products)
-	} else {
-		products, total, err = r.ProductHandler.GetProducts(ctx, storeValue, "", limitValue, offsetValue)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch products: %v", err)
+		if in.PercentOff != nil {
+			item.PercentOff = *in.PercentOff
 		}
+		if in.UnitCap != nil {
+			item.UnitCap = *in.UnitCap
+		}
+		campaign.Items = append(campaign.Items, item)
 	}
 
-	if products == nil {
-		products = []*product.Product{} // Return empty array instead of nil
+	created, err := r.ProductHandler.CreateSaleCampaign(ctx, campaign)
+	if err != nil {
+		return nil, err
 	}
+	return saleCampaignToModel(created), nil
+}
 
-	// Convert to GraphQL model
-	modelProducts := make([]*model.Product, 0, len(products))
-	for _, p := range products {
-		if p == nil {
-			continue
-		}
-		modelProducts = append(modelProducts, &model.Product{
-			ID:              int(p.ID),
-			Name:            p.Name,
-			Slug:            p.Slug,
-			Description:     p.Description,
-			Price:           p.Price,
-			Discount:        p.Discount,
-			Status:          p.Status,
-			Quantity:        p.Quantity,
-			Thumbnail:       p.Thumbnail,
-			Image:           p.Images,
-			File:            &p.File,
-			Store:           p.Store,
-			Category:        p.Category,
-			Subcategory:     p.Subcategory,
-			AlwaysAvailable: &p.AlwaysAvailbale,
-		})
+// CancelSaleCampaign is the resolver for the cancelSaleCampaign field.
+func (r *mutationResolver) CancelSaleCampaign(ctx context.Context, id int) (*model.SaleCampaign, error) {
+	campaign, err := r.ProductHandler.GetSaleCampaign(ctx, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+	if !r.canInStore(ctx, campaign.Store, store.PermProducts) {
+		return nil, fmt.Errorf("unauthorized: your role in this store does not allow this action")
 	}
 
-	return &model.ProductPaginationData{
-		Data:        modelProducts,
-		CurrentPage: offsetValue + 1,
-		PerPage:     limitValue,
-		Total:       total,
-		NextPage:    offsetValue + 2,
-		PrevPage:    offsetValue,
-	}, nil
+	if err := r.ProductHandler.CancelSaleCampaign(ctx, campaign.ID); err != nil {
+		return nil, err
+	}
+	campaign, err = r.ProductHandler.GetSaleCampaign(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	return saleCampaignToModel(campaign), nil
 }
 
-// Product is the resolver for the Product field.
-func (r *queryResolver) Product(ctx context.Context, id int) (*model.Product, error) {
-	productHandler := product.NewHandler(product.NewService(product.NewRepository()))
+// SetServiceAvailability is the resolver for the setServiceAvailability field.
+func (r *mutationResolver) SetServiceAvailability(ctx context.Context, productID int, input model.ServiceAvailabilityInput) (*model.ServiceAvailability, error) {
+	if _, err := r.requireProductManager(ctx, uint32(productID)); err != nil {
+		return nil, err
+	}
 
-	// Convert int to uint32 for the handler
-	productID := uint32(id)
+	availability := &booking.Availability{
+		ProductID:   uint32(productID),
+		Hours:       make([]booking.WeeklyHours, 0, len(input.Hours)),
+		SlotMinutes: input.SlotMinutes,
+		Capacity:    1,
+	}
+	for _, h := range input.Hours {
+		availability.Hours = append(availability.Hours, booking.WeeklyHours{Weekday: time.Weekday(h.Weekday), Opens: h.Opens, Closes: h.Closes})
+	}
+	if input.Capacity != nil {
+		availability.Capacity = *input.Capacity
+	}
+	if input.MinNoticeHours != nil {
+		availability.MinNoticeHours = *input.MinNoticeHours
+	}
+	if input.MaxAdvanceDays != nil {
+		availability.MaxAdvanceDays = *input.MaxAdvanceDays
+	}
+	if input.CancelBeforeHours != nil {
+		availability.CancelBeforeHours = *input.CancelBeforeHours
+	}
+	if input.RescheduleBeforeHours != nil {
+		availability.RescheduleBeforeHours = *input.RescheduleBeforeHours
+	}
+	if input.MaxReschedules != nil {
+		availability.MaxReschedules = *input.MaxReschedules
+	}
 
-	// Call the handler with userId 0 since we're just viewing
-	p, err := productHandler.GetProduct(ctx, productID, 0)
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	saved, err := bookingHandler.SetAvailability(ctx, availability)
 	if err != nil {
 		return nil, err
 	}
-
-	// Convert to GraphQL model
-	return &model.Product{
-		ID:              int(p.ID),
-		Name:            p.Name,
-		Slug:            p.Slug,
-		Description:     p.Description,
-		Type:            &p.Type,
-		Price:           p.Price,
-		Discount:        p.Discount,
-		Status:          p.Status,
-		Quantity:        p.Quantity,
-		Thumbnail:       p.Thumbnail,
-		Image:           p.Images,
-		File:            &p.File,
-		Store:           p.Store,
-		Category:        p.Category,
-		Subcategory:     p.Subcategory,
-		AlwaysAvailable: &p.AlwaysAvailbale,
-		UnitsSold:       p.UnitsSold,
-	}, nil
+	return availabilityToModel(saved), nil
 }
 
-// HandledProducts is the resolver for the HandledProducts field.
-func (r *queryResolver) HandledProducts(ctx context.Context, user int, typeArg string) ([]*model.HandledProducts, error) {
-	productHandler := product.NewHandler(product.NewService(product.NewRepository()))
+// CancelBooking is the resolver for the cancelBooking field.
+func (r *mutationResolver) CancelBooking(ctx context.Context, id int, reason *string) (*model.Booking, error) {
+	b, byProvider, err := r.bookingActor(ctx, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+	why := ""
+	if reason != nil {
+		why = *reason
+	}
 
-	// Convert int to uint32 for the handler
-	userID := uint32(user)
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	cancelled, err := bookingHandler.CancelBooking(ctx, b.ID, byProvider, why)
+	if err != nil {
+		return nil, err
+	}
+	return bookingToModel(cancelled), nil
+}
 
-	// Add debug logging
-	fmt.Printf("Fetching handled products for user %d with type %s\n", userID, typeArg)
+// RescheduleBooking is the resolver for the rescheduleBooking field.
+func (r *mutationResolver) RescheduleBooking(ctx context.Context, id int, startsAt time.Time) (*model.Booking, error) {
+	b, byProvider, err := r.bookingActor(ctx, uint32(id))
+	if err != nil {
+		return nil, err
+	}
 
-	// Get handled products from the handler
-	products, err := productHandler.GetHandledProducts(ctx, userID, typeArg)
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	moved, err := bookingHandler.RescheduleBooking(ctx, b.ID, slotTime(startsAt), byProvider)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch handled products: %v", err)
+		return nil, err
 	}
+	return bookingToModel(moved), nil
+}
 
-	// Add debug logging
-	fmt.Printf("Found %d products\n", len(products))
+// RestoreProduct is the resolver for the restoreProduct field.
+func (r *mutationResolver) RestoreProduct(ctx context.Context, productID int) (*model.Product, error) {
+	trashed, err := r.ProductHandler.GetTrashedProduct(ctx, uint32(productID))
+	if err != nil {
+		return nil, err
+	}
+	if !r.canInStore(ctx, trashed.Store, store.PermProducts) {
+		return nil, fmt.Errorf("unauthorized: your role in this store does not allow this action")
+	}
 
-	// Initialize empty slice
-	handledProducts := make([]*model.HandledProducts, 0)
+	restored, err := r.ProductHandler.RestoreProduct(ctx, trashed.ID)
+	if err != nil {
+		return nil, err
+	}
+	return productToModel(restored), nil
+}
 
-	// Convert to GraphQL model
-	for _, p := range products {
-		// Get the actual product details using product ID from handled_products table
-		productDetails, err := productHandler.GetProduct(ctx, p.ProductID, 0)
-		if err != nil {
-			fmt.Printf("Error fetching product details for ID %d: %v\n", p.ProductID, err)
-			continue
-		}
+// RestoreStore is the resolver for the restoreStore field.
+func (r *mutationResolver) RestoreStore(ctx context.Context, storeID int) (*model.Store, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		if productDetails == nil {
-			fmt.Printf("Product details not found for ID %d\n", p.ProductID)
-			continue
-		}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	trashed, err := storeHandler.GetTrashedStore(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
+	}
 
-		status := productDetails.Status
-		price := productDetails.Price
-		discount := productDetails.Discount
-		quantity := productDetails.Quantity
-		name := productDetails.Name
-		thumbnail := productDetails.Thumbnail
+	// Check if user is admin or store owner; deliberately owner-only, like
+	// deleting it
+	user, err := r.UserHandler.GetUser(ctx, strconv.Itoa(int(userID)))
+	if err != nil {
+		return nil, err
+	}
+	if user.Usertype != "admin" && trashed.UserID != userID {
+		return nil, fmt.Errorf("unauthorized: only store owner or admin can restore store")
+	}
 
-		handledProduct := &model.HandledProducts{
-			UserID:           int(p.UserID),
-			ProductID:        int(productDetails.ID),
-			ProductName:      &name,
-			ProductThumbnail: &thumbnail,
-			ProductPrice:     &price,
-			ProductDiscount:  &discount,
-			ProductStatus:    &status,
-			ProductQuantity:  &quantity,
-		}
-		handledProducts = append(handledProducts, handledProduct)
+	storeObj, err := storeHandler.RestoreStore(ctx, trashed.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Store{
+		ID:                 strconv.Itoa(int(storeObj.ID)),
+		Name:               storeObj.Name,
+		Link:               storeObj.Link,
+		User:               int(storeObj.UserID),
+		Description:        storeObj.Description,
+		Address:            storeObj.Address,
+		Phone:              storeObj.Phone,
+		HasPhysicalAddress: storeObj.HasPhysicalAddress,
+		Email:              storeObj.Email,
+		Thumbnail:          storeObj.Thumbnail,
+		Background:         storeObj.Background,
+		Status:             storeObj.Status,
+		Wallet:             storeObj.Wallet,
+	}, nil
+}
 
-		// Log each product being added
-		fmt.Printf("Added product: ID=%d, Name=%s\n", productDetails.ID, name)
+// InviteStoreStaff is the resolver for the inviteStoreStaff field.
+func (r *mutationResolver) InviteStoreStaff(ctx context.Context, input model.StaffInviteInput) (*model.StoreMember, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storeObj, err := r.requireStorePermission(ctx, uint32(input.StoreID), store.PermStaff)
+	if err != nil {
+		return nil, err
 	}
 
-	return handledProducts, nil
-}
+	member := &store.StoreMember{
+		StoreID:   storeObj.ID,
+		Role:      input.Role,
+		InvitedBy: userID,
+	}
+	if input.Email != nil {
+		member.Email = *input.Email
+	}
+	if input.Phone != nil {
+		member.Phone = *input.Phone
+	}
 
-// RecommendedProducts is the resolver for the RecommendedProducts field.
-func (r *queryResolver) RecommendedProducts(ctx context.Context, query string) ([]*model.Product, error) {
-	panic(fmt.Errorf("not implemented: RecommendedProducts - RecommendedProducts"))
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	invited, err := storeHandler.InviteStaff(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	invited.Store = storeObj
+	return storeMemberToModel(invited), nil
 }
 
-// RecentlyAddedProducts is the resolver for the RecentlyAddedProducts field.
-func (r *queryResolver) RecentlyAddedProducts(ctx context.Context, user int) ([]*model.Product, error) {
-	panic(fmt.Errorf("not implemented: RecentlyAddedProducts - RecentlyAddedProducts"))
-}
+// AcceptStoreInvite is the resolver for the acceptStoreInvite field.
+func (r *mutationResolver) AcceptStoreInvite(ctx context.Context, id int) (*model.StoreMember, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-// Cart is the resolver for the Cart field.
-func (r *queryResolver) Cart(ctx context.Context, user int) (*model.Cart, error) {
-	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	member, err := storeHandler.AcceptStaffInvite(ctx, uint32(id), userID)
+	if err != nil {
+		return nil, err
+	}
+	return storeMemberToModel(member), nil
+}
 
-	// Convert int to uint32 for the handler
-	userID := uint32(user)
+// UpdateStoreStaffRole is the resolver for the updateStoreStaffRole field.
+func (r *mutationResolver) UpdateStoreStaffRole(ctx context.Context, id int, role string) (*model.StoreMember, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	member, err := storeHandler.GetStaffMember(ctx, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.requireStorePermission(ctx, member.StoreID, store.PermStaff); err != nil {
+		return nil, err
+	}
 
-	result, err := cartHandler.GetCart(ctx, userID)
+	member, err = storeHandler.UpdateStaffRole(ctx, member.ID, role)
 	if err != nil {
 		return nil, err
 	}
+	return storeMemberToModel(member), nil
+}
 
-	// Convert cart items to GraphQL model
-	var cartItems []*model.CartItem
-	for _, item := range result.Items {
-		cartItem := &model.CartItem{
-			Product: &model.Product{
-				ID:        int(item.Product.ID),
-				Name:      item.Product.Name,
-				Price:     item.Product.Price,
-				Discount:  item.Product.Discount,
-				Quantity:  item.Product.Quantity,
-				Thumbnail: item.Product.Thumbnail,
-				Image:     item.Product.Images,
-				Store:     item.Product.Store,
-			},
-			Quantity: item.Quantity,
-		}
-		cartItems = append(cartItems, cartItem)
+// RemoveStoreStaff is the resolver for the removeStoreStaff field.
+func (r *mutationResolver) RemoveStoreStaff(ctx context.Context, id int) (bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
 	}
 
-	// Convert ID to string
-	id := strconv.FormatUint(uint64(result.ID), 10)
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	member, err := storeHandler.GetStaffMember(ctx, uint32(id))
+	if err != nil {
+		return false, err
+	}
+	// Members can leave a store on their own
+	if member.UserID != userID {
+		if _, err := r.requireStorePermission(ctx, member.StoreID, store.PermStaff); err != nil {
+			return false, err
+		}
+	}
 
-	return &model.Cart{
-		Items:  cartItems,
-		Total:  result.Total,
-		Active: result.Active,
-		User:   int(result.UserID),
-		ID:     &id,
-	}, nil
+	if err := storeHandler.RemoveStaff(ctx, member.ID); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// SubscriptionBundle is the resolver for the SubscriptionBundle field.
-func (r *queryResolver) SubscriptionBundle(ctx context.Context, serviceID string) (*model.SubscriptionBundle, error) {
-	panic(fmt.Errorf("not implemented: SubscriptionBundle - SubscriptionBundle"))
+// SubmitIdentityVerification is the resolver for the submitIdentityVerification field.
+func (r *mutationResolver) SubmitIdentityVerification(ctx context.Context, input model.IdentityVerificationInput) (*model.IdentityVerification, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &kyc.IdentityCheck{
+		IDType:      input.IDType,
+		IDNumber:    input.IDNumber,
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		DocumentURL: input.DocumentURL,
+	}
+	if input.DateOfBirth != nil {
+		check.DateOfBirth = *input.DateOfBirth
+	}
+	kycHandler := kyc.NewHandler(kyc.NewService(kyc.NewRepository()))
+	v, err := kycHandler.SubmitIdentity(ctx, userID, check)
+	if err != nil {
+		return nil, err
+	}
+	return identityVerificationToModel(v), nil
 }
 
-// SearchProducts is the resolver for the searchProducts field.
-func (r *queryResolver) SearchProducts(ctx context.Context, query string) ([]*model.Product, error) {
-	products, err := r.ProductHandler.SearchProducts(ctx, query)
+// ReviewIdentityVerification is the resolver for the reviewIdentityVerification field.
+func (r *mutationResolver) ReviewIdentityVerification(ctx context.Context, id int, approve bool, reason *string) (*model.IdentityVerification, error) {
+	adminID, err := r.requireAdmin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search products: %v", err)
+		return nil, err
 	}
 
-	// Convert to GraphQL model
-	modelProducts := make([]*model.Product, 0, len(products))
-	for _, p := range products {
-		if p == nil {
-			continue
-		}
-		modelProducts = append(modelProducts, &model.Product{
-			ID:              int(p.ID),
-			Name:            p.Name,
-			Slug:            p.Slug,
-			Description:     p.Description,
-			Price:           p.Price,
-			Discount:        p.Discount,
-			Status:          p.Status,
-			Quantity:        p.Quantity,
-			Thumbnail:       p.Thumbnail,
-			Image:           p.Images,
-			File:            &p.File,
-			Store:           p.Store,
-			Category:        p.Category,
-			Subcategory:     p.Subcategory,
-			AlwaysAvailable: &p.AlwaysAvailbale,
-		})
+	var rejectReason string
+	if reason != nil {
+		rejectReason = *reason
+	}
+	kycHandler := kyc.NewHandler(kyc.NewService(kyc.NewRepository()))
+	v, err := kycHandler.ReviewIdentity(ctx, uint32(id), adminID, approve, rejectReason)
+	if err != nil {
+		return nil, err
+	}
+	return identityVerificationToModel(v), nil
+}
+
+// SetStoreHours is the resolver for the setStoreHours field.
+func (r *mutationResolver) SetStoreHours(ctx context.Context, storeID int, input model.StoreHoursInput) (*model.StoreHours, error) {
+	if _, err := r.requireStorePermission(ctx, uint32(storeID), store.PermSettings); err != nil {
+		return nil, err
 	}
 
-	return modelProducts, nil
+	hours := &store.StoreHours{
+		StoreID: uint32(storeID),
+		Hours:   make([]booking.WeeklyHours, 0, len(input.Hours)),
+	}
+	for _, h := range input.Hours {
+		hours.Hours = append(hours.Hours, booking.WeeklyHours{Weekday: time.Weekday(h.Weekday), Opens: h.Opens, Closes: h.Closes})
+	}
+	if input.AwayMessage != nil {
+		hours.AwayMessage = *input.AwayMessage
+	}
+	if input.AcceptLaterOrders != nil {
+		hours.AcceptLaterOrders = *input.AcceptLaterOrders
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	saved, err := storeHandler.SetStoreHours(ctx, hours)
+	if err != nil {
+		return nil, err
+	}
+	return storeHoursToModel(saved), nil
 }
 
-// Stores is the resolver for the Stores field.
-func (r *queryResolver) Stores(ctx context.Context, user *int, limit *int, offset *int) (*model.StorePaginationData, error) {
-	// Convert nullable parameters to concrete values
-	var userID uint32
-	if user != nil {
-		userID = uint32(*user)
+// ScheduleStoreClosure is the resolver for the scheduleStoreClosure field.
+func (r *mutationResolver) ScheduleStoreClosure(ctx context.Context, input model.StoreClosureInput) (*model.StoreClosure, error) {
+	if _, err := r.requireStorePermission(ctx, uint32(input.StoreID), store.PermSettings); err != nil {
+		return nil, err
 	}
-	limitValue := 100
-	if limit != nil {
-		limitValue = *limit
+
+	closure := &store.StoreClosure{
+		StoreID:  uint32(input.StoreID),
+		Kind:     input.Kind,
+		StartsAt: input.StartsAt,
+		EndsAt:   input.EndsAt,
 	}
-	offsetValue := 0
-	if offset != nil {
-		offsetValue = *offset
+	if input.Message != nil {
+		closure.Message = *input.Message
 	}
 
-	// Get stores from repository
 	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-	stores, err := storeHandler.GetStores(ctx, userID, limitValue, offsetValue)
+	saved, err := storeHandler.ScheduleClosure(ctx, closure)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch stores: %v", err)
+		return nil, err
 	}
+	return storeClosureToModel(saved), nil
+}
 
-	// Convert to GraphQL model
-	var modelStores []*model.Store
-	for _, s := range stores {
-		if s == nil {
-			continue
-		}
+// EndStoreClosure is the resolver for the endStoreClosure field.
+func (r *mutationResolver) EndStoreClosure(ctx context.Context, id int) (*model.StoreClosure, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	closure, err := storeHandler.GetStoreClosure(ctx, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.requireStorePermission(ctx, closure.StoreID, store.PermSettings); err != nil {
+		return nil, err
+	}
 
-		// Convert followers
-		var followers []*model.StoreFollower
-		for _, f := range s.Followers {
-			followers = append(followers, &model.StoreFollower{
-				FollowerID:    int(f.FollowerID),
-				FollowerName:  f.FollowerName,
-				StoreID:       int(f.StoreID),
-				FollowerImage: f.FollowerImage,
-			})
-		}
+	ended, err := storeHandler.EndClosure(ctx, closure.ID)
+	if err != nil {
+		return nil, err
+	}
+	return storeClosureToModel(ended), nil
+}
 
-		// Convert products
-		var products []*model.Product
-		for _, p := range s.Products {
-			products = append(products, &model.Product{
-				ID:          int(p.ID),
-				Name:        p.Name,
-				Price:       p.Price,
-				Description: p.Description,
-				Discount:    p.Discount,
-				Image:       p.Images,
-				Slug:        p.Slug,
-				Quantity:    p.Quantity,
-				Status:      p.Status,
-				Thumbnail:   p.Thumbnail,
-				Store:       p.Store,
-				Category:    p.Category,
-				UnitsSold:   p.UnitsSold,
-				Subcategory: p.Subcategory,
-			})
-		}
+// PostStoreAnnouncement is the resolver for the postStoreAnnouncement field.
+func (r *mutationResolver) PostStoreAnnouncement(ctx context.Context, input model.StoreAnnouncementInput) (*model.StoreAnnouncement, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.requireStorePermission(ctx, uint32(input.StoreID), store.PermSettings); err != nil {
+		return nil, err
+	}
 
-		// Convert withdrawal accounts
-		var accounts []*model.WithdrawAccount
-		for _, a := range s.Accounts {
-			accounts = append(accounts, &model.WithdrawAccount{
-				BankCode:      a.BankCode,
-				BankName:      a.BankName,
-				BankImage:     a.BankImage,
-				AccountNumber: a.AccountNumber,
-				AccountName:   a.AccountName,
-			})
-		}
+	announcement := &store.StoreAnnouncement{
+		StoreID:  uint32(input.StoreID),
+		Title:    input.Title,
+		Body:     input.Body,
+		PostedBy: userID,
+	}
+	if input.Image != nil {
+		announcement.Image = *input.Image
+	}
 
-		modelStore := &model.Store{
-			ID:                 strconv.Itoa(int(s.ID)), // Convert ID to string
-			Link:               s.Link,
-			Name:               s.Name,
-			User:               int(s.UserID),
-			Description:        s.Description,
-			Thumbnail:          s.Thumbnail,
-			Phone:              s.Phone,
-			Background:         s.Background,
-			Address:            s.Address,
-			Status:             s.Status,
-			HasPhysicalAddress: s.HasPhysicalAddress,
-			Wallet:             float64(s.Wallet),
-			Visitors:           s.Visitors,
-			Followers:          followers,
-			Product:            products,
-			Accounts:           accounts,
-			MaintenanceMode:    s.MaintenanceMode,
-		}
-		modelStores = append(modelStores, modelStore)
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	posted, err := storeHandler.PostAnnouncement(ctx, announcement)
+	if err != nil {
+		return nil, err
 	}
+	return storeAnnouncementToModel(posted), nil
+}
 
-	return &model.StorePaginationData{
-		Data:        modelStores,
-		CurrentPage: offsetValue + 1,
-		PerPage:     limitValue,
-		Total:       len(modelStores),
-	}, nil
+// DeleteStoreAnnouncement is the resolver for the deleteStoreAnnouncement field.
+func (r *mutationResolver) DeleteStoreAnnouncement(ctx context.Context, id int) (bool, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	announcement, err := storeHandler.GetAnnouncement(ctx, uint32(id))
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.requireStorePermission(ctx, announcement.StoreID, store.PermSettings); err != nil {
+		return false, err
+	}
+
+	if err := storeHandler.DeleteAnnouncement(ctx, announcement.ID); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// Store is the resolver for the Store field.
-func (r *queryResolver) Store(ctx context.Context, id int) (*model.Store, error) {
-	panic(fmt.Errorf("not implemented: Store - Store"))
+// SetFollowDigestEmail is the resolver for the setFollowDigestEmail field.
+func (r *mutationResolver) SetFollowDigestEmail(ctx context.Context, enabled bool) (bool, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.DB.Model(&user.User{}).Where("id = ?", userID).Update("follow_digest_email", enabled).Error; err != nil {
+		return false, fmt.Errorf("failed to update follow digest: %v", err)
+	}
+	return enabled, nil
 }
 
-// Reviews is the resolver for the Reviews field.
-func (r *queryResolver) Reviews(ctx context.Context, id string, value string) ([]*model.Review, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("database connection failed")
+// SetStoreCustomerTags is the resolver for the setStoreCustomerTags field.
+func (r *mutationResolver) SetStoreCustomerTags(ctx context.Context, storeID int, userID int, tags []string) (*model.StoreCustomerProfile, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermCustomers)
+	if err != nil {
+		return nil, err
 	}
 
-	var reviews []*model.Review
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	customer, err := storeHandler.SetCustomerTags(ctx, storeObj, uint32(userID), tags)
+	if err != nil {
+		return nil, err
+	}
+	return storeCustomerToModel(customer), nil
+}
 
-	// Common struct for all review types
-	type dbReview struct {
-		StoreID   int       `json:"store_id"`
-		ProductID int       `json:"product_id"`
-		OrderID   string    `json:"order_id"`
-		SellerID  int       `json:"seller_id"`
-		Rating    float64   `json:"rating"`
-		Message   string    `json:"message"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Nickname  string    `json:"nickname"`
-		Avatar    string    `json:"avatar"`
+// AddStoreCustomerNote is the resolver for the addStoreCustomerNote field.
+func (r *mutationResolver) AddStoreCustomerNote(ctx context.Context, storeID int, userID int, body string) (*model.StoreCustomerNote, error) {
+	authorID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermCustomers)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert ID based on type
-	numericID, err := strconv.Atoi(id)
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	note, err := storeHandler.AddCustomerNote(ctx, storeObj, &store.StoreCustomerNote{
+		UserID:   uint32(userID),
+		Body:     body,
+		AuthorID: authorID,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid %s ID: %v", value, err)
+		return nil, err
 	}
+	return storeCustomerNoteToModel(note), nil
+}
 
-	// Build query based on type
-	query := db.Table("reviews").
-		Select("reviews.*, users.nickname, users.avatar").
-		Joins("LEFT JOIN users ON reviews.buyer_id = users.id")
+// DeleteStoreCustomerNote is the resolver for the deleteStoreCustomerNote field.
+func (r *mutationResolver) DeleteStoreCustomerNote(ctx context.Context, id int) (bool, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	note, err := storeHandler.GetCustomerNote(ctx, uint32(id))
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.requireStorePermission(ctx, note.StoreID, store.PermCustomers); err != nil {
+		return false, err
+	}
 
-	// Add type-specific where clause
-	switch value {
-	case "product":
-		query = query.Where("reviews.product_id = ?", numericID)
-	case "store":
-		query = query.Where("reviews.store_id = ?", numericID)
-	case "order":
-		query = query.Where("reviews.order_id = ?", numericID)
-	default:
-		return nil, fmt.Errorf("invalid value parameter: %s", value)
+	if err := storeHandler.DeleteCustomerNote(ctx, note.ID); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	// Execute query
-	var dbReviews []dbReview
-	if err := query.Scan(&dbReviews).Error; err != nil {
-		return nil, fmt.Errorf("error fetching %s reviews: %v", value, err)
+// SendStorePromo is the resolver for the sendStorePromo field.
+func (r *mutationResolver) SendStorePromo(ctx context.Context, input model.StorePromoInput) (*model.StorePromoCampaign, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storeObj, err := r.requireStorePermission(ctx, uint32(input.StoreID), store.PermCustomers)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert to model.Review objects
-	for _, r := range dbReviews {
-		review := &model.Review{
-			StoreID:   r.StoreID,
-			ProductID: r.ProductID,
-			OrderID:   r.OrderID,
-			SellerID:  r.SellerID,
-			Rating:    r.Rating,
-			Message:   &r.Message,
-			CreatedAt: &r.CreatedAt,
-			UpdatedAt: &r.UpdatedAt,
-			Buyer: &model.ReviewBuyer{
-				Nickname: r.Nickname,
-				Avatar:   r.Avatar,
-				Comment:  r.Message,
-			},
-		}
-		reviews = append(reviews, review)
+	campaign := &store.StorePromoCampaign{
+		Title:   input.Title,
+		Message: input.Message,
+		SentBy:  userID,
+	}
+	if input.Segment != nil {
+		campaign.Segment = *input.Segment
+	}
+	if input.Tag != nil {
+		campaign.Tag = *input.Tag
+	}
+	if input.ProductID != nil {
+		campaign.ProductID = uint32(*input.ProductID)
+	}
+	if input.Email != nil {
+		campaign.Email = *input.Email
+	}
+	if !store.ValidSegment(campaign.Segment) {
+		return nil, fmt.Errorf("unknown segment %q", campaign.Segment)
 	}
 
-	return reviews, nil
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	sent, err := storeHandler.SendPromo(ctx, storeObj, campaign)
+	if err != nil {
+		return nil, err
+	}
+	return storePromoCampaignToModel(sent), nil
 }
 
-// StoreByName is the resolver for the StoreByName field.
-func (r *queryResolver) StoreByName(ctx context.Context, name string) (*model.Store, error) {
+// OpenOrderDispute is the resolver for the openOrderDispute field.
+func (r *mutationResolver) OpenOrderDispute(ctx context.Context, input model.OrderDisputeInput) (*model.OrderDispute, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute := &store.OrderDispute{
+		OrderUUID: input.OrderID,
+		StoreID:   uint32(input.StoreID),
+		BuyerID:   userID,
+		Reason:    input.Reason,
+	}
+	if input.Details != nil {
+		dispute.Details = *input.Details
+	}
+
 	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	opened, err := storeHandler.OpenDispute(ctx, dispute)
+	if err != nil {
+		return nil, err
+	}
+	return orderDisputeToModel(opened), nil
+}
 
-	// Get store by name
-	s, err := storeHandler.GetStoreByName(ctx, name)
+// ResolveOrderDispute is the resolver for the resolveOrderDispute field.
+func (r *mutationResolver) ResolveOrderDispute(ctx context.Context, id int, upheld bool, resolution string) (*model.OrderDispute, error) {
+	adminID, err := r.requireAdmin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch store: %v", err)
+		return nil, err
 	}
 
-	// Convert followers to GraphQL model
-	var followers []*model.StoreFollower
-	for _, f := range s.Followers {
-		followers = append(followers, &model.StoreFollower{
-			FollowerID:    int(f.FollowerID),
-			FollowerName:  f.FollowerName,
-			StoreID:       int(f.StoreID),
-			FollowerImage: f.FollowerImage,
-		})
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	dispute, err := storeHandler.ResolveDispute(ctx, uint32(id), adminID, upheld, resolution)
+	if err != nil {
+		return nil, err
 	}
+	return orderDisputeToModel(dispute), nil
+}
 
-	// Convert products to GraphQL model
-	var products []*model.Product
-	for _, p := range s.Products {
-		products = append(products, &model.Product{
-			ID:          int(p.ID),
-			Name:        p.Name,
-			Price:       p.Price,
-			Description: p.Description,
-			Discount:    p.Discount,
-			Image:       p.Images,
-			Slug:        p.Slug,
-			Quantity:    p.Quantity,
-			Status:      p.Status,
-			Thumbnail:   p.Thumbnail,
-			Store:       p.Store,
-			Category:    p.Category,
-			Subcategory: p.Subcategory,
-		})
+// SetStorePolicy is the resolver for the setStorePolicy field.
+func (r *mutationResolver) SetStorePolicy(ctx context.Context, storeID int, input model.StorePolicyInput) (*model.StorePolicy, error) {
+	if _, err := r.requireStorePermission(ctx, uint32(storeID), store.PermSettings); err != nil {
+		return nil, err
 	}
 
-	return &model.Store{
-		ID:                 strconv.Itoa(int(s.ID)),
-		Link:               s.Link,
-		Name:               s.Name,
-		User:               int(s.UserID),
-		Description:        s.Description,
-		Thumbnail:          s.Thumbnail,
-		Phone:              s.Phone,
-		Background:         s.Background,
-		Address:            s.Address,
-		Status:             s.Status,
-		HasPhysicalAddress: s.HasPhysicalAddress,
-		Wallet:             float64(s.Wallet),
-		Visitors:           s.Visitors,
-		Followers:          followers,
-		Product:            products,
-	}, nil
-}
+	policy := &store.StorePolicy{
+		StoreID:            uint32(storeID),
+		Pickup:             input.Pickup,
+		CampusDelivery:     input.CampusDelivery,
+		NationwideShipping: input.NationwideShipping,
+		ProcessingDays:     input.ProcessingDays,
+		MinOrderValue:      input.MinOrderValue,
+		ReturnsAccepted:    input.ReturnsAccepted,
+	}
+	if input.PickupLocation != nil {
+		policy.PickupLocation = *input.PickupLocation
+	}
+	if input.ReturnWindowDays != nil {
+		policy.ReturnWindowDays = *input.ReturnWindowDays
+	}
+	if input.ReturnPolicy != nil {
+		policy.ReturnPolicy = *input.ReturnPolicy
+	}
 
-// PurchasedOrder is the resolver for the PurchasedOrder field.
-func (r *queryResolver) PurchasedOrder(ctx context.Context, user int) ([]*model.PurchasedOrder, error) {
 	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	// Pass the user ID directly as integer
-	orders, err := storeHandler.GetPurchasedOrders(ctx, fmt.Sprintf("%d", user))
+	saved, err := storeHandler.SetStorePolicy(ctx, policy)
 	if err != nil {
-		// Check for specific error types
-		switch {
-		case strings.Contains(err.Error(), "record not found"):
-			// Return empty array if no orders found
-			return []*model.PurchasedOrder{}, nil
-		case strings.Contains(err.Error(), "<!DOCTYPE"):
-			// Return empty array for HTML response
-			return []*model.PurchasedOrder{}, nil
-		case strings.Contains(err.Error(), "unsupported relations"):
-			// Return empty array for relation errors
-			return []*model.PurchasedOrder{}, nil
-		default:
-			return nil, fmt.Errorf("failed to fetch purchased orders: %v", err)
-		}
+		return nil, err
 	}
+	return storePolicyToModel(saved), nil
+}
 
-	if orders == nil {
-		return []*model.PurchasedOrder{}, nil
+// StartStoreCloseout is the resolver for the startStoreCloseout field.
+func (r *mutationResolver) StartStoreCloseout(ctx context.Context, storeID int, reason *string) (*model.StoreCloseout, error) {
+	storeObj, userID, err := r.requireStoreOwnerOrAdmin(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
 	}
 
-	// Rest of the conversion code remains the same
-	var purchasedOrders []*model.PurchasedOrder
-	for _, order := range orders {
-		if order == nil {
-			continue
-		}
+	reasonText := ""
+	if reason != nil {
+		reasonText = *reason
+	}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	closeout, err := storeHandler.StartStoreCloseout(ctx, storeObj, userID, reasonText)
+	if err != nil {
+		return nil, err
+	}
+	return storeCloseoutToModel(closeout), nil
+}
 
-		// Convert tracked products with nil check
-		var products []*model.TrackedProduct
-		if order.Products != nil {
-			for _, p := range order.Products {
-				products = append(products, &model.TrackedProduct{
-					ID:        int(p.ID),
-					Name:      p.Name,
-					Price:     p.Price,
-					Thumbnail: p.Thumbnail,
-					Discount:  p.Discount,
-					Status:    p.Status,
-				})
-			}
-		}
+// CancelStoreCloseout is the resolver for the cancelStoreCloseout field.
+func (r *mutationResolver) CancelStoreCloseout(ctx context.Context, storeID int) (bool, error) {
+	if _, _, err := r.requireStoreOwnerOrAdmin(ctx, uint32(storeID)); err != nil {
+		return false, err
+	}
 
-		// Convert delivery details with nil check and default values
-		var deliveryDetails *model.DeliveryDetails
-		if order.DeliveryDetails != nil {
-			deliveryDetails = &model.DeliveryDetails{
-				Method:  order.DeliveryDetails.Method,
-				Address: order.DeliveryDetails.Address,
-				Fee:     order.DeliveryDetails.Fee,
-			}
-		} else {
-			// Provide default values when delivery details are missing
-			deliveryDetails = &model.DeliveryDetails{
-				Method:  "digital", // Default to digital delivery
-				Address: "N/A",     // Default address
-				Fee:     0,         // Default fee
-			}
-		}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	if err := storeHandler.CancelStoreCloseout(ctx, uint32(storeID)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-		// Convert string values to float64
-		fee, _ := strconv.ParseFloat(order.Fee, 64)
-		amount, _ := strconv.ParseFloat(order.Amount, 64)
+// RequestFinalPayout is the resolver for the requestFinalPayout field.
+func (r *mutationResolver) RequestFinalPayout(ctx context.Context, storeID int, accountNumber string) (float64, error) {
+	// Only the owner's role grants withdrawals
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermWithdrawals)
+	if err != nil {
+		return 0, err
+	}
 
-		purchasedOrder := &model.PurchasedOrder{
-			CartID:          int(order.CartID),
-			Coupon:          order.Coupon,
-			Fee:             fee,
-			Status:          order.Status,
-			UserID:          order.UserID,
-			Amount:          amount,
-			UUID:            order.UUID,
-			PaymentGateway:  order.PaymentGateway,
-			PaymentMethod:   order.PaymentMethod,
-			TransRef:        order.TransRef,
-			TransStatus:     order.TransStatus,
-			Products:        products,
-			CreatedAt:       order.CreatedAt,
-			DeliveryDetails: deliveryDetails, // Now this will never be nil
-			TextRef:         order.TransRef,
-		}
-		purchasedOrders = append(purchasedOrders, purchasedOrder)
+	storeRepo := store.NewRepository()
+	closeout, err := storeRepo.GetStoreCloseout(ctx, storeObj.ID)
+	if err != nil {
+		return 0, err
+	}
+	if closeout.Status != store.CloseoutPending {
+		return 0, fmt.Errorf("start closing the store before requesting a final payout")
+	}
+	amount := storeObj.Wallet + storeObj.PaystackBalance
+	if amount <= 0 {
+		return 0, fmt.Errorf("there is nothing left in the store balance to pay out")
+	}
+	// A balance over the owner's withdrawal limit is paid out in parts, one
+	// per request, so unverified sellers can still close
+	limit, _, err := kyc.WithdrawalLimit(storeRepo.GetDB().WithContext(ctx), storeObj.ID)
+	if err != nil {
+		return 0, err
+	}
+	if limit > 0 {
+		amount = min(amount, limit)
 	}
 
-	return purchasedOrders, nil
-}
+	var bankDetails struct {
+		BankName      string
+		AccountName   string
+		AccountNumber string
+	}
+	if err := storeRepo.GetDB().Table("dva_accounts").
+		Where("store_id = ? AND account_number = ?", storeID, accountNumber).
+		First(&bankDetails).Error; err != nil {
+		return 0, fmt.Errorf("failed to get bank details: %w", err)
+	}
 
-// Skynets is the resolver for the Skynets field.
-func (r *queryResolver) Skynets(ctx context.Context, id string) ([]*model.Skynet, error) {
-	panic(fmt.Errorf("not implemented: Skynets - Skynets"))
+	_, err = withdrawal.NewRepository().CreateWithdrawal(ctx, &shared.NewWithdrawal{
+		StoreID:       storeObj.ID,
+		Amount:        amount,
+		BankName:      bankDetails.BankName,
+		AccountNumber: accountNumber,
+		AccountName:   bankDetails.AccountName,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create withdrawal: %w", err)
+	}
+	return amount, nil
 }
 
-// Skynet is the resolver for the Skynet field.
-func (r *queryResolver) Skynet(ctx context.Context, id string) (*model.Skynet, error) {
-	panic(fmt.Errorf("not implemented: Skynet - Skynet"))
-}
+// CloseStore is the resolver for the closeStore field.
+func (r *mutationResolver) CloseStore(ctx context.Context, storeID int) (*model.StoreCloseout, error) {
+	storeObj, userID, err := r.requireStoreOwnerOrAdmin(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
+	}
 
-// Mydva is the resolver for the MYDVA field.
-func (r *queryResolver) Mydva(ctx context.Context, email string) (*model.Account, error) {
 	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	closeout, err := storeHandler.CloseStore(ctx, storeObj.ID, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	account, err := storeHandler.GetDVAAccount(ctx, email)
+	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+	if _, err := cartHandler.RemoveStoreFromCarts(ctx, storeObj.Name); err != nil {
+		log.Printf("Failed to remove closed store %s from carts: %v", storeObj.Name, err)
+	}
+	return storeCloseoutToModel(closeout), nil
+}
+
+// SetStoreHandle is the resolver for the setStoreHandle field.
+func (r *mutationResolver) SetStoreHandle(ctx context.Context, storeID int, handle string) (*model.Store, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermSettings)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch DVA account: %v", err)
+		return nil, err
 	}
 
-	// Convert string ID to uint64
-	id, err := strconv.ParseUint(account.ID, 10, 64)
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	updated, err := storeHandler.SetStoreHandle(ctx, storeObj, handle)
 	if err != nil {
-		return nil, fmt.Errorf("invalid account ID: %v", err)
+		return nil, err
 	}
+	return publicStoreToModel(updated), nil
+}
 
-	// Convert customer ID to int
-	customerID, err := strconv.Atoi(account.Customer.ID)
+// Attributes is the resolver for the attributes field.
+func (r *productResolver) Attributes(ctx context.Context, obj *model.Product) ([]*model.ProductAttribute, error) {
+	var p product.Product
+	if err := r.DB.WithContext(ctx).Select("id", "attributes").First(&p, obj.ID).Error; err != nil {
+		return []*model.ProductAttribute{}, nil
+	}
+	return productAttributesToModel(p.Attributes), nil
+}
+
+// File is the resolver for the file field.
+func (r *productResolver) File(ctx context.Context, obj *model.Product) (*string, error) {
+	// The raw URL skips the signed download links, so only the store's product
+	// managers and paying buyers see it
+	if obj.File == nil || *obj.File == "" || r.managesProducts(ctx, obj.Store) {
+		return obj.File, nil
+	}
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid customer ID: %v", err)
+		return nil, nil
+	}
+	downloadHandler := download.NewHandler(download.NewService(download.NewRepository()))
+	owned, err := downloadHandler.OwnsProductFile(ctx, uint32(obj.ID), strconv.Itoa(int(userID)))
+	if err != nil || !owned {
+		return nil, err
 	}
+	return obj.File, nil
+}
 
-	// Convert bank ID to int
-	bankID, err := strconv.Atoi(account.Bank.ID)
+// PriceHistory is the resolver for the priceHistory field.
+func (r *productResolver) PriceHistory(ctx context.Context, obj *model.Product) ([]*model.PriceHistoryEntry, error) {
+	history, err := r.ProductHandler.GetPriceHistory(ctx, uint32(obj.ID))
 	if err != nil {
-		return nil, fmt.Errorf("invalid bank ID: %v", err)
+		return nil, fmt.Errorf("failed to fetch price history: %v", err)
 	}
 
-	return &model.Account{
-		Customer: &model.Customer{
-			ID:        strconv.Itoa(customerID),
-			FirstName: account.Customer.Email,
-			LastName:  "",
-		},
-		Bank: &model.Bank{
-			ID:   strconv.Itoa(bankID), // Use the converted bankID here
-			Name: account.Bank.Name,
-			Slug: account.Bank.Slug,
-		},
-		ID:            strconv.FormatUint(id, 10),
-		AccountNumber: account.AccountNumber,
-		AccountName:   account.AccountName,
-	}, nil
+	entries := make([]*model.PriceHistoryEntry, 0, len(history))
+	for _, h := range history {
+		entries = append(entries, &model.PriceHistoryEntry{
+			Price:          h.Price,
+			Discount:       h.Discount,
+			EffectivePrice: h.Price - h.Discount,
+			ChangedAt:      h.CreatedAt,
+		})
+	}
+	return entries, nil
 }
 
-// GetDVABalance is the resolver for the GetDVABalance field.
-func (r *queryResolver) GetDVABalance(ctx context.Context, accountNumber string, storeID *int) (*string, error) {
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	// Get PayStack DVA balance
-	paystackBalance, err := storeHandler.GetDVABalance(ctx, accountNumber)
+// Questions is the resolver for the questions field.
+func (r *productResolver) Questions(ctx context.Context, obj *model.Product) ([]*model.ProductQuestion, error) {
+	// Product pages show the most upvoted answered questions; the full thread is on productQuestions
+	questions, err := r.ProductHandler.GetProductQuestions(ctx, uint32(obj.ID), true, 10, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch DVA balance: %v", err)
+		return nil, fmt.Errorf("failed to fetch questions: %v", err)
 	}
 
-	totalBalance := paystackBalance
+	result := make([]*model.ProductQuestion, 0, len(questions))
+	for _, q := range questions {
+		result = append(result, productQuestionToModel(q))
+	}
+	return result, nil
+}
 
-	// If storeID is provided, update store wallet and add store earnings
-	if storeID != nil {
-		storeRepo := store.NewRepository()
-		store, err := storeRepo.GetStore(ctx, uint32(*storeID))
-		if err != nil {
-			return nil, fmt.Errorf("failed to get store: %v", err)
-		}
+// BundleItems is the resolver for the bundleItems field.
+func (r *productResolver) BundleItems(ctx context.Context, obj *model.Product) ([]*model.BundleItem, error) {
+	if !obj.IsBundle {
+		return []*model.BundleItem{}, nil
+	}
+	items, err := r.ProductHandler.GetBundleItems(ctx, uint32(obj.ID))
+	if err != nil {
+		return nil, err
+	}
 
-		// Calculate total balance including released earnings
-		earnings, err := storeHandler.GetStoreEarnings(ctx, uint32(*storeID))
-		if err == nil {
-			// Sum up all released earnings
-			for _, earning := range earnings {
-				if earning.Status == "released" {
-					totalBalance += earning.Amount
-				}
-			}
+	result := make([]*model.BundleItem, 0, len(items))
+	for _, item := range items {
+		if item.Component == nil {
+			continue
 		}
-
-		// Get total withdrawals
-		var totalWithdrawals float64
-		var withdrawals []*shared.Withdrawal
-		if err := storeRepo.GetDB().Where("store_id = ? AND status IN ?", *storeID, []string{"pending", "approved", "completed"}).Find(&withdrawals).Error; err == nil {
-			// Sum up all withdrawals
-			for _, withdrawal := range withdrawals {
-				totalWithdrawals += withdrawal.Amount
-			}
+		available := item.Component.Quantity / item.Quantity
+		if item.Component.AlwaysAvailbale {
+			available = obj.Quantity
 		}
+		result = append(result, &model.BundleItem{
+			ProductID: int(item.ComponentID),
+			Name:      item.Component.Name,
+			Thumbnail: item.Component.Thumbnail,
+			Quantity:  item.Quantity,
+			Available: available,
+		})
+	}
+	return result, nil
+}
 
-		// Subtract withdrawals from total balance
-		totalBalance -= totalWithdrawals
-
-		// Update store wallet with total balance (Paystack + released earnings - withdrawals)
-		if store.Wallet != totalBalance {
-			if err := storeRepo.UpdateWallet(ctx, uint32(*storeID), totalBalance-store.Wallet); err != nil {
-				return nil, fmt.Errorf("failed to update store wallet: %v", err)
-			}
-		}
+// PricingRules is the resolver for the pricingRules field.
+func (r *productResolver) PricingRules(ctx context.Context, obj *model.Product) ([]*model.PricingRule, error) {
+	rules, err := r.ProductHandler.GetPricingRules(ctx, uint32(obj.ID))
+	if err != nil {
+		return nil, err
 	}
 
-	balanceStr := strconv.FormatFloat(totalBalance, 'f', 2, 64)
-	return &balanceStr, nil
+	result := make([]*model.PricingRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, pricingRuleToModel(rule))
+	}
+	return result, nil
 }
 
-// MyInvoices is the resolver for the MyInvoices field.
-func (r *queryResolver) MyInvoices(ctx context.Context, storeID *int) ([]*model.Invoice, error) {
-	panic(fmt.Errorf("not implemented: MyInvoices - MyInvoices"))
+// Sale is the resolver for the sale field.
+func (r *productResolver) Sale(ctx context.Context, obj *model.Product) (*model.ActiveSale, error) {
+	sale, err := r.ProductHandler.GetActiveSale(ctx, uint32(obj.ID))
+	if err != nil || sale == nil {
+		return nil, err
+	}
+	return &model.ActiveSale{
+		CampaignID:  int(sale.CampaignID),
+		Name:        sale.Name,
+		SalePrice:   sale.UnitPrice,
+		EndsAt:      sale.EndsAt,
+		SecondsLeft: secondsUntil(sale.EndsAt),
+		Remaining:   remainingToModel(sale.Remaining),
+	}, nil
 }
 
-// MyDownloads is the resolver for the MyDownloads field.
-func (r *queryResolver) MyDownloads(ctx context.Context, id string) ([]*model.Downloads, error) {
-	panic(fmt.Errorf("not implemented: MyDownloads - MyDownloads"))
+// Availability is the resolver for the availability field.
+func (r *productResolver) Availability(ctx context.Context, obj *model.Product) (*model.ServiceAvailability, error) {
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	availability, err := bookingHandler.GetAvailability(ctx, uint32(obj.ID))
+	if err != nil || availability == nil {
+		return nil, err
+	}
+	return availabilityToModel(availability), nil
 }
 
-// GetUsers is the resolver for the getUsers field.
-func (r *queryResolver) GetUsers(ctx context.Context) ([]*model.User, error) {
-	users, err := r.UserHandler.GetUsers(ctx)
+// StorePolicy is the resolver for the storePolicy field.
+func (r *productResolver) StorePolicy(ctx context.Context, obj *model.Product) (*model.StorePolicy, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	storeObj, err := storeHandler.GetStoreByName(ctx, obj.Store)
+	if err != nil {
+		// Products of purged stores have no policy to show
+		return nil, nil
+	}
+	policy, err := storeHandler.GetStorePolicy(ctx, storeObj)
+	if err != nil {
+		return nil, err
+	}
+	return storePolicyToModel(policy), nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, limit *int, offset *int) ([]*model.User, error) {
+	userHandler := user.NewHandler(user.NewService(user.NewRepository()))
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Get all users
+	users, err := userHandler.GetUsers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch users: %v", err)
 	}
 
-	log.Printf("Fetched %d users", len(users))
+	// Handle pagination if offset and limit are provided
+	start := 0
+	if offset != nil {
+		start = *offset
+	}
+
+	end := len(users)
+	if limit != nil {
+		if start+*limit < end {
+			end = start + *limit
+		}
+	}
+
+	// Apply pagination
+	if start < len(users) {
+		users = users[start:end]
+	} else {
+		users = []*user.User{}
+	}
 
 	var userResponses []*model.User
 	for _, u := range users {
-		log.Printf("Processing user: ID=%d, Type=%s", u.ID, u.Usertype)
-
 		active := true
 		if u.Active != nil {
 			active = *u.Active
 		}
 
-		// Convert string to pointer for Dob fields
-		var dobPtr *string
+		// Convert string to pointer for optional fields
+		var dobPtr, genderPtr, avatarPtr *string
 		if u.Dob != "" {
 			dobPtr = &u.Dob
 		}
-
-		// Convert string to pointer for Gender fields
-		var genderPtr *string
 		if u.Gender != "" {
 			genderPtr = &u.Gender
 		}
-
-		// Convert string to pointer for Avatar field
-		var avatarPtr *string
 		if u.Avatar != "" {
 			avatarPtr = &u.Avatar
 		}
@@ -2828,51 +3115,38 @@ func (r *queryResolver) GetUsers(ctx context.Context) ([]*model.User, error) {
 			Online:   u.Online,
 		}
 
-		// If user is a seller, fetch all their stores
+		// If user is a seller, fetch their store
 		if u.Usertype == "seller" {
-			log.Printf("Fetching stores for seller user ID: %d", u.ID)
-			storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-			stores, err := storeHandler.GetStores(ctx, u.ID, 0, 0) // Fetch all stores for this user
-			if err != nil {
-				log.Printf("Error fetching stores for user %d: %v", u.ID, err)
-			} else {
-				log.Printf("Found %d stores for user %d", len(stores), u.ID)
-				if len(stores) > 0 {
-					var storeModels []*model.Store
-					for _, s := range stores {
-						log.Printf("Processing store: ID=%d, Name=%s", s.ID, s.Name)
-						// Convert followers to GraphQL model
-						var followers []*model.StoreFollower
-						for _, f := range s.Followers {
-							followers = append(followers, &model.StoreFollower{
-								FollowerID:    int(f.FollowerID),
-								FollowerName:  f.FollowerName,
-								StoreID:       int(f.StoreID),
-								FollowerImage: f.FollowerImage,
-							})
-						}
+			// Get the store for this seller
+			userStore, err := storeHandler.GetStore(ctx, u.ID)
+			if err == nil && userStore != nil {
+				// Convert followers to GraphQL model
+				var followers []*model.StoreFollower
+				for _, f := range userStore.Followers {
+					followers = append(followers, &model.StoreFollower{
+						FollowerID:    int(f.FollowerID),
+						FollowerName:  f.FollowerName,
+						StoreID:       int(f.StoreID),
+						FollowerImage: f.FollowerImage,
+					})
+				}
 
-						storeModel := &model.Store{
-							ID:                 strconv.Itoa(int(s.ID)),
-							Name:               s.Name,
-							Link:               s.Link,
-							Description:        s.Description,
-							Address:            s.Address,
-							Phone:              s.Phone,
-							HasPhysicalAddress: s.HasPhysicalAddress,
-							Thumbnail:          s.Thumbnail,
-							Background:         s.Background,
-							Status:             s.Status,
-							User:               int(s.UserID),
-							Wallet:             float64(s.Wallet),
-							Followers:          followers,
-							Email:              s.Email,
-							MaintenanceMode:    s.MaintenanceMode,
-						}
-						storeModels = append(storeModels, storeModel)
-					}
-					userResponse.Stores = storeModels
+				storeModel := &model.Store{
+					ID:                 strconv.Itoa(int(userStore.ID)),
+					Name:               userStore.Name,
+					Link:               userStore.Link,
+					Description:        userStore.Description,
+					Address:            userStore.Address,
+					Phone:              userStore.Phone,
+					HasPhysicalAddress: userStore.HasPhysicalAddress,
+					Thumbnail:          userStore.Thumbnail,
+					Background:         userStore.Background,
+					Status:             userStore.Status,
+					User:               int(userStore.UserID),
+					Wallet:             float64(userStore.Wallet),
+					Followers:          followers,
 				}
+				userResponse.Stores = []*model.Store{storeModel}
 			}
 		}
 
@@ -2882,38 +3156,97 @@ func (r *queryResolver) GetUsers(ctx context.Context) ([]*model.User, error) {
 	return userResponses, nil
 }
 
-// AllStores is the resolver for the allStores field.
-func (r *queryResolver) AllStores(ctx context.Context, limit *int, offset *int) (*model.StorePaginationData, error) {
-	// Convert nullable parameters to concrete values
-	limitValue := 100
-	if limit != nil {
-		limitValue = *limit
+// User is the resolver for the User field.
+func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error) {
+	result, err := r.UserHandler.GetUser(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
 	}
-	offsetValue := 0
-	if offset != nil {
-		offsetValue = *offset
+
+	if result == nil {
+		return nil, fmt.Errorf("user not found")
 	}
 
-	// Get all stores from repository with debug logging
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-	stores, err := storeHandler.GetStores(ctx, 0, limitValue, offsetValue)
+	// Convert repository user to GraphQL model with proper type handling
+	user := &model.User{
+		ID:       strconv.FormatUint(uint64(result.ID), 10),
+		UUID:     result.UUID,
+		Email:    result.Email,
+		Fullname: result.Fullname,
+		Phone:    result.Phone,
+		Campus:   result.Campus,
+		Usertype: result.Usertype,
+		Online:   result.Online,
+		Active:   *result.Active,
+		Twofa:    *result.Twofa,
+	}
+
+	// Handle optional string fields
+	if result.Avatar != "" {
+		user.Avatar = &result.Avatar
+	}
+	if result.Gender != "" {
+		user.Gender = &result.Gender
+	}
+	if result.Dob != "" {
+		user.Dob = &result.Dob
+	}
+
+	// Handle tokens
+	if result.AccessToken != "" {
+		user.AccessToken = &result.AccessToken
+	}
+	if result.RefreshToken != "" {
+		user.RefreshToken = &result.RefreshToken
+	}
+
+	// Handle PaymentDetails if they exist
+	if result.PaymentDetails.Name != "" {
+		user.PaymentDetails = &model.PaymentDetails{
+			Name:    result.PaymentDetails.Name,
+			Phone:   result.PaymentDetails.Phone,
+			Address: result.PaymentDetails.Address,
+			Info:    result.PaymentDetails.Info,
+		}
+	}
+
+	return user, nil
+}
+
+// Categories is the resolver for the Categories field.
+func (r *queryResolver) Categories(ctx context.Context) ([]*model.Category, error) {
+	categories, err := r.ProductHandler.GetCategories(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch stores: %v", err)
+		return nil, fmt.Errorf("failed to fetch categories: %v", err)
 	}
 
-	// Add debug logging
-	log.Printf("Retrieved %d stores from database", len(stores))
+	result := make([]*model.Category, 0, len(categories))
+	for _, cat := range categories {
+		result = append(result, categoryToModel(cat))
+	}
+	return result, nil
+}
+
+// FollowedStores is the resolver for the FollowedStores field.
+func (r *queryResolver) FollowedStores(ctx context.Context, userID int) ([]*model.Store, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Convert int to uint32
+	userIDUint := uint32(userID)
+
+	// Get followed stores
+	stores, err := storeHandler.GetFollowedStores(ctx, userIDUint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch followed stores: %v", err)
+	}
 
 	// Convert to GraphQL model
 	var modelStores []*model.Store
 	for _, s := range stores {
 		if s == nil {
-			log.Printf("Skipping nil store")
 			continue
 		}
 
-		log.Printf("Processing store: ID=%d, Name=%s", s.ID, s.Name)
-
 		// Convert followers
 		var followers []*model.StoreFollower
 		for _, f := range s.Followers {
@@ -2941,25 +3274,14 @@ func (r *queryResolver) AllStores(ctx context.Context, limit *int, offset *int)
 				Thumbnail:   p.Thumbnail,
 				Store:       p.Store,
 				Category:    p.Category,
-				UnitsSold:   p.UnitsSold,
 				Subcategory: p.Subcategory,
 			})
 		}
 
-		// Convert withdrawal accounts
-		var accounts []*model.WithdrawAccount
-		for _, a := range s.Accounts {
-			accounts = append(accounts, &model.WithdrawAccount{
-				BankCode:      a.BankCode,
-				BankName:      a.BankName,
-				BankImage:     a.BankImage,
-				AccountNumber: a.AccountNumber,
-				AccountName:   a.AccountName,
-			})
-		}
-
 		modelStore := &model.Store{
 			ID:                 strconv.Itoa(int(s.ID)),
+			RatingAverage:      s.RatingAverage,
+			ReviewCount:        s.ReviewCount,
 			Link:               s.Link,
 			Name:               s.Name,
 			User:               int(s.UserID),
@@ -2974,513 +3296,2548 @@ func (r *queryResolver) AllStores(ctx context.Context, limit *int, offset *int)
 			Visitors:           s.Visitors,
 			Followers:          followers,
 			Product:            products,
-			Accounts:           accounts,
 		}
 		modelStores = append(modelStores, modelStore)
-		log.Printf("Added store to response: ID=%s, Name=%s", modelStore.ID, modelStore.Name)
 	}
 
-	log.Printf("Returning %d stores in response", len(modelStores))
+	return modelStores, nil
+}
 
-	return &model.StorePaginationData{
-		Data:        modelStores,
+// SellerOrders is the resolver for the SellerOrders field.
+func (r *queryResolver) SellerOrders(ctx context.Context, storeName string) ([]*model.Order, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	orders, err := storeHandler.GetOrdersByStore(ctx, storeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch orders: %v", err)
+	}
+
+	var result []*model.Order
+	for _, order := range orders {
+		// Verify transaction status with Paystack if needed
+		if order.PaymentGateway == "paystack" && (order.Status == "" || order.Status == "not completed" || order.TransStatus == "not paid") {
+			verificationResponse, err := utils.VerifyPaystackTransaction(order.UUID)
+			if err == nil && verificationResponse.Status {
+				if verificationResponse.Data.Status == "success" {
+					// Update to pending if the order is new/empty status or not completed
+					err = storeHandler.UpdateOrderStatus(ctx, order.UUID, "pending", "paid")
+					if err == nil {
+						order.Status = "pending"
+						order.TransStatus = "paid"
+					}
+				}
+			}
+		}
+
+		// Convert products
+		var products []*model.Product
+		for _, p := range order.Products {
+			products = append(products, &model.Product{
+				ID:        int(p.ID),
+				Name:      p.Name,
+				Price:     p.Price,
+				Thumbnail: p.Thumbnail,
+				Status:    p.Status == "active",
+			})
+		}
+
+		// Convert delivery details
+		var deliveryDetails *model.DeliveryDetails
+		if order.DeliveryDetails != nil {
+			deliveryDetails = &model.DeliveryDetails{
+				Method:  order.DeliveryDetails.Method,
+				Address: order.DeliveryDetails.Address,
+				Fee:     order.DeliveryDetails.Fee,
+			}
+		}
+
+		result = append(result, &model.Order{
+			CartID:          int(order.CartID),
+			UUID:            order.UUID,
+			Amount:          order.Amount,
+			Status:          order.Status,
+			PaymentGateway:  order.PaymentGateway,
+			PaymentMethod:   order.PaymentMethod,
+			TransRef:        order.TransRef,
+			TransStatus:     order.TransStatus,
+			Products:        products,
+			DeliveryDetails: deliveryDetails,
+			TextRef:         &order.TransRef,
+			CreatedAt:       &order.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// Category is the resolver for the Category field.
+func (r *queryResolver) Category(ctx context.Context, id int) (*model.Category, error) {
+	categoryID := uint32(id)
+	category, err := r.ProductHandler.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category: %v", err)
+	}
+
+	if category == nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	return categoryToModel(category), nil
+}
+
+// SubCategory is the resolver for the SubCategory field.
+func (r *queryResolver) SubCategory(ctx context.Context, id string) (*model.SubCategory, error) {
+	panic(fmt.Errorf("not implemented: SubCategory - SubCategory"))
+}
+
+// Products is the resolver for the Products field.
+func (r *queryResolver) Products(ctx context.Context, store *string, categorySlug *string, limit *int, offset *int) (*model.ProductPaginationData, error) {
+	fmt.Printf("Received Arguments - limit: %v, offset: %v, store: %v\n", limit, offset, store)
+
+	// Convert nullable parameters
+	limitValue := 12
+	if limit != nil {
+		limitValue = *limit
+	}
+
+	offsetValue := 0
+	if offset != nil {
+		offsetValue = *offset
+	}
+
+	storeValue := ""
+	if store != nil {
+		storeValue = *store
+	}
+
+	var products []*product.Product
+	var total int
+	var err error
+
+	if store != nil && strings.Contains(*store, "-") {
+		// This is a search query
+		searchQuery := strings.TrimSuffix(*store, "-")
+		products, err = r.ProductHandler.SearchProducts(ctx, searchQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search products: %v", err)
+		}
+		total = len(products)
+	} else {
+		listCtx := ctx
+		// Sellers also see their own products that are still in moderation
+		if storeValue != "" && r.managesProducts(ctx, storeValue) {
+			listCtx = product.WithUnmoderated(ctx)
+		}
+		products, total, err = r.ProductHandler.GetProducts(listCtx, storeValue, "", limitValue, offsetValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch products: %v", err)
+		}
+	}
+
+	if products == nil {
+		products = []*product.Product{} // Return empty array instead of nil
+	}
+
+	// Convert to GraphQL model
+	modelProducts := make([]*model.Product, 0, len(products))
+	for _, p := range products {
+		if p == nil {
+			continue
+		}
+		modelProducts = append(modelProducts, &model.Product{
+			ID:              int(p.ID),
+			RatingAverage:   p.RatingAverage,
+			ReviewCount:     p.ReviewCount,
+			Name:            p.Name,
+			Slug:            p.Slug,
+			Description:     p.Description,
+			Price:           p.Price,
+			Discount:        p.Discount,
+			Status:          p.Status,
+			Quantity:        p.Quantity,
+			Thumbnail:       p.Thumbnail,
+			Image:           p.Images,
+			File:            &p.File,
+			Store:           p.Store,
+			Category:        p.Category,
+			Subcategory:     p.Subcategory,
+			AlwaysAvailable: &p.AlwaysAvailbale,
+			IsBundle:        p.IsBundle,
+		})
+	}
+
+	return &model.ProductPaginationData{
+		Data:        modelProducts,
 		CurrentPage: offsetValue + 1,
 		PerPage:     limitValue,
-		Total:       len(modelStores),
+		Total:       total,
+		NextPage:    offsetValue + 2,
+		PrevPage:    offsetValue,
 	}, nil
 }
 
-// GetAllProducts is the resolver for the getAllProducts field.
-func (r *queryResolver) GetAllProducts(ctx context.Context) ([]*model.Product, error) {
+// CategoryFacets is the resolver for the categoryFacets field.
+func (r *queryResolver) CategoryFacets(ctx context.Context, categoryID int) ([]*model.Facet, error) {
+	facets, err := r.ProductHandler.GetCategoryFacets(ctx, uint32(categoryID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch facets: %v", err)
+	}
+
+	result := make([]*model.Facet, 0, len(facets))
+	for _, f := range facets {
+		values := make([]*model.FacetValue, 0, len(f.Values))
+		for _, v := range f.Values {
+			values = append(values, &model.FacetValue{Value: v.Value, Count: v.Count})
+		}
+		result = append(result, &model.Facet{Name: f.Name, Label: f.Label, Values: values})
+	}
+	return result, nil
+}
+
+// ProductsByCategory is the resolver for the productsByCategory field.
+func (r *queryResolver) ProductsByCategory(ctx context.Context, categoryID int, filters []*model.FacetFilterInput, limit *int, offset *int) (*model.ProductPaginationData, error) {
+	limitValue := 12
+	if limit != nil && *limit > 0 {
+		limitValue = *limit
+	}
+	offsetValue := 0
+	if offset != nil && *offset > 0 {
+		offsetValue = *offset
+	}
+
+	selected := make(map[string][]string, len(filters))
+	for _, f := range filters {
+		selected[f.Name] = append(selected[f.Name], f.Values...)
+	}
+
+	products, total, err := r.ProductHandler.GetProductsByCategory(ctx, uint32(categoryID), selected, limitValue, offsetValue)
+	if err != nil {
+		return nil, err
+	}
+
+	modelProducts := make([]*model.Product, 0, len(products))
+	for _, p := range products {
+		categoryID := int(p.CategoryID)
+		modelProducts = append(modelProducts, &model.Product{
+			ID:              int(p.ID),
+			RatingAverage:   p.RatingAverage,
+			ReviewCount:     p.ReviewCount,
+			Name:            p.Name,
+			Slug:            p.Slug,
+			Description:     p.Description,
+			Price:           p.Price,
+			Discount:        p.Discount,
+			Status:          p.Status,
+			Quantity:        p.Quantity,
+			Thumbnail:       p.Thumbnail,
+			Image:           p.Images,
+			File:            &p.File,
+			Store:           p.Store,
+			Category:        p.Category,
+			Subcategory:     p.Subcategory,
+			CategoryID:      &categoryID,
+			AlwaysAvailable: &p.AlwaysAvailbale,
+			Type:            &p.Type,
+			IsBundle:        p.IsBundle,
+		})
+	}
+
+	return &model.ProductPaginationData{
+		Data:        modelProducts,
+		CurrentPage: offsetValue + 1,
+		PerPage:     limitValue,
+		Total:       total,
+		NextPage:    offsetValue + 2,
+		PrevPage:    offsetValue,
+	}, nil
+}
+
+// Product is the resolver for the Product field.
+func (r *queryResolver) Product(ctx context.Context, id int) (*model.Product, error) {
 	productHandler := product.NewHandler(product.NewService(product.NewRepository()))
-	products, err := productHandler.GetAllProducts(ctx)
+
+	// Convert int to uint32 for the handler
+	productID := uint32(id)
+
+	// Call the handler with userId 0 since we're just viewing
+	p, err := productHandler.GetProduct(ctx, productID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Products awaiting or failing moderation are only visible to their seller and admins
+	if p.ModerationStatus != "" && p.ModerationStatus != product.ModerationApproved {
+		if _, err := r.requireProductManager(ctx, productID); err != nil {
+			if _, err := r.requireAdmin(ctx); err != nil {
+				return nil, fmt.Errorf("product not found")
+			}
+		}
+	}
+
+	return productToModel(p), nil
+}
+
+// ProductBySlug is the resolver for the ProductBySlug field.
+func (r *queryResolver) ProductBySlug(ctx context.Context, store string, slug string) (*model.Product, error) {
+	p, err := r.ProductHandler.GetProductBySlug(ctx, store, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Products awaiting or failing moderation are only visible to their seller and admins
+	if p.ModerationStatus != "" && p.ModerationStatus != product.ModerationApproved {
+		if _, err := r.requireProductManager(ctx, p.ID); err != nil {
+			if _, err := r.requireAdmin(ctx); err != nil {
+				return nil, fmt.Errorf("product not found")
+			}
+		}
+	}
+
+	return productToModel(p), nil
+}
+
+// HandledProducts is the resolver for the HandledProducts field.
+func (r *queryResolver) HandledProducts(ctx context.Context, user int, typeArg string) ([]*model.HandledProducts, error) {
+	productHandler := product.NewHandler(product.NewService(product.NewRepository()))
+
+	// Convert int to uint32 for the handler
+	userID := uint32(user)
+
+	// Add debug logging
+	fmt.Printf("Fetching handled products for user %d with type %s\n", userID, typeArg)
+
+	// Get handled products from the handler
+	products, err := productHandler.GetHandledProducts(ctx, userID, typeArg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch handled products: %v", err)
+	}
+
+	// Add debug logging
+	fmt.Printf("Found %d products\n", len(products))
+
+	// Initialize empty slice
+	handledProducts := make([]*model.HandledProducts, 0)
+
+	// Convert to GraphQL model
+	for _, p := range products {
+		// Get the actual product details using product ID from handled_products table
+		productDetails, err := productHandler.GetProduct(ctx, p.ProductID, 0)
+		if err != nil {
+			fmt.Printf("Error fetching product details for ID %d: %v\n", p.ProductID, err)
+			continue
+		}
+
+		if productDetails == nil {
+			fmt.Printf("Product details not found for ID %d\n", p.ProductID)
+			continue
+		}
+
+		status := productDetails.Status
+		price := productDetails.Price
+		discount := productDetails.Discount
+		quantity := productDetails.Quantity
+		name := productDetails.Name
+		thumbnail := productDetails.Thumbnail
+
+		handledProduct := &model.HandledProducts{
+			UserID:           int(p.UserID),
+			ProductID:        int(productDetails.ID),
+			ProductName:      &name,
+			ProductThumbnail: &thumbnail,
+			ProductPrice:     &price,
+			ProductDiscount:  &discount,
+			ProductStatus:    &status,
+			ProductQuantity:  &quantity,
+		}
+		handledProducts = append(handledProducts, handledProduct)
+
+		// Log each product being added
+		fmt.Printf("Added product: ID=%d, Name=%s\n", productDetails.ID, name)
+	}
+
+	return handledProducts, nil
+}
+
+// RecommendedProducts is the resolver for the RecommendedProducts field.
+func (r *queryResolver) RecommendedProducts(ctx context.Context, query string) ([]*model.Product, error) {
+	panic(fmt.Errorf("not implemented: RecommendedProducts - RecommendedProducts"))
+}
+
+// RecentlyAddedProducts is the resolver for the RecentlyAddedProducts field.
+func (r *queryResolver) RecentlyAddedProducts(ctx context.Context, user int) ([]*model.Product, error) {
+	panic(fmt.Errorf("not implemented: RecentlyAddedProducts - RecentlyAddedProducts"))
+}
+
+// Cart is the resolver for the Cart field.
+func (r *queryResolver) Cart(ctx context.Context, user int) (*model.Cart, error) {
+	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+
+	// Convert int to uint32 for the handler
+	userID := uint32(user)
+
+	result, err := cartHandler.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert cart items to GraphQL model
+	var cartItems []*model.CartItem
+	for _, item := range result.Items {
+		cartItem := &model.CartItem{
+			Product: &model.Product{
+				ID:        int(item.Product.ID),
+				Name:      item.Product.Name,
+				Price:     item.Product.Price,
+				Discount:  item.Product.Discount,
+				Quantity:  item.Product.Quantity,
+				Thumbnail: item.Product.Thumbnail,
+				Image:     item.Product.Images,
+				Store:     item.Product.Store,
+				IsBundle:  item.Product.IsBundle,
+			},
+			Quantity:  item.Quantity,
+			Subtotal:  item.Subtotal,
+			Deal:      appliedDealToModel(item.Deal),
+			SlotStart: item.SlotStart,
+		}
+		cartItems = append(cartItems, cartItem)
+	}
+
+	// Convert ID to string
+	id := strconv.FormatUint(uint64(result.ID), 10)
+
+	return &model.Cart{
+		Items:  cartItems,
+		Total:  result.Total,
+		Active: result.Active,
+		User:   int(result.UserID),
+		ID:     &id,
+	}, nil
+}
+
+// SubscriptionBundle is the resolver for the SubscriptionBundle field.
+func (r *queryResolver) SubscriptionBundle(ctx context.Context, serviceID string) (*model.SubscriptionBundle, error) {
+	panic(fmt.Errorf("not implemented: SubscriptionBundle - SubscriptionBundle"))
+}
+
+// SearchProducts is the resolver for the searchProducts field.
+func (r *queryResolver) SearchProducts(ctx context.Context, query string) ([]*model.Product, error) {
+	products, err := r.ProductHandler.SearchProducts(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %v", err)
+	}
+
+	// Convert to GraphQL model
+	modelProducts := make([]*model.Product, 0, len(products))
+	for _, p := range products {
+		if p == nil {
+			continue
+		}
+		modelProducts = append(modelProducts, &model.Product{
+			ID:              int(p.ID),
+			RatingAverage:   p.RatingAverage,
+			ReviewCount:     p.ReviewCount,
+			Name:            p.Name,
+			Slug:            p.Slug,
+			Description:     p.Description,
+			Price:           p.Price,
+			Discount:        p.Discount,
+			Status:          p.Status,
+			Quantity:        p.Quantity,
+			Thumbnail:       p.Thumbnail,
+			Image:           p.Images,
+			File:            &p.File,
+			Store:           p.Store,
+			Category:        p.Category,
+			Subcategory:     p.Subcategory,
+			AlwaysAvailable: &p.AlwaysAvailbale,
+		})
+	}
+
+	return modelProducts, nil
+}
+
+// Stores is the resolver for the Stores field.
+func (r *queryResolver) Stores(ctx context.Context, user *int, limit *int, offset *int) (*model.StorePaginationData, error) {
+	// Convert nullable parameters to concrete values
+	var userID uint32
+	if user != nil {
+		userID = uint32(*user)
+	}
+	limitValue := 100
+	if limit != nil {
+		limitValue = *limit
+	}
+	offsetValue := 0
+	if offset != nil {
+		offsetValue = *offset
+	}
+
+	// Get stores from repository
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	stores, err := storeHandler.GetStores(ctx, userID, limitValue, offsetValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stores: %v", err)
+	}
+
+	// Convert to GraphQL model
+	var modelStores []*model.Store
+	for _, s := range stores {
+		if s == nil {
+			continue
+		}
+
+		// Convert followers
+		var followers []*model.StoreFollower
+		for _, f := range s.Followers {
+			followers = append(followers, &model.StoreFollower{
+				FollowerID:    int(f.FollowerID),
+				FollowerName:  f.FollowerName,
+				StoreID:       int(f.StoreID),
+				FollowerImage: f.FollowerImage,
+			})
+		}
+
+		// Convert products
+		var products []*model.Product
+		for _, p := range s.Products {
+			products = append(products, &model.Product{
+				ID:          int(p.ID),
+				Name:        p.Name,
+				Price:       p.Price,
+				Description: p.Description,
+				Discount:    p.Discount,
+				Image:       p.Images,
+				Slug:        p.Slug,
+				Quantity:    p.Quantity,
+				Status:      p.Status,
+				Thumbnail:   p.Thumbnail,
+				Store:       p.Store,
+				Category:    p.Category,
+				UnitsSold:   p.UnitsSold,
+				Subcategory: p.Subcategory,
+			})
+		}
+
+		// Convert withdrawal accounts
+		var accounts []*model.WithdrawAccount
+		for _, a := range s.Accounts {
+			accounts = append(accounts, &model.WithdrawAccount{
+				BankCode:      a.BankCode,
+				BankName:      a.BankName,
+				BankImage:     a.BankImage,
+				AccountNumber: a.AccountNumber,
+				AccountName:   a.AccountName,
+			})
+		}
+
+		modelStore := &model.Store{
+			ID:                 strconv.Itoa(int(s.ID)), // Convert ID to string
+			RatingAverage:      s.RatingAverage,
+			ReviewCount:        s.ReviewCount,
+			Link:               s.Link,
+			Name:               s.Name,
+			User:               int(s.UserID),
+			Description:        s.Description,
+			Thumbnail:          s.Thumbnail,
+			Phone:              s.Phone,
+			Background:         s.Background,
+			Address:            s.Address,
+			Status:             s.Status,
+			HasPhysicalAddress: s.HasPhysicalAddress,
+			Wallet:             float64(s.Wallet),
+			Visitors:           s.Visitors,
+			Followers:          followers,
+			Product:            products,
+			Accounts:           accounts,
+			MaintenanceMode:    s.MaintenanceMode,
+		}
+		modelStores = append(modelStores, modelStore)
+	}
+
+	return &model.StorePaginationData{
+		Data:        modelStores,
+		CurrentPage: offsetValue + 1,
+		PerPage:     limitValue,
+		Total:       len(modelStores),
+	}, nil
+}
+
+// Store is the resolver for the Store field.
+func (r *queryResolver) Store(ctx context.Context, id int) (*model.Store, error) {
+	panic(fmt.Errorf("not implemented: Store - Store"))
+}
+
+// Reviews is the resolver for the Reviews field.
+func (r *queryResolver) Reviews(ctx context.Context, id string, value string, limit *int, offset *int) ([]*model.Review, error) {
+	var filter review.Filter
+	switch value {
+	case "order":
+		filter.OrderID = id
+	case "product", "store":
+		numericID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s ID: %v", value, err)
+		}
+		if value == "product" {
+			filter.ProductID = uint32(numericID)
+		} else {
+			filter.StoreID = uint32(numericID)
+		}
+	default:
+		return nil, fmt.Errorf("invalid value parameter: %s", value)
+	}
+
+	l, o := 0, 0
+	if limit != nil {
+		l = *limit
+	}
+	if offset != nil {
+		o = *offset
+	}
+
+	reviewHandler := review.NewHandler(review.NewService(review.NewRepository()))
+	found, err := reviewHandler.GetReviews(ctx, filter, l, o)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s reviews: %v", value, err)
+	}
+
+	reviews := make([]*model.Review, 0, len(found))
+	for _, rv := range found {
+		reviews = append(reviews, reviewToModel(rv))
+	}
+	return reviews, nil
+}
+
+// StoreByName is the resolver for the StoreByName field.
+func (r *queryResolver) StoreByName(ctx context.Context, name string) (*model.Store, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Get store by name
+	s, err := storeHandler.GetStoreByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch store: %v", err)
+	}
+
+	// Convert followers to GraphQL model
+	var followers []*model.StoreFollower
+	for _, f := range s.Followers {
+		followers = append(followers, &model.StoreFollower{
+			FollowerID:    int(f.FollowerID),
+			FollowerName:  f.FollowerName,
+			StoreID:       int(f.StoreID),
+			FollowerImage: f.FollowerImage,
+		})
+	}
+
+	// Convert products to GraphQL model
+	var products []*model.Product
+	for _, p := range s.Products {
+		products = append(products, &model.Product{
+			ID:          int(p.ID),
+			Name:        p.Name,
+			Price:       p.Price,
+			Description: p.Description,
+			Discount:    p.Discount,
+			Image:       p.Images,
+			Slug:        p.Slug,
+			Quantity:    p.Quantity,
+			Status:      p.Status,
+			Thumbnail:   p.Thumbnail,
+			Store:       p.Store,
+			Category:    p.Category,
+			Subcategory: p.Subcategory,
+		})
+	}
+
+	return &model.Store{
+		ID:                 strconv.Itoa(int(s.ID)),
+		RatingAverage:      s.RatingAverage,
+		ReviewCount:        s.ReviewCount,
+		Link:               s.Link,
+		Name:               s.Name,
+		User:               int(s.UserID),
+		Description:        s.Description,
+		Thumbnail:          s.Thumbnail,
+		Phone:              s.Phone,
+		Background:         s.Background,
+		Address:            s.Address,
+		Status:             s.Status,
+		HasPhysicalAddress: s.HasPhysicalAddress,
+		Wallet:             float64(s.Wallet),
+		Visitors:           s.Visitors,
+		Followers:          followers,
+		Product:            products,
+	}, nil
+}
+
+// PurchasedOrder is the resolver for the PurchasedOrder field.
+func (r *queryResolver) PurchasedOrder(ctx context.Context, user int) ([]*model.PurchasedOrder, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Pass the user ID directly as integer
+	orders, err := storeHandler.GetPurchasedOrders(ctx, fmt.Sprintf("%d", user))
+	if err != nil {
+		// Check for specific error types
+		switch {
+		case strings.Contains(err.Error(), "record not found"):
+			// Return empty array if no orders found
+			return []*model.PurchasedOrder{}, nil
+		case strings.Contains(err.Error(), "<!DOCTYPE"):
+			// Return empty array for HTML response
+			return []*model.PurchasedOrder{}, nil
+		case strings.Contains(err.Error(), "unsupported relations"):
+			// Return empty array for relation errors
+			return []*model.PurchasedOrder{}, nil
+		default:
+			return nil, fmt.Errorf("failed to fetch purchased orders: %v", err)
+		}
+	}
+
+	if orders == nil {
+		return []*model.PurchasedOrder{}, nil
+	}
+
+	// Rest of the conversion code remains the same
+	var purchasedOrders []*model.PurchasedOrder
+	for _, order := range orders {
+		if order == nil {
+			continue
+		}
+
+		// Convert tracked products with nil check
+		var products []*model.TrackedProduct
+		if order.Products != nil {
+			for _, p := range order.Products {
+				tracked := &model.TrackedProduct{
+					ID:        int(p.ID),
+					Name:      p.Name,
+					Price:     p.Price,
+					Thumbnail: p.Thumbnail,
+					Discount:  p.Discount,
+					Status:    p.Status,
+				}
+				if p.DealLabel != "" {
+					tracked.DealLabel = &p.DealLabel
+					tracked.DealSavings = &p.DealSavings
+				}
+				if p.BookingID != 0 {
+					bookingID := int(p.BookingID)
+					tracked.BookingID = &bookingID
+					tracked.SlotStart = p.SlotStart
+				}
+				tracked.ShipsAfter = p.ShipsAfter
+				if p.Fulfilment != "" {
+					tracked.Fulfilment = &p.Fulfilment
+				}
+				products = append(products, tracked)
+			}
+		}
+
+		// Convert delivery details with nil check and default values
+		var deliveryDetails *model.DeliveryDetails
+		if order.DeliveryDetails != nil {
+			deliveryDetails = &model.DeliveryDetails{
+				Method:  order.DeliveryDetails.Method,
+				Address: order.DeliveryDetails.Address,
+				Fee:     order.DeliveryDetails.Fee,
+			}
+		} else {
+			// Provide default values when delivery details are missing
+			deliveryDetails = &model.DeliveryDetails{
+				Method:  "digital", // Default to digital delivery
+				Address: "N/A",     // Default address
+				Fee:     0,         // Default fee
+			}
+		}
+
+		// Convert string values to float64
+		fee, _ := strconv.ParseFloat(order.Fee, 64)
+		amount, _ := strconv.ParseFloat(order.Amount, 64)
+
+		purchasedOrder := &model.PurchasedOrder{
+			CartID:          int(order.CartID),
+			Coupon:          order.Coupon,
+			Fee:             fee,
+			Status:          order.Status,
+			UserID:          order.UserID,
+			Amount:          amount,
+			UUID:            order.UUID,
+			PaymentGateway:  order.PaymentGateway,
+			PaymentMethod:   order.PaymentMethod,
+			TransRef:        order.TransRef,
+			TransStatus:     order.TransStatus,
+			Products:        products,
+			CreatedAt:       order.CreatedAt,
+			DeliveryDetails: deliveryDetails, // Now this will never be nil
+			TextRef:         order.TransRef,
+		}
+		purchasedOrders = append(purchasedOrders, purchasedOrder)
+	}
+
+	return purchasedOrders, nil
+}
+
+// Skynets is the resolver for the Skynets field.
+func (r *queryResolver) Skynets(ctx context.Context, id string) ([]*model.Skynet, error) {
+	panic(fmt.Errorf("not implemented: Skynets - Skynets"))
+}
+
+// Skynet is the resolver for the Skynet field.
+func (r *queryResolver) Skynet(ctx context.Context, id string) (*model.Skynet, error) {
+	panic(fmt.Errorf("not implemented: Skynet - Skynet"))
+}
+
+// Mydva is the resolver for the MYDVA field.
+func (r *queryResolver) Mydva(ctx context.Context, email string) (*model.Account, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	account, err := storeHandler.GetDVAAccount(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DVA account: %v", err)
+	}
+
+	// Convert string ID to uint64
+	id, err := strconv.ParseUint(account.ID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %v", err)
+	}
+
+	// Convert customer ID to int
+	customerID, err := strconv.Atoi(account.Customer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid customer ID: %v", err)
+	}
+
+	// Convert bank ID to int
+	bankID, err := strconv.Atoi(account.Bank.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bank ID: %v", err)
+	}
+
+	return &model.Account{
+		Customer: &model.Customer{
+			ID:        strconv.Itoa(customerID),
+			FirstName: account.Customer.Email,
+			LastName:  "",
+		},
+		Bank: &model.Bank{
+			ID:   strconv.Itoa(bankID), // Use the converted bankID here
+			Name: account.Bank.Name,
+			Slug: account.Bank.Slug,
+		},
+		ID:            strconv.FormatUint(id, 10),
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+	}, nil
+}
+
+// GetDVABalance is the resolver for the GetDVABalance field.
+func (r *queryResolver) GetDVABalance(ctx context.Context, accountNumber string, storeID *int) (*string, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Get PayStack DVA balance
+	paystackBalance, err := storeHandler.GetDVABalance(ctx, accountNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DVA balance: %v", err)
+	}
+
+	totalBalance := paystackBalance
+
+	// If storeID is provided, update store wallet and add store earnings
+	if storeID != nil {
+		storeRepo := store.NewRepository()
+		store, err := storeRepo.GetStore(ctx, uint32(*storeID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get store: %v", err)
+		}
+
+		// Calculate total balance including released earnings
+		earnings, err := storeHandler.GetStoreEarnings(ctx, uint32(*storeID))
+		if err == nil {
+			// Sum up all released earnings
+			for _, earning := range earnings {
+				if earning.Status == "released" {
+					totalBalance += earning.Amount
+				}
+			}
+		}
+
+		// Get total withdrawals
+		var totalWithdrawals float64
+		var withdrawals []*shared.Withdrawal
+		if err := storeRepo.GetDB().Where("store_id = ? AND status IN ?", *storeID, []string{"pending", "approved", "completed"}).Find(&withdrawals).Error; err == nil {
+			// Sum up all withdrawals
+			for _, withdrawal := range withdrawals {
+				totalWithdrawals += withdrawal.Amount
+			}
+		}
+
+		// Subtract withdrawals from total balance
+		totalBalance -= totalWithdrawals
+
+		// Update store wallet with total balance (Paystack + released earnings - withdrawals)
+		if store.Wallet != totalBalance {
+			if err := storeRepo.UpdateWallet(ctx, uint32(*storeID), totalBalance-store.Wallet); err != nil {
+				return nil, fmt.Errorf("failed to update store wallet: %v", err)
+			}
+		}
+	}
+
+	balanceStr := strconv.FormatFloat(totalBalance, 'f', 2, 64)
+	return &balanceStr, nil
+}
+
+// MyInvoices is the resolver for the MyInvoices field.
+func (r *queryResolver) MyInvoices(ctx context.Context, storeID *int) ([]*model.Invoice, error) {
+	panic(fmt.Errorf("not implemented: MyInvoices - MyInvoices"))
+}
+
+// MyDownloads is the resolver for the MyDownloads field.
+func (r *queryResolver) MyDownloads(ctx context.Context, id string) ([]*model.Downloads, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id != strconv.Itoa(int(userID)) {
+		return nil, fmt.Errorf("unauthorized: you can only view your own downloads")
+	}
+
+	downloadHandler := download.NewHandler(download.NewService(download.NewRepository()))
+	downloads, err := downloadHandler.GetUserDownloads(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Downloads, 0, len(downloads))
+	for _, d := range downloads {
+		users := make([]*string, 0, len(d.Users))
+		for i := range d.Users {
+			users = append(users, &d.Users[i])
+		}
+		result = append(result, &model.Downloads{
+			ID:            d.ID,
+			Thumbnail:     d.Thumbnail,
+			Name:          d.Name,
+			Price:         d.Price,
+			Discount:      d.Discount,
+			UUID:          d.UUID,
+			Users:         users,
+			DownloadCount: d.DownloadCount,
+			MaxDownloads:  d.MaxDownloads,
+			CreatedAt:     &d.CreatedAt,
+			UpdatedAt:     &d.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+// GetUsers is the resolver for the getUsers field.
+func (r *queryResolver) GetUsers(ctx context.Context) ([]*model.User, error) {
+	users, err := r.UserHandler.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %v", err)
+	}
+
+	log.Printf("Fetched %d users", len(users))
+
+	var userResponses []*model.User
+	for _, u := range users {
+		log.Printf("Processing user: ID=%d, Type=%s", u.ID, u.Usertype)
+
+		active := true
+		if u.Active != nil {
+			active = *u.Active
+		}
+
+		// Convert string to pointer for Dob fields
+		var dobPtr *string
+		if u.Dob != "" {
+			dobPtr = &u.Dob
+		}
+
+		// Convert string to pointer for Gender fields
+		var genderPtr *string
+		if u.Gender != "" {
+			genderPtr = &u.Gender
+		}
+
+		// Convert string to pointer for Avatar field
+		var avatarPtr *string
+		if u.Avatar != "" {
+			avatarPtr = &u.Avatar
+		}
+
+		userResponse := &model.User{
+			ID:       strconv.FormatInt(int64(u.ID), 10),
+			UUID:     u.UUID,
+			Fullname: u.Fullname,
+			Email:    u.Email,
+			Phone:    u.Phone,
+			Campus:   u.Campus,
+			Usertype: u.Usertype,
+			Active:   active,
+			Avatar:   avatarPtr,
+			Dob:      dobPtr,
+			Gender:   genderPtr,
+			Online:   u.Online,
+		}
+
+		// If user is a seller, fetch all their stores
+		if u.Usertype == "seller" {
+			log.Printf("Fetching stores for seller user ID: %d", u.ID)
+			storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+			stores, err := storeHandler.GetStores(ctx, u.ID, 0, 0) // Fetch all stores for this user
+			if err != nil {
+				log.Printf("Error fetching stores for user %d: %v", u.ID, err)
+			} else {
+				log.Printf("Found %d stores for user %d", len(stores), u.ID)
+				if len(stores) > 0 {
+					var storeModels []*model.Store
+					for _, s := range stores {
+						log.Printf("Processing store: ID=%d, Name=%s", s.ID, s.Name)
+						// Convert followers to GraphQL model
+						var followers []*model.StoreFollower
+						for _, f := range s.Followers {
+							followers = append(followers, &model.StoreFollower{
+								FollowerID:    int(f.FollowerID),
+								FollowerName:  f.FollowerName,
+								StoreID:       int(f.StoreID),
+								FollowerImage: f.FollowerImage,
+							})
+						}
+
+						storeModel := &model.Store{
+							ID:                 strconv.Itoa(int(s.ID)),
+							Name:               s.Name,
+							Link:               s.Link,
+							Description:        s.Description,
+							Address:            s.Address,
+							Phone:              s.Phone,
+							HasPhysicalAddress: s.HasPhysicalAddress,
+							Thumbnail:          s.Thumbnail,
+							Background:         s.Background,
+							Status:             s.Status,
+							User:               int(s.UserID),
+							Wallet:             float64(s.Wallet),
+							Followers:          followers,
+							Email:              s.Email,
+							MaintenanceMode:    s.MaintenanceMode,
+						}
+						storeModels = append(storeModels, storeModel)
+					}
+					userResponse.Stores = storeModels
+				}
+			}
+		}
+
+		userResponses = append(userResponses, userResponse)
+	}
+
+	return userResponses, nil
+}
+
+// AllStores is the resolver for the allStores field.
+func (r *queryResolver) AllStores(ctx context.Context, limit *int, offset *int) (*model.StorePaginationData, error) {
+	// Convert nullable parameters to concrete values
+	limitValue := 100
+	if limit != nil {
+		limitValue = *limit
+	}
+	offsetValue := 0
+	if offset != nil {
+		offsetValue = *offset
+	}
+
+	// Get all stores from repository with debug logging
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	stores, err := storeHandler.GetStores(ctx, 0, limitValue, offsetValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stores: %v", err)
+	}
+
+	// Add debug logging
+	log.Printf("Retrieved %d stores from database", len(stores))
+
+	// Convert to GraphQL model
+	var modelStores []*model.Store
+	for _, s := range stores {
+		if s == nil {
+			log.Printf("Skipping nil store")
+			continue
+		}
+
+		log.Printf("Processing store: ID=%d, Name=%s", s.ID, s.Name)
+
+		// Convert followers
+		var followers []*model.StoreFollower
+		for _, f := range s.Followers {
+			followers = append(followers, &model.StoreFollower{
+				FollowerID:    int(f.FollowerID),
+				FollowerName:  f.FollowerName,
+				StoreID:       int(f.StoreID),
+				FollowerImage: f.FollowerImage,
+			})
+		}
+
+		// Convert products
+		var products []*model.Product
+		for _, p := range s.Products {
+			products = append(products, &model.Product{
+				ID:          int(p.ID),
+				Name:        p.Name,
+				Price:       p.Price,
+				Description: p.Description,
+				Discount:    p.Discount,
+				Image:       p.Images,
+				Slug:        p.Slug,
+				Quantity:    p.Quantity,
+				Status:      p.Status,
+				Thumbnail:   p.Thumbnail,
+				Store:       p.Store,
+				Category:    p.Category,
+				UnitsSold:   p.UnitsSold,
+				Subcategory: p.Subcategory,
+			})
+		}
+
+		// Convert withdrawal accounts
+		var accounts []*model.WithdrawAccount
+		for _, a := range s.Accounts {
+			accounts = append(accounts, &model.WithdrawAccount{
+				BankCode:      a.BankCode,
+				BankName:      a.BankName,
+				BankImage:     a.BankImage,
+				AccountNumber: a.AccountNumber,
+				AccountName:   a.AccountName,
+			})
+		}
+
+		modelStore := &model.Store{
+			ID:                 strconv.Itoa(int(s.ID)),
+			RatingAverage:      s.RatingAverage,
+			ReviewCount:        s.ReviewCount,
+			Link:               s.Link,
+			Name:               s.Name,
+			User:               int(s.UserID),
+			Description:        s.Description,
+			Thumbnail:          s.Thumbnail,
+			Phone:              s.Phone,
+			Background:         s.Background,
+			Address:            s.Address,
+			Status:             s.Status,
+			HasPhysicalAddress: s.HasPhysicalAddress,
+			Wallet:             float64(s.Wallet),
+			Visitors:           s.Visitors,
+			Followers:          followers,
+			Product:            products,
+			Accounts:           accounts,
+		}
+		modelStores = append(modelStores, modelStore)
+		log.Printf("Added store to response: ID=%s, Name=%s", modelStore.ID, modelStore.Name)
+	}
+
+	log.Printf("Returning %d stores in response", len(modelStores))
+
+	return &model.StorePaginationData{
+		Data:        modelStores,
+		CurrentPage: offsetValue + 1,
+		PerPage:     limitValue,
+		Total:       len(modelStores),
+	}, nil
+}
+
+// GetAllProducts is the resolver for the getAllProducts field.
+func (r *queryResolver) GetAllProducts(ctx context.Context) ([]*model.Product, error) {
+	productHandler := product.NewHandler(product.NewService(product.NewRepository()))
+	products, err := productHandler.GetAllProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch all products: %v", err)
+	}
+
+	var productResponses []*model.Product
+	for _, p := range products {
+		if p == nil {
+			continue
+		}
+
+		// Convert images to string array
+		var images []string
+		if p.Thumbnail != "" {
+			images = []string{p.Thumbnail}
+		}
+
+		productResponse := &model.Product{
+			ID:            int(p.ID),
+			RatingAverage: p.RatingAverage,
+			ReviewCount:   p.ReviewCount,
+			Name:          p.Name,
+			Description:   p.Description,
+			Price:         p.Price,
+			Discount:      p.Discount,
+			Status:        p.Status,
+			Image:         images,
+			Quantity:      p.Quantity,
+			Store:         p.Store, // Store is already a string in the database
+			Category:      p.Category,
+			Subcategory:   p.Subcategory,
+			UnitsSold:     p.UnitsSold,
+		}
+		productResponses = append(productResponses, productResponse)
+	}
+
+	return productResponses, nil
+}
+
+// GetAllReviews is the resolver for the getAllReviews field.
+func (r *queryResolver) GetAllReviews(ctx context.Context) ([]*model.Review, error) {
+	reviewHandler := review.NewHandler(review.NewService(review.NewRepository()))
+	found, err := reviewHandler.GetReviews(ctx, review.Filter{}, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reviews: %v", err)
+	}
+
+	reviews := make([]*model.Review, 0, len(found))
+	for _, rv := range found {
+		reviews = append(reviews, reviewToModel(rv))
+	}
+	return reviews, nil
+}
+
+// GetAllOrders is the resolver for the getAllOrders field.
+func (r *queryResolver) GetAllOrders(ctx context.Context) ([]*model.Order, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Get all orders
+	orders, err := storeHandler.GetAllOrders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch all orders: %v", err)
+	}
+
+	var result []*model.Order
+	for _, order := range orders {
+		// Convert products
+		var products []*model.Product
+		for _, p := range order.Products {
+			products = append(products, &model.Product{
+				ID:        int(p.ID),
+				Name:      p.Name,
+				Price:     p.Price,
+				Thumbnail: p.Thumbnail,
+				Status:    p.Status == "active",
+			})
+		}
+
+		// Convert delivery details
+		var deliveryDetails *model.DeliveryDetails
+		if order.DeliveryDetails != nil {
+			deliveryDetails = &model.DeliveryDetails{
+				Method:  order.DeliveryDetails.Method,
+				Address: order.DeliveryDetails.Address,
+				Fee:     order.DeliveryDetails.Fee,
+			}
+		}
+
+		result = append(result, &model.Order{
+			CartID:          int(order.CartID),
+			UUID:            order.UUID,
+			Amount:          order.Amount,
+			Status:          order.Status,
+			PaymentGateway:  order.PaymentGateway,
+			PaymentMethod:   order.PaymentMethod,
+			TransRef:        order.TransRef,
+			TransStatus:     order.TransStatus,
+			Products:        products,
+			DeliveryDetails: deliveryDetails,
+			TextRef:         &order.TransRef,
+			CreatedAt:       &order.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// Add/Update the Chats query resolver
+func (r *queryResolver) Chats(ctx context.Context, userID string) ([]*model.Chat, error) {
+	// Convert userID string to uint32
+	uid, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %v", err)
+	}
+
+	// Use GetChatLists instead of GetChatsByUserID
+	chats, err := r.MessageHandler.GetChatLists(ctx, uint32(uid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user chats: %v", err)
+	}
+
+	var result []*model.Chat
+	for _, chat := range chats {
+		var messages []*model.Message
+		for _, msg := range chat.Messages {
+			// Get user details for the sender
+			senderUser, err := r.UserHandler.GetUser(ctx, strconv.FormatUint(uint64(msg.Sender), 10))
+			if err != nil {
+				log.Printf("Error fetching user details: %v", err)
+				continue
+			}
+
+			var mediaStr *string
+			if msg.Media != nil {
+				s := string(*msg.Media)
+				mediaStr = &s
+			}
+
+			// Create user model
+			user := &model.User{
+				ID:       strconv.FormatUint(uint64(senderUser.ID), 10),
+				Fullname: senderUser.Fullname,
+				Avatar:   &senderUser.Avatar,
+				Online:   senderUser.Online,
+			}
+
+			messages = append(messages, &model.Message{
+				ID:        strconv.FormatInt(int64(msg.ID), 10),
+				ChatID:    strconv.FormatInt(int64(msg.ChatID), 10),
+				Content:   msg.Content,
+				Sender:    strconv.FormatInt(int64(msg.Sender), 10),
+				Media:     mediaStr,
+				IsRead:    msg.IsRead,
+				Users:     []*model.User{user}, // Add user details here
+				CreatedAt: msg.CreatedAt.Format(time.RFC3339),
+				UpdatedAt: func() *string {
+					if !msg.UpdatedAt.IsZero() {
+						t := msg.UpdatedAt.Format(time.RFC3339)
+						return &t
+					}
+					return nil
+				}(),
+			})
+		}
+
+		result = append(result, &model.Chat{
+			ID:            strconv.FormatInt(int64(chat.ID), 10),
+			Messages:      messages,
+			LatestMessage: nil, // Add latest message if needed
+			UnreadCount:   chat.UnreadCount,
+		})
+	}
+
+	return result, nil
+}
+
+// Fix the Messages query resolver
+func (r *queryResolver) Messages(ctx context.Context, chatID string) ([]*model.Message, error) {
+	messages, err := r.MessageHandler.GetMessages(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*model.Message
+	for _, msg := range messages {
+		createdAt := msg.CreatedAt.Format(time.RFC3339)
+		var updatedAt *string
+		if !msg.UpdatedAt.IsZero() {
+			t := msg.UpdatedAt.Format(time.RFC3339)
+			updatedAt = &t
+		}
+
+		result = append(result, &model.Message{
+			ID:        strconv.FormatInt(int64(msg.ID), 10),
+			ChatID:    strconv.FormatInt(int64(msg.ChatID), 10),
+			Content:   msg.Content,
+			Sender:    strconv.FormatInt(int64(msg.Sender), 10),
+			Media:     (*string)(msg.Media),
+			IsRead:    msg.IsRead,
+			CreatedAt: createdAt,
+			UpdatedAt: updatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// Subscribers is the resolver for the subscribers field.
+func (r *queryResolver) Subscribers(ctx context.Context) ([]*model.Subscriber, error) {
+	panic(fmt.Errorf("not implemented: Subscribers - subscribers"))
+}
+
+// GetDVAAccount is the resolver for the GetDVAAccount field.
+func (r *queryResolver) GetDVAAccount(ctx context.Context, userID string) (*model.DVAAccount, error) {
+	// Get DVA account details using the userID string directly
+	dvaAccount, err := r.UserHandler.GetDVAAccount(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DVA account: %v", err)
+	}
+
+	// Check if dvaAccount or its nested fields are nil
+	if dvaAccount == nil {
+		return nil, fmt.Errorf("DVA account not found")
+	}
+
+	// Convert to GraphQL model
+	return &model.DVAAccount{
+		ID:            dvaAccount.ID,
+		AccountName:   dvaAccount.AccountName,
+		AccountNumber: dvaAccount.AccountNumber,
+		Customer: &model.Customer{
+			ID:        dvaAccount.Customer.ID,
+			Email:     dvaAccount.Customer.Email,
+			FirstName: "",
+			LastName:  "",
+		},
+		Bank: &model.Bank{ // Changed from DVABank to Bank
+			ID:   dvaAccount.Bank.ID,
+			Name: dvaAccount.Bank.Name,
+			Slug: dvaAccount.Bank.Slug,
+		},
+	}, nil
+}
+
+// GetStoreEarnings is the resolver for the getStoreEarnings field.
+func (r *queryResolver) GetStoreEarnings(ctx context.Context, storeID int) ([]*model.StoreEarnings, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Get store earnings
+	earnings, err := storeHandler.GetStoreEarnings(ctx, uint32(storeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch store earnings: %v", err)
+	}
+
+	// Convert to GraphQL model
+	var result []*model.StoreEarnings
+	for _, e := range earnings {
+		result = append(result, &model.StoreEarnings{
+			ID:              strconv.FormatUint(uint64(e.ID), 10),
+			StoreID:         int(e.StoreID),
+			OrderID:         e.OrderID,
+			Amount:          e.Amount,
+			Status:          e.Status,
+			TransactionType: e.TransactionType,
+			CreatedAt:       e.CreatedAt,
+			UpdatedAt:       e.UpdatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// CheckStoreEarningsDiscrepancy is the resolver for the checkStoreEarningsDiscrepancy field.
+func (r *queryResolver) CheckStoreEarningsDiscrepancy(ctx context.Context, storeID int) (*model.StoreEarningsDiscrepancy, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+
+	// Get discrepancy information
+	deliveredOrdersCount, totalEarnings, err := storeHandler.CheckStoreEarningsDiscrepancy(ctx, uint32(storeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check store earnings discrepancy: %v", err)
+	}
+
+	return &model.StoreEarningsDiscrepancy{
+		DeliveredOrdersCount: deliveredOrdersCount,
+		TotalEarnings:        totalEarnings,
+	}, nil
+}
+
+// GetWithdrawalsForAdmin is the resolver for the getWithdrawalsForAdmin field.
+func (r *queryResolver) GetWithdrawalsForAdmin(ctx context.Context, status *string) ([]*model.AdminWithdrawal, error) {
+	widrawalService := withdrawal.NewService(withdrawal.NewRepository())
+	storeRepo := store.NewRepository() // Need store repo to get store names
+
+	// Fetch withdrawals using the new service method
+	withdrawals, err := widrawalService.GetWithdrawals(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch withdrawals: %v", err)
+	}
+
+	var adminWithdrawals []*model.AdminWithdrawal
+	for _, w := range withdrawals {
+		// Fetch store name for each withdrawal
+		storeObj, err := storeRepo.GetStore(ctx, w.StoreID)
+		sellerName := "Unknown Seller" // Default value
+		if err == nil {
+			sellerName = storeObj.Name
+		} else {
+			log.Printf("Warning: Failed to get store for withdrawal %d (storeID %d): %v", w.ID, w.StoreID, err)
+		}
+
+		adminWithdrawals = append(adminWithdrawals, &model.AdminWithdrawal{
+			ID:            strconv.FormatUint(uint64(w.ID), 10),
+			SellerName:    sellerName,
+			AccountNumber: w.AccountNumber,
+			BankName:      w.BankName,
+			Amount:        w.Amount,
+			Time:          w.CreatedAt.Format("03:04PM"),    // Format time
+			Date:          w.CreatedAt.Format("02-01-2006"), // Format date
+			Status:        w.Status,
+			StoreID:       strconv.FormatUint(uint64(w.StoreID), 10),
+		})
+	}
+
+	return adminWithdrawals, nil
+}
+
+// GetWithdrawalDetails is the resolver for the getWithdrawalDetails field.
+func (r *queryResolver) GetWithdrawalDetails(ctx context.Context, id string) (*model.AdminWithdrawal, error) {
+	widrawalService := withdrawal.NewService(withdrawal.NewRepository())
+	storeRepo := store.NewRepository() // Need store repo to get store names
+
+	// Convert the ID string to uint32
+	widrawalID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid withdrawal ID: %v", err)
+	}
+
+	// Fetch the specific withdrawal using the service method
+	w, err := widrawalService.GetWithdrawal(ctx, uint32(widrawalID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch withdrawal details: %v", err)
+	}
+
+	// Fetch store name for the withdrawal
+	storeObj, err := storeRepo.GetStore(ctx, w.StoreID)
+	sellerName := "Unknown Seller" // Default value
+	if err == nil {
+		sellerName = storeObj.Name
+	} else {
+		log.Printf("Warning: Failed to get store for withdrawal %d (storeID %d): %v", w.ID, w.StoreID, err)
+	}
+
+	// Map the withdrawal data to the AdminWithdrawal type
+	adminWithdrawal := &model.AdminWithdrawal{
+		ID:            strconv.FormatUint(uint64(w.ID), 10),
+		SellerName:    sellerName,
+		AccountNumber: w.AccountNumber,
+		BankName:      w.BankName,
+		Amount:        w.Amount,
+		Time:          w.CreatedAt.Format("03:04PM"),    // Format time
+		Date:          w.CreatedAt.Format("02-01-2006"), // Format date
+		Status:        w.Status,
+		StoreID:       strconv.FormatUint(uint64(w.StoreID), 10),
+	}
+
+	return adminWithdrawal, nil
+}
+
+// GetStoreTransactions is the resolver for the getStoreTransactions field.
+func (r *queryResolver) GetStoreTransactions(ctx context.Context, storeID int) (*model.StoreTransactions, error) {
+	// First get the store to get its name and user_id
+	var store struct {
+		Name   string
+		UserID uint32
+	}
+	if err := r.DB.Table("stores").Select("name, user_id").Where("id = ?", storeID).First(&store).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch store: %v", err)
+	}
+
+	// Get order transactions
+	var orderTransactions []*model.DepositTransaction
+	if err := r.DB.Raw(`
+		SELECT
+			o.id::text as id,
+			'order_payment' as type,
+			o.fee::float as amount,
+			o.trans_ref as reference,
+			o.status,
+			o.created_at,
+			'Payment for order #' || o.id::text || 
+			CASE 
+				WHEN o.products IS NOT NULL AND jsonb_array_length(o.products) > 0 THEN 
+					' (' || (
+						SELECT STRING_AGG(product->>'name', ', ')
+						FROM jsonb_array_elements(o.products) AS product
+					) || ')'
+				ELSE '' 
+			END as description
+		FROM orders o
+		WHERE ? = ANY(o.stores_id) AND o.trans_status = 'paid'
+		ORDER BY o.created_at DESC
+	`, store.Name).Scan(&orderTransactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch order transactions: %v", err)
+	}
+
+	// Get user's email from users table
+	var userEmail string
+	if err := r.DB.Table("users").Where("id = ?", store.UserID).Select("email").Scan(&userEmail).Error; err != nil {
+		log.Printf("Warning: Failed to get user email for store %s (user_id: %d): %v", store.Name, store.UserID, err)
+	} else {
+		// Get Paystack direct deposit transactions using user's email
+		paystackDeposits, err := r.UserHandler.GetPaystackDepositTransactions(ctx, userEmail)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch Paystack direct deposits for store %s: %v", store.Name, err)
+			// Continue without Paystack deposits if there's an error
+		} else {
+			// Combine all deposits
+			orderTransactions = append(orderTransactions, paystackDeposits...)
+		}
+	}
+
+	// Get withdrawals
+	var withdrawals []*model.WithdrawalTransaction
+	if err := r.DB.Raw(`
+		SELECT
+			id::text as id,
+			amount,
+			status,
+			bank_name,
+			account_number,
+			account_name,
+			created_at,
+			approved_at
+		FROM withdrawals
+		WHERE store_id = ?
+		ORDER BY created_at DESC
+	`, storeID).Scan(&withdrawals).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch withdrawals: %v", err)
+	}
+
+	// Sort deposits by created_at
+	sort.Slice(orderTransactions, func(i, j int) bool {
+		return orderTransactions[i].CreatedAt.After(orderTransactions[j].CreatedAt)
+	})
+
+	return &model.StoreTransactions{
+		Deposits:    orderTransactions,
+		Withdrawals: withdrawals,
+	}, nil
+}
+
+// ProductViewStats is the resolver for the productViewStats field.
+func (r *queryResolver) ProductViewStats(ctx context.Context, productID int, from time.Time, to time.Time) ([]*model.ProductViewStat, error) {
+	if _, err := r.requireProductManager(ctx, uint32(productID)); err != nil {
+		return nil, err
+	}
+
+	stats, err := r.ProductHandler.GetProductViewStats(ctx, uint32(productID), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.ProductViewStat, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, &model.ProductViewStat{
+			Day:           s.Day,
+			Views:         s.Views,
+			UniqueViewers: s.UniqueViewers,
+		})
+	}
+	return result, nil
+}
+
+// ProductModerationQueue is the resolver for the productModerationQueue field.
+func (r *queryResolver) ProductModerationQueue(ctx context.Context, status *string) ([]*model.ProductModeration, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	statusValue := ""
+	if status != nil {
+		statusValue = *status
+	}
+
+	adminHandler := admin.NewHandler(admin.NewService(admin.NewRepository()))
+	entries, err := adminHandler.GetModerationQueue(ctx, statusValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation queue: %v", err)
+	}
+
+	result := make([]*model.ProductModeration, 0, len(entries))
+	for _, e := range entries {
+		item := &model.ProductModeration{
+			ID:         int(e.ID),
+			ProductID:  int(e.ProductID),
+			Store:      e.Store,
+			Trigger:    e.Trigger,
+			Flags:      e.Flags,
+			Status:     e.Status,
+			ReviewedAt: e.ReviewedAt,
+			CreatedAt:  e.CreatedAt,
+		}
+		if item.Flags == nil {
+			item.Flags = []string{}
+		}
+		if e.Reason != "" {
+			reason := e.Reason
+			item.Reason = &reason
+		}
+		if e.ReviewerID != 0 {
+			reviewerID := int(e.ReviewerID)
+			item.ReviewerID = &reviewerID
+		}
+		if p, err := r.ProductHandler.GetProduct(ctx, e.ProductID, 0); err == nil {
+			item.Product = &model.Product{
+				ID:               int(p.ID),
+				Name:             p.Name,
+				Slug:             p.Slug,
+				Description:      p.Description,
+				Price:            p.Price,
+				Discount:         p.Discount,
+				Status:           p.Status,
+				Quantity:         p.Quantity,
+				Thumbnail:        p.Thumbnail,
+				Image:            p.Images,
+				Store:            p.Store,
+				Category:         p.Category,
+				Subcategory:      p.Subcategory,
+				UnitsSold:        p.UnitsSold,
+				ModerationStatus: &p.ModerationStatus,
+				ModerationReason: &p.ModerationReason,
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *queryResolver) Notifications(ctx context.Context, unreadOnly *bool, limit *int) ([]*model.Notification, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unread := unreadOnly != nil && *unreadOnly
+	limitValue := 50
+	if limit != nil {
+		limitValue = *limit
+	}
+
+	notificationHandler := notification.NewHandler(notification.NewService(notification.NewRepository()))
+	notifications, err := notificationHandler.GetNotifications(ctx, userID, unread, limitValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %v", err)
+	}
+
+	result := make([]*model.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		item := &model.Notification{
+			ID:        int(n.ID),
+			Type:      n.Type,
+			Title:     n.Title,
+			Message:   n.Message,
+			Read:      n.Read,
+			CreatedAt: n.CreatedAt,
+		}
+		if n.Link != "" {
+			link := n.Link
+			item.Link = &link
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// ProductQuestions is the resolver for the productQuestions field.
+func (r *queryResolver) ProductQuestions(ctx context.Context, productID int, answeredOnly *bool, limit *int, offset *int) ([]*model.ProductQuestion, error) {
+	limitValue := 20
+	if limit != nil {
+		limitValue = *limit
+	}
+	offsetValue := 0
+	if offset != nil {
+		offsetValue = *offset
+	}
+
+	questions, err := r.ProductHandler.GetProductQuestions(ctx, uint32(productID), answeredOnly != nil && *answeredOnly, limitValue, offsetValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch questions: %v", err)
+	}
+
+	result := make([]*model.ProductQuestion, 0, len(questions))
+	for _, q := range questions {
+		result = append(result, productQuestionToModel(q))
+	}
+	return result, nil
+}
+
+// ProductQAModerationQueue is the resolver for the productQAModerationQueue field.
+func (r *queryResolver) ProductQAModerationQueue(ctx context.Context) (*model.ProductQAQueue, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	questions, answers, err := r.ProductHandler.GetQAModerationQueue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch moderation queue: %v", err)
+	}
+
+	queue := &model.ProductQAQueue{
+		Questions: make([]*model.ProductQuestion, 0, len(questions)),
+		Answers:   make([]*model.ProductAnswer, 0, len(answers)),
+	}
+	for _, q := range questions {
+		queue.Questions = append(queue.Questions, productQuestionToModel(q))
+	}
+	for _, a := range answers {
+		queue.Answers = append(queue.Answers, productAnswerToModel(a))
+	}
+	return queue, nil
+}
+
+// Images is the resolver for the images field.
+func (r *queryResolver) Images(ctx context.Context, ownerType string, ownerID int) ([]*model.MediaImage, error) {
+	mediaHandler := media.NewHandler(media.NewService(media.NewRepository()))
+	images, err := mediaHandler.GetImages(ctx, ownerType, uint32(ownerID))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.MediaImage, 0, len(images))
+	for _, img := range images {
+		renditions := make([]*model.ImageRendition, 0, len(img.Renditions))
+		for _, rd := range img.Renditions {
+			renditions = append(renditions, &model.ImageRendition{
+				Size:   rd.Size,
+				Format: rd.Format,
+				URL:    rd.URL,
+				Width:  rd.Width,
+				Height: rd.Height,
+				Bytes:  rd.Bytes,
+			})
+		}
+		result = append(result, &model.MediaImage{
+			ID:          int(img.ID),
+			OwnerType:   img.OwnerType,
+			OwnerID:     int(img.OwnerID),
+			Role:        img.Role,
+			Width:       img.Width,
+			Height:      img.Height,
+			OriginalURL: img.OriginalURL,
+			Renditions:  renditions,
+			CreatedAt:   img.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// DownloadAuditLog is the resolver for the downloadAuditLog field.
+func (r *queryResolver) DownloadAuditLog(ctx context.Context, downloadID string) ([]*model.DownloadEvent, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	downloadHandler := download.NewHandler(download.NewService(download.NewRepository()))
+	events, err := downloadHandler.GetEvents(ctx, downloadID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.DownloadEvent, 0, len(events))
+	for _, e := range events {
+		item := &model.DownloadEvent{
+			ID:         int(e.ID),
+			DownloadID: e.DownloadID,
+			UserID:     e.UserID,
+			Action:     e.Action,
+			IP:         e.IP,
+			UserAgent:  e.UserAgent,
+			CreatedAt:  e.CreatedAt,
+		}
+		if e.Reason != "" {
+			reason := e.Reason
+			item.Reason = &reason
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// ActiveDeals is the resolver for the activeDeals field.
+func (r *queryResolver) ActiveDeals(ctx context.Context, limit *int, offset *int) ([]*model.SaleDeal, error) {
+	l, o := 20, 0
+	if limit != nil && *limit > 0 && *limit <= 100 {
+		l = *limit
+	}
+	if offset != nil && *offset > 0 {
+		o = *offset
+	}
+
+	items, err := r.ProductHandler.GetActiveDeals(ctx, l, o)
+	if err != nil {
+		return nil, err
+	}
+	deals := make([]*model.SaleDeal, 0, len(items))
+	for _, item := range items {
+		deals = append(deals, &model.SaleDeal{
+			Product:      productToModel(item.Product),
+			CampaignID:   int(item.CampaignID),
+			CampaignName: item.Campaign.Name,
+			SalePrice:    item.UnitPrice(item.Product),
+			RegularPrice: item.Product.EffectivePrice(),
+			EndsAt:       item.Campaign.EndsAt,
+			SecondsLeft:  secondsUntil(item.Campaign.EndsAt),
+			Remaining:    remainingToModel(item.Remaining()),
+		})
+	}
+	return deals, nil
+}
+
+// StoreSaleCampaigns is the resolver for the storeSaleCampaigns field.
+func (r *queryResolver) StoreSaleCampaigns(ctx context.Context, storeID int, includePast *bool) ([]*model.SaleCampaign, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	campaigns, err := r.ProductHandler.GetStoreSaleCampaigns(ctx, storeObj.Name, includePast != nil && *includePast)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.SaleCampaign, 0, len(campaigns))
+	for _, c := range campaigns {
+		result = append(result, saleCampaignToModel(c))
+	}
+	return result, nil
+}
+
+// BookingSlots is the resolver for the bookingSlots field.
+func (r *queryResolver) BookingSlots(ctx context.Context, productID int, from time.Time, to time.Time) ([]*model.BookingSlot, error) {
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	slots, err := bookingHandler.GetSlots(ctx, uint32(productID), from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.BookingSlot, 0, len(slots))
+	for _, slot := range slots {
+		result = append(result, &model.BookingSlot{
+			StartsAt:  slot.StartsAt,
+			EndsAt:    slot.EndsAt,
+			Capacity:  slot.Capacity,
+			Available: slot.Available,
+		})
+	}
+	return result, nil
+}
+
+// MyBookings is the resolver for the myBookings field.
+func (r *queryResolver) MyBookings(ctx context.Context, upcomingOnly *bool) ([]*model.Booking, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	bookings, err := bookingHandler.GetUserBookings(ctx, userID, upcomingOnly != nil && *upcomingOnly)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.Booking, 0, len(bookings))
+	for _, b := range bookings {
+		result = append(result, bookingToModel(b))
+	}
+	return result, nil
+}
+
+// StoreBookings is the resolver for the storeBookings field.
+func (r *queryResolver) StoreBookings(ctx context.Context, storeID int, from time.Time, to time.Time) ([]*model.Booking, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	bookingHandler := booking.NewHandler(booking.NewService(booking.NewRepository()))
+	bookings, err := bookingHandler.GetStoreBookings(ctx, storeObj.Name, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.Booking, 0, len(bookings))
+	for _, b := range bookings {
+		result = append(result, bookingToModel(b))
+	}
+	return result, nil
+}
+
+// ProductTrash is the resolver for the productTrash field.
+func (r *queryResolver) ProductTrash(ctx context.Context, storeID int) ([]*model.TrashedProduct, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermProducts)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := r.ProductHandler.GetTrashedProducts(ctx, storeObj.Name)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.TrashedProduct, 0, len(products))
+	for _, p := range products {
+		result = append(result, &model.TrashedProduct{
+			Product:   productToModel(p),
+			DeletedAt: p.DeletedAt.Time,
+			PurgeAt:   p.DeletedAt.Time.Add(product.TrashRetention),
+		})
+	}
+	return result, nil
+}
+
+// StoreTrash is the resolver for the storeTrash field.
+func (r *queryResolver) StoreTrash(ctx context.Context) ([]*model.TrashedStore, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	stores, err := storeHandler.GetTrashedStores(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.TrashedStore, 0, len(stores))
+	for _, s := range stores {
+		count, err := storeHandler.CountTrashedStoreProducts(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &model.TrashedStore{
+			ID:           int(s.ID),
+			Name:         s.Name,
+			Thumbnail:    s.Thumbnail,
+			DeletedAt:    s.DeletedAt.Time,
+			PurgeAt:      s.DeletedAt.Time.Add(product.TrashRetention),
+			ProductCount: count,
+		})
+	}
+	return result, nil
+}
+
+// StoreAnalytics is the resolver for the storeAnalytics field.
+func (r *queryResolver) StoreAnalytics(ctx context.Context, storeID int, from time.Time, to time.Time, granularity *string) (*model.StoreAnalytics, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermAnalytics)
+	if err != nil {
+		return nil, err
+	}
+
+	g := ""
+	if granularity != nil {
+		g = *granularity
+	}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	analytics, err := storeHandler.GetStoreAnalytics(ctx, storeObj.Name, from, to, g)
+	if err != nil {
+		return nil, err
+	}
+	return storeAnalyticsToModel(analytics), nil
+}
+
+// StoreStaff is the resolver for the storeStaff field.
+func (r *queryResolver) StoreStaff(ctx context.Context, storeID int) ([]*model.StoreMember, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermStaff)
+	if err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	members, err := storeHandler.GetStoreStaff(ctx, storeObj.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		m.Store = storeObj
+	}
+	return storeMembersToModel(members), nil
+}
+
+// MyStoreRole is the resolver for the myStoreRole field.
+func (r *queryResolver) MyStoreRole(ctx context.Context, storeID int) (*string, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	storeObj, err := storeHandler.GetStore(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
+	}
+	role, err := storeHandler.GetStoreRole(ctx, storeObj, userID)
+	if err != nil || role == "" {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// MyStoreInvites is the resolver for the myStoreInvites field.
+func (r *queryResolver) MyStoreInvites(ctx context.Context) ([]*model.StoreMember, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	invites, err := storeHandler.GetUserStaffInvites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return storeMembersToModel(invites), nil
+}
+
+// MyStoreMemberships is the resolver for the myStoreMemberships field.
+func (r *queryResolver) MyStoreMemberships(ctx context.Context) ([]*model.StoreMember, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	members, err := storeHandler.GetUserMemberships(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch all products: %v", err)
+		return nil, err
 	}
+	return storeMembersToModel(members), nil
+}
 
-	var productResponses []*model.Product
-	for _, p := range products {
-		if p == nil {
-			continue
-		}
+// MyVerification is the resolver for the myVerification field.
+func (r *queryResolver) MyVerification(ctx context.Context) (*model.VerificationStatus, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		// Convert images to string array
-		var images []string
-		if p.Thumbnail != "" {
-			images = []string{p.Thumbnail}
-		}
+	kycHandler := kyc.NewHandler(kyc.NewService(kyc.NewRepository()))
+	status, err := kycHandler.GetStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return verificationStatusToModel(status), nil
+}
 
-		productResponse := &model.Product{
-			ID:          int(p.ID),
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-			Discount:    p.Discount,
-			Status:      p.Status,
-			Image:       images,
-			Quantity:    p.Quantity,
-			Store:       p.Store, // Store is already a string in the database
-			Category:    p.Category,
-			Subcategory: p.Subcategory,
-			UnitsSold:   p.UnitsSold,
-		}
-		productResponses = append(productResponses, productResponse)
+// VerificationReviewQueue is the resolver for the verificationReviewQueue field.
+func (r *queryResolver) VerificationReviewQueue(ctx context.Context, status *string) ([]*model.IdentityVerification, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
 	}
 
-	return productResponses, nil
+	var filter string
+	if status != nil {
+		filter = *status
+	}
+	kycHandler := kyc.NewHandler(kyc.NewService(kyc.NewRepository()))
+	queue, err := kycHandler.GetReviewQueue(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.IdentityVerification, 0, len(queue))
+	for _, v := range queue {
+		result = append(result, identityVerificationToModel(v))
+	}
+	return result, nil
 }
 
-// GetAllReviews is the resolver for the getAllReviews field.
-func (r *queryResolver) GetAllReviews(ctx context.Context) ([]*model.Review, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("database connection failed")
-	}
-
-	var reviews []*model.Review
-
-	// Query struct for database
-	type dbReview struct {
-		StoreID   int       `json:"store_id"`
-		ProductID int       `json:"product_id"`
-		OrderID   string    `json:"order_id"`
-		SellerID  int       `json:"seller_id"`
-		Rating    float64   `json:"rating"`
-		Message   string    `json:"message"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		Nickname  string    `json:"nickname"`
-		Avatar    string    `json:"avatar"`
-		Comment   string    `json:"comment"`
-	}
-
-	// Get all reviews with user information
-	var dbReviews []dbReview
-	if err := db.Table("reviews").
-		Select("reviews.*, users.nickname, users.avatar").
-		Joins("LEFT JOIN users ON reviews.buyer_id = users.id").
-		Scan(&dbReviews).Error; err != nil {
-		return nil, fmt.Errorf("error fetching reviews: %v", err)
+// StoreHours is the resolver for the storeHours field.
+func (r *queryResolver) StoreHours(ctx context.Context, storeID int) (*model.StoreHours, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	hours, err := storeHandler.GetStoreHours(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
 	}
+	return storeHoursToModel(hours), nil
+}
 
-	// Convert to model.Review objects
-	for _, r := range dbReviews {
-		review := &model.Review{
-			StoreID:   r.StoreID,
-			ProductID: r.ProductID,
-			OrderID:   r.OrderID,
-			SellerID:  r.SellerID,
-			Rating:    r.Rating,
-			Message:   &r.Message,
-			CreatedAt: &r.CreatedAt,
-			UpdatedAt: &r.UpdatedAt,
-			Buyer: &model.ReviewBuyer{
-				Nickname: r.Nickname,
-				Avatar:   r.Avatar,
-				Comment:  r.Comment,
-			},
+// StoreClosures is the resolver for the storeClosures field.
+func (r *queryResolver) StoreClosures(ctx context.Context, storeID int, includePast *bool) ([]*model.StoreClosure, error) {
+	past := false
+	if includePast != nil && *includePast {
+		// Past closures are only of interest to the store's own staff
+		if _, err := r.requireStorePermission(ctx, uint32(storeID), store.PermSettings); err != nil {
+			return nil, err
 		}
-		reviews = append(reviews, review)
+		past = true
 	}
 
-	return reviews, nil
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	closures, err := storeHandler.GetStoreClosures(ctx, uint32(storeID), past)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.StoreClosure, 0, len(closures))
+	for _, c := range closures {
+		result = append(result, storeClosureToModel(c))
+	}
+	return result, nil
 }
 
-// GetAllOrders is the resolver for the getAllOrders field.
-func (r *queryResolver) GetAllOrders(ctx context.Context) ([]*model.Order, error) {
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+// CheckoutAvailability is the resolver for the checkoutAvailability field.
+func (r *queryResolver) CheckoutAvailability(ctx context.Context) ([]*model.StoreAvailability, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get all orders
-	orders, err := storeHandler.GetAllOrders(ctx)
+	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+	userCart, err := cartHandler.GetCart(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch all orders: %v", err)
+		return nil, err
 	}
 
-	var result []*model.Order
-	for _, order := range orders {
-		// Convert products
-		var products []*model.Product
-		for _, p := range order.Products {
-			products = append(products, &model.Product{
-				ID:        int(p.ID),
-				Name:      p.Name,
-				Price:     p.Price,
-				Thumbnail: p.Thumbnail,
-				Status:    p.Status == "active",
-			})
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	now := time.Now()
+	seen := map[string]bool{}
+	result := []*model.StoreAvailability{}
+	for _, item := range userCart.Items {
+		if item.Product == nil || seen[item.Product.Store] {
+			continue
 		}
-
-		// Convert delivery details
-		var deliveryDetails *model.DeliveryDetails
-		if order.DeliveryDetails != nil {
-			deliveryDetails = &model.DeliveryDetails{
-				Method:  order.DeliveryDetails.Method,
-				Address: order.DeliveryDetails.Address,
-				Fee:     order.DeliveryDetails.Fee,
-			}
+		seen[item.Product.Store] = true
+		storeObj, err := storeHandler.GetStoreByName(ctx, item.Product.Store)
+		if err != nil {
+			return nil, err
 		}
-
-		result = append(result, &model.Order{
-			CartID:          int(order.CartID),
-			UUID:            order.UUID,
-			Amount:          order.Amount,
-			Status:          order.Status,
-			PaymentGateway:  order.PaymentGateway,
-			PaymentMethod:   order.PaymentMethod,
-			TransRef:        order.TransRef,
-			TransStatus:     order.TransStatus,
-			Products:        products,
-			DeliveryDetails: deliveryDetails,
-			TextRef:         &order.TransRef,
-			CreatedAt:       &order.CreatedAt,
-		})
+		availability, err := storeHandler.GetStoreAvailability(ctx, storeObj, now)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, storeAvailabilityToModel(availability))
 	}
-
 	return result, nil
 }
 
-// Add/Update the Chats query resolver
-func (r *queryResolver) Chats(ctx context.Context, userID string) ([]*model.Chat, error) {
-	// Convert userID string to uint32
-	uid, err := strconv.ParseUint(userID, 10, 32)
+// FollowerFeed is the resolver for the followerFeed field.
+func (r *queryResolver) FollowerFeed(ctx context.Context, first *int, after *string) (*model.FollowerFeed, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %v", err)
+		return nil, err
 	}
 
-	// Use GetChatLists instead of GetChatsByUserID
-	chats, err := r.MessageHandler.GetChatLists(ctx, uint32(uid))
+	limit := 20
+	if first != nil {
+		limit = *first
+	}
+	var cursor string
+	if after != nil {
+		cursor = *after
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	page, err := storeHandler.GetFollowerFeed(ctx, userID, limit, cursor)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user chats: %v", err)
+		return nil, err
+	}
+	items := make([]*model.FeedItem, 0, len(page.Items))
+	for _, f := range page.Items {
+		items = append(items, feedItemToModel(f))
 	}
+	feed := &model.FollowerFeed{Items: items}
+	if page.NextCursor != "" {
+		feed.NextCursor = &page.NextCursor
+	}
+	return feed, nil
+}
 
-	var result []*model.Chat
-	for _, chat := range chats {
-		var messages []*model.Message
-		for _, msg := range chat.Messages {
-			// Get user details for the sender
-			senderUser, err := r.UserHandler.GetUser(ctx, strconv.FormatUint(uint64(msg.Sender), 10))
-			if err != nil {
-				log.Printf("Error fetching user details: %v", err)
-				continue
-			}
+// StoreAnnouncements is the resolver for the storeAnnouncements field.
+func (r *queryResolver) StoreAnnouncements(ctx context.Context, storeID int, limit *int, offset *int) ([]*model.StoreAnnouncement, error) {
+	pageLimit, pageOffset := 20, 0
+	if limit != nil {
+		pageLimit = *limit
+	}
+	if offset != nil {
+		pageOffset = *offset
+	}
 
-			var mediaStr *string
-			if msg.Media != nil {
-				s := string(*msg.Media)
-				mediaStr = &s
-			}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	announcements, err := storeHandler.GetStoreAnnouncements(ctx, uint32(storeID), pageLimit, pageOffset)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.StoreAnnouncement, 0, len(announcements))
+	for _, a := range announcements {
+		result = append(result, storeAnnouncementToModel(a))
+	}
+	return result, nil
+}
 
-			// Create user model
-			user := &model.User{
-				ID:       strconv.FormatUint(uint64(senderUser.ID), 10),
-				Fullname: senderUser.Fullname,
-				Avatar:   &senderUser.Avatar,
-				Online:   senderUser.Online,
-			}
+// StoreCustomers is the resolver for the storeCustomers field.
+func (r *queryResolver) StoreCustomers(ctx context.Context, storeID int, filter *model.StoreCustomerFilter) (*model.StoreCustomerList, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermCustomers)
+	if err != nil {
+		return nil, err
+	}
 
-			messages = append(messages, &model.Message{
-				ID:        strconv.FormatInt(int64(msg.ID), 10),
-				ChatID:    strconv.FormatInt(int64(msg.ChatID), 10),
-				Content:   msg.Content,
-				Sender:    strconv.FormatInt(int64(msg.Sender), 10),
-				Media:     mediaStr,
-				IsRead:    msg.IsRead,
-				Users:     []*model.User{user}, // Add user details here
-				CreatedAt: msg.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: func() *string {
-					if !msg.UpdatedAt.IsZero() {
-						t := msg.UpdatedAt.Format(time.RFC3339)
-						return &t
-					}
-					return nil
-				}(),
-			})
+	customerFilter := &store.CustomerFilter{}
+	if filter != nil {
+		if filter.Segment != nil {
+			customerFilter.Segment = *filter.Segment
+		}
+		if filter.Tag != nil {
+			customerFilter.Tag = *filter.Tag
+		}
+		if filter.Search != nil {
+			customerFilter.Search = *filter.Search
+		}
+		if filter.Sort != nil {
+			customerFilter.Sort = *filter.Sort
+		}
+		if filter.Limit != nil {
+			customerFilter.Limit = *filter.Limit
+		}
+		if filter.Offset != nil {
+			customerFilter.Offset = *filter.Offset
 		}
-
-		result = append(result, &model.Chat{
-			ID:            strconv.FormatInt(int64(chat.ID), 10),
-			Messages:      messages,
-			LatestMessage: nil, // Add latest message if needed
-			UnreadCount:   chat.UnreadCount,
-		})
 	}
 
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	list, err := storeHandler.GetStoreCustomers(ctx, storeObj, customerFilter)
+	if err != nil {
+		return nil, err
+	}
+	result := &model.StoreCustomerList{
+		Total:     list.Total,
+		Customers: make([]*model.StoreCustomerProfile, 0, len(list.Customers)),
+	}
+	for _, c := range list.Customers {
+		result.Customers = append(result.Customers, storeCustomerToModel(c))
+	}
 	return result, nil
 }
 
-// Fix the Messages query resolver
-func (r *queryResolver) Messages(ctx context.Context, chatID string) ([]*model.Message, error) {
-	messages, err := r.MessageHandler.GetMessages(ctx, chatID)
+// StoreCustomer is the resolver for the storeCustomer field.
+func (r *queryResolver) StoreCustomer(ctx context.Context, storeID int, userID int) (*model.StoreCustomerProfile, error) {
+	storeObj, err := r.requireStorePermission(ctx, uint32(storeID), store.PermCustomers)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []*model.Message
-	for _, msg := range messages {
-		createdAt := msg.CreatedAt.Format(time.RFC3339)
-		var updatedAt *string
-		if !msg.UpdatedAt.IsZero() {
-			t := msg.UpdatedAt.Format(time.RFC3339)
-			updatedAt = &t
-		}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	customer, err := storeHandler.GetStoreCustomer(ctx, storeObj, uint32(userID))
+	if err != nil {
+		return nil, err
+	}
+	return storeCustomerToModel(customer), nil
+}
 
-		result = append(result, &model.Message{
-			ID:        strconv.FormatInt(int64(msg.ID), 10),
-			ChatID:    strconv.FormatInt(int64(msg.ChatID), 10),
-			Content:   msg.Content,
-			Sender:    strconv.FormatInt(int64(msg.Sender), 10),
-			Media:     (*string)(msg.Media),
-			IsRead:    msg.IsRead,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-		})
+// StoreCustomerNotes is the resolver for the storeCustomerNotes field.
+func (r *queryResolver) StoreCustomerNotes(ctx context.Context, storeID int, userID int) ([]*model.StoreCustomerNote, error) {
+	if _, err := r.requireStorePermission(ctx, uint32(storeID), store.PermCustomers); err != nil {
+		return nil, err
 	}
 
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	notes, err := storeHandler.GetCustomerNotes(ctx, uint32(storeID), uint32(userID))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.StoreCustomerNote, 0, len(notes))
+	for _, n := range notes {
+		result = append(result, storeCustomerNoteToModel(n))
+	}
 	return result, nil
 }
 
-// Subscribers is the resolver for the subscribers field.
-func (r *queryResolver) Subscribers(ctx context.Context) ([]*model.Subscriber, error) {
-	panic(fmt.Errorf("not implemented: Subscribers - subscribers"))
+// StorePromoCampaigns is the resolver for the storePromoCampaigns field.
+func (r *queryResolver) StorePromoCampaigns(ctx context.Context, storeID int) ([]*model.StorePromoCampaign, error) {
+	if _, err := r.requireStorePermission(ctx, uint32(storeID), store.PermCustomers); err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	campaigns, err := storeHandler.GetPromoCampaigns(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.StorePromoCampaign, 0, len(campaigns))
+	for _, c := range campaigns {
+		result = append(result, storePromoCampaignToModel(c))
+	}
+	return result, nil
+}
+
+// MyOrderDisputes is the resolver for the myOrderDisputes field.
+func (r *queryResolver) MyOrderDisputes(ctx context.Context) ([]*model.OrderDispute, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	disputes, err := storeHandler.GetBuyerDisputes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return orderDisputesToModel(disputes), nil
 }
 
-// GetDVAAccount is the resolver for the GetDVAAccount field.
-func (r *queryResolver) GetDVAAccount(ctx context.Context, userID string) (*model.DVAAccount, error) {
-	// Get DVA account details using the userID string directly
-	dvaAccount, err := r.UserHandler.GetDVAAccount(ctx, userID)
+// StoreDisputes is the resolver for the storeDisputes field.
+func (r *queryResolver) StoreDisputes(ctx context.Context, storeID int) ([]*model.OrderDispute, error) {
+	if _, err := r.requireStorePermission(ctx, uint32(storeID), store.PermOrders); err != nil {
+		return nil, err
+	}
+
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	disputes, err := storeHandler.GetStoreDisputes(ctx, uint32(storeID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get DVA account: %v", err)
+		return nil, err
 	}
+	return orderDisputesToModel(disputes), nil
+}
 
-	// Check if dvaAccount or its nested fields are nil
-	if dvaAccount == nil {
-		return nil, fmt.Errorf("DVA account not found")
+// DisputeQueue is the resolver for the disputeQueue field.
+func (r *queryResolver) DisputeQueue(ctx context.Context, status *string) ([]*model.OrderDispute, error) {
+	if _, err := r.requireAdmin(ctx); err != nil {
+		return nil, err
 	}
 
-	// Convert to GraphQL model
-	return &model.DVAAccount{
-		ID:            dvaAccount.ID,
-		AccountName:   dvaAccount.AccountName,
-		AccountNumber: dvaAccount.AccountNumber,
-		Customer: &model.Customer{
-			ID:        dvaAccount.Customer.ID,
-			Email:     dvaAccount.Customer.Email,
-			FirstName: "",
-			LastName:  "",
-		},
-		Bank: &model.Bank{ // Changed from DVABank to Bank
-			ID:   dvaAccount.Bank.ID,
-			Name: dvaAccount.Bank.Name,
-			Slug: dvaAccount.Bank.Slug,
-		},
-	}, nil
+	filter := store.DisputeOpen
+	if status != nil {
+		filter = *status
+	}
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	disputes, err := storeHandler.GetDisputeQueue(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return orderDisputesToModel(disputes), nil
 }
 
-// GetStoreEarnings is the resolver for the getStoreEarnings field.
-func (r *queryResolver) GetStoreEarnings(ctx context.Context, storeID int) ([]*model.StoreEarnings, error) {
+// StorePolicy is the resolver for the storePolicy field.
+func (r *queryResolver) StorePolicy(ctx context.Context, storeID int) (*model.StorePolicy, error) {
 	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	// Get store earnings
-	earnings, err := storeHandler.GetStoreEarnings(ctx, uint32(storeID))
+	storeObj, err := storeHandler.GetStore(ctx, uint32(storeID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch store earnings: %v", err)
+		return nil, err
 	}
+	policy, err := storeHandler.GetStorePolicy(ctx, storeObj)
+	if err != nil {
+		return nil, err
+	}
+	return storePolicyToModel(policy), nil
+}
 
-	// Convert to GraphQL model
-	var result []*model.StoreEarnings
-	for _, e := range earnings {
-		result = append(result, &model.StoreEarnings{
-			ID:              strconv.FormatUint(uint64(e.ID), 10),
-			StoreID:         int(e.StoreID),
-			OrderID:         e.OrderID,
-			Amount:          e.Amount,
-			Status:          e.Status,
-			TransactionType: e.TransactionType,
-			CreatedAt:       e.CreatedAt,
-			UpdatedAt:       e.UpdatedAt,
-		})
+// CheckoutPolicies is the resolver for the checkoutPolicies field.
+func (r *queryResolver) CheckoutPolicies(ctx context.Context, fulfilment []*model.FulfilmentChoiceInput) ([]*model.StoreCheckoutCheck, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
+	cartHandler := cart.NewHandler(cart.NewService(cart.NewRepository()))
+	checks, err := cartHandler.CheckoutPolicies(ctx, userID, fulfilmentChoices(fulfilment))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.StoreCheckoutCheck, 0, len(checks))
+	for _, c := range checks {
+		result = append(result, policyCheckToModel(c))
+	}
 	return result, nil
 }
 
-// CheckStoreEarningsDiscrepancy is the resolver for the checkStoreEarningsDiscrepancy field.
-func (r *queryResolver) CheckStoreEarningsDiscrepancy(ctx context.Context, storeID int) (*model.StoreEarningsDiscrepancy, error) {
-	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
-
-	// Get discrepancy information
-	deliveredOrdersCount, totalEarnings, err := storeHandler.CheckStoreEarningsDiscrepancy(ctx, uint32(storeID))
+// StoreCloseoutChecklist is the resolver for the storeCloseoutChecklist field.
+func (r *queryResolver) StoreCloseoutChecklist(ctx context.Context, storeID int) (*model.StoreCloseoutChecklist, error) {
+	storeObj, _, err := r.requireStoreOwnerOrAdmin(ctx, uint32(storeID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to check store earnings discrepancy: %v", err)
+		return nil, err
 	}
 
-	return &model.StoreEarningsDiscrepancy{
-		DeliveredOrdersCount: deliveredOrdersCount,
-		TotalEarnings:        totalEarnings,
-	}, nil
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	checklist, err := storeHandler.GetCloseoutChecklist(ctx, storeObj)
+	if err != nil {
+		return nil, err
+	}
+	return closeoutChecklistToModel(checklist), nil
 }
 
-// GetWithdrawalsForAdmin is the resolver for the getWithdrawalsForAdmin field.
-func (r *queryResolver) GetWithdrawalsForAdmin(ctx context.Context, status *string) ([]*model.AdminWithdrawal, error) {
-	widrawalService := withdrawal.NewService(withdrawal.NewRepository())
-	storeRepo := store.NewRepository() // Need store repo to get store names
-
-	// Fetch withdrawals using the new service method
-	withdrawals, err := widrawalService.GetWithdrawals(ctx, status)
+// StoreByHandle is the resolver for the storeByHandle field.
+func (r *queryResolver) StoreByHandle(ctx context.Context, handle string) (*model.Store, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	s, err := storeHandler.GetStoreByHandle(ctx, handle)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch withdrawals: %v", err)
+		return nil, err
 	}
+	return publicStoreToModel(s), nil
+}
 
-	var adminWithdrawals []*model.AdminWithdrawal
-	for _, w := range withdrawals {
-		// Fetch store name for each withdrawal
-		storeObj, err := storeRepo.GetStore(ctx, w.StoreID)
-		sellerName := "Unknown Seller" // Default value
-		if err == nil {
-			sellerName = storeObj.Name
-		} else {
-			log.Printf("Warning: Failed to get store for withdrawal %d (storeID %d): %v", w.ID, w.StoreID, err)
-		}
-
-		adminWithdrawals = append(adminWithdrawals, &model.AdminWithdrawal{
-			ID:            strconv.FormatUint(uint64(w.ID), 10),
-			SellerName:    sellerName,
-			AccountNumber: w.AccountNumber,
-			BankName:      w.BankName,
-			Amount:        w.Amount,
-			Time:          w.CreatedAt.Format("03:04PM"),    // Format time
-			Date:          w.CreatedAt.Format("02-01-2006"), // Format date
-			Status:        w.Status,
-			StoreID:       strconv.FormatUint(uint64(w.StoreID), 10),
-		})
+// CheckStoreHandle is the resolver for the checkStoreHandle field.
+func (r *queryResolver) CheckStoreHandle(ctx context.Context, handle string, storeID *int) (*model.StoreHandleCheck, error) {
+	var id uint32
+	if storeID != nil {
+		id = uint32(*storeID)
 	}
 
-	return adminWithdrawals, nil
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	return handleCheckToModel(handle, storeHandler.CheckStoreHandle(ctx, handle, id))
 }
 
-// GetWithdrawalDetails is the resolver for the getWithdrawalDetails field.
-func (r *queryResolver) GetWithdrawalDetails(ctx context.Context, id string) (*model.AdminWithdrawal, error) {
-	widrawalService := withdrawal.NewService(withdrawal.NewRepository())
-	storeRepo := store.NewRepository() // Need store repo to get store names
-
-	// Convert the ID string to uint32
-	widrawalID, err := strconv.ParseUint(id, 10, 32)
+// QRCode is the resolver for the qrCode field.
+func (r *queryResolver) QRCode(ctx context.Context, kind string, id int) (*model.QRCode, error) {
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	target, err := storeHandler.GetQRTarget(ctx, kind, uint32(id))
 	if err != nil {
-		return nil, fmt.Errorf("invalid withdrawal ID: %v", err)
+		return nil, err
 	}
+	return qrCodeToModel(target), nil
+}
 
-	// Fetch the specific withdrawal using the service method
-	w, err := widrawalService.GetWithdrawal(ctx, uint32(widrawalID))
+// ActiveSales is the resolver for the activeSales field.
+func (r *storeResolver) ActiveSales(ctx context.Context, obj *model.Store) ([]*model.SaleCampaign, error) {
+	campaigns, err := r.ProductHandler.GetStoreSaleCampaigns(ctx, obj.Name, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch withdrawal details: %v", err)
-	}
-
-	// Fetch store name for the withdrawal
-	storeObj, err := storeRepo.GetStore(ctx, w.StoreID)
-	sellerName := "Unknown Seller" // Default value
-	if err == nil {
-		sellerName = storeObj.Name
-	} else {
-		log.Printf("Warning: Failed to get store for withdrawal %d (storeID %d): %v", w.ID, w.StoreID, err)
+		return nil, err
 	}
-
-	// Map the withdrawal data to the AdminWithdrawal type
-	adminWithdrawal := &model.AdminWithdrawal{
-		ID:            strconv.FormatUint(uint64(w.ID), 10),
-		SellerName:    sellerName,
-		AccountNumber: w.AccountNumber,
-		BankName:      w.BankName,
-		Amount:        w.Amount,
-		Time:          w.CreatedAt.Format("03:04PM"),    // Format time
-		Date:          w.CreatedAt.Format("02-01-2006"), // Format date
-		Status:        w.Status,
-		StoreID:       strconv.FormatUint(uint64(w.StoreID), 10),
+	result := make([]*model.SaleCampaign, 0, len(campaigns))
+	for _, c := range campaigns {
+		result = append(result, saleCampaignToModel(c))
 	}
+	return result, nil
+}
 
-	return adminWithdrawal, nil
+// VerificationTier is the resolver for the verificationTier field.
+func (r *storeResolver) VerificationTier(ctx context.Context, obj *model.Store) (string, error) {
+	kycHandler := kyc.NewHandler(kyc.NewService(kyc.NewRepository()))
+	return kycHandler.GetUserTier(ctx, uint32(obj.User))
 }
 
-// GetStoreTransactions is the resolver for the getStoreTransactions field.
-func (r *queryResolver) GetStoreTransactions(ctx context.Context, storeID int) (*model.StoreTransactions, error) {
-	// First get the store to get its name and user_id
-	var store struct {
-		Name   string
-		UserID uint32
+// Verified is the resolver for the verified field.
+func (r *storeResolver) Verified(ctx context.Context, obj *model.Store) (bool, error) {
+	kycHandler := kyc.NewHandler(kyc.NewService(kyc.NewRepository()))
+	tier, err := kycHandler.GetUserTier(ctx, uint32(obj.User))
+	if err != nil {
+		return false, err
 	}
-	if err := r.DB.Table("stores").Select("name, user_id").Where("id = ?", storeID).First(&store).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch store: %v", err)
+	return tier == kyc.TierIdentity, nil
+}
+
+// Availability is the resolver for the availability field.
+func (r *storeResolver) Availability(ctx context.Context, obj *model.Store) (*model.StoreAvailability, error) {
+	storeID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %v", err)
 	}
+	storeObj := &store.Store{ID: uint32(storeID), Name: obj.Name, MaintenanceMode: obj.MaintenanceMode}
 
-	// Get order transactions
-	var orderTransactions []*model.DepositTransaction
-	if err := r.DB.Raw(`
-		SELECT
-			o.id::text as id,
-			'order_payment' as type,
-			o.fee::float as amount,
-			o.trans_ref as reference,
-			o.status,
-			o.created_at,
-			'Payment for order #' || o.id::text || 
-			CASE 
-				WHEN o.products IS NOT NULL AND jsonb_array_length(o.products) > 0 THEN 
-					' (' || (
-						SELECT STRING_AGG(product->>'name', ', ')
-						FROM jsonb_array_elements(o.products) AS product
-					) || ')'
-				ELSE '' 
-			END as description
-		FROM orders o
-		WHERE ? = ANY(o.stores_id) AND o.trans_status = 'paid'
-		ORDER BY o.created_at DESC
-	`, store.Name).Scan(&orderTransactions).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch order transactions: %v", err)
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	availability, err := storeHandler.GetStoreAvailability(ctx, storeObj, time.Now())
+	if err != nil {
+		return nil, err
 	}
+	return storeAvailabilityToModel(availability), nil
+}
 
-	// Get user's email from users table
-	var userEmail string
-	if err := r.DB.Table("users").Where("id = ?", store.UserID).Select("email").Scan(&userEmail).Error; err != nil {
-		log.Printf("Warning: Failed to get user email for store %s (user_id: %d): %v", store.Name, store.UserID, err)
-	} else {
-		// Get Paystack direct deposit transactions using user's email
-		paystackDeposits, err := r.UserHandler.GetPaystackDepositTransactions(ctx, userEmail)
-		if err != nil {
-			log.Printf("Warning: Failed to fetch Paystack direct deposits for store %s: %v", store.Name, err)
-			// Continue without Paystack deposits if there's an error
-		} else {
-			// Combine all deposits
-			orderTransactions = append(orderTransactions, paystackDeposits...)
-		}
+// Scorecard is the resolver for the scorecard field.
+func (r *storeResolver) Scorecard(ctx context.Context, obj *model.Store) (*model.SellerScorecard, error) {
+	storeID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %v", err)
 	}
 
-	// Get withdrawals
-	var withdrawals []*model.WithdrawalTransaction
-	if err := r.DB.Raw(`
-		SELECT
-			id::text as id,
-			amount,
-			status,
-			bank_name,
-			account_number,
-			account_name,
-			created_at,
-			approved_at
-		FROM withdrawals
-		WHERE store_id = ?
-		ORDER BY created_at DESC
-	`, storeID).Scan(&withdrawals).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch withdrawals: %v", err)
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	scorecard, err := storeHandler.GetStoreScorecard(ctx, uint32(storeID))
+	if err != nil {
+		return nil, err
 	}
+	return sellerScorecardToModel(scorecard), nil
+}
 
-	// Sort deposits by created_at
-	sort.Slice(orderTransactions, func(i, j int) bool {
-		return orderTransactions[i].CreatedAt.After(orderTransactions[j].CreatedAt)
-	})
+// Policy is the resolver for the policy field.
+func (r *storeResolver) Policy(ctx context.Context, obj *model.Store) (*model.StorePolicy, error) {
+	storeID, err := strconv.ParseUint(obj.ID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %v", err)
+	}
+	storeObj := &store.Store{ID: uint32(storeID), Address: obj.Address, HasPhysicalAddress: obj.HasPhysicalAddress}
 
-	return &model.StoreTransactions{
-		Deposits:    orderTransactions,
-		Withdrawals: withdrawals,
-	}, nil
+	storeHandler := store.NewHandler(store.NewService(store.NewRepository()))
+	policy, err := storeHandler.GetStorePolicy(ctx, storeObj)
+	if err != nil {
+		return nil, err
+	}
+	return storePolicyToModel(policy), nil
 }
 
 // ProductSearchResults is the resolver for the productSearchResults field.
@@ -3491,12 +5848,20 @@ func (r *subscriptionResolver) ProductSearchResults(ctx context.Context, query s
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Product returns ProductResolver implementation.
+func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Store returns StoreResolver implementation.
+func (r *Resolver) Store() StoreResolver { return &storeResolver{r} }
+
 // Subscription returns SubscriptionResolver implementation.
 func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
 
 type mutationResolver struct{ *Resolver }
+type productResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type storeResolver struct{ *Resolver }
 type subscriptionResolver struct{ *Resolver }
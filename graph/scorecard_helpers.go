@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func sellerScorecardToModel(s *store.StoreScorecard) *model.SellerScorecard {
+	item := &model.SellerScorecard{
+		Badge:              s.Badge,
+		Score:              s.Score,
+		Orders:             s.Orders,
+		AvgShipHours:       s.AvgShipHours,
+		CancellationRate:   s.CancellationRate,
+		DisputeRate:        s.DisputeRate,
+		AvgResponseMinutes: s.AvgResponseMinutes,
+		Rating:             s.Rating,
+		Reviews:            s.Reviews,
+	}
+	// Unscored stores get a placeholder scorecard
+	if !s.ComputedAt.IsZero() {
+		item.ComputedAt = &s.ComputedAt
+	}
+	return item
+}
+
+func orderDisputeToModel(d *store.OrderDispute) *model.OrderDispute {
+	item := &model.OrderDispute{
+		ID:         int(d.ID),
+		OrderID:    d.OrderUUID,
+		StoreID:    int(d.StoreID),
+		BuyerID:    int(d.BuyerID),
+		Reason:     d.Reason,
+		Status:     d.Status,
+		ResolvedAt: d.ResolvedAt,
+		CreatedAt:  d.CreatedAt,
+	}
+	if d.Details != "" {
+		item.Details = &d.Details
+	}
+	if d.Resolution != "" {
+		item.Resolution = &d.Resolution
+	}
+	return item
+}
+
+func orderDisputesToModel(disputes []*store.OrderDispute) []*model.OrderDispute {
+	result := make([]*model.OrderDispute, 0, len(disputes))
+	for _, d := range disputes {
+		result = append(result, orderDisputeToModel(d))
+	}
+	return result
+}
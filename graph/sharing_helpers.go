@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"strconv"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+// publicStoreToModel converts a store for public lookups, leaving out its
+// wallet and bank accounts.
+func publicStoreToModel(s *store.Store) *model.Store {
+	return &model.Store{
+		ID:                 strconv.Itoa(int(s.ID)),
+		Link:               s.Link,
+		Name:               s.Name,
+		User:               int(s.UserID),
+		Description:        s.Description,
+		Address:            s.Address,
+		Status:             s.Status,
+		Thumbnail:          s.Thumbnail,
+		Phone:              s.Phone,
+		Background:         s.Background,
+		HasPhysicalAddress: s.HasPhysicalAddress,
+		Email:              s.Email,
+		Visitors:           s.Visitors,
+		MaintenanceMode:    s.MaintenanceMode,
+		RatingAverage:      s.RatingAverage,
+		ReviewCount:        s.ReviewCount,
+	}
+}
+
+// handleCheckToModel reports a handle as taken or invalid when the check
+// failed with a user facing error, and passes other errors on.
+func handleCheckToModel(handle string, checkErr error) (*model.StoreHandleCheck, error) {
+	result := &model.StoreHandleCheck{Handle: store.NormalizeHandle(handle), Available: checkErr == nil}
+	if checkErr != nil {
+		appErr, ok := checkErr.(*errors.AppError)
+		if !ok {
+			return nil, checkErr
+		}
+		result.Reason = &appErr.Message
+	}
+	return result, nil
+}
+
+func qrCodeToModel(t *store.QRTarget) *model.QRCode {
+	return &model.QRCode{
+		Kind:    t.Kind,
+		ID:      int(t.TargetID),
+		ScanURL: t.ScanURL(),
+		PageURL: t.PageURL(),
+		PngURL:  t.ImageURL(store.QRFormatPNG),
+		SVGURL:  t.ImageURL(store.QRFormatSVG),
+	}
+}
+
+func trafficSourcesToModel(sources []*store.TrafficSource) []*model.TrafficSource {
+	result := make([]*model.TrafficSource, 0, len(sources))
+	for _, s := range sources {
+		result = append(result, &model.TrafficSource{Source: s.Source, Views: s.Views})
+	}
+	return result
+}
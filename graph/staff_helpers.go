@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+func storeMemberToModel(m *store.StoreMember) *model.StoreMember {
+	item := &model.StoreMember{
+		ID:              int(m.ID),
+		StoreID:         int(m.StoreID),
+		Role:            m.Role,
+		Status:          m.Status,
+		InviteExpiresAt: m.InviteExpiresAt,
+		AcceptedAt:      m.AcceptedAt,
+		CreatedAt:       m.CreatedAt,
+	}
+	if m.Store != nil {
+		item.StoreName = m.Store.Name
+	}
+	if m.UserID != 0 {
+		userID := int(m.UserID)
+		item.UserID = &userID
+	}
+	if m.Email != "" {
+		item.Email = &m.Email
+	}
+	if m.Phone != "" {
+		item.Phone = &m.Phone
+	}
+	return item
+}
+
+func storeMembersToModel(members []*store.StoreMember) []*model.StoreMember {
+	result := make([]*model.StoreMember, 0, len(members))
+	for _, m := range members {
+		result = append(result, storeMemberToModel(m))
+	}
+	return result
+}
@@ -2,6 +2,8 @@ package admin
 
 import (
 	"context"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
 )
 
 type Admin struct {
@@ -25,9 +27,11 @@ type Repository interface {
 	CreateAdmin(ctx context.Context, input *Admin) (*LoginAdminRes, error)
 	GetAdmin(ctx context.Context, id uint8) (*Admin, error)
 	GetAdmins(ctx context.Context) ([]*Admin, error)
-	ApproveProduct()
+	GetModerationQueue(ctx context.Context, status string) ([]*product.ProductModeration, error)
+	ApproveProduct(ctx context.Context, productID, reviewerID uint32) (*product.Product, error)
+	RejectProduct(ctx context.Context, productID, reviewerID uint32, reason string) (*product.Product, error)
+	SetStoreTrusted(ctx context.Context, storeID uint32, trusted bool) error
 	MessageUser()
-
 }
 
 type Service interface {
@@ -35,5 +39,8 @@ type Service interface {
 	CreateAdmin(ctx context.Context, input *Admin) (*LoginAdminRes, error)
 	GetAdmin(ctx context.Context, id uint8) (*Admin, error)
 	GetAdmins(ctx context.Context) ([]*Admin, error)
-
+	GetModerationQueue(ctx context.Context, status string) ([]*product.ProductModeration, error)
+	ApproveProduct(ctx context.Context, productID, reviewerID uint32) (*product.Product, error)
+	RejectProduct(ctx context.Context, productID, reviewerID uint32, reason string) (*product.Product, error)
+	SetStoreTrusted(ctx context.Context, storeID uint32, trusted bool) error
 }
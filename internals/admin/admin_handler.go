@@ -0,0 +1,33 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) GetModerationQueue(ctx context.Context, status string) ([]*product.ProductModeration, error) {
+	return h.Service.GetModerationQueue(ctx, status)
+}
+
+func (h *Handler) ApproveProduct(ctx context.Context, productID, reviewerID uint32) (*product.Product, error) {
+	return h.Service.ApproveProduct(ctx, productID, reviewerID)
+}
+
+func (h *Handler) RejectProduct(ctx context.Context, productID, reviewerID uint32, reason string) (*product.Product, error) {
+	return h.Service.RejectProduct(ctx, productID, reviewerID, reason)
+}
+
+func (h *Handler) SetStoreTrusted(ctx context.Context, storeID uint32, trusted bool) error {
+	return h.Service.SetStoreTrusted(ctx, storeID, trusted)
+}
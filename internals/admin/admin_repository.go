@@ -2,58 +2,144 @@ package admin
 
 import (
 	"context"
-	"log"
-	"os"
-	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
 	"gorm.io/gorm"
 )
 
 type repository struct {
 	db *gorm.DB
 }
-func NewRepository() Repository {
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
-	}
-	dbURI := os.Getenv("DB_URI")
-
-	// Initialize the database connection
-	db, err := gorm.Open(postgres.Open(dbURI), &gorm.Config{})
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	}
 
+func NewRepository() Repository {
 	return &repository{
-		db: db,
+		db: database.GetDB(),
 	}
 }
 
-func (r *repository) Login(ctx context.Context, input *LoginAdminReq) (*LoginAdminRes,error){
-	
+func (r *repository) Login(ctx context.Context, input *LoginAdminReq) (*LoginAdminRes, error) {
+
 	panic("")
 }
 
-func (r *repository) CreateAdmin(ctx context.Context, input *Admin) (*LoginAdminRes,error){
-	
+func (r *repository) CreateAdmin(ctx context.Context, input *Admin) (*LoginAdminRes, error) {
+
 	panic("")
 }
 
-func (r *repository) GetAdmin(ctx context.Context, id uint8) (*Admin,error){
-	
+func (r *repository) GetAdmin(ctx context.Context, id uint8) (*Admin, error) {
+
 	panic("")
 }
 
-func (r *repository) GetAdmins(ctx context.Context) ([]*Admin,error){
-	
+func (r *repository) GetAdmins(ctx context.Context) ([]*Admin, error) {
+
 	panic("")
 }
 
-func (r *repository) ApproveProduct(){
-	
+// GetModerationQueue lists moderation entries with the given status, oldest
+// first. An empty status lists pending entries.
+func (r *repository) GetModerationQueue(ctx context.Context, status string) ([]*product.ProductModeration, error) {
+	if status == "" {
+		status = product.ModerationPending
+	}
+	var entries []*product.ProductModeration
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
 
+func (r *repository) ApproveProduct(ctx context.Context, productID, reviewerID uint32) (*product.Product, error) {
+	return r.reviewProduct(ctx, productID, reviewerID, product.ModerationApproved, "")
 }
-func (r *repository) MessageUser(){
-	
+
+func (r *repository) RejectProduct(ctx context.Context, productID, reviewerID uint32, reason string) (*product.Product, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A reason is required to reject a product")
+	}
+	return r.reviewProduct(ctx, productID, reviewerID, product.ModerationRejected, reason)
+}
+
+// reviewProduct records an admin decision on a product and closes its open
+// queue entry.
+func (r *repository) reviewProduct(ctx context.Context, productID, reviewerID uint32, status, reason string) (*product.Product, error) {
+	var p product.Product
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", productID).First(&p).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+			}
+			return err
+		}
+
+		if err := tx.Model(&p).Updates(map[string]interface{}{
+			"moderation_status": status,
+			"moderation_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+		p.ModerationStatus = status
+		p.ModerationReason = reason
+
+		now := time.Now()
+		result := tx.Model(&product.ProductModeration{}).
+			Where("product_id = ? AND status = ?", productID, product.ModerationPending).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"reason":      reason,
+				"reviewer_id": reviewerID,
+				"reviewed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		// Keep a record of decisions made on products that were never queued
+		if result.RowsAffected == 0 {
+			return tx.Create(&product.ProductModeration{
+				ProductID:  productID,
+				Store:      p.Store,
+				Trigger:    "review",
+				Flags:      []string{},
+				Status:     status,
+				Reason:     reason,
+				ReviewerID: reviewerID,
+				ReviewedAt: &now,
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SetStoreTrusted lets a store's products skip the moderation queue, unless
+// they are flagged by the automatic checks.
+func (r *repository) SetStoreTrusted(ctx context.Context, storeID uint32, trusted bool) error {
+	result := r.db.WithContext(ctx).Table("stores").
+		Where("id = ? AND deleted_at IS NULL", storeID).
+		Update("trusted", trusted)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Store not found")
+	}
+	return nil
+}
+
+func (r *repository) MessageUser() {
 
 }
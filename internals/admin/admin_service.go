@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+func (s *service) GetModerationQueue(c context.Context, status string) ([]*product.ProductModeration, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetModerationQueue(ctx, status)
+}
+
+func (s *service) ApproveProduct(c context.Context, productID, reviewerID uint32) (*product.Product, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.ApproveProduct(ctx, productID, reviewerID)
+}
+
+func (s *service) RejectProduct(c context.Context, productID, reviewerID uint32, reason string) (*product.Product, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.RejectProduct(ctx, productID, reviewerID, reason)
+}
+
+func (s *service) SetStoreTrusted(c context.Context, storeID uint32, trusted bool) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.SetStoreTrusted(ctx, storeID, trusted)
+}
@@ -0,0 +1,108 @@
+package booking
+
+import (
+	"context"
+	"time"
+)
+
+// Booking states
+const (
+	// StatusHeld keeps a slot for a buyer between checkout and payment
+	StatusHeld      = "held"
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled"
+)
+
+const (
+	// HoldTTL is how long a slot stays held for an unpaid checkout.
+	HoldTTL = 30 * time.Minute
+	// ReminderLeadTime is how long before a booking reminders are sent.
+	ReminderLeadTime = 24 * time.Hour
+	// MaxSlotRange caps the period slots can be listed for at once.
+	MaxSlotRange = 31 * 24 * time.Hour
+)
+
+// WeeklyHours is a window a service is offered in every week, with times of
+// day like "09:00" in campus time.
+type WeeklyHours struct {
+	Weekday time.Weekday `json:"weekday"`
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+// Availability is when a service listing can be booked and the rules for
+// changing a booking. Capacity is how many seats one slot has, e.g. 1 for a
+// haircut or 10 for a tutorial class.
+type Availability struct {
+	ID                    uint32        `json:"id" gorm:"primaryKey"`
+	ProductID             uint32        `json:"product_id" gorm:"not null;uniqueIndex"`
+	Store                 string        `json:"store" gorm:"not null;index"`
+	Hours                 []WeeklyHours `json:"hours" gorm:"type:jsonb;serializer:json"`
+	SlotMinutes           int           `json:"slot_minutes" gorm:"not null"`
+	Capacity              int           `json:"capacity" gorm:"not null;default:1"`
+	MinNoticeHours        int           `json:"min_notice_hours"`
+	MaxAdvanceDays        int           `json:"max_advance_days"`
+	CancelBeforeHours     int           `json:"cancel_before_hours"`
+	RescheduleBeforeHours int           `json:"reschedule_before_hours"`
+	MaxReschedules        int           `json:"max_reschedules"`
+	CreatedAt             time.Time     `json:"created_at"`
+	UpdatedAt             time.Time     `json:"updated_at"`
+}
+
+func (Availability) TableName() string {
+	return "service_availability"
+}
+
+// Booking is a buyer's place in one slot of a service.
+type Booking struct {
+	ID             uint32     `json:"id" gorm:"primaryKey"`
+	ProductID      uint32     `json:"product_id" gorm:"not null;index"`
+	ProductName    string     `json:"product_name"`
+	Store          string     `json:"store" gorm:"not null;index"`
+	UserID         uint32     `json:"user_id" gorm:"not null;index"`
+	OrderUUID      string     `json:"order_uuid" gorm:"index"`
+	Seats          int        `json:"seats" gorm:"not null;default:1"`
+	StartsAt       time.Time  `json:"starts_at" gorm:"not null;index"`
+	EndsAt         time.Time  `json:"ends_at" gorm:"not null"`
+	Status         string     `json:"status" gorm:"not null;index"`
+	HoldExpiresAt  *time.Time `json:"hold_expires_at"`
+	Reschedules    int        `json:"reschedules"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	CancelledAt    *time.Time `json:"cancelled_at"`
+	CancelledBy    string     `json:"cancelled_by"` // "customer" or "provider"
+	CancelReason   string     `json:"cancel_reason"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Slot is one bookable time of a service and the seats left in it.
+type Slot struct {
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Capacity  int       `json:"capacity"`
+	Available int       `json:"available"`
+}
+
+type Repository interface {
+	GetAvailability(ctx context.Context, productId uint32) (*Availability, error)
+	SetAvailability(ctx context.Context, a *Availability) (*Availability, error)
+	GetSlots(ctx context.Context, productId uint32, from, to time.Time) ([]*Slot, error)
+	GetBooking(ctx context.Context, id uint32) (*Booking, error)
+	GetUserBookings(ctx context.Context, userId uint32, upcomingOnly bool) ([]*Booking, error)
+	GetStoreBookings(ctx context.Context, storeName string, from, to time.Time) ([]*Booking, error)
+	CancelBooking(ctx context.Context, id uint32, byProvider bool, reason string) (*Booking, error)
+	RescheduleBooking(ctx context.Context, id uint32, startsAt time.Time, byProvider bool) (*Booking, error)
+	SendReminders(ctx context.Context) (int, error)
+}
+
+type Service interface {
+	GetAvailability(ctx context.Context, productId uint32) (*Availability, error)
+	SetAvailability(ctx context.Context, a *Availability) (*Availability, error)
+	GetSlots(ctx context.Context, productId uint32, from, to time.Time) ([]*Slot, error)
+	GetBooking(ctx context.Context, id uint32) (*Booking, error)
+	GetUserBookings(ctx context.Context, userId uint32, upcomingOnly bool) ([]*Booking, error)
+	GetStoreBookings(ctx context.Context, storeName string, from, to time.Time) ([]*Booking, error)
+	CancelBooking(ctx context.Context, id uint32, byProvider bool, reason string) (*Booking, error)
+	RescheduleBooking(ctx context.Context, id uint32, startsAt time.Time, byProvider bool) (*Booking, error)
+	SendReminders(ctx context.Context) (int, error)
+}
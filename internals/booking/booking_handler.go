@@ -0,0 +1,52 @@
+package booking
+
+import (
+	"context"
+	"time"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) GetAvailability(ctx context.Context, productId uint32) (*Availability, error) {
+	return h.Service.GetAvailability(ctx, productId)
+}
+
+func (h *Handler) SetAvailability(ctx context.Context, a *Availability) (*Availability, error) {
+	return h.Service.SetAvailability(ctx, a)
+}
+
+func (h *Handler) GetSlots(ctx context.Context, productId uint32, from, to time.Time) ([]*Slot, error) {
+	return h.Service.GetSlots(ctx, productId, from, to)
+}
+
+func (h *Handler) GetBooking(ctx context.Context, id uint32) (*Booking, error) {
+	return h.Service.GetBooking(ctx, id)
+}
+
+func (h *Handler) GetUserBookings(ctx context.Context, userId uint32, upcomingOnly bool) ([]*Booking, error) {
+	return h.Service.GetUserBookings(ctx, userId, upcomingOnly)
+}
+
+func (h *Handler) GetStoreBookings(ctx context.Context, storeName string, from, to time.Time) ([]*Booking, error) {
+	return h.Service.GetStoreBookings(ctx, storeName, from, to)
+}
+
+func (h *Handler) CancelBooking(ctx context.Context, id uint32, byProvider bool, reason string) (*Booking, error) {
+	return h.Service.CancelBooking(ctx, id, byProvider, reason)
+}
+
+func (h *Handler) RescheduleBooking(ctx context.Context, id uint32, startsAt time.Time, byProvider bool) (*Booking, error) {
+	return h.Service.RescheduleBooking(ctx, id, startsAt, byProvider)
+}
+
+func (h *Handler) SendReminders(ctx context.Context) (int, error) {
+	return h.Service.SendReminders(ctx)
+}
@@ -0,0 +1,374 @@
+package booking
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+func validateAvailability(a *Availability) error {
+	if len(a.Hours) == 0 {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Add at least one weekly opening window")
+	}
+	if err := ValidateWeeklyHours(a.Hours); err != nil {
+		return err
+	}
+	switch {
+	case a.SlotMinutes < 5 || a.SlotMinutes > 12*60:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Slots must be between 5 minutes and 12 hours long")
+	case a.Capacity < 1 || a.Capacity > 500:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Each slot must have between 1 and 500 places")
+	case a.MinNoticeHours < 0 || a.CancelBeforeHours < 0 || a.RescheduleBeforeHours < 0 || a.MaxReschedules < 0:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Notice periods and reschedule limits cannot be negative")
+	case a.MaxAdvanceDays < 1 || a.MaxAdvanceDays > 365:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Bookings can open between 1 and 365 days ahead")
+	}
+	return nil
+}
+
+func (r *repository) GetAvailability(ctx context.Context, productId uint32) (*Availability, error) {
+	return LoadAvailability(r.db.WithContext(ctx), productId)
+}
+
+// SetAvailability creates or replaces the booking setup of a service listing.
+func (r *repository) SetAvailability(ctx context.Context, a *Availability) (*Availability, error) {
+	var p product.Product
+	if err := r.db.WithContext(ctx).First(&p, a.ProductID).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+	if p.Type != product.CategoryService {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Only listings in a service category can take bookings")
+	}
+	if a.MaxAdvanceDays == 0 {
+		a.MaxAdvanceDays = 30
+	}
+	if err := validateAvailability(a); err != nil {
+		return nil, err
+	}
+
+	a.ID = 0
+	a.Store = p.Store
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"store", "hours", "slot_minutes", "capacity", "min_notice_hours", "max_advance_days",
+			"cancel_before_hours", "reschedule_before_hours", "max_reschedules", "updated_at",
+		}),
+	}).Create(a).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save availability: %v", err)
+	}
+	return r.GetAvailability(ctx, a.ProductID)
+}
+
+func (r *repository) GetSlots(ctx context.Context, productId uint32, from, to time.Time) ([]*Slot, error) {
+	if !to.After(from) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "The end of the range must be after its start")
+	}
+	if to.Sub(from) > MaxSlotRange {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Slots can be listed for at most 31 days at a time")
+	}
+	a, err := r.GetAvailability(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return []*Slot{}, nil
+	}
+	return openSlots(r.db.WithContext(ctx), a, from, to, time.Now(), 0)
+}
+
+func (r *repository) GetBooking(ctx context.Context, id uint32) (*Booking, error) {
+	var b Booking
+	err := r.db.WithContext(ctx).First(&b, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Booking not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetUserBookings lists a customer's bookings, soonest first. Lapsed holds of
+// checkouts that were never paid are left out.
+func (r *repository) GetUserBookings(ctx context.Context, userId uint32, upcomingOnly bool) ([]*Booking, error) {
+	now := time.Now()
+	query := r.db.WithContext(ctx).Where("user_id = ?", userId).
+		Where("status <> ? OR hold_expires_at > ?", StatusHeld, now)
+	if upcomingOnly {
+		query = activeBookings(query, now).Where("ends_at > ?", now)
+	}
+	var bookings []*Booking
+	if err := query.Order("starts_at").Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %v", err)
+	}
+	return bookings, nil
+}
+
+// GetStoreBookings lists the bookings of a store's services starting in
+// [from, to), soonest first.
+func (r *repository) GetStoreBookings(ctx context.Context, storeName string, from, to time.Time) ([]*Booking, error) {
+	var bookings []*Booking
+	err := r.db.WithContext(ctx).
+		Where("store = ? AND starts_at >= ? AND starts_at < ?", storeName, from, to).
+		Where("status <> ? OR hold_expires_at > ?", StatusHeld, time.Now()).
+		Order("starts_at").
+		Find(&bookings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bookings: %v", err)
+	}
+	return bookings, nil
+}
+
+// CancelBooking frees a booking's seats. Customers can only cancel up to the
+// service's cancellation notice; providers can cancel any time before the
+// booking starts but must give a reason.
+func (r *repository) CancelBooking(ctx context.Context, id uint32, byProvider bool, reason string) (*Booking, error) {
+	b, err := r.GetBooking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch {
+	case b.Status == StatusCancelled:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This booking is already cancelled")
+	case !b.StartsAt.After(now):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This booking has already started")
+	}
+
+	reason = strings.TrimSpace(reason)
+	cancelledBy := "customer"
+	if byProvider {
+		if reason == "" {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Let the customer know why the booking is cancelled")
+		}
+		cancelledBy = "provider"
+	} else if a, err := r.GetAvailability(ctx, b.ProductID); err == nil && a != nil {
+		if now.After(b.StartsAt.Add(-time.Duration(a.CancelBeforeHours) * time.Hour)) {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST",
+				fmt.Sprintf("Bookings can only be cancelled up to %d hours before they start", a.CancelBeforeHours))
+		}
+	}
+
+	err = r.db.WithContext(ctx).Model(b).Updates(map[string]interface{}{
+		"status":        StatusCancelled,
+		"cancelled_at":  now,
+		"cancelled_by":  cancelledBy,
+		"cancel_reason": reason,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel booking: %v", err)
+	}
+
+	if b.Status == StatusConfirmed {
+		message := fmt.Sprintf("%s on %s has been cancelled.", b.ProductName, formatSlot(b.StartsAt))
+		if reason != "" {
+			message += " Reason: " + reason
+		}
+		if byProvider {
+			r.notifyCustomer(ctx, b, "Your booking was cancelled", message)
+		} else {
+			r.notifyProvider(ctx, b, "A booking was cancelled", message)
+		}
+	}
+	return r.GetBooking(ctx, id)
+}
+
+// RescheduleBooking moves a confirmed booking to another slot with room.
+// Customers are held to the service's reschedule notice and limit; providers
+// can move a booking any time before it starts.
+func (r *repository) RescheduleBooking(ctx context.Context, id uint32, startsAt time.Time, byProvider bool) (*Booking, error) {
+	b, err := r.GetBooking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch {
+	case b.Status != StatusConfirmed:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Only confirmed bookings can be rescheduled")
+	case !b.StartsAt.After(now):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This booking has already started")
+	case startsAt.Equal(b.StartsAt):
+		return b, nil
+	}
+
+	a, err := r.GetAvailability(ctx, b.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This service no longer takes bookings")
+	}
+	if !byProvider {
+		if b.Reschedules >= a.MaxReschedules {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This booking cannot be rescheduled again")
+		}
+		if now.After(b.StartsAt.Add(-time.Duration(a.RescheduleBeforeHours) * time.Hour)) {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST",
+				fmt.Sprintf("Bookings can only be rescheduled up to %d hours before they start", a.RescheduleBeforeHours))
+		}
+	}
+
+	previous := b.StartsAt
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockAvailability(tx, a); err != nil {
+			return err
+		}
+		slot, err := findSlot(tx, a, startsAt, b.Seats, b.ID)
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"starts_at":        slot.StartsAt,
+			"ends_at":          slot.EndsAt,
+			"reminder_sent_at": nil,
+		}
+		// Only the customer's own changes count towards their limit
+		if !byProvider {
+			updates["reschedules"] = gorm.Expr("reschedules + 1")
+		}
+		return tx.Model(b).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("%s has moved from %s to %s.", b.ProductName, formatSlot(previous), formatSlot(startsAt))
+	if byProvider {
+		r.notifyCustomer(ctx, b, "Your booking was rescheduled", message)
+	} else {
+		r.notifyProvider(ctx, b, "A booking was rescheduled", message)
+	}
+	return r.GetBooking(ctx, id)
+}
+
+// SendReminders reminds customers and providers of confirmed bookings starting
+// within ReminderLeadTime. Each booking is reminded once per time it is set for.
+func (r *repository) SendReminders(ctx context.Context) (int, error) {
+	now := time.Now()
+	var due []*Booking
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND reminder_sent_at IS NULL", StatusConfirmed).
+		Where("starts_at > ? AND starts_at <= ?", now, now.Add(ReminderLeadTime)).
+		Order("starts_at").
+		Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, b := range due {
+		// Claim the reminder first so overlapping runs never send it twice
+		result := r.db.WithContext(ctx).Model(&Booking{}).
+			Where("id = ? AND reminder_sent_at IS NULL", b.ID).
+			Update("reminder_sent_at", now)
+		if result.Error != nil {
+			return sent, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		when := formatSlot(b.StartsAt)
+		r.notifyCustomer(ctx, b, "Upcoming booking", fmt.Sprintf("Reminder: %s is booked for %s.", b.ProductName, when))
+		r.notifyProvider(ctx, b, "Upcoming booking", fmt.Sprintf("Reminder: %s has a booking for %s (%d place(s)).", b.ProductName, when, b.Seats))
+		sent++
+	}
+	return sent, nil
+}
+
+// ConfirmOrder confirms the bookings held for a paid order, in the same
+// transaction that marks the order paid, and returns them for
+// NotifyConfirmed once that commits. A payment arriving after its hold lapsed
+// still confirms the booking; the provider can reschedule it if the slot
+// filled up.
+func ConfirmOrder(ctx context.Context, tx *gorm.DB, orderUUID string) ([]*Booking, error) {
+	var held []*Booking
+	if err := tx.WithContext(ctx).Where("order_uuid = ? AND status = ?", orderUUID, StatusHeld).Find(&held).Error; err != nil {
+		return nil, err
+	}
+	if len(held) == 0 {
+		return held, nil
+	}
+	err := tx.WithContext(ctx).Model(&Booking{}).
+		Where("order_uuid = ? AND status = ?", orderUUID, StatusHeld).
+		Updates(map[string]interface{}{"status": StatusConfirmed, "hold_expires_at": nil}).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range held {
+		b.Status = StatusConfirmed
+		b.HoldExpiresAt = nil
+	}
+	return held, nil
+}
+
+// ReleaseOrder cancels the bookings held for an order whose checkout did not
+// go through, freeing their slots now rather than when the holds lapse.
+func ReleaseOrder(tx *gorm.DB, orderUUID string) error {
+	return tx.Model(&Booking{}).
+		Where("order_uuid = ? AND status = ?", orderUUID, StatusHeld).
+		Updates(map[string]interface{}{"status": StatusCancelled, "hold_expires_at": nil}).Error
+}
+
+// NotifyConfirmed lets the customer and provider of each confirmed booking
+// know.
+func NotifyConfirmed(ctx context.Context, db *gorm.DB, confirmed []*Booking) {
+	r := &repository{db: db}
+	for _, b := range confirmed {
+		when := formatSlot(b.StartsAt)
+		r.notifyCustomer(ctx, b, "Booking confirmed", fmt.Sprintf("%s is booked for %s.", b.ProductName, when))
+		r.notifyProvider(ctx, b, "New booking", fmt.Sprintf("%s was booked for %s (%d place(s)).", b.ProductName, when, b.Seats))
+	}
+}
+
+func formatSlot(t time.Time) string {
+	return t.In(Location).Format("Mon 2 Jan, 3:04 PM")
+}
+
+func (r *repository) notifyCustomer(ctx context.Context, b *Booking, title, message string) {
+	r.notify(ctx, b.UserID, b, title, message)
+}
+
+func (r *repository) notifyProvider(ctx context.Context, b *Booking, title, message string) {
+	var ownerID uint32
+	r.db.WithContext(ctx).Table("stores").Select("user_id").Where("name = ? AND deleted_at IS NULL", b.Store).Limit(1).Scan(&ownerID)
+	if ownerID == 0 {
+		return
+	}
+	r.notify(ctx, ownerID, b, title, message)
+}
+
+func (r *repository) notify(ctx context.Context, userID uint32, b *Booking, title, message string) {
+	notifier := notification.NewService(notification.NewRepository())
+	_, err := notifier.Notify(ctx, &notification.Notification{
+		UserID:  userID,
+		Type:    notification.TypeBooking,
+		Title:   title,
+		Message: message,
+		Link:    fmt.Sprintf("/bookings/%d", b.ID),
+	}, true)
+	if err != nil {
+		log.Printf("failed to notify user %d of booking %d: %v", userID, b.ID, err)
+	}
+}
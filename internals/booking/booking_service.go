@@ -0,0 +1,73 @@
+package booking
+
+import (
+	"context"
+	"time"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+func (s *service) GetAvailability(c context.Context, productId uint32) (*Availability, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetAvailability(ctx, productId)
+}
+
+func (s *service) SetAvailability(c context.Context, a *Availability) (*Availability, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.SetAvailability(ctx, a)
+}
+
+func (s *service) GetSlots(c context.Context, productId uint32, from, to time.Time) ([]*Slot, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetSlots(ctx, productId, from, to)
+}
+
+func (s *service) GetBooking(c context.Context, id uint32) (*Booking, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetBooking(ctx, id)
+}
+
+func (s *service) GetUserBookings(c context.Context, userId uint32, upcomingOnly bool) ([]*Booking, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetUserBookings(ctx, userId, upcomingOnly)
+}
+
+func (s *service) GetStoreBookings(c context.Context, storeName string, from, to time.Time) ([]*Booking, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreBookings(ctx, storeName, from, to)
+}
+
+func (s *service) CancelBooking(c context.Context, id uint32, byProvider bool, reason string) (*Booking, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.CancelBooking(ctx, id, byProvider, reason)
+}
+
+func (s *service) RescheduleBooking(c context.Context, id uint32, startsAt time.Time, byProvider bool) (*Booking, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.RescheduleBooking(ctx, id, startsAt, byProvider)
+}
+
+func (s *service) SendReminders(ctx context.Context) (int, error) {
+	// Every reminder is emailed to both sides, so this gets more time than a single write
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.SendReminders(ctx)
+}
@@ -0,0 +1,223 @@
+package booking
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Location is the campus time zone weekly hours are given in.
+var Location = loadLocation()
+
+func loadLocation() *time.Location {
+	if loc, err := time.LoadLocation("Africa/Lagos"); err == nil {
+		return loc
+	}
+	return time.FixedZone("WAT", 60*60)
+}
+
+// ClockOffset turns a time of day like "09:30" into the time since midnight.
+func ClockOffset(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Invalid time of day %q, use HH:MM", clock))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ValidateWeeklyHours checks that every window falls on a real weekday and
+// closes after it opens.
+func ValidateWeeklyHours(hours []WeeklyHours) error {
+	for _, h := range hours {
+		if h.Weekday < time.Sunday || h.Weekday > time.Saturday {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Weekdays run from 0 (Sunday) to 6 (Saturday)")
+		}
+		opens, err := ClockOffset(h.Opens)
+		if err != nil {
+			return err
+		}
+		closes, err := ClockOffset(h.Closes)
+		if err != nil {
+			return err
+		}
+		if closes <= opens {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s opens at %s but closes at %s", h.Weekday, h.Opens, h.Closes))
+		}
+	}
+	return nil
+}
+
+func (a *Availability) slotLength() time.Duration {
+	return time.Duration(a.SlotMinutes) * time.Minute
+}
+
+// window narrows [from, to) to the times that can be booked at now, given the
+// minimum notice and how far ahead bookings open.
+func (a *Availability) window(from, to, now time.Time) (time.Time, time.Time) {
+	if earliest := now.Add(time.Duration(a.MinNoticeHours) * time.Hour); from.Before(earliest) {
+		from = earliest
+	}
+	if a.MaxAdvanceDays > 0 {
+		if latest := now.AddDate(0, 0, a.MaxAdvanceDays); to.After(latest) {
+			to = latest
+		}
+	}
+	return from, to
+}
+
+// slots lists the slots starting in [from, to), ignoring what is booked.
+func (a *Availability) slots(from, to, now time.Time) []*Slot {
+	from, to = a.window(from, to, now)
+	length := a.slotLength()
+	if length <= 0 || !from.Before(to) {
+		return nil
+	}
+
+	var slots []*Slot
+	local := from.In(Location)
+	for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, Location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, h := range a.Hours {
+			if h.Weekday != day.Weekday() {
+				continue
+			}
+			opens, err := ClockOffset(h.Opens)
+			if err != nil {
+				continue
+			}
+			closes, err := ClockOffset(h.Closes)
+			if err != nil {
+				continue
+			}
+			for start := day.Add(opens); !start.Add(length).After(day.Add(closes)); start = start.Add(length) {
+				if start.Before(from) || !start.Before(to) {
+					continue
+				}
+				slots = append(slots, &Slot{StartsAt: start, EndsAt: start.Add(length), Capacity: a.Capacity})
+			}
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots
+}
+
+// activeBookings limits a query to bookings that take up seats: confirmed ones
+// and held ones whose hold has not run out.
+func activeBookings(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("status = ? OR (status = ? AND hold_expires_at > ?)", StatusConfirmed, StatusHeld, now)
+}
+
+// bookedSeats counts the seats taken in each slot of a product starting in
+// [from, to), keyed by the slot's Unix start time. excludeID leaves out a
+// booking being moved.
+func bookedSeats(db *gorm.DB, productID uint32, from, to, now time.Time, excludeID uint32) (map[int64]int, error) {
+	var rows []struct {
+		StartsAt time.Time
+		Seats    int
+	}
+	err := activeBookings(db.Model(&Booking{}), now).
+		Select("starts_at, SUM(seats) AS seats").
+		Where("product_id = ? AND starts_at >= ? AND starts_at < ? AND id <> ?", productID, from, to, excludeID).
+		Group("starts_at").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bookings: %v", err)
+	}
+	booked := make(map[int64]int, len(rows))
+	for _, row := range rows {
+		booked[row.StartsAt.Unix()] = row.Seats
+	}
+	return booked, nil
+}
+
+// openSlots lists the slots of a service starting in [from, to) with the seats
+// left in each.
+func openSlots(db *gorm.DB, a *Availability, from, to, now time.Time, excludeID uint32) ([]*Slot, error) {
+	slots := a.slots(from, to, now)
+	if len(slots) == 0 {
+		return []*Slot{}, nil
+	}
+	booked, err := bookedSeats(db, a.ProductID, slots[0].StartsAt, slots[len(slots)-1].EndsAt, now, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		slot.Available = max(slot.Capacity-booked[slot.StartsAt.Unix()], 0)
+	}
+	return slots, nil
+}
+
+// findSlot checks that startsAt is a slot of the service with room for seats.
+func findSlot(db *gorm.DB, a *Availability, startsAt time.Time, seats int, excludeID uint32) (*Slot, error) {
+	if seats < 1 {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Book at least one place")
+	}
+	slots, err := openSlots(db, a, startsAt, startsAt.Add(time.Second), time.Now(), excludeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		if !slot.StartsAt.Equal(startsAt) {
+			continue
+		}
+		if slot.Available == 0 {
+			return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "That time is fully booked, please pick another")
+		}
+		if slot.Available < seats {
+			return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("Only %d places left at that time", slot.Available))
+		}
+		return slot, nil
+	}
+	return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "That time cannot be booked, please pick one of the available slots")
+}
+
+// lockAvailability serialises bookings of a service for the rest of tx, so two
+// checkouts cannot both take the last seat of a slot.
+func lockAvailability(tx *gorm.DB, a *Availability) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&Availability{}, a.ID).Error
+}
+
+// LoadAvailability returns the booking setup of a product, or nil if it does
+// not take bookings.
+func LoadAvailability(db *gorm.DB, productID uint32) (*Availability, error) {
+	var a Availability
+	err := db.Where("product_id = ?", productID).First(&a).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CheckSlot reports whether seats can be booked at startsAt, without holding
+// them. Carts use it to reject a slot as soon as it is picked.
+func CheckSlot(db *gorm.DB, a *Availability, startsAt time.Time, seats int) error {
+	_, err := findSlot(db, a, startsAt, seats, 0)
+	return err
+}
+
+// Hold books b's seats for an unpaid checkout. The hold lapses after HoldTTL
+// unless the order is paid and ConfirmOrder is called.
+func Hold(tx *gorm.DB, a *Availability, b *Booking) error {
+	if err := lockAvailability(tx, a); err != nil {
+		return err
+	}
+	slot, err := findSlot(tx, a, b.StartsAt, b.Seats, 0)
+	if err != nil {
+		return err
+	}
+	expires := time.Now().Add(HoldTTL)
+	b.ID = 0
+	b.ProductID = a.ProductID
+	b.Store = a.Store
+	b.EndsAt = slot.EndsAt
+	b.Status = StatusHeld
+	b.HoldExpiresAt = &expires
+	return tx.Create(b).Error
+}
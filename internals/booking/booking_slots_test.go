@@ -0,0 +1,186 @@
+package booking
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return testdb.Open(t, &Booking{})
+}
+
+func TestConfirmOrderKeepsHoldPastTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	startsAt := now.Add(48 * time.Hour).Truncate(time.Hour)
+	lapsed := now.Add(HoldTTL + time.Minute)
+
+	tests := []struct {
+		name   string
+		paid   bool
+		booked int
+		status string
+	}{
+		{name: "paid order keeps its seats", paid: true, booked: 2, status: StatusConfirmed},
+		{name: "unpaid hold lapses", paid: false, booked: 0, status: StatusHeld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			expires := now.Add(HoldTTL)
+			held := &Booking{
+				ProductID:     7,
+				Store:         "campus-cuts",
+				UserID:        3,
+				OrderUUID:     "order-1",
+				Seats:         2,
+				StartsAt:      startsAt,
+				EndsAt:        startsAt.Add(time.Hour),
+				Status:        StatusHeld,
+				HoldExpiresAt: &expires,
+			}
+			if err := db.Create(held).Error; err != nil {
+				t.Fatalf("failed to create booking: %v", err)
+			}
+
+			if tt.paid {
+				err := db.Transaction(func(tx *gorm.DB) error {
+					confirmed, err := ConfirmOrder(ctx, tx, held.OrderUUID)
+					if err == nil && len(confirmed) != 1 {
+						t.Errorf("confirmed %d bookings, want 1", len(confirmed))
+					}
+					return err
+				})
+				if err != nil {
+					t.Fatalf("ConfirmOrder: %v", err)
+				}
+			}
+
+			booked, err := bookedSeats(db, held.ProductID, startsAt, startsAt.Add(time.Hour), lapsed, 0)
+			if err != nil {
+				t.Fatalf("bookedSeats: %v", err)
+			}
+			if got := booked[startsAt.Unix()]; got != tt.booked {
+				t.Errorf("seats booked after HoldTTL = %d, want %d", got, tt.booked)
+			}
+
+			var stored Booking
+			if err := db.First(&stored, held.ID).Error; err != nil {
+				t.Fatalf("failed to reload booking: %v", err)
+			}
+			if stored.Status != tt.status {
+				t.Errorf("status = %q, want %q", stored.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestAvailabilitySlots(t *testing.T) {
+	at := func(day int, clock string) time.Time {
+		offset, err := ClockOffset(clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2026, time.October, day, 0, 0, 0, 0, Location).Add(offset)
+	}
+	monday := []WeeklyHours{{Weekday: time.Monday, Opens: "09:00", Closes: "11:00"}}
+
+	tests := []struct {
+		name         string
+		availability Availability
+		from, to     time.Time
+		now          time.Time
+		want         []string
+	}{
+		{
+			name:         "hourly slots",
+			availability: Availability{Hours: monday, SlotMinutes: 60},
+			from:         at(19, "00:00"), to: at(20, "00:00"), now: at(18, "08:00"),
+			want: []string{"Mon 09:00", "Mon 10:00"},
+		},
+		{
+			name:         "last slot must end by closing",
+			availability: Availability{Hours: []WeeklyHours{{Weekday: time.Monday, Opens: "09:00", Closes: "11:30"}}, SlotMinutes: 60},
+			from:         at(19, "00:00"), to: at(20, "00:00"), now: at(18, "08:00"),
+			want: []string{"Mon 09:00", "Mon 10:00"},
+		},
+		{
+			name:         "minimum notice",
+			availability: Availability{Hours: monday, SlotMinutes: 60, MinNoticeHours: 1},
+			from:         at(19, "00:00"), to: at(20, "00:00"), now: at(19, "08:30"),
+			want: []string{"Mon 10:00"},
+		},
+		{
+			name:         "beyond advance booking",
+			availability: Availability{Hours: monday, SlotMinutes: 60, MaxAdvanceDays: 1},
+			from:         at(19, "00:00"), to: at(20, "00:00"), now: at(18, "08:00"),
+		},
+		{
+			name: "windows sorted across days",
+			availability: Availability{Hours: []WeeklyHours{
+				{Weekday: time.Tuesday, Opens: "08:00", Closes: "08:30"},
+				{Weekday: time.Monday, Opens: "14:00", Closes: "15:00"},
+				{Weekday: time.Monday, Opens: "09:00", Closes: "10:00"},
+			}, SlotMinutes: 30},
+			from: at(19, "00:00"), to: at(21, "00:00"), now: at(18, "08:00"),
+			want: []string{"Mon 09:00", "Mon 09:30", "Mon 14:00", "Mon 14:30", "Tue 08:00"},
+		},
+		{
+			name:         "range ends mid-day",
+			availability: Availability{Hours: monday, SlotMinutes: 30},
+			from:         at(19, "09:15"), to: at(19, "10:30"), now: at(18, "08:00"),
+			want: []string{"Mon 09:30", "Mon 10:00"},
+		},
+		{
+			name:         "no slot length",
+			availability: Availability{Hours: monday},
+			from:         at(19, "00:00"), to: at(20, "00:00"), now: at(18, "08:00"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, slot := range tt.availability.slots(tt.from, tt.to, tt.now) {
+				if slot.EndsAt.Sub(slot.StartsAt) != tt.availability.slotLength() {
+					t.Errorf("slot %v runs to %v", slot.StartsAt, slot.EndsAt)
+				}
+				got = append(got, slot.StartsAt.In(Location).Format("Mon 15:04"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("slots = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseOrderFreesHeldSeats(t *testing.T) {
+	db := openTestDB(t)
+	now := time.Now()
+	startsAt := now.Add(48 * time.Hour).Truncate(time.Hour)
+	expires := now.Add(HoldTTL)
+	bookings := []*Booking{
+		{ProductID: 7, Store: "campus-cuts", UserID: 3, OrderUUID: "order-1", Seats: 2, StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour), Status: StatusHeld, HoldExpiresAt: &expires},
+		{ProductID: 7, Store: "campus-cuts", UserID: 4, OrderUUID: "order-2", Seats: 1, StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour), Status: StatusHeld, HoldExpiresAt: &expires},
+	}
+	if err := db.Create(bookings).Error; err != nil {
+		t.Fatalf("failed to create bookings: %v", err)
+	}
+
+	if err := ReleaseOrder(db, "order-1"); err != nil {
+		t.Fatalf("ReleaseOrder: %v", err)
+	}
+
+	booked, err := bookedSeats(db, 7, startsAt, startsAt.Add(time.Hour), now, 0)
+	if err != nil {
+		t.Fatalf("bookedSeats: %v", err)
+	}
+	if got := booked[startsAt.Unix()]; got != 1 {
+		t.Errorf("seats booked = %d, want 1", got)
+	}
+}
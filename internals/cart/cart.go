@@ -3,6 +3,7 @@ package cart
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/samstringzz/alutamarket-backend/internals/product"
 	"github.com/samstringzz/alutamarket-backend/internals/store"
@@ -24,10 +25,14 @@ type Cart struct {
 }
 
 type CartItems struct {
-	Product  Product `gorm:"embedded"`
-	CartID   uint32  `json:"cart" db:"cart_id"`
-	Quantity int     `json:"quantity" db:"quantity"`
-	Fee      float64 `json:"fee" db:"fee"`
+	Product  Product              `gorm:"embedded"`
+	CartID   uint32               `json:"cart" db:"cart_id"`
+	Quantity int                  `json:"quantity" db:"quantity"`
+	Fee      float64              `json:"fee" db:"fee"`
+	Subtotal float64              `json:"subtotal" db:"subtotal"` // after the deal, if any
+	Deal     *product.AppliedDeal `json:"deal,omitempty" db:"deal"`
+	// Slot picked for a bookable service; Quantity is the places booked in it
+	SlotStart *time.Time `json:"slot_start,omitempty" db:"slot_start"`
 }
 
 type Repository interface {
@@ -36,6 +41,9 @@ type Repository interface {
 	GetCart(ctx context.Context, user uint32) (*Cart, error)
 	MakePayment(ctx context.Context, w http.ResponseWriter, r *http.Request)
 	InitiatePayment(ctx context.Context, req Order) (string, error)
+	RemoveProductFromCarts(ctx context.Context, productID uint32) (int, error)
+	RemoveStoreFromCarts(ctx context.Context, storeName string) (int, error)
+	CheckoutPolicies(ctx context.Context, userID uint32, choices map[string]string) ([]*PolicyCheck, error)
 }
 
 type Service interface {
@@ -44,5 +52,8 @@ type Service interface {
 	GetCart(ctx context.Context, user uint32) (*Cart, error)
 	MakePayment(ctx context.Context, w http.ResponseWriter, r *http.Request)
 	InitiatePayment(ctx context.Context, req Order) (string, error)
+	RemoveProductFromCarts(ctx context.Context, productID uint32) (int, error)
+	RemoveStoreFromCarts(ctx context.Context, storeName string) (int, error)
+	CheckoutPolicies(ctx context.Context, userID uint32, choices map[string]string) ([]*PolicyCheck, error)
 	GetProduct(ctx context.Context, productId uint32) (*product.Product, error)
 }
@@ -0,0 +1,86 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+)
+
+// RemoveProductFromCarts takes a deleted product out of every active cart.
+func (r *repository) RemoveProductFromCarts(ctx context.Context, productID uint32) (int, error) {
+	match := []map[string]interface{}{{"Product": map[string]interface{}{"id": productID}}}
+	return r.removeFromCarts(ctx, match, func(item *CartItems) bool {
+		return item.Product.ID == productID
+	})
+}
+
+// RemoveStoreFromCarts takes the products of a deleted store out of every
+// active cart.
+func (r *repository) RemoveStoreFromCarts(ctx context.Context, storeName string) (int, error) {
+	match := []map[string]interface{}{{"Product": map[string]interface{}{"store": storeName}}}
+	return r.removeFromCarts(ctx, match, func(item *CartItems) bool {
+		return item.Product.Store == storeName
+	})
+}
+
+// removeFromCarts drops the items picked by drop from the active carts whose
+// items contain match, hands their stock back and prices the carts again. It
+// returns how many carts changed.
+func (r *repository) removeFromCarts(ctx context.Context, match interface{}, drop func(*CartItems) bool) (int, error) {
+	filter, err := json.Marshal(match)
+	if err != nil {
+		return 0, err
+	}
+	var carts []*Cart
+	if err := r.db.WithContext(ctx).
+		Where("active = true AND items::jsonb @> ?::jsonb", string(filter)).
+		Find(&carts).Error; err != nil {
+		return 0, fmt.Errorf("failed to find carts: %v", err)
+	}
+
+	changed := 0
+	for _, cart := range carts {
+		kept := make([]*CartItems, 0, len(cart.Items))
+		stores := make([]*string, 0, len(cart.StoresID))
+		seen := map[string]bool{}
+		for _, item := range cart.Items {
+			if item.Product == nil {
+				continue
+			}
+			if !drop(item) {
+				kept = append(kept, item)
+				if !seen[item.Product.Store] {
+					seen[item.Product.Store] = true
+					store := item.Product.Store
+					stores = append(stores, &store)
+				}
+				continue
+			}
+			// Booked services never took stock
+			if item.SlotStart != nil {
+				continue
+			}
+			// The product is already in the trash, so stock goes back to it there
+			var p product.Product
+			if err := r.db.WithContext(ctx).Unscoped().Where("id = ?", item.Product.ID).First(&p).Error; err != nil {
+				continue
+			}
+			if err := product.AdjustStock(r.db.WithContext(ctx).Unscoped(), &p, item.Quantity); err != nil {
+				return changed, err
+			}
+		}
+
+		cart.Items = kept
+		cart.StoresID = stores
+		if cart.Total, err = priceCart(r.db.WithContext(ctx), cart.Items); err != nil {
+			return changed, err
+		}
+		if err := r.db.WithContext(ctx).Save(cart).Error; err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
@@ -67,3 +67,15 @@ func (h *Handler) IntitiatePayment(ctx context.Context, input Order) (string, er
 	}
 	return item, nil
 }
+
+func (h *Handler) RemoveProductFromCarts(ctx context.Context, productID uint32) (int, error) {
+	return h.Service.RemoveProductFromCarts(ctx, productID)
+}
+
+func (h *Handler) RemoveStoreFromCarts(ctx context.Context, storeName string) (int, error) {
+	return h.Service.RemoveStoreFromCarts(ctx, storeName)
+}
+
+func (h *Handler) CheckoutPolicies(ctx context.Context, userID uint32, choices map[string]string) ([]*PolicyCheck, error) {
+	return h.Service.CheckoutPolicies(ctx, userID, choices)
+}
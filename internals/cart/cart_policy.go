@@ -0,0 +1,99 @@
+package cart
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+// PolicyCheck is a store's part of a cart checked against the store's
+// fulfilment policy. Problem is empty if the store can take the order.
+type PolicyCheck struct {
+	Store    string             `json:"store"`
+	Policy   *store.StorePolicy `json:"policy"`
+	Method   string             `json:"method"`
+	Subtotal float64            `json:"subtotal"`
+	Problem  string             `json:"problem"`
+}
+
+// storeSubtotals totals each store's lines after discounts and deals.
+func storeSubtotals(items []*CartItems) map[string]float64 {
+	totals := map[string]float64{}
+	for _, item := range items {
+		line := (item.Product.Price - item.Product.Discount) * float64(item.Quantity)
+		if item.Deal != nil {
+			line -= item.Deal.Savings
+		}
+		totals[item.Product.Store] += line
+	}
+	return totals
+}
+
+// checkPolicies checks each store's subtotal and the buyer's fulfilment
+// choice for it against the store's policy. Stores without a choice get the
+// first method their policy offers.
+func (r *repository) checkPolicies(ctx context.Context, totals map[string]float64, choices map[string]string) ([]*PolicyCheck, error) {
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	storeRepo := store.NewRepository()
+	checks := make([]*PolicyCheck, 0, len(names))
+	for _, name := range names {
+		storeObj, err := storeRepo.GetStoreByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := storeRepo.GetStorePolicy(ctx, storeObj)
+		if err != nil {
+			return nil, err
+		}
+		method := choices[name]
+		if methods := policy.Methods(); method == "" && len(methods) > 0 {
+			method = methods[0]
+		}
+		checks = append(checks, &PolicyCheck{
+			Store:    name,
+			Policy:   policy,
+			Method:   method,
+			Subtotal: totals[name],
+			Problem:  policy.OrderProblem(name, method, totals[name]),
+		})
+	}
+	return checks, nil
+}
+
+// CheckoutPolicies checks the user's cart against the policies of the stores
+// in it, so problems show before the buyer pays.
+func (r *repository) CheckoutPolicies(ctx context.Context, userID uint32, choices map[string]string) ([]*PolicyCheck, error) {
+	cart, err := r.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := priceCart(r.db.WithContext(ctx), cart.Items); err != nil {
+		return nil, err
+	}
+	return r.checkPolicies(ctx, storeSubtotals(cart.Items), choices)
+}
+
+// enforcePolicies is checkPolicies failing on the first problem. It returns
+// the fulfilment method of each store.
+func (r *repository) enforcePolicies(ctx context.Context, totals map[string]float64, choices map[string]string) (map[string]string, error) {
+	checks, err := r.checkPolicies(ctx, totals, choices)
+	if err != nil {
+		return nil, err
+	}
+	methods := make(map[string]string, len(checks))
+	for _, check := range checks {
+		if check.Problem != "" {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", check.Problem)
+		}
+		methods[check.Store] = check.Method
+	}
+	return methods, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -16,6 +17,8 @@ import (
 	"github.com/lib/pq"
 	"github.com/samstringzz/alutamarket-backend/database"
 	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
 	"github.com/samstringzz/alutamarket-backend/internals/product"
 	"github.com/samstringzz/alutamarket-backend/internals/store"
 	"github.com/samstringzz/alutamarket-backend/internals/user"
@@ -49,16 +52,73 @@ func NewRepository() Repository {
 	}
 }
 
-func calculateTotalCartCost(data []*CartItems) float64 {
+// calculateTotalCartCost prices every cart line, applying the best multi-buy
+// deal or live sale of its product, and returns the cart total.
+func calculateTotalCartCost(data []*CartItems, pricing *product.Pricing) float64 {
 	var total float64
 
 	for _, item := range data {
 		quantity := item.Quantity
-		total += float64(quantity) * (item.Product.Price - item.Product.Discount)
+		unitPrice := item.Product.Price - item.Product.Discount
+		item.Deal = pricing.BestDeal(item.Product.ID, unitPrice, quantity)
+		item.Subtotal = float64(quantity) * unitPrice
+		if item.Deal != nil {
+			item.Subtotal -= item.Deal.Savings
+		}
+		total += item.Subtotal
 	}
 	return total
 }
 
+// priceCart prices a cart with its products as they are now in db, not as
+// they were when added, along with their current deals and sales. The lines
+// keep the refreshed prices, moderation status and deletion.
+func priceCart(db *gorm.DB, items []*CartItems) (float64, error) {
+	ids := make([]uint32, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.Product.ID)
+	}
+	current := map[uint32]*product.Product{}
+	if len(ids) > 0 {
+		var products []*product.Product
+		err := db.Unscoped().Select("id, price, discount, moderation_status, deleted_at").Where("id IN ?", ids).Find(&products).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to load cart products: %v", err)
+		}
+		for _, p := range products {
+			current[p.ID] = p
+		}
+	}
+	now := time.Now()
+	for _, item := range items {
+		p, ok := current[item.Product.ID]
+		if !ok {
+			// Purged products count as deleted
+			item.Product.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+			continue
+		}
+		item.Product.Price, item.Product.Discount = p.Price, p.Discount
+		item.Product.ModerationStatus, item.Product.DeletedAt = p.ModerationStatus, p.DeletedAt
+	}
+
+	pricing, err := product.LoadPricing(db, ids, now)
+	if err != nil {
+		return 0, err
+	}
+	return calculateTotalCartCost(items, pricing), nil
+}
+
+// unavailableItem returns the first line of a priced cart whose product has
+// been deleted or is not approved, or nil.
+func unavailableItem(items []*CartItems) *CartItems {
+	for _, item := range items {
+		if item.Product.DeletedAt.Valid || item.Product.ModerationStatus != product.ModerationApproved {
+			return item
+		}
+	}
+	return nil
+}
+
 // func (r *repository) ModifyCart(ctx context.Context, req *CartItems, user uint32) (*Cart, error) {
 // 	prd := &product.Product{}
 // 	var err2 error
@@ -144,19 +204,26 @@ func (r *repository) ModifyCart(ctx context.Context, req *CartItems, user uint32
 	prd := &product.Product{}
 	var err2 error
 
+	// Only approved products can be added, as only they are listed; ones
+	// taken down after being added can still be taken out of the cart
+	query := r.db.Model(&prd)
+	if req.Quantity > 0 {
+		query = query.Where("moderation_status = ?", product.ModerationApproved)
+	}
+
 	// Validate product information
 	if req.Product.ID == 0 && req.Product.Name == "" {
 		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "both id and name cannot be empty")
 	} else if req.Product.ID == 0 {
-		err2 = r.db.Model(&prd).
+		err2 = query.
 			Where("name = ?", req.Product.Name).
 			First(&prd).Error
 	} else if req.Product.Name == "" {
-		err2 = r.db.Model(&prd).
+		err2 = query.
 			Where("id = ?", req.Product.ID).
 			First(&prd).Error
 	} else {
-		err2 = r.db.Model(&prd).
+		err2 = query.
 			Where("name = ? OR id = ?", req.Product.Name, req.Product.ID).
 			First(&prd).Error
 	}
@@ -165,93 +232,170 @@ func (r *repository) ModifyCart(ctx context.Context, req *CartItems, user uint32
 		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
 	}
 
+	// Services are booked by the slot rather than taken from stock
+	availability, err := booking.LoadAvailability(r.db, prd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if availability != nil {
+		return r.modifyServiceItem(req, prd, availability, user)
+	}
+
 	// Ensure sufficient product quantity
 	newQuantity := prd.Quantity + (-req.Quantity)
 	if req.Quantity > prd.Quantity && !prd.AlwaysAvailbale {
 		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Product Quantity Exceeded")
 	}
 
-	// Update product quantity
-	r.db.Model(prd).Update("quantity", newQuantity)
-
-	// Fetch the user's cart
+	// The stock change and the cart are saved together, so a failed cart
+	// write or a refused change leaves the stock as it was
 	var cart *Cart
-	err := r.db.Where("user_id = ? AND active = ?", user, true).First(&cart).Error
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// Take the units out of stock; a bundle takes them from its components
+		if err := product.AdjustStock(tx, prd, -req.Quantity); err != nil {
+			return err
+		}
 
-	if err == nil {
-		// Cart exists, modify it
-		req.Product = prd
-		found := false
-		for i, item := range cart.Items {
-			if req.Product.ID == item.Product.ID {
-				if req.Quantity+item.Quantity == 0 {
-					// Remove the item from the cart when quantity becomes zero or negative
-					cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
-					for _, p := range cart.Items {
-						cart.StoresID = append(cart.StoresID, &p.Product.Store)
-					}
-					r.db.Model(prd).Update("quantity", prd.Quantity+item.Quantity)
-
-					// Check if store ID should be removed from StoresID
-					storeIDStillInCart := false
-					for _, remainingItem := range cart.Items {
-						if remainingItem.Product.Store == prd.Store {
-							storeIDStillInCart = true
-							break
+		// Fetch the user's cart
+		err := tx.Where("user_id = ? AND active = ?", user, true).First(&cart).Error
+
+		if err == nil {
+			// Cart exists, modify it
+			req.Product = prd
+			found := false
+			for i, item := range cart.Items {
+				if req.Product.ID == item.Product.ID {
+					if req.Quantity+item.Quantity == 0 {
+						// Remove the item from the cart when quantity becomes zero or negative
+						cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+						for _, p := range cart.Items {
+							cart.StoresID = append(cart.StoresID, &p.Product.Store)
 						}
+
+						// Check if store ID should be removed from StoresID
+						storeIDStillInCart := false
+						for _, remainingItem := range cart.Items {
+							if remainingItem.Product.Store == prd.Store {
+								storeIDStillInCart = true
+								break
+							}
+						}
+						if !storeIDStillInCart {
+							removeStoreIDFromCart(cart, prd.Store)
+						}
+					} else if req.Quantity+item.Quantity < 0 && !item.Product.AlwaysAvailbale {
+						return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Product Quantity Exceeded")
+					} else {
+						item.Quantity += req.Quantity
 					}
-					if !storeIDStillInCart {
-						removeStoreIDFromCart(cart, prd.Store)
-					}
-				} else if req.Quantity+item.Quantity < 0 && !item.Product.AlwaysAvailbale {
-					r.db.Model(prd).Update("quantity", prd.Quantity+req.Quantity)
-					return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Product Quantity Exceeded")
-				} else {
-					item.Quantity += req.Quantity
+					found = true
+					break
 				}
-				found = true
-				break
 			}
-		}
 
-		if !found && req.Quantity > 0 {
-			// Add the product to the cart if the quantity is positive
-			cart.Items = append(cart.Items, req)
+			if !found && req.Quantity > 0 {
+				// Add the product to the cart if the quantity is positive
+				cart.Items = append(cart.Items, req)
 
-			// Add store ID to StoresID if not already present
-			if !storeIDExistsInCart(cart, prd.Store) {
-				cart.StoresID = append(cart.StoresID, &prd.Store)
+				// Add store ID to StoresID if not already present
+				if !storeIDExistsInCart(cart, prd.Store) {
+					cart.StoresID = append(cart.StoresID, &prd.Store)
+				}
+			}
+		} else {
+			// Cart does not exist, create a new one
+			req.Product = prd
+			req.Product.Quantity = newQuantity
+			if req.Quantity > 0 {
+				cart.Items = append(cart.Items, req)
+
+				// Add store ID to StoresID
+				for _, p := range cart.Items {
+					cart.StoresID = append(cart.StoresID, &p.Product.Store)
+				}
 			}
+			cart.UserID = user
+			cart.Active = true
 		}
 
 		// Recalculate cart total
-		cart.Total = calculateTotalCartCost(cart.Items)
-		err = r.db.Save(&cart).Error
+		cart.Total, err = priceCart(tx, cart.Items)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		return cart, nil
-	} else {
-		// Cart does not exist, create a new one
-		req.Product = prd
-		req.Product.Quantity = newQuantity
-		if req.Quantity > 0 {
-			cart.Items = append(cart.Items, req)
-
-			// Add store ID to StoresID
-			for _, p := range cart.Items {
-				cart.StoresID = append(cart.StoresID, &p.Product.Store)
+		return tx.Save(&cart).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// modifyServiceItem adds, changes or removes a bookable service in the cart.
+// The item's quantity is the places booked in its slot; the slot is checked
+// here but only held once the buyer checks out.
+func (r *repository) modifyServiceItem(req *CartItems, prd *product.Product, availability *booking.Availability, user uint32) (*Cart, error) {
+	cart := &Cart{}
+	if err := r.db.Where("user_id = ? AND active = ?", user, true).First(cart).Error; err != nil {
+		cart = &Cart{UserID: user, Active: true}
+	}
+
+	var item *CartItems
+	index := -1
+	for i, existing := range cart.Items {
+		if existing.Product.ID == prd.ID {
+			item, index = existing, i
+			break
+		}
+	}
+	quantity := req.Quantity
+	if item != nil {
+		quantity += item.Quantity
+	}
+
+	if quantity <= 0 {
+		if index >= 0 {
+			cart.Items = append(cart.Items[:index], cart.Items[index+1:]...)
+		}
+		storeIDStillInCart := false
+		for _, remainingItem := range cart.Items {
+			if remainingItem.Product.Store == prd.Store {
+				storeIDStillInCart = true
+				break
 			}
 		}
-		cart.UserID = user
-		cart.Active = true
-		cart.Total += float64(req.Quantity) * (prd.Price - prd.Discount)
-		err = r.db.Save(&cart).Error
-		if err != nil {
+		if !storeIDStillInCart {
+			removeStoreIDFromCart(cart, prd.Store)
+		}
+	} else {
+		slot := req.SlotStart
+		if slot == nil && item != nil {
+			slot = item.SlotStart
+		}
+		if slot == nil {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Pick a time slot to book this service")
+		}
+		if err := booking.CheckSlot(r.db, availability, *slot, quantity); err != nil {
 			return nil, err
 		}
+		if item == nil {
+			item = &CartItems{}
+			cart.Items = append(cart.Items, item)
+			if !storeIDExistsInCart(cart, prd.Store) {
+				cart.StoresID = append(cart.StoresID, &prd.Store)
+			}
+		}
+		item.Product, item.Quantity, item.SlotStart = prd, quantity, slot
 	}
 
+	var err error
+	cart.Total, err = priceCart(r.db, cart.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.Save(cart).Error; err != nil {
+		return nil, err
+	}
 	return cart, nil
 }
 
@@ -304,12 +448,15 @@ func (r *repository) RemoveAllCart(ctx context.Context, id uint32) error {
 
 	// Iterate over the items in the cart and update the product quantities
 	for _, item := range cart.Items {
-		var product product.Product
-		if err := r.db.Where("id = ?", item.Product.ID).First(&product).Error; err != nil {
+		// Booked services never took stock
+		if item.SlotStart != nil {
+			continue
+		}
+		var p product.Product
+		if err := r.db.Where("id = ?", item.Product.ID).First(&p).Error; err != nil {
 			return err
 		}
-		product.Quantity += item.Quantity
-		if err := r.db.Save(&product).Error; err != nil {
+		if err := product.AdjustStock(r.db, &p, item.Quantity); err != nil {
 			return err
 		}
 	}
@@ -536,36 +683,63 @@ func (r *repository) InitiatePayment(ctx context.Context, input Order) (string,
 	}
 	// log.Printf("Customer Details: ID=%d, Name=%s, Email=%s\n", customer.ID, customer.PaymentDetails.Name, customer.Email)
 
-	// Payment gateway processing (this is I/O-bound, consider running it concurrently)
-	paymentLinkChan := make(chan string)
-	paymentErrChan := make(chan error)
-
-	// Convert input.Amount from string to float64 for calculations
-	amount, err := strconv.ParseFloat(input.Amount, 64)
+	// Deals and sales may have changed since the cart was last modified, so
+	// the buyer is charged what the cart costs now, never what the client
+	// sends. A quoted amount that no longer matches is turned back for the
+	// buyer to review.
+	total, err := priceCart(r.db.WithContext(ctx), cart.Items)
 	if err != nil {
-		return "", fmt.Errorf("invalid amount: %v", err)
+		return "", err
 	}
-
-	// Payment gateway processing
-	go func() {
-		paymentLink, err := processPaymentGateway(input.PaymentGateway, UUID, amount, redirectUrl, customer)
+	if item := unavailableItem(cart.Items); item != nil {
+		return "", errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("%s is no longer available; remove it from your cart", item.Product.Name))
+	}
+	if input.Amount != "" {
+		quoted, err := strconv.ParseFloat(input.Amount, 64)
 		if err != nil {
-			paymentErrChan <- err
-		} else {
-			paymentLinkChan <- paymentLink
+			return "", errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Invalid amount")
 		}
-	}()
+		if math.Abs(quoted-total) >= 0.01 {
+			return "", errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("Your cart now comes to %.2f; review it and try again", total))
+		}
+	}
 
-	// Handle concurrent payment processing
-	var paymentLink string
-	select {
-	case paymentLink = <-paymentLinkChan:
-	case err := <-paymentErrChan:
-		log.Println("Error received from payment processing:", err)
+	// Each seller's verification tier caps what their stores can sell in a month
+	storeTotals := storeSubtotals(cart.Items)
+	for storeName, total := range storeTotals {
+		if err := kyc.CheckSales(r.db.WithContext(ctx), storeName, total); err != nil {
+			return "", err
+		}
+	}
+
+	// Stores set how they fulfil orders and the least they take
+	fulfilment, err := r.enforcePolicies(ctx, storeTotals, input.FulfilmentChoices)
+	if err != nil {
 		return "", err
 	}
 
-	// log.Println("Payment link obtained:", paymentLink)
+	// Closed stores only take orders if they ship them once they reopen
+	storeRepo := store.NewRepository()
+	closedStores := map[string]*store.StoreAvailability{}
+	for storeName := range storeTotals {
+		storeObj, err := storeRepo.GetStoreByName(ctx, storeName)
+		if err != nil {
+			return "", err
+		}
+		availability, err := storeRepo.GetStoreAvailability(ctx, storeObj, time.Now())
+		if err != nil {
+			return "", err
+		}
+		if availability.Open {
+			continue
+		}
+		if !availability.AcceptsOrders {
+			return "", errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("%s is closed and not taking orders right now", storeName))
+		}
+		closedStores[storeName] = availability
+	}
+
+	amount := math.Round(total*100) / 100
 
 	// Convert total amount back to string for the order
 	totalAmountStr := strconv.FormatFloat(amount, 'f', 2, 64)
@@ -578,16 +752,13 @@ func (r *repository) InitiatePayment(ctx context.Context, input Order) (string,
 		}
 	}
 
-	// Convert amount string to float64 for DeliveryDetails
-	feeAmount, _ := strconv.ParseFloat(input.Amount, 64)
-
 	newOrder := &store.Order{
 		Amount:      totalAmountStr,
 		UserID:      strconv.FormatUint(uint64(cart.UserID), 10),
 		UUID:        UUID,
 		TransStatus: "not paid",
 		Status:      "not completed",
-		Fee:         input.Amount,
+		Fee:         totalAmountStr,
 		Coupon:      input.Coupon,
 		CartID:      cart.ID,
 		StoresID:    storeIDsArray, // Use converted array
@@ -605,7 +776,7 @@ func (r *repository) InitiatePayment(ctx context.Context, input Order) (string,
 		DeliveryDetails: &store.DeliveryDetails{ // Add & to create pointer
 			Method:  customer.PaymentDetails.Info,
 			Address: customer.PaymentDetails.Address,
-			Fee:     feeAmount, // Use converted float64 value
+			Fee:     amount,
 		},
 	}
 	// log.Printf("New order created: %+v\n", newOrder)
@@ -625,53 +796,78 @@ func (r *repository) InitiatePayment(ctx context.Context, input Order) (string,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
+		if item.Deal != nil {
+			product.DealLabel = item.Deal.Label
+			product.DealSavings = item.Deal.Savings
+			product.SaleItemID = item.Deal.SaleItemID
+			product.SaleUnits = item.Deal.SaleUnits
+		}
+		product.Fulfilment = fulfilment[item.Product.Store]
+		if closed, ok := closedStores[item.Product.Store]; ok && item.SlotStart == nil {
+			product.ShipsAfter = closed.ReopensAt
+		}
 		// newOrder.StoresID = append(newOrder.StoresID, &product.Store)
 		products = append(products, product)
 	}
 	newOrder.Products = products
 
-	// Mark the cart as inactive and process the order concurrently
-	if paymentLink != "" {
-		cart.Active = false
-		// Convert input.Amount to float64 before adding to cart.Total
-		amountFloat, _ := strconv.ParseFloat(input.Amount, 64)
-		cart.Total += amountFloat
-
-		errChan = make(chan error, 3)
-
-		// Save the cart concurrently
-		go func() {
-			log.Println("Saving cart...")
-			if err := r.db.Save(&cart).Error; err != nil {
-				errChan <- err
-				log.Println("Error saving cart:", err)
-			} else {
-				errChan <- nil
-				log.Println("Cart saved successfully.")
+	// The order and the holds on its booked slots are saved before the payment
+	// link is requested, so there is never a link without them
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for i, item := range cart.Items {
+			if item.SlotStart == nil {
+				continue
 			}
-		}()
-
-		// Save the order in the database concurrently
-		go func() {
-			// log.Println("Saving order to database...")
-			if err := r.db.Model(&store.Order{}).Save(newOrder).Error; err != nil {
-				errChan <- err
-				log.Println("Error saving order to database:", err)
-			} else {
-				errChan <- nil
-				log.Println("Order saved to database successfully.")
+			availability, err := booking.LoadAvailability(tx, item.Product.ID)
+			if err != nil {
+				return err
+			}
+			if availability == nil {
+				return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s no longer takes bookings", item.Product.Name))
+			}
+			held := &booking.Booking{
+				ProductName: item.Product.Name,
+				UserID:      cart.UserID,
+				OrderUUID:   UUID,
+				Seats:       item.Quantity,
+				StartsAt:    *item.SlotStart,
 			}
-		}()
+			if err := booking.Hold(tx, availability, held); err != nil {
+				return err
+			}
+			newOrder.Products[i].BookingID = held.ID
+			newOrder.Products[i].SlotStart = item.SlotStart
+		}
+		return tx.Model(&store.Order{}).Save(newOrder).Error
+	})
+	if err != nil {
+		return "", err
+	}
 
-		// Wait for all goroutines to finish and check for errors
-		for i := 0; i < 2; i++ {
-			if err := <-errChan; err != nil {
-				log.Println("Error encountered during final save steps:", err)
-				return "", err
+	paymentLink, err := processPaymentGateway(input.PaymentGateway, UUID, amount, redirectUrl, customer)
+	if err != nil || paymentLink == "" {
+		// The order can't be paid without a link, so it goes and its slots are freed
+		cleanup := r.db.Transaction(func(tx *gorm.DB) error {
+			if err := booking.ReleaseOrder(tx, UUID); err != nil {
+				return err
 			}
+			return tx.Unscoped().Where("uuid = ?", UUID).Delete(&store.Order{}).Error
+		})
+		if cleanup != nil {
+			log.Printf("Failed to drop order %s after its payment link failed: %v", UUID, cleanup)
+		}
+		if err != nil {
+			log.Println("Error received from payment processing:", err)
 		}
+		return "", err
 	}
 
+	cart.Active = false
+	cart.Total += amount
+	if err := r.db.Save(&cart).Error; err != nil {
+		log.Println("Error saving cart:", err)
+		return "", err
+	}
 	return paymentLink, nil
 }
 
@@ -703,14 +899,28 @@ func (r *repository) MakePayment(ctx context.Context, w http.ResponseWriter, req
 		}
 
 		// Update order status to paid and pending
-		err = r.db.Model(order).Updates(map[string]interface{}{
-			"status":       "pending",
-			"trans_status": "paid",
-		}).Error
+		wasPaid := order.TransStatus == "paid"
+		err = r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(order).Updates(map[string]interface{}{
+				"status":       "pending",
+				"trans_status": "paid",
+			}).Error; err != nil {
+				return err
+			}
+			if wasPaid {
+				return nil
+			}
+			return store.RecordSaleUnits(tx, order)
+		})
 		if err != nil {
 			fmt.Println("Error updating order status: ", err)
 			return
 		}
+		if confirmed, err := booking.ConfirmOrder(ctx, r.db, order.UUID); err != nil {
+			fmt.Println("Error confirming bookings: ", err)
+		} else {
+			booking.NotifyConfirmed(ctx, r.db, confirmed)
+		}
 
 		// Get the cart to process store credits
 		cart, _ := r.GetCart(ctx, order.CartID)
@@ -774,6 +984,11 @@ func (r *repository) MakePayment(ctx context.Context, w http.ResponseWriter, req
 				fmt.Println("Error Getting order: ", err)
 				return
 			}
+			if confirmed, err := booking.ConfirmOrder(ctx, r.db, order.UUID); err != nil {
+				fmt.Println("Error confirming bookings: ", err)
+			} else {
+				booking.NotifyConfirmed(ctx, r.db, confirmed)
+			}
 			cart, _ := r.GetCart(ctx, order.CartID)
 			for _, item := range cart.Items {
 				// Convert order.Fee from string to float64
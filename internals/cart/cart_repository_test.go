@@ -0,0 +1,114 @@
+package cart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+)
+
+func TestCalculateTotalCartCost(t *testing.T) {
+	pricing := &product.Pricing{
+		Rules: map[uint32][]*product.PricingRule{
+			1: {{ID: 10, ProductID: 1, Type: product.DealQuantityTier, MinQuantity: 3, UnitPrice: 800}},
+			2: {{ID: 20, ProductID: 2, Type: product.DealBuyXGetY, BuyQuantity: 2, FreeQuantity: 1}},
+			3: {{ID: 30, ProductID: 3, Type: product.DealQuantityTier, MinQuantity: 2, UnitPrice: 450}},
+		},
+		Sales: map[uint32]*product.ActiveSale{
+			3: {ItemID: 5, UnitPrice: 400, Remaining: 1},
+			4: {ItemID: 6, UnitPrice: 300, Remaining: -1},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		product   product.Product
+		quantity  int
+		subtotal  float64
+		hasDeal   bool
+		saleUnits int
+	}{
+		{name: "no deal", product: product.Product{ID: 9, Price: 1000}, quantity: 2, subtotal: 2000},
+		{name: "discount", product: product.Product{ID: 9, Price: 1000, Discount: 250}, quantity: 2, subtotal: 1500},
+		{name: "below tier", product: product.Product{ID: 1, Price: 1000}, quantity: 2, subtotal: 2000},
+		{name: "tier", product: product.Product{ID: 1, Price: 1000}, quantity: 3, subtotal: 2400, hasDeal: true},
+		{name: "buy two get one", product: product.Product{ID: 2, Price: 600}, quantity: 7, subtotal: 3000, hasDeal: true},
+		{name: "tier beats capped sale", product: product.Product{ID: 3, Price: 500}, quantity: 4, subtotal: 1800, hasDeal: true},
+		{name: "capped sale beats tier", product: product.Product{ID: 3, Price: 500}, quantity: 1, subtotal: 400, hasDeal: true, saleUnits: 1},
+		{name: "sale off discounted price", product: product.Product{ID: 4, Price: 600, Discount: 100}, quantity: 2, subtotal: 600, hasDeal: true, saleUnits: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &CartItems{Product: &tt.product, Quantity: tt.quantity}
+			total := calculateTotalCartCost([]*CartItems{item}, pricing)
+			if total != tt.subtotal || item.Subtotal != tt.subtotal {
+				t.Errorf("total = %v, subtotal = %v, want %v", total, item.Subtotal, tt.subtotal)
+			}
+			if (item.Deal != nil) != tt.hasDeal {
+				t.Fatalf("deal = %+v, want deal %v", item.Deal, tt.hasDeal)
+			}
+			if item.Deal != nil && item.Deal.SaleUnits != tt.saleUnits {
+				t.Errorf("sale units = %d, want %d", item.Deal.SaleUnits, tt.saleUnits)
+			}
+		})
+	}
+
+	t.Run("lines add up", func(t *testing.T) {
+		items := []*CartItems{
+			{Product: &product.Product{ID: 1, Price: 1000}, Quantity: 3},
+			{Product: &product.Product{ID: 9, Price: 250}, Quantity: 2},
+		}
+		if total := calculateTotalCartCost(items, pricing); total != 2900 {
+			t.Errorf("total = %v, want 2900", total)
+		}
+	})
+}
+
+func TestPriceCartUsesCurrentProducts(t *testing.T) {
+	db := testdb.Open(t, &product.Product{}, &product.PricingRule{}, &product.SaleCampaign{}, &product.SaleItem{})
+	now := time.Now()
+	products := []*product.Product{
+		{ID: 1, Name: "Repriced", Price: 1200, Discount: 200, ModerationStatus: product.ModerationApproved},
+		{ID: 2, Name: "On sale", Price: 1000, ModerationStatus: product.ModerationApproved},
+		{ID: 3, Name: "Taken down", Price: 500, ModerationStatus: product.ModerationRejected},
+	}
+	if err := db.Create(products).Error; err != nil {
+		t.Fatalf("failed to create products: %v", err)
+	}
+	campaign := &product.SaleCampaign{Name: "Week one", Store: "campus-cuts", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	if err := db.Create(campaign).Error; err != nil {
+		t.Fatalf("failed to create sale: %v", err)
+	}
+	if err := db.Create(&product.SaleItem{CampaignID: campaign.ID, ProductID: 2, PercentOff: 50}).Error; err != nil {
+		t.Fatalf("failed to create sale item: %v", err)
+	}
+
+	// The cart was filled before the prices changed
+	items := []*CartItems{
+		{Product: &product.Product{ID: 1, Name: "Repriced", Price: 800, ModerationStatus: product.ModerationApproved}, Quantity: 2},
+		{Product: &product.Product{ID: 2, Name: "On sale", Price: 400, ModerationStatus: product.ModerationApproved}, Quantity: 1},
+	}
+	total, err := priceCart(db, items)
+	if err != nil {
+		t.Fatalf("priceCart: %v", err)
+	}
+	if total != 2500 {
+		t.Errorf("total = %v, want 2500", total)
+	}
+	if item := unavailableItem(items); item != nil {
+		t.Errorf("unavailableItem() = %s, want none", item.Product.Name)
+	}
+
+	items = append(items, &CartItems{Product: &product.Product{ID: 3, Name: "Taken down", Price: 500, ModerationStatus: product.ModerationApproved}, Quantity: 1})
+	items = append(items, &CartItems{Product: &product.Product{ID: 4, Name: "Purged", Price: 500, ModerationStatus: product.ModerationApproved}, Quantity: 1})
+	if _, err := priceCart(db, items); err != nil {
+		t.Fatalf("priceCart: %v", err)
+	}
+	if item := unavailableItem(items); item == nil || item.Product.ID != 3 {
+		t.Errorf("unavailableItem() = %+v, want the taken down product", item)
+	}
+	if !items[3].Product.DeletedAt.Valid {
+		t.Error("a purged product was not marked deleted")
+	}
+}
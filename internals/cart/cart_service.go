@@ -69,3 +69,21 @@ func (s *service) InitiatePayment(c context.Context, input Order) (string, error
 	}
 	return r, nil
 }
+
+func (s *service) RemoveProductFromCarts(ctx context.Context, productID uint32) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.RemoveProductFromCarts(ctx, productID)
+}
+
+func (s *service) RemoveStoreFromCarts(ctx context.Context, storeName string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.RemoveStoreFromCarts(ctx, storeName)
+}
+
+func (s *service) CheckoutPolicies(ctx context.Context, userID uint32, choices map[string]string) ([]*PolicyCheck, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CheckoutPolicies(ctx, userID, choices)
+}
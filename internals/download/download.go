@@ -0,0 +1,92 @@
+package download
+
+import (
+	"context"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+// LinkTTL is how long a signed download link stays valid.
+const LinkTTL = 15 * time.Minute
+
+// Actions recorded in the download audit log
+const (
+	ActionIssued = "issued"
+	ActionServed = "served"
+	ActionDenied = "denied"
+)
+
+// Event is one entry of the download audit log.
+type Event struct {
+	ID         uint32    `json:"id" gorm:"primaryKey"`
+	DownloadID string    `json:"download_id" gorm:"index"`
+	UserID     string    `json:"user_id" gorm:"index"`
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (Event) TableName() string {
+	return "download_events"
+}
+
+// LinkUse records that a signed link was used, so the download it starts is
+// counted once however many requests it takes, like ranged requests resuming
+// it.
+type LinkUse struct {
+	ID         uint32    `json:"id" gorm:"primaryKey"`
+	DownloadID string    `json:"download_id" gorm:"not null;uniqueIndex:idx_download_link_uses_link"`
+	Signature  string    `json:"signature" gorm:"not null;uniqueIndex:idx_download_link_uses_link"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (LinkUse) TableName() string {
+	return "download_link_uses"
+}
+
+// Link is a signed, expiring URL for one purchased file.
+type Link struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Remaining int       `json:"remaining"`
+}
+
+// Entitlement is a user's right to a purchased file, checked against the paid order.
+type Entitlement struct {
+	Download   *store.Downloads
+	UserID     string
+	BuyerEmail string
+	Watermark  bool
+}
+
+// Remaining is how many more times the file can be downloaded.
+func (e *Entitlement) Remaining() int {
+	if n := e.Download.MaxDownloads - e.Download.DownloadCount; n > 0 {
+		return n
+	}
+	return 0
+}
+
+type Repository interface {
+	GetEntitlement(ctx context.Context, downloadID, userID string) (*Entitlement, error)
+	OwnsProductFile(ctx context.Context, productID uint32, userID string) (bool, error)
+	GetUserDownloads(ctx context.Context, userID string) ([]*store.Downloads, error)
+	ClaimDownload(ctx context.Context, downloadID, signature string) error
+	IsProtectedFile(ctx context.Context, name string) (bool, error)
+	LogEvent(ctx context.Context, event *Event) error
+	GetEvents(ctx context.Context, downloadID string) ([]*Event, error)
+}
+
+type Service interface {
+	IssueLink(ctx context.Context, downloadID, userID, ip, userAgent string) (*Link, error)
+	Authorize(ctx context.Context, downloadID, userID string, expires int64, signature string) (*Entitlement, error)
+	OwnsProductFile(ctx context.Context, productID uint32, userID string) (bool, error)
+	GetUserDownloads(ctx context.Context, userID string) ([]*store.Downloads, error)
+	ClaimDownload(ctx context.Context, downloadID, signature string) error
+	IsProtectedFile(ctx context.Context, name string) (bool, error)
+	LogEvent(ctx context.Context, event *Event) error
+	GetEvents(ctx context.Context, downloadID string) ([]*Event, error)
+}
@@ -0,0 +1,33 @@
+package download
+
+import (
+	"context"
+
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) IssueLink(ctx context.Context, downloadID, userID, ip, userAgent string) (*Link, error) {
+	return h.Service.IssueLink(ctx, downloadID, userID, ip, userAgent)
+}
+
+func (h *Handler) OwnsProductFile(ctx context.Context, productID uint32, userID string) (bool, error) {
+	return h.Service.OwnsProductFile(ctx, productID, userID)
+}
+
+func (h *Handler) GetUserDownloads(ctx context.Context, userID string) ([]*store.Downloads, error) {
+	return h.Service.GetUserDownloads(ctx, userID)
+}
+
+func (h *Handler) GetEvents(ctx context.Context, downloadID string) ([]*Event, error) {
+	return h.Service.GetEvents(ctx, downloadID)
+}
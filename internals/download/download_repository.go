@@ -0,0 +1,161 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// paidStatuses are the order trans_status values the payment webhooks set on success.
+var paidStatuses = []string{"success", "successful", "paid"}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// GetEntitlement loads a purchased file and checks that userID bought it through
+// an order that has been paid.
+func (r *repository) GetEntitlement(ctx context.Context, downloadID, userID string) (*Entitlement, error) {
+	var d store.Downloads
+	if err := r.db.WithContext(ctx).Where("id = ?", downloadID).First(&d).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Download not found")
+	}
+
+	owned := false
+	for _, u := range d.Users {
+		if u == userID {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "You have not purchased this file")
+	}
+
+	var paid int64
+	err := r.db.WithContext(ctx).Table("orders").
+		Where("uuid = ? AND user_id = ? AND trans_status IN ?", d.UUID, userID, paidStatuses).
+		Count(&paid).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check order payment: %v", err)
+	}
+	if paid == 0 {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "The order for this file has not been paid")
+	}
+
+	entitlement := &Entitlement{Download: &d, UserID: userID}
+	r.db.WithContext(ctx).Table("users").Select("email").Where("id = ?", userID).Scan(&entitlement.BuyerEmail)
+	if d.ProductID != 0 {
+		r.db.WithContext(ctx).Table("products").Select("watermark").Where("id = ?", d.ProductID).Scan(&entitlement.Watermark)
+	}
+	return entitlement, nil
+}
+
+// OwnsProductFile reports whether userID bought the file of a product through
+// an order that has been paid.
+func (r *repository) OwnsProductFile(ctx context.Context, productID uint32, userID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&store.Downloads{}).
+		Joins("JOIN orders ON orders.uuid = downloads.uuid").
+		Where("downloads.product_id = ? AND downloads.users LIKE ?", productID, fmt.Sprintf(`%%"%s"%%`, userID)).
+		Where("orders.user_id = ? AND orders.trans_status IN ?", userID, paidStatuses).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check purchase: %v", err)
+	}
+	return count > 0, nil
+}
+
+func (r *repository) GetUserDownloads(ctx context.Context, userID string) ([]*store.Downloads, error) {
+	var downloads []*store.Downloads
+	err := r.db.WithContext(ctx).
+		Where("users LIKE ?", fmt.Sprintf(`%%"%s"%%`, userID)).
+		Order("created_at DESC").
+		Find(&downloads).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get downloads: %v", err)
+	}
+	return downloads, nil
+}
+
+// ClaimDownload uses up one of a purchase's downloads the first time the
+// signed link with signature is used. Later requests with the same link are
+// part of that download. It fails once the limit is reached.
+func (r *repository) ClaimDownload(ctx context.Context, downloadID, signature string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		used := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&LinkUse{DownloadID: downloadID, Signature: signature})
+		if used.Error != nil {
+			return used.Error
+		}
+		if used.RowsAffected == 0 {
+			return nil
+		}
+		result := tx.Model(&store.Downloads{}).
+			Where("id = ? AND download_count < max_downloads", downloadID).
+			UpdateColumn("download_count", gorm.Expr("download_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "Download limit reached for this purchase")
+		}
+		return nil
+	})
+}
+
+// likeEscaper escapes the LIKE wildcards in a file name so it only matches
+// itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// IsProtectedFile reports whether an uploaded file is sold as a digital product
+// and so may only be fetched through a signed link.
+func (r *repository) IsProtectedFile(ctx context.Context, name string) (bool, error) {
+	pattern := "%/" + likeEscaper.Replace(name)
+	var count int64
+	err := r.db.WithContext(ctx).Table("products").
+		Where(`file = ? OR file LIKE ? ESCAPE '\'`, name, pattern).
+		Count(&count).Error
+	if err != nil {
+		return true, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	err = r.db.WithContext(ctx).Model(&store.Downloads{}).
+		Where(`file = ? OR file LIKE ? ESCAPE '\'`, name, pattern).
+		Count(&count).Error
+	if err != nil {
+		return true, err
+	}
+	return count > 0, nil
+}
+
+func (r *repository) LogEvent(ctx context.Context, event *Event) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *repository) GetEvents(ctx context.Context, downloadID string) ([]*Event, error) {
+	var events []*Event
+	err := r.db.WithContext(ctx).
+		Where("download_id = ?", downloadID).
+		Order("created_at DESC").
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download events: %v", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,124 @@
+package download
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+)
+
+func openTestRepository(t *testing.T) *repository {
+	t.Helper()
+	db := testdb.Open(t, &product.Product{}, &store.Downloads{}, &store.Order{}, &LinkUse{})
+	return &repository{db: db}
+}
+
+// create inserts test rows, failing the test if any of them cannot be saved.
+func create(t *testing.T, r *repository, rows ...interface{}) {
+	t.Helper()
+	for _, row := range rows {
+		if err := r.db.Create(row).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", row, err)
+		}
+	}
+}
+
+func TestIsProtectedFile(t *testing.T) {
+	r := openTestRepository(t)
+	create(t, r,
+		&product.Product{File: "https://api.example.com/download/course-notes.pdf"},
+		&store.Downloads{ID: "dl-1", File: "past-questions.zip"},
+	)
+
+	tests := []struct {
+		name string
+		file string
+		want bool
+	}{
+		{name: "product file", file: "course-notes.pdf", want: true},
+		{name: "download file", file: "past-questions.zip", want: true},
+		{name: "underscore is not a wildcard", file: "course_notes.pdf"},
+		{name: "percent is not a wildcard", file: "%.pdf"},
+		{name: "unrelated file", file: "avatar.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.IsProtectedFile(context.Background(), tt.file)
+			if err != nil {
+				t.Fatalf("IsProtectedFile: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsProtectedFile(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimDownloadOncePerLink(t *testing.T) {
+	ctx := context.Background()
+	r := openTestRepository(t)
+	create(t, r, &store.Downloads{ID: "dl-1", File: "notes.pdf", MaxDownloads: 2})
+
+	claims := []struct {
+		signature string
+		wantErr   bool
+	}{
+		{signature: "link-a"},
+		{signature: "link-a"}, // resuming the same download
+		{signature: "link-b"},
+		{signature: "link-b"},
+		{signature: "link-c", wantErr: true},
+	}
+	for i, c := range claims {
+		err := r.ClaimDownload(ctx, "dl-1", c.signature)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("claim %d with %s: err = %v, want error %v", i, c.signature, err, c.wantErr)
+		}
+	}
+
+	var count int
+	r.db.Raw(`SELECT download_count FROM downloads WHERE id = 'dl-1'`).Scan(&count)
+	if count != 2 {
+		t.Errorf("download_count = %d, want 2", count)
+	}
+	var uses int64
+	r.db.Model(&LinkUse{}).Where("signature = ?", "link-c").Count(&uses)
+	if uses != 0 {
+		t.Error("a link refused for the download limit was recorded as used")
+	}
+}
+
+func TestOwnsProductFile(t *testing.T) {
+	r := openTestRepository(t)
+	create(t, r,
+		&store.Downloads{ID: "dl-1", UUID: "order-1", ProductID: 7, Users: []string{"3"}},
+		&store.Downloads{ID: "dl-2", UUID: "order-2", ProductID: 8, Users: []string{"4"}},
+		&store.Order{UUID: "order-1", UserID: "3", TransStatus: "success"},
+		&store.Order{UUID: "order-2", UserID: "4", TransStatus: "pending"},
+	)
+
+	tests := []struct {
+		name      string
+		productID uint32
+		userID    string
+		want      bool
+	}{
+		{name: "paid buyer", productID: 7, userID: "3", want: true},
+		{name: "other user", productID: 7, userID: "33"},
+		{name: "other product", productID: 8, userID: "3"},
+		{name: "unpaid order", productID: 8, userID: "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.OwnsProductFile(context.Background(), tt.productID, tt.userID)
+			if err != nil {
+				t.Fatalf("OwnsProductFile: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OwnsProductFile(%d, %q) = %v, want %v", tt.productID, tt.userID, got, tt.want)
+			}
+		})
+	}
+}
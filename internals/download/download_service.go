@@ -0,0 +1,109 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+// IssueLink gives an entitled buyer a signed link to their file that expires
+// after LinkTTL. Every request is written to the audit log.
+func (s *service) IssueLink(c context.Context, downloadID, userID, ip, userAgent string) (*Link, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	event := &Event{DownloadID: downloadID, UserID: userID, Action: ActionIssued, IP: ip, UserAgent: userAgent}
+	entitlement, err := s.Repository.GetEntitlement(ctx, downloadID, userID)
+	if err == nil && entitlement.Remaining() == 0 {
+		err = errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "Download limit reached for this purchase")
+	}
+	if err != nil {
+		event.Action = ActionDenied
+		event.Reason = err.Error()
+		s.Repository.LogEvent(ctx, event)
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(LinkTTL)
+	linkURL, err := signedURL(downloadID, userID, expiresAt.Unix())
+	if err != nil {
+		event.Action = ActionDenied
+		event.Reason = err.Error()
+		s.Repository.LogEvent(ctx, event)
+		return nil, err
+	}
+	s.Repository.LogEvent(ctx, event)
+	return &Link{
+		URL:       linkURL,
+		ExpiresAt: expiresAt,
+		Remaining: entitlement.Remaining(),
+	}, nil
+}
+
+// Authorize checks a signed link and that the user is still entitled to the file.
+func (s *service) Authorize(c context.Context, downloadID, userID string, expires int64, signature string) (*Entitlement, error) {
+	if _, err := signingKey(); err != nil {
+		return nil, err
+	}
+	if !validSignature(downloadID, userID, expires, signature) {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "Invalid download link")
+	}
+	if time.Now().Unix() > expires {
+		return nil, errors.NewAppError(http.StatusGone, "GONE", "Download link has expired")
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetEntitlement(ctx, downloadID, userID)
+}
+
+func (s *service) OwnsProductFile(c context.Context, productID uint32, userID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.OwnsProductFile(ctx, productID, userID)
+}
+
+func (s *service) GetUserDownloads(c context.Context, userID string) ([]*store.Downloads, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetUserDownloads(ctx, userID)
+}
+
+func (s *service) ClaimDownload(c context.Context, downloadID, signature string) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.ClaimDownload(ctx, downloadID, signature)
+}
+
+func (s *service) IsProtectedFile(c context.Context, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.IsProtectedFile(ctx, name)
+}
+
+func (s *service) LogEvent(c context.Context, event *Event) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.LogEvent(ctx, event)
+}
+
+func (s *service) GetEvents(c context.Context, downloadID string) ([]*Event, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetEvents(ctx, downloadID)
+}
@@ -0,0 +1,100 @@
+package download
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	t.Setenv("DOWNLOAD_SIGNING_SECRET", "download-secret")
+	t.Setenv("SECRET_KEY", "")
+	const expires int64 = 1767225600
+
+	signature, err := sign("dl-1", "42", expires)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		downloadID string
+		userID     string
+		expires    int64
+		signature  string
+		want       bool
+	}{
+		{name: "valid", downloadID: "dl-1", userID: "42", expires: expires, signature: signature, want: true},
+		{name: "other download", downloadID: "dl-2", userID: "42", expires: expires, signature: signature},
+		{name: "other user", downloadID: "dl-1", userID: "43", expires: expires, signature: signature},
+		{name: "extended expiry", downloadID: "dl-1", userID: "42", expires: expires + 3600, signature: signature},
+		{name: "tampered signature", downloadID: "dl-1", userID: "42", expires: expires, signature: strings.Repeat("0", len(signature))},
+		{name: "empty signature", downloadID: "dl-1", userID: "42", expires: expires},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validSignature(tt.downloadID, tt.userID, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("validSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigningKeyFallback(t *testing.T) {
+	tests := []struct {
+		name         string
+		downloadKey  string
+		secretKey    string
+		wantErr      bool
+		sameAsSecret bool
+	}{
+		{name: "download secret", downloadKey: "download-secret", secretKey: "app-secret"},
+		{name: "falls back to SECRET_KEY", secretKey: "app-secret", sameAsSecret: true},
+		{name: "no secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DOWNLOAD_SIGNING_SECRET", tt.downloadKey)
+			t.Setenv("SECRET_KEY", tt.secretKey)
+			key, err := signingKey()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("signingKey() = %q, want an error", key)
+				}
+				if validSignature("dl-1", "42", 1, "") {
+					t.Error("validSignature() accepted a link with no signing key")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("signingKey: %v", err)
+			}
+			if got := string(key) == tt.secretKey; got != tt.sameAsSecret {
+				t.Errorf("signingKey() = %q, SECRET_KEY is %q", key, tt.secretKey)
+			}
+		})
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	t.Setenv("DOWNLOAD_SIGNING_SECRET", "download-secret")
+	t.Setenv("DOMAIN", "https://api.example.com/")
+
+	link, err := signedURL("dl 1", "42", 1767225600)
+	if err != nil {
+		t.Fatalf("signedURL: %v", err)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("signedURL() = %q is not a URL: %v", link, err)
+	}
+	if u.Path != "/downloads/dl 1" {
+		t.Errorf("path = %q, want /downloads/dl 1", u.Path)
+	}
+	query := u.Query()
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if !validSignature("dl 1", query.Get("user"), expires, query.Get("signature")) {
+		t.Errorf("signedURL() = %q does not verify", link)
+	}
+}
@@ -0,0 +1,60 @@
+package download
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Watermark stamps text across a JPEG or PNG file. Other file types cannot be
+// marked and are returned unchanged with ok set to false.
+func Watermark(data []byte, text string) (marked []byte, ok bool) {
+	contentType := http.DetectContentType(data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return data, false
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return data, false
+	}
+	bounds := src.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, src, bounds.Min, draw.Src)
+
+	face := basicfont.Face7x13
+	drawer := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(color.RGBA{128, 128, 128, 160}),
+		Face: face,
+	}
+	lineHeight := face.Metrics().Height.Ceil()
+	step := drawer.MeasureString(text).Ceil() + 40
+	// Repeat the text on every few lines, shifting each row so cropping one
+	// corner does not remove it.
+	for row, y := 0, bounds.Min.Y+lineHeight; y < bounds.Max.Y; row, y = row+1, y+lineHeight*6 {
+		for x := bounds.Min.X - (row%2)*step/2; x < bounds.Max.X; x += step {
+			drawer.Dot = fixed.P(x, y)
+			drawer.DrawString(text)
+		}
+	}
+
+	var buf bytes.Buffer
+	if contentType == "image/png" {
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		return data, false
+	}
+	return buf.Bytes(), true
+}
@@ -0,0 +1,116 @@
+package kyc
+
+import (
+	"context"
+	"time"
+)
+
+// Verification tiers, from least to most trusted
+const (
+	TierUnverified = "unverified"
+	TierPhone      = "phone_verified"
+	TierIdentity   = "id_verified"
+)
+
+// Limits caps what a seller can do while on a tier. Zero means no limit.
+type Limits struct {
+	MonthlySales     float64 `json:"monthly_sales"`     // paid sales across all the seller's stores this month
+	SingleWithdrawal float64 `json:"single_withdrawal"` // largest amount one withdrawal can take out
+	Listings         int     `json:"listings"`          // live products across all the seller's stores
+}
+
+// TierLimits holds the limits of every tier, in naira.
+var TierLimits = map[string]Limits{
+	TierUnverified: {MonthlySales: 50000, SingleWithdrawal: 10000, Listings: 10},
+	TierPhone:      {MonthlySales: 500000, SingleWithdrawal: 100000, Listings: 100},
+	TierIdentity:   {},
+}
+
+// ID document review states
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Accepted ID numbers. Both are 11 digits.
+const (
+	IDTypeBVN = "bvn"
+	IDTypeNIN = "nin"
+)
+
+// Verification is a user's verification tier and their latest ID submission.
+// Only the last four digits of the ID number are kept.
+type Verification struct {
+	ID              uint32     `json:"id" gorm:"primaryKey"`
+	UserID          uint32     `json:"user_id" gorm:"not null;uniqueIndex"`
+	Tier            string     `json:"tier" gorm:"not null;default:unverified"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	IDType          string     `json:"id_type"`
+	IDNumberLast4   string     `json:"id_number_last4"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	DocumentURL     string     `json:"document_url"`
+	Status          string     `json:"status" gorm:"index"` // empty until an ID is submitted
+	Provider        string     `json:"provider"`
+	ProviderRef     string     `json:"provider_ref"`
+	RejectionReason string     `json:"rejection_reason"`
+	SubmittedAt     *time.Time `json:"submitted_at"`
+	ReviewedBy      uint32     `json:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (Verification) TableName() string {
+	return "kyc_verifications"
+}
+
+// Status is where a seller stands against the limits of their tier.
+type Status struct {
+	Tier         string        `json:"tier"`
+	Limits       Limits        `json:"limits"`
+	MonthlySales float64       `json:"monthly_sales"`
+	Listings     int           `json:"listings"`
+	Verification *Verification `json:"verification"`
+}
+
+// IdentityCheck is what a seller submits to be ID verified.
+type IdentityCheck struct {
+	IDType      string `json:"id_type"`
+	IDNumber    string `json:"id_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	DateOfBirth string `json:"date_of_birth"` // YYYY-MM-DD
+	DocumentURL string `json:"document_url"`
+}
+
+// IdentityResult is a provider's answer to an identity check.
+type IdentityResult struct {
+	Matched   bool   `json:"matched"`
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"` // why the check did not match
+}
+
+// Provider looks up an ID number with a verification service and reports
+// whether the details submitted match its record.
+type Provider interface {
+	Name() string
+	VerifyIdentity(ctx context.Context, check *IdentityCheck) (*IdentityResult, error)
+}
+
+type Repository interface {
+	GetStatus(ctx context.Context, userID uint32) (*Status, error)
+	GetUserTier(ctx context.Context, userID uint32) (string, error)
+	SubmitIdentity(ctx context.Context, userID uint32, check *IdentityCheck) (*Verification, error)
+	GetReviewQueue(ctx context.Context, status string) ([]*Verification, error)
+	ReviewIdentity(ctx context.Context, id, adminID uint32, approve bool, reason string) (*Verification, error)
+}
+
+type Service interface {
+	GetStatus(ctx context.Context, userID uint32) (*Status, error)
+	GetUserTier(ctx context.Context, userID uint32) (string, error)
+	SubmitIdentity(ctx context.Context, userID uint32, check *IdentityCheck) (*Verification, error)
+	GetReviewQueue(ctx context.Context, status string) ([]*Verification, error)
+	ReviewIdentity(ctx context.Context, id, adminID uint32, approve bool, reason string) (*Verification, error)
+}
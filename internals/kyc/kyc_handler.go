@@ -0,0 +1,35 @@
+package kyc
+
+import (
+	"context"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) GetStatus(ctx context.Context, userID uint32) (*Status, error) {
+	return h.Service.GetStatus(ctx, userID)
+}
+
+func (h *Handler) GetUserTier(ctx context.Context, userID uint32) (string, error) {
+	return h.Service.GetUserTier(ctx, userID)
+}
+
+func (h *Handler) SubmitIdentity(ctx context.Context, userID uint32, check *IdentityCheck) (*Verification, error) {
+	return h.Service.SubmitIdentity(ctx, userID, check)
+}
+
+func (h *Handler) GetReviewQueue(ctx context.Context, status string) ([]*Verification, error) {
+	return h.Service.GetReviewQueue(ctx, status)
+}
+
+func (h *Handler) ReviewIdentity(ctx context.Context, id, adminID uint32, approve bool, reason string) (*Verification, error) {
+	return h.Service.ReviewIdentity(ctx, id, adminID, approve, reason)
+}
@@ -0,0 +1,169 @@
+package kyc
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TierOf returns the verification tier of a user.
+func TierOf(db *gorm.DB, userID uint32) (string, error) {
+	var tiers []string
+	if err := db.Model(&Verification{}).Where("user_id = ?", userID).Pluck("tier", &tiers).Error; err != nil {
+		return "", fmt.Errorf("failed to get verification tier: %v", err)
+	}
+	if len(tiers) == 0 {
+		return TierUnverified, nil
+	}
+	return tiers[0], nil
+}
+
+// MarkPhoneVerified moves a user up to the phone tier once their OTP checks
+// out. Users already ID verified keep their tier.
+func MarkPhoneVerified(db *gorm.DB, userID uint32) error {
+	now := time.Now()
+	v := &Verification{UserID: userID, Tier: TierPhone, PhoneVerifiedAt: &now}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"phone_verified_at": now,
+			"tier":              gorm.Expr("CASE WHEN kyc_verifications.tier = ? THEN ? ELSE kyc_verifications.tier END", TierUnverified, TierPhone),
+			"updated_at":        now,
+		}),
+	}).Create(v).Error
+}
+
+// WithdrawalLimit returns the largest amount one withdrawal from a store can
+// take out under its owner's tier, 0 meaning no limit, and the tier.
+func WithdrawalLimit(db *gorm.DB, storeID uint32) (float64, string, error) {
+	ownerID, err := ownerOf(db, "id = ?", storeID)
+	if err != nil {
+		return 0, "", err
+	}
+	tier, err := TierOf(db, ownerID)
+	if err != nil {
+		return 0, "", err
+	}
+	return TierLimits[tier].SingleWithdrawal, tier, nil
+}
+
+// CheckWithdrawal fails if a withdrawal from a store is larger than its
+// owner's tier allows.
+func CheckWithdrawal(db *gorm.DB, storeID uint32, amount float64) error {
+	limit, tier, err := WithdrawalLimit(db, storeID)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && amount > limit {
+		return errors.NewAppError(http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("Withdrawals are limited to ₦%.2f at a time until you verify your %s", limit, nextStep(tier)))
+	}
+	return nil
+}
+
+// CheckListing fails if the owner of a store already has as many live
+// products as their tier allows.
+func CheckListing(db *gorm.DB, storeName string) error {
+	ownerID, err := ownerOf(db, "name = ?", storeName)
+	if err != nil {
+		return err
+	}
+	tier, err := TierOf(db, ownerID)
+	if err != nil {
+		return err
+	}
+	limit := TierLimits[tier].Listings
+	if limit == 0 {
+		return nil
+	}
+	listings, err := countListings(db, ownerID)
+	if err != nil {
+		return err
+	}
+	if listings >= limit {
+		return errors.NewAppError(http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("You can list up to %d products until you verify your %s", limit, nextStep(tier)))
+	}
+	return nil
+}
+
+// CheckSales fails if taking an order worth amount would push the owner of a
+// store past their tier's monthly sales.
+func CheckSales(db *gorm.DB, storeName string, amount float64) error {
+	ownerID, err := ownerOf(db, "name = ?", storeName)
+	if err != nil {
+		return err
+	}
+	tier, err := TierOf(db, ownerID)
+	if err != nil {
+		return err
+	}
+	limit := TierLimits[tier].MonthlySales
+	if limit == 0 {
+		return nil
+	}
+	sales, err := monthlySales(db, ownerID)
+	if err != nil {
+		return err
+	}
+	if sales+amount > limit {
+		return errors.NewAppError(http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("%s can't take orders this large right now. Remove its items to check out the rest of your cart", storeName))
+	}
+	return nil
+}
+
+// nextStep names what a seller on a tier verifies to lift their limits.
+func nextStep(tier string) string {
+	if tier == TierUnverified {
+		return "phone number"
+	}
+	return "identity"
+}
+
+func ownerOf(db *gorm.DB, query string, arg interface{}) (uint32, error) {
+	var owners []uint32
+	if err := db.Table("stores").Where(query, arg).Where("deleted_at IS NULL").Limit(1).Pluck("user_id", &owners).Error; err != nil {
+		return 0, fmt.Errorf("failed to get store owner: %v", err)
+	}
+	if len(owners) == 0 {
+		return 0, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Store not found")
+	}
+	return owners[0], nil
+}
+
+// countListings counts the live products across all of a user's stores.
+func countListings(db *gorm.DB, userID uint32) (int, error) {
+	var count int64
+	err := db.Table("products").
+		Where("deleted_at IS NULL AND store IN (?)", db.Table("stores").Select("name").Where("user_id = ? AND deleted_at IS NULL", userID)).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count listings: %v", err)
+	}
+	return int(count), nil
+}
+
+// monthlySales sums the paid sales of all of a user's stores since the start
+// of the month. They are read from the orders rather than the daily store
+// aggregates, so orders paid since the last rollup count too. Lines are
+// valued as in the store analytics.
+func monthlySales(db *gorm.DB, userID uint32) (float64, error) {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	var total float64
+	err := db.Raw(`
+		SELECT COALESCE(SUM(
+			(COALESCE((l->>'price')::numeric, 0) - COALESCE((l->>'discount')::numeric, 0)) * COALESCE((l->>'quantity')::int, 0)
+				- COALESCE((l->>'deal_savings')::numeric, 0)), 0)
+		FROM orders o, jsonb_array_elements(o.products::jsonb) AS l
+		WHERE o.trans_status = 'paid' AND o.status <> 'canceled' AND o.deleted_at IS NULL
+			AND o.created_at >= ?
+			AND l->>'store' IN (SELECT name FROM stores WHERE user_id = ? AND deleted_at IS NULL)`,
+		monthStart, userID).Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get monthly sales: %v", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,42 @@
+package kyc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// NewProvider returns the identity provider named by KYC_PROVIDER. Only the
+// local stub exists so far; unknown names fall back to it.
+func NewProvider() Provider {
+	switch name := os.Getenv("KYC_PROVIDER"); name {
+	case "", "stub":
+		return &StubProvider{}
+	default:
+		log.Printf("Unknown KYC_PROVIDER %q, using the stub provider", name)
+		return &StubProvider{}
+	}
+}
+
+// StubProvider stands in for a real verification service in development. It
+// matches every check except ID numbers ending in 0000, which lets the
+// rejected path be tried out.
+type StubProvider struct{}
+
+func (p *StubProvider) Name() string {
+	return "stub"
+}
+
+func (p *StubProvider) VerifyIdentity(ctx context.Context, check *IdentityCheck) (*IdentityResult, error) {
+	result := &IdentityResult{
+		Matched:   true,
+		Reference: fmt.Sprintf("stub-%d", time.Now().UnixNano()),
+	}
+	if len(check.IDNumber) >= 4 && check.IDNumber[len(check.IDNumber)-4:] == "0000" {
+		result.Matched = false
+		result.Reason = "The details do not match the ID record"
+	}
+	return result, nil
+}
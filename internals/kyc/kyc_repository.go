@@ -0,0 +1,196 @@
+package kyc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db       *gorm.DB
+	provider Provider
+}
+
+func NewRepository() Repository {
+	return &repository{
+		db:       database.GetDB(),
+		provider: NewProvider(),
+	}
+}
+
+func (r *repository) GetStatus(ctx context.Context, userID uint32) (*Status, error) {
+	db := r.db.WithContext(ctx)
+	status := &Status{Tier: TierUnverified}
+
+	var v Verification
+	err := db.Where("user_id = ?", userID).First(&v).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get verification: %v", err)
+	}
+	if err == nil {
+		status.Tier = v.Tier
+		status.Verification = &v
+	}
+	status.Limits = TierLimits[status.Tier]
+
+	if status.MonthlySales, err = monthlySales(db, userID); err != nil {
+		return nil, err
+	}
+	if status.Listings, err = countListings(db, userID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (r *repository) GetUserTier(ctx context.Context, userID uint32) (string, error) {
+	return TierOf(r.db.WithContext(ctx), userID)
+}
+
+func validateIdentityCheck(check *IdentityCheck) error {
+	check.IDType = strings.ToLower(strings.TrimSpace(check.IDType))
+	check.IDNumber = strings.TrimSpace(check.IDNumber)
+	check.FirstName = strings.TrimSpace(check.FirstName)
+	check.LastName = strings.TrimSpace(check.LastName)
+	check.DocumentURL = strings.TrimSpace(check.DocumentURL)
+
+	if check.IDType != IDTypeBVN && check.IDType != IDTypeNIN {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "ID type must be bvn or nin")
+	}
+	if len(check.IDNumber) != 11 || strings.Trim(check.IDNumber, "0123456789") != "" {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "ID number must be 11 digits")
+	}
+	if check.FirstName == "" || check.LastName == "" {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "First and last name are required")
+	}
+	if check.DateOfBirth != "" {
+		if _, err := time.Parse("2006-01-02", check.DateOfBirth); err != nil {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Date of birth must be YYYY-MM-DD")
+		}
+	}
+	if check.DocumentURL == "" {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Upload a photo of your ID document")
+	}
+	return nil
+}
+
+// SubmitIdentity checks an ID number with the provider. Matches wait in the
+// review queue for an admin to look at the document; mismatches are rejected
+// straight away.
+func (r *repository) SubmitIdentity(ctx context.Context, userID uint32, check *IdentityCheck) (*Verification, error) {
+	if err := validateIdentityCheck(check); err != nil {
+		return nil, err
+	}
+
+	var v Verification
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&v).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get verification: %v", err)
+	}
+	if err == gorm.ErrRecordNotFound || v.Tier == TierUnverified {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "Verify your phone number before your identity")
+	}
+	if v.Tier == TierIdentity {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Your identity is already verified")
+	}
+	if v.Status == StatusPending {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Your ID is already waiting for review")
+	}
+
+	result, err := r.provider.VerifyIdentity(ctx, check)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify identity: %v", err)
+	}
+
+	now := time.Now()
+	v.IDType = check.IDType
+	v.IDNumberLast4 = check.IDNumber[len(check.IDNumber)-4:]
+	v.FirstName = check.FirstName
+	v.LastName = check.LastName
+	v.DocumentURL = check.DocumentURL
+	v.Provider = r.provider.Name()
+	v.ProviderRef = result.Reference
+	v.SubmittedAt = &now
+	v.ReviewedBy = 0
+	v.ReviewedAt = nil
+	v.Status = StatusPending
+	v.RejectionReason = ""
+	if !result.Matched {
+		v.Status = StatusRejected
+		v.RejectionReason = result.Reason
+		v.ReviewedAt = &now
+	}
+	if err := r.db.WithContext(ctx).Save(&v).Error; err != nil {
+		return nil, fmt.Errorf("failed to save verification: %v", err)
+	}
+	return &v, nil
+}
+
+func (r *repository) GetReviewQueue(ctx context.Context, status string) ([]*Verification, error) {
+	if status == "" {
+		status = StatusPending
+	}
+	var queue []*Verification
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("submitted_at ASC").Find(&queue).Error; err != nil {
+		return nil, fmt.Errorf("failed to get review queue: %v", err)
+	}
+	return queue, nil
+}
+
+// ReviewIdentity records an admin's decision on a pending ID submission and
+// lets the seller know.
+func (r *repository) ReviewIdentity(ctx context.Context, id, adminID uint32, approve bool, reason string) (*Verification, error) {
+	var v Verification
+	if err := r.db.WithContext(ctx).First(&v, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Verification not found")
+		}
+		return nil, err
+	}
+	if v.Status != StatusPending {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Only pending submissions can be reviewed")
+	}
+	reason = strings.TrimSpace(reason)
+	if !approve && reason == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Give a reason for rejecting the submission")
+	}
+
+	now := time.Now()
+	v.ReviewedBy = adminID
+	v.ReviewedAt = &now
+	if approve {
+		v.Status = StatusApproved
+		v.Tier = TierIdentity
+		v.RejectionReason = ""
+	} else {
+		v.Status = StatusRejected
+		v.RejectionReason = reason
+	}
+	if err := r.db.WithContext(ctx).Save(&v).Error; err != nil {
+		return nil, fmt.Errorf("failed to save verification: %v", err)
+	}
+
+	title, message := "Identity verified", "Your ID was approved. Your stores now show a verified badge and your selling limits are lifted."
+	if !approve {
+		title, message = "Identity verification rejected", fmt.Sprintf("Your ID was not approved: %s. You can submit it again.", reason)
+	}
+	notifier := notification.NewService(notification.NewRepository())
+	_, err := notifier.Notify(ctx, &notification.Notification{
+		UserID:  v.UserID,
+		Type:    notification.TypeVerification,
+		Title:   title,
+		Message: message,
+		Link:    "/account/verification",
+	}, true)
+	if err != nil {
+		log.Printf("failed to notify user %d of verification %d: %v", v.UserID, v.ID, err)
+	}
+	return &v, nil
+}
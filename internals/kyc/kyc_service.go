@@ -0,0 +1,48 @@
+package kyc
+
+import (
+	"context"
+	"time"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+func (s *service) GetStatus(c context.Context, userID uint32) (*Status, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetStatus(ctx, userID)
+}
+
+func (s *service) GetUserTier(c context.Context, userID uint32) (string, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetUserTier(ctx, userID)
+}
+
+func (s *service) SubmitIdentity(c context.Context, userID uint32, check *IdentityCheck) (*Verification, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.SubmitIdentity(ctx, userID, check)
+}
+
+func (s *service) GetReviewQueue(c context.Context, status string) ([]*Verification, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetReviewQueue(ctx, status)
+}
+
+func (s *service) ReviewIdentity(c context.Context, id, adminID uint32, approve bool, reason string) (*Verification, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.ReviewIdentity(ctx, id, adminID, approve, reason)
+}
@@ -0,0 +1,108 @@
+package media
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Owners an image can be attached to
+const (
+	OwnerProduct = "product"
+	OwnerStore   = "store"
+)
+
+// Roles decide which field of the owner an upload fills. Product images are
+// appended to the gallery; the other roles replace the current picture.
+const (
+	RoleImage      = "image"
+	RoleThumbnail  = "thumbnail"
+	RoleBackground = "background"
+)
+
+// ownerRoles lists the roles each owner type accepts.
+var ownerRoles = map[string][]string{
+	OwnerProduct: {RoleImage, RoleThumbnail},
+	OwnerStore:   {RoleThumbnail, RoleBackground},
+}
+
+// ValidRole reports whether an owner of the given type can have an image in role.
+func ValidRole(ownerType, role string) bool {
+	for _, r := range ownerRoles[ownerType] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Rendition sizes, by the length of the longest edge in pixels
+const (
+	SizeThumbnail = "thumbnail"
+	SizeMedium    = "medium"
+	SizeLarge     = "large"
+)
+
+// RenditionFormat is the format of every rendition and of the clean original.
+// WebP uploads are accepted, but there is no WebP encoder without cgo, so
+// nothing is served as WebP.
+const RenditionFormat = "jpeg"
+
+var renditionSizes = []struct {
+	Name    string
+	MaxEdge int
+}{
+	{SizeThumbnail, 200},
+	{SizeMedium, 600},
+	{SizeLarge, 1200},
+}
+
+// Image is an uploaded picture after validation and clean up. OriginalURL points
+// at a re-encoded copy of the upload with its metadata removed.
+type Image struct {
+	ID          uint32            `json:"id" gorm:"primaryKey"`
+	OwnerType   string            `json:"owner_type" gorm:"index:idx_images_owner"`
+	OwnerID     uint32            `json:"owner_id" gorm:"index:idx_images_owner"`
+	Role        string            `json:"role"`
+	UploadedBy  uint32            `json:"uploaded_by"`
+	MimeType    string            `json:"mime_type"` // type of the file as uploaded
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	OriginalURL string            `json:"original_url"`
+	Renditions  []*ImageRendition `json:"renditions" gorm:"foreignKey:ImageID"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DeletedAt   gorm.DeletedAt    `json:"deleted_at" gorm:"index"`
+}
+
+// ImageRendition is a resized copy of an image.
+type ImageRendition struct {
+	ID      uint32 `json:"id" gorm:"primaryKey"`
+	ImageID uint32 `json:"image_id" gorm:"index"`
+	Size    string `json:"size"`
+	Format  string `json:"format"`
+	URL     string `json:"url"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Bytes   int    `json:"bytes"`
+}
+
+// URL returns the address of the named rendition, or the original if there is none.
+func (i *Image) URL(size string) string {
+	for _, r := range i.Renditions {
+		if r.Size == size {
+			return r.URL
+		}
+	}
+	return i.OriginalURL
+}
+
+type Repository interface {
+	CreateImage(ctx context.Context, image *Image) (*Image, error)
+	GetImages(ctx context.Context, ownerType string, ownerID uint32) ([]*Image, error)
+}
+
+type Service interface {
+	UploadImage(ctx context.Context, image *Image, data []byte) (*Image, error)
+	GetImages(ctx context.Context, ownerType string, ownerID uint32) ([]*Image, error)
+}
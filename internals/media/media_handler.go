@@ -0,0 +1,23 @@
+package media
+
+import (
+	"context"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) UploadImage(ctx context.Context, image *Image, data []byte) (*Image, error) {
+	return h.Service.UploadImage(ctx, image, data)
+}
+
+func (h *Handler) GetImages(ctx context.Context, ownerType string, ownerID uint32) ([]*Image, error) {
+	return h.Service.GetImages(ctx, ownerType, ownerID)
+}
@@ -0,0 +1,276 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	stddraw "image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxUploadSize is the largest image file accepted
+	MaxUploadSize = 15 << 20
+	// Uploads must be at least MinDimension pixels on each side and at most
+	// MaxDimension. MaxPixels guards against small files that decode huge; a
+	// 24 MP upload takes about 100 MB once decoded.
+	MinDimension = 200
+	MaxDimension = 8000
+	MaxPixels    = 24_000_000
+
+	jpegQuality = 82
+	uploadDir   = "./uploads/"
+)
+
+// allowedTypes maps accepted upload MIME types to the format name used by image.Decode.
+// No WebP encoder is available without cgo, so every rendition is written as
+// RenditionFormat.
+var allowedTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// processed is an upload decoded, re-oriented and re-encoded without metadata.
+type processed struct {
+	mimeType   string
+	width      int
+	height     int
+	original   []byte
+	renditions []*processedRendition
+}
+
+type processedRendition struct {
+	size   string
+	width  int
+	height int
+	data   []byte
+}
+
+// processImage checks an upload's type and dimensions and produces a clean copy
+// of it plus the resized renditions. Re-encoding drops EXIF and other metadata,
+// so the camera orientation is applied to the pixels first.
+func processImage(data []byte) (*processed, error) {
+	if len(data) > MaxUploadSize {
+		return nil, fmt.Errorf("image is larger than %d MB", MaxUploadSize>>20)
+	}
+
+	mimeType := http.DetectContentType(data)
+	if _, ok := allowedTypes[mimeType]; !ok {
+		return nil, fmt.Errorf("unsupported image type %q, upload a JPEG, PNG or WebP image", mimeType)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %v", err)
+	}
+	if format != allowedTypes[mimeType] {
+		return nil, fmt.Errorf("image content does not match its type %q", mimeType)
+	}
+	if cfg.Width < MinDimension || cfg.Height < MinDimension {
+		return nil, fmt.Errorf("image must be at least %dx%d pixels", MinDimension, MinDimension)
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension {
+		return nil, fmt.Errorf("image must be at most %dx%d pixels", MaxDimension, MaxDimension)
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("image must be at most %d megapixels", MaxPixels/1_000_000)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %v", err)
+	}
+	img := flatten(decoded)
+	if mimeType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	bounds := img.Bounds()
+	result := &processed{
+		mimeType: mimeType,
+		width:    bounds.Dx(),
+		height:   bounds.Dy(),
+	}
+	if result.original, err = encodeJPEG(img); err != nil {
+		return nil, err
+	}
+
+	for _, size := range renditionSizes {
+		resized := resize(img, size.MaxEdge)
+		encoded, err := encodeJPEG(resized)
+		if err != nil {
+			return nil, err
+		}
+		result.renditions = append(result.renditions, &processedRendition{
+			size:   size.Name,
+			width:  resized.Bounds().Dx(),
+			height: resized.Bounds().Dy(),
+			data:   encoded,
+		})
+	}
+	return result, nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten draws img onto a white background so transparent PNG and WebP areas
+// do not turn black in JPEG. The copy is RGBA, which the steps after it work
+// on directly.
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	stddraw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, stddraw.Src)
+	stddraw.Draw(dst, dst.Bounds(), img, bounds.Min, stddraw.Over)
+	return dst
+}
+
+// resize scales img down so its longest edge is maxEdge. Smaller images are
+// returned as they are.
+func resize(img image.Image, maxEdge int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxEdge && h <= maxEdge {
+		return img
+	}
+	if w >= h {
+		h = h * maxEdge / w
+		w = maxEdge
+	} else {
+		w = w * maxEdge / h
+		h = maxEdge
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG file. It returns 1,
+// meaning no change, when the tag is missing or unreadable.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xDA || length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation turns img upright according to an EXIF orientation value,
+// moving whole pixels between the RGBA buffers.
+func applyOrientation(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	swap := orientation >= 5
+	dw, dh := w, h
+	if swap {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotate 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // flip vertically
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], row[x*4:x*4+4])
+		}
+	}
+	return dst
+}
+
+// saveFile writes data under the uploads directory and returns its download URL.
+func saveFile(name string, data []byte) (string, error) {
+	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(uploadDir, name), data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to save image: %v", err)
+	}
+	return strings.TrimRight(os.Getenv("DOMAIN"), "/") + "/download/" + name, nil
+}
+
+func newFileName(size string) string {
+	return fmt.Sprintf("%s-%s.jpg", uuid.New().String(), size)
+}
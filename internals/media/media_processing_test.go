@@ -0,0 +1,46 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestApplyOrientation(t *testing.T) {
+	// A 3x2 image whose pixels are numbered row by row:
+	//   0 1 2
+	//   3 4 5
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.Set(i%3, i/3, color.RGBA{R: uint8(i), A: 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8 // rows of the result
+	}{
+		{orientation: 1, want: [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{orientation: 2, want: [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{orientation: 3, want: [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{orientation: 4, want: [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{orientation: 5, want: [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{orientation: 6, want: [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{orientation: 7, want: [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{orientation: 8, want: [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+		{orientation: 9, want: [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+	}
+	for _, tt := range tests {
+		got := applyOrientation(src, tt.orientation)
+		if got.Bounds().Dx() != len(tt.want[0]) || got.Bounds().Dy() != len(tt.want) {
+			t.Errorf("orientation %d: size %v, want %dx%d", tt.orientation, got.Bounds().Size(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if c := got.RGBAAt(x, y); c.R != want || c.A != 255 {
+					t.Errorf("orientation %d: pixel (%d,%d) = %d, want %d", tt.orientation, x, y, c.R, want)
+				}
+			}
+		}
+	}
+}
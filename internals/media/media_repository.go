@@ -0,0 +1,96 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateImage saves an image with its renditions and points the owner's picture
+// fields at the new files: product images get the large rendition, thumbnails
+// the thumbnail one and store backgrounds the large one.
+func (r *repository) CreateImage(ctx context.Context, image *Image) (*Image, error) {
+	if image.OwnerType == OwnerProduct {
+		return r.createProductImage(ctx, image)
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+
+		var update *gorm.DB
+		switch {
+		case image.OwnerType == OwnerStore && image.Role == RoleThumbnail:
+			update = tx.Table("stores").Where("id = ?", image.OwnerID).Update("thumbnail", image.URL(SizeThumbnail))
+		case image.OwnerType == OwnerStore && image.Role == RoleBackground:
+			update = tx.Table("stores").Where("id = ?", image.OwnerID).Update("background", image.URL(SizeLarge))
+		default:
+			return fmt.Errorf("a %s cannot have a %s image", image.OwnerType, image.Role)
+		}
+		return update.Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+// createProductImage sets a product's picture through the product update, so
+// a new picture sends the product back for review like any other edit a
+// buyer would see.
+func (r *repository) createProductImage(ctx context.Context, image *Image) (*Image, error) {
+	products := product.NewRepository()
+	existing, err := products.GetProduct(ctx, image.OwnerID, 0)
+	if err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+
+	update := &product.NewProduct{ID: strconv.FormatUint(uint64(image.OwnerID), 10)}
+	switch image.Role {
+	case RoleImage:
+		update.Images = append(append([]string{}, existing.Images...), image.URL(SizeLarge))
+	case RoleThumbnail:
+		update.Thumbnail = image.URL(SizeThumbnail)
+	default:
+		return nil, fmt.Errorf("a %s cannot have a %s image", image.OwnerType, image.Role)
+	}
+
+	if err := r.db.WithContext(ctx).Create(image).Error; err != nil {
+		return nil, err
+	}
+	if _, err := products.UpdateProduct(ctx, update); err != nil {
+		r.db.WithContext(ctx).Select("Renditions").Delete(image)
+		return nil, err
+	}
+	return image, nil
+}
+
+func (r *repository) GetImages(ctx context.Context, ownerType string, ownerID uint32) ([]*Image, error) {
+	var images []*Image
+	err := r.db.WithContext(ctx).
+		Preload("Renditions").
+		Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at").
+		Find(&images).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images: %v", err)
+	}
+	return images, nil
+}
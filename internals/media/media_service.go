@@ -0,0 +1,88 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+// UploadImage validates and cleans an uploaded image, writes the clean original
+// and its renditions to storage and records them against the image's owner.
+// Whatever the upload's type, the files written are JPEG (see RenditionFormat).
+func (s *service) UploadImage(c context.Context, image *Image, data []byte) (*Image, error) {
+	if !ValidRole(image.OwnerType, image.Role) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A %s cannot have a %s image", image.OwnerType, image.Role))
+	}
+
+	result, err := processImage(data)
+	if err != nil {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", err.Error())
+	}
+
+	var saved []string
+	cleanup := func() {
+		for _, name := range saved {
+			os.Remove(filepath.Join(uploadDir, name))
+		}
+	}
+
+	name := newFileName("original")
+	if image.OriginalURL, err = saveFile(name, result.original); err != nil {
+		return nil, err
+	}
+	saved = append(saved, name)
+
+	image.MimeType = result.mimeType
+	image.Width = result.width
+	image.Height = result.height
+	image.Renditions = nil
+	for _, rendition := range result.renditions {
+		name := newFileName(rendition.size)
+		url, err := saveFile(name, rendition.data)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		saved = append(saved, name)
+		image.Renditions = append(image.Renditions, &ImageRendition{
+			Size:   rendition.size,
+			Format: RenditionFormat,
+			URL:    url,
+			Width:  rendition.width,
+			Height: rendition.height,
+			Bytes:  len(rendition.data),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	created, err := s.Repository.CreateImage(ctx, image)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *service) GetImages(c context.Context, ownerType string, ownerID uint32) ([]*Image, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetImages(ctx, ownerType, ownerID)
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"context"
+	"time"
+)
+
+// Notification types
+const (
+	TypeProductModeration = "product_moderation"
+	TypePriceDrop         = "price_drop"
+	TypeBackInStock       = "back_in_stock"
+	TypeProductQuestion   = "product_question"
+	TypeBooking           = "booking"
+	TypeStoreInvite       = "store_invite"
+	TypeVerification      = "verification"
+	TypeStoreReopened     = "store_reopened"
+	TypeStorePromo        = "store_promo"
+	TypeOrderDispute      = "order_dispute"
+)
+
+// Notification is an in-app message for a user. Notifications sent with email
+// are also mailed to the user's address.
+type Notification struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	UserID    uint32    `json:"user_id" gorm:"index"`
+	Type      string    `json:"type" gorm:"index"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Link      string    `json:"link"`
+	Read      bool      `json:"read" gorm:"default:false"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+type Repository interface {
+	CreateNotification(ctx context.Context, n *Notification) (*Notification, error)
+	GetNotifications(ctx context.Context, userID uint32, unreadOnly bool, limit int) ([]*Notification, error)
+	MarkRead(ctx context.Context, userID, id uint32) error
+	GetRecipient(ctx context.Context, userID uint32) (name string, email string, err error)
+}
+
+type Service interface {
+	Notify(ctx context.Context, n *Notification, email bool) (*Notification, error)
+	GetNotifications(ctx context.Context, userID uint32, unreadOnly bool, limit int) ([]*Notification, error)
+	MarkRead(ctx context.Context, userID, id uint32) error
+}
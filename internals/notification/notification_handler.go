@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"context"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) Notify(ctx context.Context, n *Notification, email bool) (*Notification, error) {
+	return h.Service.Notify(ctx, n, email)
+}
+
+func (h *Handler) GetNotifications(ctx context.Context, userID uint32, unreadOnly bool, limit int) ([]*Notification, error) {
+	return h.Service.GetNotifications(ctx, userID, unreadOnly, limit)
+}
+
+func (h *Handler) MarkRead(ctx context.Context, userID, id uint32) error {
+	return h.Service.MarkRead(ctx, userID, id)
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+func (r *repository) CreateNotification(ctx context.Context, n *Notification) (*Notification, error) {
+	if err := r.db.WithContext(ctx).Create(n).Error; err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (r *repository) GetNotifications(ctx context.Context, userID uint32, unreadOnly bool, limit int) ([]*Notification, error) {
+	var notifications []*Notification
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read = ?", false)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *repository) MarkRead(ctx context.Context, userID, id uint32) error {
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Notification not found")
+	}
+	return nil
+}
+
+func (r *repository) GetRecipient(ctx context.Context, userID uint32) (string, string, error) {
+	var recipient struct {
+		Fullname string
+		Email    string
+	}
+	err := r.db.WithContext(ctx).Table("users").
+		Select("fullname, email").
+		Where("id = ?", userID).
+		Take(&recipient).Error
+	if err != nil {
+		return "", "", err
+	}
+	return recipient.Fullname, recipient.Email, nil
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+// Notify stores an in-app notification and, when email is set, mails it to the
+// user. A failed email is logged but does not fail the notification.
+func (s *service) Notify(c context.Context, n *Notification, email bool) (*Notification, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+
+	created, err := s.Repository.CreateNotification(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	if !email {
+		return created, nil
+	}
+
+	name, address, err := s.Repository.GetRecipient(ctx, n.UserID)
+	if err != nil || address == "" {
+		log.Printf("notification %d: no email recipient for user %d: %v", created.ID, n.UserID, err)
+		return created, nil
+	}
+	contents := map[string]string{
+		"name":    name,
+		"title":   n.Title,
+		"message": n.Message,
+		"link":    n.Link,
+	}
+	if err := utils.SendEmail(os.Getenv("ONE_SIGNAL_NOTIFICATION_TEMPLATE_ID"), n.Title, []string{address}, contents); err != nil {
+		log.Printf("notification %d: failed to send email: %v", created.ID, err)
+	}
+	return created, nil
+}
+
+func (s *service) GetNotifications(c context.Context, userID uint32, unreadOnly bool, limit int) ([]*Notification, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetNotifications(ctx, userID, unreadOnly, limit)
+}
+
+func (s *service) MarkRead(c context.Context, userID, id uint32) error {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.MarkRead(ctx, userID, id)
+}
@@ -21,15 +21,25 @@ type AdsGen struct {
 	Units    uint8     `json:"units" db:"units"`
 	Validity time.Time `json:"validity" db:"validity"`
 }
+
+// Category is a node of the category tree. Products link to the node that fits
+// them best, at any depth, and inherit the attribute schema of its ancestors.
 type Category struct {
 	gorm.Model
-	ID            int           `json:"id" db:"id"`
-	Name          string        `json:"name" db:"name"`
-	Slug          string        `json:"slug" db:"slug"`
-	Type          string        `json:"type" db:"type"`
-	SubCategories []SubCategory `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE;" json:"subcategories"`
+	ID            int                 `json:"id" db:"id"`
+	Name          string              `json:"name" db:"name"`
+	Slug          string              `json:"slug" db:"slug"`
+	Type          string              `json:"type" db:"type"`
+	ParentID      *uint32             `json:"parent_id" db:"parent_id" gorm:"index"`
+	Path          string              `json:"path" db:"path"` // IDs from the root down to this node, e.g. "1/4/9"
+	Depth         int                 `json:"depth" db:"depth"`
+	Attributes    []CategoryAttribute `json:"attributes" gorm:"-"` // own and inherited attributes
+	Children      []*Category         `json:"children" gorm:"-"`
+	SubCategories []SubCategory       `json:"subcategories" gorm:"-"` // direct children, for older clients
 }
 
+// SubCategory is the old second level of categories. Subcategories are now
+// child categories and this type only describes them to older clients.
 type SubCategory struct {
 	gorm.Model
 	Name       string     `json:"name"`
@@ -40,16 +50,6 @@ type SubCategory struct {
 
 type Order struct{}
 
-type Review struct {
-	gorm.Model
-	ID        string  `json:"id" db:"id"`
-	Username  string  `json:"username" db:"username"`
-	Image     string  `json:"image" db:"image"`
-	Message   string  `json:"message" db:"message"`
-	Seller    string  `json:"seller" db:"seller"`
-	Rating    float64 `json:"rating" db:"rating"`
-	ProductID uint32  `json:"product" db:"product_id"`
-}
 type VariantValue struct {
 	Value  string   `json:"value" db:"value"`
 	Price  float64  `json:"price,omitempty" db:"price"`
@@ -63,23 +63,25 @@ type VariantType struct {
 
 type NewProduct struct {
 	gorm.Model
-	ID              string         `json:"id" db:"id"`
-	Name            string         `json:"name" db:"name"`
-	Description     string         `json:"description" db:"description"`
-	Images          []string       `gorm:"serializer:json" json:"image" db:"image"`
-	Thumbnail       string         `json:"thumbnail" db:"thumbnail"`
-	Price           float64        `json:"price" db:"price"`
-	Discount        float64        `json:"discount" db:"discount"`
-	Status          *bool          `json:"status" db:"status"`
-	Quantity        int            `json:"quantity" db:"quantity"`
-	File            string         `json:"file" db:"file"`
-	Slug            string         `json:"slug" db:"slug"`
-	Variant         []*VariantType `gorm:"serializer:json" json:"variant,omitempty" db:"variant"`
-	Store           string         `json:"store" db:"store"`
-	CategoryID      uint8          `json:"category" db:"category_id"`
-	SubCategoryID   uint8          `json:"subcategory" db:"subcategory_id"`
-	SubCategoryName string         `json:"subcategory_name" db:"sub_category_name"`
-	AlwaysAvailbale bool           `json:"always_available" db:"always_available"`
+	ID              string            `json:"id" db:"id"`
+	Name            string            `json:"name" db:"name"`
+	Description     string            `json:"description" db:"description"`
+	Images          []string          `gorm:"serializer:json" json:"image" db:"image"`
+	Thumbnail       string            `json:"thumbnail" db:"thumbnail"`
+	Price           float64           `json:"price" db:"price"`
+	Discount        float64           `json:"discount" db:"discount"`
+	Status          *bool             `json:"status" db:"status"`
+	Quantity        int               `json:"quantity" db:"quantity"`
+	File            string            `json:"file" db:"file"`
+	Slug            string            `json:"slug" db:"slug"`
+	Variant         []*VariantType    `gorm:"serializer:json" json:"variant,omitempty" db:"variant"`
+	Store           string            `json:"store" db:"store"`
+	CategoryID      uint32            `json:"category" db:"category_id"`
+	SubCategoryID   uint32            `json:"subcategory" db:"subcategory_id"`
+	SubCategoryName string            `json:"subcategory_name" db:"sub_category_name"`
+	AlwaysAvailbale bool              `json:"always_available" db:"always_available"`
+	SKU             string            `json:"sku" db:"sku"`
+	Attributes      map[string]string `json:"attributes" db:"attributes"` // nil leaves an existing product's attributes as they are
 }
 
 type UpdateStore struct {
@@ -97,8 +99,8 @@ type UpdateStore struct {
 	Slug            string         `json:"slug" db:"slug"`
 	Variant         []*VariantType `gorm:"serializer:json" json:"variant,omitempty" db:"variant"`
 	Store           string         `json:"store" db:"store"`
-	CategoryID      uint8          `json:"category" db:"category_id"`
-	SubCategoryID   uint8          `json:"subcategory" db:"sub_category_id"`
+	CategoryID      uint32         `json:"category" db:"category_id"`
+	SubCategoryID   uint32         `json:"subcategory" db:"sub_category_id"`
 	AlwaysAvailbale bool           `json:"always_available" db:"always_available"`
 }
 
@@ -157,29 +159,146 @@ func (a Uint32Array) Value() (driver.Value, error) {
 
 // Update the Product struct
 type Product struct {
-	ID              uint32         `json:"id" gorm:"primaryKey"`
-	Name            string         `json:"name"`
-	Slug            string         `json:"slug"`
-	Description     string         `json:"description"`
-	Images          pq.StringArray `json:"images" gorm:"type:text[]"`
-	Thumbnail       string         `json:"thumbnail"`
-	Price           float64        `json:"price"`
-	Discount        float64        `json:"discount"`
-	Status          bool           `json:"status"`
-	AlwaysAvailbale bool           `json:"always_available"`
-	Quantity        int            `json:"quantity"`
-	File            string         `json:"file"`
-	Store           string         `json:"store"`
-	Category        string         `json:"category"`
-	Subcategory     string         `json:"subcategory"`
-	Type            string         `json:"type"`
-	UnitsSold       int            `json:"units_sold"`
-	Variant         []*VariantType `json:"variant" gorm:"type:jsonb;serializer:json"`
-	Views           Uint32Array    `json:"views" gorm:"type:integer[]"`
-	Reviews         []Review       `json:"reviews" gorm:"type:jsonb;serializer:json"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID               uint32            `json:"id" gorm:"primaryKey"`
+	Name             string            `json:"name"`
+	Slug             string            `json:"slug"`
+	SKU              string            `json:"sku" gorm:"index"`
+	Description      string            `json:"description"`
+	Images           pq.StringArray    `json:"images" gorm:"type:text[]"`
+	Thumbnail        string            `json:"thumbnail"`
+	Price            float64           `json:"price"`
+	Discount         float64           `json:"discount"`
+	Status           bool              `json:"status"`
+	AlwaysAvailbale  bool              `json:"always_available"`
+	Quantity         int               `json:"quantity"`
+	IsBundle         bool              `json:"is_bundle"` // stock comes from the bundled products, see BundleItem
+	File             string            `json:"file"`
+	Watermark        bool              `json:"watermark"` // stamp the buyer's email on downloaded files
+	Store            string            `json:"store"`
+	CategoryID       uint32            `json:"category_id" gorm:"index"`
+	Category         string            `json:"category"`
+	Subcategory      string            `json:"subcategory"`
+	Attributes       ProductAttributes `json:"attributes" gorm:"type:jsonb;serializer:json"`
+	Type             string            `json:"type"`
+	UnitsSold        int               `json:"units_sold"`
+	Variant          []*VariantType    `json:"variant" gorm:"type:jsonb;serializer:json"`
+	RatingAverage    float64           `json:"rating_average"`
+	ReviewCount      int               `json:"review_count"`
+	ModerationStatus string            `json:"moderation_status" gorm:"index;default:approved"`
+	ModerationReason string            `json:"moderation_reason"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt    `json:"deleted_at" gorm:"index"`
+}
+
+// EffectivePrice is what a buyer pays for one unit of the product.
+func (p *Product) EffectivePrice() float64 {
+	return p.Price - p.Discount
+}
+
+// IsSoldOut reports whether the product can no longer be bought.
+func (p *Product) IsSoldOut() bool {
+	return p.Quantity <= 0 && !p.AlwaysAvailbale
+}
+
+// ProductPriceHistory records a product's price and discount each time either changes.
+type ProductPriceHistory struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	ProductID uint32    `json:"product_id" gorm:"index"`
+	Price     float64   `json:"price"`
+	Discount  float64   `json:"discount"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+func (ProductPriceHistory) TableName() string {
+	return "product_price_history"
+}
+
+// Back-in-stock subscription states
+const (
+	StockAlertWaiting   = "waiting"
+	StockAlertNotified  = "notified"
+	StockAlertCancelled = "cancelled"
+)
+
+// StockSubscription asks for a notification when a sold-out product, or one of
+// its variants, is back in stock. Variant is "name:value", or empty for the
+// product as a whole.
+type StockSubscription struct {
+	ID         uint32     `json:"id" gorm:"primaryKey"`
+	ProductID  uint32     `json:"product_id" gorm:"index"`
+	Variant    string     `json:"variant"`
+	UserID     uint32     `json:"user_id" gorm:"index"`
+	Status     string     `json:"status" gorm:"index"`
+	NotifiedAt *time.Time `json:"notified_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+}
+
+// Moderation states of a product. Only approved products are listed publicly.
+const (
+	ModerationPending  = "pending"
+	ModerationApproved = "approved"
+	ModerationRejected = "rejected"
+)
+
+// ProductModeration is a moderation queue entry, opened when a product is created
+// or edited by a store that is not trusted, or when automatic checks flag it.
+type ProductModeration struct {
+	ID         uint32         `json:"id" gorm:"primaryKey"`
+	ProductID  uint32         `json:"product_id" gorm:"index"`
+	Store      string         `json:"store" gorm:"index"`
+	Trigger    string         `json:"trigger"` // create/update
+	Flags      pq.StringArray `json:"flags" gorm:"type:text[]"`
+	Status     string         `json:"status" gorm:"index"`
+	Reason     string         `json:"reason"`
+	ReviewerID uint32         `json:"reviewer_id"`
+	ReviewedAt *time.Time     `json:"reviewed_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// ProductView is a single view event. Anonymous viewers have a zero UserID and are
+// identified by their SessionID instead. Each view links to the viewer's one
+// before it, so two requests counting a view after the same one collide on
+// the unique key.
+type ProductView struct {
+	ID         uint32    `json:"id" gorm:"primaryKey"`
+	ProductID  uint32    `json:"product_id" gorm:"index;uniqueIndex:idx_product_views_viewer_previous"`
+	UserID     uint32    `json:"user_id" gorm:"index"`
+	SessionID  string    `json:"session_id" gorm:"index"`
+	Viewer     string    `json:"-" gorm:"uniqueIndex:idx_product_views_viewer_previous"`                    // "u" and the user ID, or "s" and the session ID
+	PreviousID uint32    `json:"-" gorm:"not null;default:0;uniqueIndex:idx_product_views_viewer_previous"` // the viewer's view of the product before this one
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// ProductViewDaily holds the per-day view counts of a product.
+type ProductViewDaily struct {
+	ProductID     uint32    `json:"product_id" gorm:"primaryKey"`
+	Day           time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Views         int       `json:"views"`
+	UniqueViewers int       `json:"unique_viewers"`
+}
+
+func (ProductViewDaily) TableName() string {
+	return "product_view_daily"
+}
+
+// ImportRowResult is the outcome of a single row of a bulk product import.
+// Row is the line number in the uploaded sheet, counting the header as row 1.
+type ImportRowResult struct {
+	Row       int    `json:"row"`
+	Action    string `json:"action"` // created/updated/failed
+	ProductID uint32 `json:"product_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
 }
 
 type ProductPaginationData struct {
@@ -212,8 +331,9 @@ type HandledProduct struct {
 	UnitSold        int            `json:"unit_sold" gorm:"column:unit_sold"`
 	Variant         []*VariantType `json:"variant" gorm:"type:jsonb;serializer:json"`
 	Views           Uint32Array    `json:"views" gorm:"type:integer[]"`
-	Reviews         []Review       `json:"reviews" gorm:"type:jsonb;serializer:json"`
 	AlwaysAvailbale bool           `json:"always_availbale" gorm:"column:always_availbale"`
+	SavedPrice      float64        `json:"saved_price" gorm:"column:saved_price"`     // effective price when the product was saved
+	AlertedPrice    float64        `json:"alerted_price" gorm:"column:alerted_price"` // price of the last drop alert, 0 if none
 	Product         *Product       `json:"-" gorm:"foreignKey:ProductID"`
 }
 
@@ -222,8 +342,26 @@ type Repository interface {
 	CreateSubCategory(ctx context.Context, subcategory SubCategory) (*Category, error)
 	GetCategories(ctx context.Context) ([]*Category, error)
 	GetCategory(ctx context.Context, id uint32) (*Category, error)
+	SetCategoryAttributes(ctx context.Context, categoryId uint32, attributes []CategoryAttribute) (*Category, error)
+	GetCategoryFacets(ctx context.Context, categoryId uint32) ([]*Facet, error)
+	GetProductsByCategory(ctx context.Context, categoryId uint32, filters map[string][]string, limit, offset int) ([]*Product, int, error)
 	CreateProduct(ctx context.Context, product *NewProduct) (*Product, error)
 	GetProduct(ctx context.Context, productId, userId uint32) (*Product, error)
+	GetProductBySlug(ctx context.Context, storeName, slug string) (*Product, error)
+	GetBundleItems(ctx context.Context, bundleId uint32) ([]*BundleItem, error)
+	SetBundleItems(ctx context.Context, bundleId uint32, items []*BundleItem) (*Product, error)
+	GetPricingRules(ctx context.Context, productId uint32) ([]*PricingRule, error)
+	SetPricingRules(ctx context.Context, productId uint32, rules []*PricingRule) ([]*PricingRule, error)
+	CreateSaleCampaign(ctx context.Context, c *SaleCampaign) (*SaleCampaign, error)
+	GetSaleCampaign(ctx context.Context, id uint32) (*SaleCampaign, error)
+	CancelSaleCampaign(ctx context.Context, id uint32) error
+	GetStoreSaleCampaigns(ctx context.Context, storeName string, includePast bool) ([]*SaleCampaign, error)
+	GetActiveDeals(ctx context.Context, limit, offset int) ([]*SaleItem, error)
+	GetActiveSale(ctx context.Context, productId uint32) (*ActiveSale, error)
+	GetTrashedProduct(ctx context.Context, id uint32) (*Product, error)
+	GetTrashedProducts(ctx context.Context, storeName string) ([]*Product, error)
+	RestoreProduct(ctx context.Context, id uint32) (*Product, error)
+	PurgeTrashedProducts(ctx context.Context, before time.Time) (int, error)
 	GetProducts(ctx context.Context, store string, categorySlug string, limit int, offset int) ([]*Product, int, error)
 	AddHandledProduct(ctx context.Context, userId, productId uint32, eventType string) (*HandledProduct, error)
 	AddSavedForLater(ctx context.Context, userId, productId uint32) (*HandledProduct, error)
@@ -234,10 +372,25 @@ type Repository interface {
 	// GetProductByFilter(ctx context.Context, filter string,filterOption string )(*Product,error)    //by slug,by store,by id,(by category||subcategory)
 	UpdateProduct(ctx context.Context, req *NewProduct) (*Product, error)
 	DeleteProduct(ctx context.Context, id uint32) error
-	AddReview(ctx context.Context, input *Review) (*Review, error)
-	GetProductReviews(ctx context.Context, productId uint32, sellerId string) ([]*Review, error)
 	Products(ctx context.Context, store *string, categorySlug *string, limit *int, offset *int) (*model.ProductPaginationData, error)
 	GetAllProducts(ctx context.Context) ([]*Product, error)
+	RecordProductView(ctx context.Context, view *ProductView) (bool, error)
+	GetProductViewStats(ctx context.Context, productId uint32, from, to time.Time) ([]*ProductViewDaily, error)
+	RollupProductViews(ctx context.Context, day time.Time) error
+	ImportProducts(ctx context.Context, storeName string, rows []map[string]string) (*ImportReport, error)
+	GetStoreCatalog(ctx context.Context, storeName string) ([]*Product, error)
+	GetPriceHistory(ctx context.Context, productId uint32) ([]*ProductPriceHistory, error)
+	NotifyPriceDrops(ctx context.Context, productId uint32) (int, error)
+	SubscribeToRestock(ctx context.Context, sub *StockSubscription) (*StockSubscription, error)
+	CancelRestockSubscription(ctx context.Context, userId, productId uint32, variant string) error
+	NotifyRestock(ctx context.Context, productId uint32) (int, error)
+	GetRestockedProductIDs(ctx context.Context) ([]uint32, error)
+	AskQuestion(ctx context.Context, q *ProductQuestion) (*ProductQuestion, error)
+	AnswerQuestion(ctx context.Context, a *ProductAnswer) (*ProductAnswer, error)
+	GetProductQuestions(ctx context.Context, productId uint32, answeredOnly bool, limit, offset int) ([]*ProductQuestion, error)
+	UpvoteQA(ctx context.Context, userId uint32, kind string, id uint32) (int, error)
+	ModerateQA(ctx context.Context, kind string, id uint32, approve bool) error
+	GetQAModerationQueue(ctx context.Context) ([]*ProductQuestion, []*ProductAnswer, error)
 }
 
 type Service interface {
@@ -245,8 +398,26 @@ type Service interface {
 	CreateSubCategory(ctx context.Context, subcategory SubCategory) (*Category, error)
 	GetCategories(ctx context.Context) ([]*Category, error)
 	GetCategory(ctx context.Context, id uint32) (*Category, error)
+	SetCategoryAttributes(ctx context.Context, categoryId uint32, attributes []CategoryAttribute) (*Category, error)
+	GetCategoryFacets(ctx context.Context, categoryId uint32) ([]*Facet, error)
+	GetProductsByCategory(ctx context.Context, categoryId uint32, filters map[string][]string, limit, offset int) ([]*Product, int, error)
 	CreateProduct(ctx context.Context, product *NewProduct) (*Product, error)
 	GetProduct(ctx context.Context, productId, userId uint32) (*Product, error)
+	GetProductBySlug(ctx context.Context, storeName, slug string) (*Product, error)
+	GetBundleItems(ctx context.Context, bundleId uint32) ([]*BundleItem, error)
+	SetBundleItems(ctx context.Context, bundleId uint32, items []*BundleItem) (*Product, error)
+	GetPricingRules(ctx context.Context, productId uint32) ([]*PricingRule, error)
+	SetPricingRules(ctx context.Context, productId uint32, rules []*PricingRule) ([]*PricingRule, error)
+	CreateSaleCampaign(ctx context.Context, c *SaleCampaign) (*SaleCampaign, error)
+	GetSaleCampaign(ctx context.Context, id uint32) (*SaleCampaign, error)
+	CancelSaleCampaign(ctx context.Context, id uint32) error
+	GetStoreSaleCampaigns(ctx context.Context, storeName string, includePast bool) ([]*SaleCampaign, error)
+	GetActiveDeals(ctx context.Context, limit, offset int) ([]*SaleItem, error)
+	GetActiveSale(ctx context.Context, productId uint32) (*ActiveSale, error)
+	GetTrashedProduct(ctx context.Context, id uint32) (*Product, error)
+	GetTrashedProducts(ctx context.Context, storeName string) ([]*Product, error)
+	RestoreProduct(ctx context.Context, id uint32) (*Product, error)
+	PurgeTrashedProducts(ctx context.Context, before time.Time) (int, error)
 	GetProducts(ctx context.Context, store string, categorySlug string, limit int, offset int) ([]*Product, int, error)
 	AddHandledProduct(ctx context.Context, userId, productId uint32, eventType string) (*HandledProduct, error)
 	AddSavedForLater(ctx context.Context, userId, productId uint32) (*HandledProduct, error)
@@ -255,8 +426,23 @@ type Service interface {
 	DeleteProduct(ctx context.Context, id uint32) error
 	GetRecommendedProducts(ctx context.Context, query string) ([]*Product, error)
 	SearchProducts(ctx context.Context, query string) ([]*Product, error)
-	AddReview(ctx context.Context, input *Review) (*Review, error)
-	GetProductReviews(ctx context.Context, productId uint32, sellerId string) ([]*Review, error)
 	GetAllProducts(ctx context.Context) ([]*Product, error)
 	UpdateProduct(ctx context.Context, req *NewProduct) (*Product, error)
+	RecordProductView(ctx context.Context, view *ProductView) (bool, error)
+	GetProductViewStats(ctx context.Context, productId uint32, from, to time.Time) ([]*ProductViewDaily, error)
+	RollupProductViews(ctx context.Context, day time.Time) error
+	ImportProducts(ctx context.Context, storeName string, rows []map[string]string) (*ImportReport, error)
+	GetStoreCatalog(ctx context.Context, storeName string) ([]*Product, error)
+	GetPriceHistory(ctx context.Context, productId uint32) ([]*ProductPriceHistory, error)
+	NotifyPriceDrops(ctx context.Context, productId uint32) (int, error)
+	SubscribeToRestock(ctx context.Context, sub *StockSubscription) (*StockSubscription, error)
+	CancelRestockSubscription(ctx context.Context, userId, productId uint32, variant string) error
+	NotifyRestock(ctx context.Context, productId uint32) (int, error)
+	GetRestockedProductIDs(ctx context.Context) ([]uint32, error)
+	AskQuestion(ctx context.Context, q *ProductQuestion) (*ProductQuestion, error)
+	AnswerQuestion(ctx context.Context, a *ProductAnswer) (*ProductAnswer, error)
+	GetProductQuestions(ctx context.Context, productId uint32, answeredOnly bool, limit, offset int) ([]*ProductQuestion, error)
+	UpvoteQA(ctx context.Context, userId uint32, kind string, id uint32) (int, error)
+	ModerateQA(ctx context.Context, kind string, id uint32, approve bool) error
+	GetQAModerationQueue(ctx context.Context) ([]*ProductQuestion, []*ProductAnswer, error)
 }
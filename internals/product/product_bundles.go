@@ -0,0 +1,190 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+// MaxBundleItems caps the number of different products in one bundle.
+const MaxBundleItems = 10
+
+// BundleItem is one product packed into a bundle, like the past questions in a
+// "textbook + past questions" pack. A bundle keeps no stock of its own: selling
+// one takes Quantity units of every component.
+type BundleItem struct {
+	ID          uint32    `json:"id" gorm:"primaryKey"`
+	BundleID    uint32    `json:"bundle_id" gorm:"not null;uniqueIndex:idx_product_bundle_items_pair"`
+	ComponentID uint32    `json:"component_id" gorm:"not null;index;uniqueIndex:idx_product_bundle_items_pair"`
+	Quantity    int       `json:"quantity" gorm:"not null;default:1"`
+	Component   *Product  `json:"component,omitempty" gorm:"foreignKey:ComponentID"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (BundleItem) TableName() string {
+	return "product_bundle_items"
+}
+
+// refreshBundleStock recomputes the stock of bundles from their components: as
+// many bundles as the scarcest component allows. A bundle is always available
+// only when all its components are.
+func refreshBundleStock(tx *gorm.DB, bundleIDs []uint32) error {
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+	return tx.Exec(`
+		UPDATE products b SET
+			quantity = COALESCE(s.available, 0),
+			always_availbale = s.always_available
+		FROM (
+			SELECT bi.bundle_id,
+				MIN(GREATEST(COALESCE(c.quantity, 0), 0) / bi.quantity)
+					FILTER (WHERE c.id IS NULL OR NOT c.always_availbale) AS available,
+				BOOL_AND(COALESCE(c.always_availbale, FALSE)) AS always_available
+			FROM product_bundle_items bi
+			LEFT JOIN products c ON c.id = bi.component_id AND c.deleted_at IS NULL
+			WHERE bi.bundle_id IN ?
+			GROUP BY bi.bundle_id
+		) s
+		WHERE b.id = s.bundle_id`, bundleIDs).Error
+}
+
+// refreshBundlesContaining recomputes the stock of every bundle that includes
+// one of the given products.
+func refreshBundlesContaining(tx *gorm.DB, componentIDs []uint32) error {
+	var bundleIDs []uint32
+	if err := tx.Model(&BundleItem{}).
+		Where("component_id IN ?", componentIDs).
+		Distinct().Pluck("bundle_id", &bundleIDs).Error; err != nil {
+		return err
+	}
+	return refreshBundleStock(tx, bundleIDs)
+}
+
+// AdjustStock changes a product's stock by delta: negative to take units for
+// a cart, positive to put them back. For a bundle the components are adjusted
+// instead, and any bundle sharing a changed product is recomputed.
+func AdjustStock(tx *gorm.DB, p *Product, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	if !p.IsBundle {
+		if err := tx.Model(&Product{}).Where("id = ?", p.ID).
+			UpdateColumn("quantity", gorm.Expr("quantity + ?", delta)).Error; err != nil {
+			return err
+		}
+		p.Quantity += delta
+		return refreshBundlesContaining(tx, []uint32{p.ID})
+	}
+
+	var items []*BundleItem
+	if err := tx.Preload("Component").Where("bundle_id = ?", p.ID).Find(&items).Error; err != nil {
+		return err
+	}
+	componentIDs := make([]uint32, 0, len(items))
+	for _, item := range items {
+		if item.Component == nil {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A product in this bundle is no longer available")
+		}
+		change := delta * item.Quantity
+		if change < 0 && !item.Component.AlwaysAvailbale && item.Component.Quantity < -change {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST",
+				fmt.Sprintf("Only %d of %s left for this bundle", item.Component.Quantity/item.Quantity, item.Component.Name))
+		}
+		if err := tx.Model(&Product{}).Where("id = ?", item.ComponentID).
+			UpdateColumn("quantity", gorm.Expr("quantity + ?", change)).Error; err != nil {
+			return err
+		}
+		componentIDs = append(componentIDs, item.ComponentID)
+	}
+	if err := refreshBundlesContaining(tx, componentIDs); err != nil {
+		return err
+	}
+	return tx.Select("quantity").Where("id = ?", p.ID).First(p).Error
+}
+
+func (r *repository) GetBundleItems(ctx context.Context, bundleId uint32) ([]*BundleItem, error) {
+	var items []*BundleItem
+	if err := r.db.WithContext(ctx).Preload("Component").
+		Where("bundle_id = ?", bundleId).
+		Order("id").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bundle items: %v", err)
+	}
+	return items, nil
+}
+
+// SetBundleItems replaces the contents of a bundle. Components must be regular
+// products of the same store; an empty list turns the bundle back into a
+// regular product with no stock.
+func (r *repository) SetBundleItems(ctx context.Context, bundleId uint32, items []*BundleItem) (*Product, error) {
+	var bundle Product
+	if err := r.db.WithContext(ctx).First(&bundle, bundleId).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+	if len(items) > MaxBundleItems {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A bundle can hold at most %d products", MaxBundleItems))
+	}
+
+	seen := map[uint32]bool{}
+	for _, item := range items {
+		if item.ComponentID == bundleId {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A bundle cannot contain itself")
+		}
+		if seen[item.ComponentID] {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Each product can only appear once in a bundle")
+		}
+		seen[item.ComponentID] = true
+		if item.Quantity < 1 {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Bundle quantities must be at least 1")
+		}
+
+		var component Product
+		if err := r.db.WithContext(ctx).First(&component, item.ComponentID).Error; err != nil {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", fmt.Sprintf("Product %d not found", item.ComponentID))
+		}
+		if component.Store != bundle.Store {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s belongs to another store", component.Name))
+		}
+		if component.IsBundle {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s is a bundle itself", component.Name))
+		}
+	}
+
+	var usedIn int64
+	r.db.WithContext(ctx).Model(&BundleItem{}).Where("component_id = ?", bundleId).Count(&usedIn)
+	if usedIn > 0 && len(items) > 0 {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This product is part of another bundle and cannot be a bundle itself")
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundleId).Delete(&BundleItem{}).Error; err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := tx.Create(&BundleItem{BundleID: bundleId, ComponentID: item.ComponentID, Quantity: item.Quantity}).Error; err != nil {
+				return err
+			}
+		}
+		if len(items) == 0 {
+			return tx.Model(&Product{}).Where("id = ?", bundleId).
+				Updates(map[string]interface{}{"is_bundle": false, "quantity": 0, "always_availbale": false}).Error
+		}
+		if err := tx.Model(&Product{}).Where("id = ?", bundleId).Update("is_bundle", true).Error; err != nil {
+			return err
+		}
+		return refreshBundleStock(tx, []uint32{bundleId})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save bundle: %v", err)
+	}
+
+	if err := r.db.WithContext(ctx).First(&bundle, bundleId).Error; err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
@@ -0,0 +1,441 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+// Kinds of category. Products take on the kind of the category they are listed
+// in as their Type.
+const (
+	CategoryPhysical = "physical"
+	CategoryDigital  = "digital"
+	CategoryService  = "service"
+)
+
+// Attribute types a category can define.
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
+)
+
+// CategoryAttribute is a structured field that products in a category fill in,
+// like size and colour for clothing or author and edition for textbooks. Child
+// categories inherit the attributes of their ancestors and can redefine one by
+// reusing its name.
+type CategoryAttribute struct {
+	ID         uint32         `json:"id" gorm:"primaryKey"`
+	CategoryID uint32         `json:"category_id" gorm:"not null;uniqueIndex:idx_category_attributes_name"`
+	Name       string         `json:"name" gorm:"not null;uniqueIndex:idx_category_attributes_name"`
+	Label      string         `json:"label"`
+	Type       string         `json:"type" gorm:"not null;default:text"`
+	Options    pq.StringArray `json:"options" gorm:"type:text[]"` // allowed values of an enum
+	Required   bool           `json:"required"`
+	Facet      bool           `json:"facet"` // offered as a search filter
+	Position   int            `json:"position"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// ProductAttributes holds a product's attribute values keyed by attribute name.
+type ProductAttributes map[string]string
+
+// Facet lists the values of a facet attribute found among a category's
+// products, with how many products carry each value.
+type Facet struct {
+	Name   string        `json:"name"`
+	Label  string        `json:"label"`
+	Values []*FacetValue `json:"values"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// normalizeAttributes checks an attribute schema submitted for a category.
+func normalizeAttributes(attributes []CategoryAttribute) ([]CategoryAttribute, error) {
+	seen := map[string]bool{}
+	for i := range attributes {
+		a := &attributes[i]
+		a.Name = strings.ToLower(strings.TrimSpace(a.Name))
+		if !attributeNamePattern.MatchString(a.Name) {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST",
+				fmt.Sprintf("attribute name %q must start with a letter and use only lowercase letters, digits and underscores", a.Name))
+		}
+		if seen[a.Name] {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("attribute %q is defined twice", a.Name))
+		}
+		seen[a.Name] = true
+
+		if a.Label = strings.TrimSpace(a.Label); a.Label == "" {
+			a.Label = a.Name
+		}
+		if a.Type == "" {
+			a.Type = AttributeText
+		}
+		switch a.Type {
+		case AttributeText, AttributeNumber, AttributeBoolean:
+			a.Options = nil
+		case AttributeEnum:
+			var options pq.StringArray
+			for _, o := range a.Options {
+				if o = strings.TrimSpace(o); o != "" {
+					options = append(options, o)
+				}
+			}
+			if len(options) == 0 {
+				return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("enum attribute %q needs at least one option", a.Name))
+			}
+			a.Options = options
+		default:
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST",
+				fmt.Sprintf("attribute %q has unknown type %q", a.Name, a.Type))
+		}
+		a.Position = i
+	}
+	return attributes, nil
+}
+
+// validateAttributes checks product attribute values against a category's
+// schema and returns them in canonical form: trimmed, enums spelled like their
+// option and booleans as "true" or "false".
+func validateAttributes(schema []CategoryAttribute, values map[string]string) (ProductAttributes, error) {
+	byName := make(map[string]CategoryAttribute, len(schema))
+	for _, a := range schema {
+		byName[a.Name] = a
+	}
+
+	clean := ProductAttributes{}
+	for name, value := range values {
+		name = strings.ToLower(strings.TrimSpace(name))
+		attribute, ok := byName[name]
+		if !ok {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("attribute %q does not apply to this category", name))
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+
+		switch attribute.Type {
+		case AttributeNumber:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s must be a number", attribute.Label))
+			}
+		case AttributeBoolean:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s must be true or false", attribute.Label))
+			}
+			value = strconv.FormatBool(b)
+		case AttributeEnum:
+			matched := ""
+			for _, option := range attribute.Options {
+				if strings.EqualFold(option, value) {
+					matched = option
+					break
+				}
+			}
+			if matched == "" {
+				return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST",
+					fmt.Sprintf("%s must be one of: %s", attribute.Label, strings.Join(attribute.Options, ", ")))
+			}
+			value = matched
+		}
+		clean[name] = value
+	}
+
+	for _, a := range schema {
+		if _, ok := clean[a.Name]; a.Required && !ok {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s is required", a.Label))
+		}
+	}
+	return clean, nil
+}
+
+// keepSchemaAttributes drops the values a schema has no attribute for, so a
+// product moved to another category keeps only what still applies.
+func keepSchemaAttributes(schema []CategoryAttribute, values ProductAttributes) map[string]string {
+	kept := map[string]string{}
+	for _, a := range schema {
+		if v, ok := values[a.Name]; ok {
+			kept[a.Name] = v
+		}
+	}
+	return kept
+}
+
+// inheritAttributes merges a category's own attributes over those it inherits.
+func inheritAttributes(inherited, own []CategoryAttribute) []CategoryAttribute {
+	merged := append([]CategoryAttribute{}, inherited...)
+	for _, a := range own {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == a.Name {
+				merged[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, a)
+		}
+	}
+	return merged
+}
+
+// categoryTree loads every category with its attributes, links children to
+// their parents and resolves inherited attributes. It returns the categories
+// by ID and the roots.
+func (r *repository) categoryTree(ctx context.Context) (map[uint32]*Category, []*Category, error) {
+	var categories []*Category
+	if err := r.db.WithContext(ctx).Order("depth, name").Find(&categories).Error; err != nil {
+		return nil, nil, fmt.Errorf("error retrieving categories: %v", err)
+	}
+	var attributes []CategoryAttribute
+	if err := r.db.WithContext(ctx).Order("category_id, position").Find(&attributes).Error; err != nil {
+		return nil, nil, fmt.Errorf("error retrieving category attributes: %v", err)
+	}
+
+	own := map[uint32][]CategoryAttribute{}
+	for _, a := range attributes {
+		own[a.CategoryID] = append(own[a.CategoryID], a)
+	}
+
+	byID := make(map[uint32]*Category, len(categories))
+	for _, c := range categories {
+		c.Children = []*Category{}
+		c.SubCategories = []SubCategory{}
+		byID[uint32(c.ID)] = c
+	}
+
+	var roots []*Category
+	for _, c := range categories {
+		parent, ok := (*Category)(nil), false
+		if c.ParentID != nil {
+			parent, ok = byID[*c.ParentID]
+		}
+		if !ok {
+			roots = append(roots, c)
+			continue
+		}
+		parent.Children = append(parent.Children, c)
+		parent.SubCategories = append(parent.SubCategories, SubCategory{
+			Name:       c.Name,
+			Slug:       c.Slug,
+			CategoryID: uint32(parent.ID),
+		})
+	}
+
+	var resolve func(c *Category, inherited []CategoryAttribute)
+	resolve = func(c *Category, inherited []CategoryAttribute) {
+		c.Attributes = inheritAttributes(inherited, own[uint32(c.ID)])
+		for _, child := range c.Children {
+			resolve(child, c.Attributes)
+		}
+	}
+	for _, root := range roots {
+		resolve(root, nil)
+	}
+	return byID, roots, nil
+}
+
+// findCategory returns the category itself or its descendant named or slugged
+// value, searching the nearest levels first.
+func findCategory(c *Category, value string) *Category {
+	if value == "" {
+		return c
+	}
+	queue := append([]*Category{}, c.Children...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if strings.EqualFold(next.Name, value) || strings.EqualFold(next.Slug, value) {
+			return next
+		}
+		queue = append(queue, next.Children...)
+	}
+	return nil
+}
+
+// rootOf returns the top level category above c, or c if it is one.
+func rootOf(byID map[uint32]*Category, c *Category) *Category {
+	for c.ParentID != nil {
+		parent, ok := byID[*c.ParentID]
+		if !ok {
+			break
+		}
+		c = parent
+	}
+	return c
+}
+
+// resolveProductCategory finds the category a product request points at: the
+// category itself, or the subcategory of it named in the request.
+func (r *repository) resolveProductCategory(ctx context.Context, categoryId uint32, subcategory string) (node, root *Category, err error) {
+	byID, _, err := r.categoryTree(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	category, ok := byID[categoryId]
+	if !ok {
+		return nil, nil, fmt.Errorf("category with ID %d not found", categoryId)
+	}
+	node = findCategory(category, strings.TrimSpace(subcategory))
+	if node == nil {
+		return nil, nil, fmt.Errorf("subcategory '%s' not found in category. Available subcategories: %v",
+			subcategory,
+			getSubcategoryNames(category.SubCategories))
+	}
+	return node, rootOf(byID, node), nil
+}
+
+// applyCategory links a product to a category node and stores the validated
+// attribute values. The free text category names are kept for older clients.
+func applyCategory(p *Product, node, root *Category, values map[string]string) error {
+	attributes, err := validateAttributes(node.Attributes, values)
+	if err != nil {
+		return err
+	}
+	p.CategoryID = uint32(node.ID)
+	p.Type = node.Type
+	p.Category = root.Name
+	p.Subcategory = ""
+	if node != root {
+		p.Subcategory = node.Name
+	}
+	p.Attributes = attributes
+	return nil
+}
+
+// subtreeIDs is a query for the IDs of a category and all its descendants.
+func (r *repository) subtreeIDs(column string, value interface{}) *gorm.DB {
+	return r.db.Table("categories AS sub").
+		Select("sub.id").
+		Joins("JOIN categories AS top ON sub.id = top.id OR sub.path LIKE top.path || '/%'").
+		Where("top."+column+" = ? AND sub.deleted_at IS NULL", value)
+}
+
+func (r *repository) SetCategoryAttributes(ctx context.Context, categoryId uint32, attributes []CategoryAttribute) (*Category, error) {
+	attributes, err := normalizeAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.GetCategory(ctx, categoryId); err != nil {
+		return nil, err
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", categoryId).Delete(&CategoryAttribute{}).Error; err != nil {
+			return err
+		}
+		for i := range attributes {
+			attributes[i].ID = 0
+			attributes[i].CategoryID = categoryId
+		}
+		if len(attributes) == 0 {
+			return nil
+		}
+		return tx.Create(&attributes).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save category attributes: %v", err)
+	}
+	return r.GetCategory(ctx, categoryId)
+}
+
+func (r *repository) GetCategoryFacets(ctx context.Context, categoryId uint32) ([]*Facet, error) {
+	category, err := r.GetCategory(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := []*Facet{}
+	for _, a := range category.Attributes {
+		if !a.Facet {
+			continue
+		}
+		var values []*FacetValue
+		err := r.db.WithContext(ctx).Table("products").
+			Select("products.attributes ->> ? AS value, COUNT(*) AS count", a.Name).
+			Where("products.category_id IN (?)", r.subtreeIDs("id", categoryId)).
+			Where("products.deleted_at IS NULL AND products.status = ? AND products.moderation_status = ?", true, ModerationApproved).
+			Where("products.attributes ->> ? IS NOT NULL", a.Name).
+			Group("value").
+			Order("count DESC, value").
+			Scan(&values).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s values: %v", a.Name, err)
+		}
+		if values == nil {
+			values = []*FacetValue{}
+		}
+		facets = append(facets, &Facet{Name: a.Name, Label: a.Label, Values: values})
+	}
+	return facets, nil
+}
+
+func (r *repository) GetProductsByCategory(ctx context.Context, categoryId uint32, filters map[string][]string, limit, offset int) ([]*Product, int, error) {
+	category, err := r.GetCategory(ctx, categoryId)
+	if err != nil {
+		return nil, 0, err
+	}
+	known := map[string]bool{}
+	for _, a := range category.Attributes {
+		known[a.Name] = a.Facet
+	}
+
+	query := r.db.WithContext(ctx).Model(&Product{}).
+		Joins("LEFT JOIN stores ON stores.name = products.store").
+		Where("products.category_id IN (?)", r.subtreeIDs("id", categoryId)).
+		Where("products.status = ? AND products.moderation_status = ?", true, ModerationApproved).
+		Where("stores.deleted_at IS NULL AND stores.maintenance_mode = ?", false)
+
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return nil, 0, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%q is not a filter of this category", name))
+		}
+		if len(filters[name]) == 0 {
+			continue
+		}
+		query = query.Where("products.attributes ->> ? IN ?", name, filters[name])
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []*Product
+	if err := query.Order("products.created_at DESC").Limit(limit).Offset(offset * limit).Find(&products).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, p := range products {
+		if p.Images == nil {
+			p.Images = []string{}
+		}
+		if p.Variant == nil {
+			p.Variant = []*VariantType{}
+		}
+	}
+	return products, int(total), nil
+}
@@ -0,0 +1,222 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+// Kinds of multi-buy deal.
+const (
+	// DealQuantityTier lowers the unit price once MinQuantity units are bought
+	DealQuantityTier = "quantity_tier"
+	// DealBuyXGetY makes FreeQuantity units free for every BuyQuantity paid ones
+	DealBuyXGetY = "buy_x_get_y"
+
+	// MaxPricingRules caps the number of deals on one product.
+	MaxPricingRules = 5
+)
+
+// PricingRule is a multi-buy deal on a product. When several apply to a cart
+// line only the one saving the buyer the most is used.
+type PricingRule struct {
+	ID           uint32    `json:"id" gorm:"primaryKey"`
+	ProductID    uint32    `json:"product_id" gorm:"not null;index"`
+	Type         string    `json:"type" gorm:"not null"`
+	MinQuantity  int       `json:"min_quantity"`
+	UnitPrice    float64   `json:"unit_price"`
+	BuyQuantity  int       `json:"buy_quantity"`
+	FreeQuantity int       `json:"free_quantity"`
+	Label        string    `json:"label"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (PricingRule) TableName() string {
+	return "product_pricing_rules"
+}
+
+// AppliedDeal is the deal used on a cart or order line and what it saved. It is
+// either a multi-buy rule or, when SaleItemID is set, a flash sale covering
+// SaleUnits of the line until EndsAt.
+type AppliedDeal struct {
+	RuleID     uint32     `json:"rule_id"`
+	Label      string     `json:"label"`
+	FreeUnits  int        `json:"free_units,omitempty"`
+	Savings    float64    `json:"savings"`
+	SaleItemID uint32     `json:"sale_item_id,omitempty"`
+	SaleUnits  int        `json:"sale_units,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+}
+
+func (rule *PricingRule) defaultLabel() string {
+	if rule.Type == DealBuyXGetY {
+		return fmt.Sprintf("Buy %d get %d free", rule.BuyQuantity, rule.FreeQuantity)
+	}
+	return fmt.Sprintf("₦%s each for %d or more", formatAmount(rule.UnitPrice), rule.MinQuantity)
+}
+
+func formatAmount(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// Apply works out the deal on quantity units selling at unitPrice each, or nil
+// if the rule does not apply.
+func (rule *PricingRule) Apply(unitPrice float64, quantity int) *AppliedDeal {
+	deal := &AppliedDeal{RuleID: rule.ID, Label: rule.Label}
+	if deal.Label == "" {
+		deal.Label = rule.defaultLabel()
+	}
+
+	switch rule.Type {
+	case DealQuantityTier:
+		if quantity < rule.MinQuantity || rule.UnitPrice >= unitPrice {
+			return nil
+		}
+		deal.Savings = float64(quantity) * (unitPrice - rule.UnitPrice)
+	case DealBuyXGetY:
+		group := rule.BuyQuantity + rule.FreeQuantity
+		if rule.BuyQuantity < 1 || rule.FreeQuantity < 1 || quantity < group {
+			return nil
+		}
+		deal.FreeUnits = quantity / group * rule.FreeQuantity
+		deal.Savings = float64(deal.FreeUnits) * unitPrice
+	default:
+		return nil
+	}
+
+	if deal.Savings <= 0 {
+		return nil
+	}
+	deal.Savings = math.Round(deal.Savings*100) / 100
+	return deal
+}
+
+// BestDeal returns the deal saving the most on a line, or nil if none applies.
+func BestDeal(rules []*PricingRule, unitPrice float64, quantity int) *AppliedDeal {
+	var best *AppliedDeal
+	for _, rule := range rules {
+		if deal := rule.Apply(unitPrice, quantity); deal != nil && (best == nil || deal.Savings > best.Savings) {
+			best = deal
+		}
+	}
+	return best
+}
+
+// Pricing holds everything that can lower the price of cart lines: the
+// multi-buy deals and live sales of their products.
+type Pricing struct {
+	Rules map[uint32][]*PricingRule
+	Sales map[uint32]*ActiveSale
+}
+
+// LoadPricing loads the deals and sales of the given products as they stand at
+// now.
+func LoadPricing(db *gorm.DB, productIDs []uint32, now time.Time) (*Pricing, error) {
+	rules, err := loadPricingRules(db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := activeSales(db, productIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	return &Pricing{Rules: rules, Sales: sales}, nil
+}
+
+// BestDeal returns whichever of the product's deals and sale saves the most on
+// a line. Deals and sales never stack.
+func (p *Pricing) BestDeal(productID uint32, unitPrice float64, quantity int) *AppliedDeal {
+	best := BestDeal(p.Rules[productID], unitPrice, quantity)
+	if sale, ok := p.Sales[productID]; ok {
+		if deal := sale.Deal(unitPrice, quantity); deal != nil && (best == nil || deal.Savings > best.Savings) {
+			best = deal
+		}
+	}
+	return best
+}
+
+// loadPricingRules returns the deals of the given products keyed by product ID.
+func loadPricingRules(db *gorm.DB, productIDs []uint32) (map[uint32][]*PricingRule, error) {
+	rules := map[uint32][]*PricingRule{}
+	if len(productIDs) == 0 {
+		return rules, nil
+	}
+	var all []*PricingRule
+	if err := db.Where("product_id IN ?", productIDs).Find(&all).Error; err != nil {
+		return nil, fmt.Errorf("failed to load pricing rules: %v", err)
+	}
+	for _, rule := range all {
+		rules[rule.ProductID] = append(rules[rule.ProductID], rule)
+	}
+	return rules, nil
+}
+
+func validatePricingRule(rule *PricingRule, p *Product) error {
+	switch rule.Type {
+	case DealQuantityTier:
+		if rule.MinQuantity < 2 {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A quantity tier needs a minimum quantity of at least 2")
+		}
+		if rule.UnitPrice <= 0 || rule.UnitPrice >= p.EffectivePrice() {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A quantity tier price must be above zero and below the product price")
+		}
+		rule.BuyQuantity, rule.FreeQuantity = 0, 0
+	case DealBuyXGetY:
+		if rule.BuyQuantity < 1 || rule.FreeQuantity < 1 {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Buy and free quantities must be at least 1")
+		}
+		rule.MinQuantity, rule.UnitPrice = rule.BuyQuantity+rule.FreeQuantity, 0
+	default:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Unknown deal type %q", rule.Type))
+	}
+	return nil
+}
+
+func (r *repository) GetPricingRules(ctx context.Context, productId uint32) ([]*PricingRule, error) {
+	var rules []*PricingRule
+	if err := r.db.WithContext(ctx).Where("product_id = ?", productId).Order("id").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pricing rules: %v", err)
+	}
+	return rules, nil
+}
+
+// SetPricingRules replaces the deals on a product.
+func (r *repository) SetPricingRules(ctx context.Context, productId uint32, rules []*PricingRule) ([]*PricingRule, error) {
+	var p Product
+	if err := r.db.WithContext(ctx).First(&p, productId).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+	if len(rules) > MaxPricingRules {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A product can have at most %d deals", MaxPricingRules))
+	}
+	for _, rule := range rules {
+		if err := validatePricingRule(rule, &p); err != nil {
+			return nil, err
+		}
+		rule.ID = 0
+		rule.ProductID = productId
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productId).Delete(&PricingRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save pricing rules: %v", err)
+	}
+	return r.GetPricingRules(ctx, productId)
+}
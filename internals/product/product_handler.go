@@ -1,6 +1,9 @@
 package product
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Handler struct {
 	Service
@@ -52,6 +55,26 @@ func (h *Handler) GetProduct(ctx context.Context, productId, userId uint32) (*Pr
 	return item, nil
 }
 
+func (h *Handler) GetProductBySlug(ctx context.Context, storeName, slug string) (*Product, error) {
+	return h.Service.GetProductBySlug(ctx, storeName, slug)
+}
+
+func (h *Handler) GetBundleItems(ctx context.Context, bundleId uint32) ([]*BundleItem, error) {
+	return h.Service.GetBundleItems(ctx, bundleId)
+}
+
+func (h *Handler) SetBundleItems(ctx context.Context, bundleId uint32, items []*BundleItem) (*Product, error) {
+	return h.Service.SetBundleItems(ctx, bundleId, items)
+}
+
+func (h *Handler) GetPricingRules(ctx context.Context, productId uint32) ([]*PricingRule, error) {
+	return h.Service.GetPricingRules(ctx, productId)
+}
+
+func (h *Handler) SetPricingRules(ctx context.Context, productId uint32, rules []*PricingRule) ([]*PricingRule, error) {
+	return h.Service.SetPricingRules(ctx, productId, rules)
+}
+
 func (h *Handler) GetCategory(ctx context.Context, id uint32) (*Category, error) {
 	item, err := h.Service.GetCategory(ctx, id)
 	if err != nil {
@@ -60,6 +83,18 @@ func (h *Handler) GetCategory(ctx context.Context, id uint32) (*Category, error)
 	return item, nil
 }
 
+func (h *Handler) SetCategoryAttributes(ctx context.Context, categoryId uint32, attributes []CategoryAttribute) (*Category, error) {
+	return h.Service.SetCategoryAttributes(ctx, categoryId, attributes)
+}
+
+func (h *Handler) GetCategoryFacets(ctx context.Context, categoryId uint32) ([]*Facet, error) {
+	return h.Service.GetCategoryFacets(ctx, categoryId)
+}
+
+func (h *Handler) GetProductsByCategory(ctx context.Context, categoryId uint32, filters map[string][]string, limit, offset int) ([]*Product, int, error) {
+	return h.Service.GetProductsByCategory(ctx, categoryId, filters, limit, offset)
+}
+
 func (h *Handler) GetProducts(ctx context.Context, store string, categorySlug string, limit int, offset int) ([]*Product, int, error) {
 	return h.Service.GetProducts(ctx, store, categorySlug, limit, offset)
 }
@@ -109,14 +144,6 @@ func (h *Handler) SearchProducts(ctx context.Context, query string) ([]*Product,
 	return products, nil
 }
 
-func (h *Handler) GetProductReviews(ctx context.Context, productId uint32, sellerId string) ([]*Review, error) {
-	item, err := h.Service.GetProductReviews(ctx, productId, sellerId)
-	if err != nil {
-		return nil, err
-	}
-	return item, nil
-}
-
 // func (h *Handler) GetRecentlyViewedProducts(ctx context.Context, user uint32)([]*Product,error){
 // 	item, err := h.Service.GetRecentlyViewedProducts(ctx, user)
 // 	if err != nil {
@@ -125,14 +152,6 @@ func (h *Handler) GetProductReviews(ctx context.Context, productId uint32, selle
 // 	return item, nil
 // }
 
-func (h *Handler) AddReview(ctx context.Context, input *Review) (*Review, error) {
-	item, err := h.Service.AddReview(ctx, input)
-	if err != nil {
-		return nil, err
-	}
-	return item, nil
-}
-
 func (h *Handler) GetAllProducts(ctx context.Context) ([]*Product, error) {
 	return h.Service.GetAllProducts(ctx)
 }
@@ -144,3 +163,79 @@ func (h *Handler) GetAllProducts(ctx context.Context) ([]*Product, error) {
 // 	}
 // 	return nil
 // }
+
+func (h *Handler) RecordProductView(ctx context.Context, view *ProductView) (bool, error) {
+	return h.Service.RecordProductView(ctx, view)
+}
+
+func (h *Handler) GetProductViewStats(ctx context.Context, productId uint32, from, to time.Time) ([]*ProductViewDaily, error) {
+	return h.Service.GetProductViewStats(ctx, productId, from, to)
+}
+
+func (h *Handler) GetPriceHistory(ctx context.Context, productId uint32) ([]*ProductPriceHistory, error) {
+	return h.Service.GetPriceHistory(ctx, productId)
+}
+
+func (h *Handler) NotifyPriceDrops(ctx context.Context, productId uint32) (int, error) {
+	return h.Service.NotifyPriceDrops(ctx, productId)
+}
+
+func (h *Handler) SubscribeToRestock(ctx context.Context, sub *StockSubscription) (*StockSubscription, error) {
+	return h.Service.SubscribeToRestock(ctx, sub)
+}
+
+func (h *Handler) CancelRestockSubscription(ctx context.Context, userId, productId uint32, variant string) error {
+	return h.Service.CancelRestockSubscription(ctx, userId, productId, variant)
+}
+
+func (h *Handler) AskQuestion(ctx context.Context, q *ProductQuestion) (*ProductQuestion, error) {
+	return h.Service.AskQuestion(ctx, q)
+}
+
+func (h *Handler) AnswerQuestion(ctx context.Context, a *ProductAnswer) (*ProductAnswer, error) {
+	return h.Service.AnswerQuestion(ctx, a)
+}
+
+func (h *Handler) GetProductQuestions(ctx context.Context, productId uint32, answeredOnly bool, limit, offset int) ([]*ProductQuestion, error) {
+	return h.Service.GetProductQuestions(ctx, productId, answeredOnly, limit, offset)
+}
+
+func (h *Handler) CreateSaleCampaign(ctx context.Context, c *SaleCampaign) (*SaleCampaign, error) {
+	return h.Service.CreateSaleCampaign(ctx, c)
+}
+
+func (h *Handler) GetSaleCampaign(ctx context.Context, id uint32) (*SaleCampaign, error) {
+	return h.Service.GetSaleCampaign(ctx, id)
+}
+
+func (h *Handler) CancelSaleCampaign(ctx context.Context, id uint32) error {
+	return h.Service.CancelSaleCampaign(ctx, id)
+}
+
+func (h *Handler) GetStoreSaleCampaigns(ctx context.Context, storeName string, includePast bool) ([]*SaleCampaign, error) {
+	return h.Service.GetStoreSaleCampaigns(ctx, storeName, includePast)
+}
+
+func (h *Handler) GetActiveDeals(ctx context.Context, limit, offset int) ([]*SaleItem, error) {
+	return h.Service.GetActiveDeals(ctx, limit, offset)
+}
+
+func (h *Handler) GetActiveSale(ctx context.Context, productId uint32) (*ActiveSale, error) {
+	return h.Service.GetActiveSale(ctx, productId)
+}
+
+func (h *Handler) GetTrashedProduct(ctx context.Context, id uint32) (*Product, error) {
+	return h.Service.GetTrashedProduct(ctx, id)
+}
+
+func (h *Handler) GetTrashedProducts(ctx context.Context, storeName string) ([]*Product, error) {
+	return h.Service.GetTrashedProducts(ctx, storeName)
+}
+
+func (h *Handler) RestoreProduct(ctx context.Context, id uint32) (*Product, error) {
+	return h.Service.RestoreProduct(ctx, id)
+}
+
+func (h *Handler) PurgeTrashedProducts(ctx context.Context, before time.Time) (int, error) {
+	return h.Service.PurgeTrashedProducts(ctx, before)
+}
@@ -0,0 +1,378 @@
+package product
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Columns of the bulk import/export sheet. An export can be edited and uploaded back as is.
+const (
+	ImportColumnSKU             = "sku"
+	ImportColumnSlug            = "slug"
+	ImportColumnName            = "name"
+	ImportColumnDescription     = "description"
+	ImportColumnPrice           = "price"
+	ImportColumnDiscount        = "discount"
+	ImportColumnQuantity        = "quantity"
+	ImportColumnAlwaysAvailable = "always_available"
+	ImportColumnStatus          = "status"
+	ImportColumnCategory        = "category"
+	ImportColumnSubcategory     = "subcategory"
+	ImportColumnAttributes      = "attributes"
+	ImportColumnThumbnail       = "thumbnail"
+	ImportColumnImages          = "images"
+	ImportColumnFile            = "file"
+	ExportColumnUnitsSold       = "units_sold"
+
+	// MaxImportRows caps the number of products a single upload may touch
+	MaxImportRows = 1000
+
+	uploadDir = "./uploads/"
+)
+
+var catalogColumns = []string{
+	ImportColumnSKU, ImportColumnSlug, ImportColumnName, ImportColumnDescription,
+	ImportColumnPrice, ImportColumnDiscount, ImportColumnQuantity, ImportColumnAlwaysAvailable,
+	ImportColumnStatus, ImportColumnCategory, ImportColumnSubcategory, ImportColumnAttributes,
+	ImportColumnThumbnail, ImportColumnImages, ImportColumnFile, ExportColumnUnitsSold,
+}
+
+// ParseImportFile reads a CSV or XLSX upload into rows keyed by lower-cased header names.
+func ParseImportFile(filename string, r io.Reader) ([]map[string]string, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		all, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV file: %v", err)
+		}
+		for _, record := range all {
+			for i := range record {
+				record[i] = unescapeFormula(record[i])
+			}
+		}
+		records = all
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX file: %v", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("XLSX file has no sheets")
+		}
+		records, err = f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XLSX sheet: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, upload a .csv or .xlsx file", filepath.Ext(filename))
+	}
+
+	if len(records) < 2 {
+		return nil, fmt.Errorf("file must have a header row and at least one product row")
+	}
+	if len(records)-1 > MaxImportRows {
+		return nil, fmt.Errorf("file has %d rows, the limit is %d per upload", len(records)-1, MaxImportRows)
+	}
+
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	if !contains(header, ImportColumnName) && !contains(header, ImportColumnSKU) && !contains(header, ImportColumnSlug) {
+		return nil, fmt.Errorf("header must contain at least one of %q, %q or %q", ImportColumnName, ImportColumnSKU, ImportColumnSlug)
+	}
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, h := range header {
+			if i < len(record) {
+				row[h] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// newProductFromImportRow validates a sheet row and converts it into a create/update request.
+func newProductFromImportRow(row map[string]string, categories []*Category) (*NewProduct, error) {
+	req := &NewProduct{
+		Name:        row[ImportColumnName],
+		Description: row[ImportColumnDescription],
+		SKU:         row[ImportColumnSKU],
+		File:        row[ImportColumnFile],
+	}
+
+	if row[ImportColumnSKU] == "" && row[ImportColumnSlug] == "" && req.Name == "" {
+		return nil, fmt.Errorf("row needs a sku, slug or name")
+	}
+
+	var err error
+	if req.Price, err = parseImportFloat(row, ImportColumnPrice); err != nil {
+		return nil, err
+	}
+	if req.Discount, err = parseImportFloat(row, ImportColumnDiscount); err != nil {
+		return nil, err
+	}
+	if req.Price < 0 || req.Discount < 0 {
+		return nil, fmt.Errorf("price and discount cannot be negative")
+	}
+	if req.Price > 0 && req.Discount > req.Price {
+		return nil, fmt.Errorf("discount cannot exceed price")
+	}
+
+	if v := row[ImportColumnQuantity]; v != "" {
+		if req.Quantity, err = strconv.Atoi(v); err != nil || req.Quantity < 0 {
+			return nil, fmt.Errorf("quantity %q is not a valid whole number", v)
+		}
+	}
+	if req.AlwaysAvailbale, err = parseImportBool(row, ImportColumnAlwaysAvailable, false); err != nil {
+		return nil, err
+	}
+	status, err := parseImportBool(row, ImportColumnStatus, true)
+	if err != nil {
+		return nil, err
+	}
+	req.Status = &status
+
+	if v := row[ImportColumnCategory]; v != "" {
+		category := matchCategory(categories, v)
+		if category == nil {
+			return nil, fmt.Errorf("category %q does not exist", v)
+		}
+		req.CategoryID = uint32(category.ID)
+
+		if sub := row[ImportColumnSubcategory]; sub != "" {
+			node := findCategory(category, sub)
+			if node == nil {
+				return nil, fmt.Errorf("subcategory %q not found in category %q. Available subcategories: %v",
+					sub, category.Name, getSubcategoryNames(category.SubCategories))
+			}
+			req.SubCategoryName = node.Name
+		}
+	}
+	if v := row[ImportColumnAttributes]; v != "" {
+		if req.Attributes, err = parseImportAttributes(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := row[ImportColumnThumbnail]; v != "" {
+		if req.Thumbnail, err = resolveImageURL(v); err != nil {
+			return nil, err
+		}
+	}
+	if v := row[ImportColumnImages]; v != "" {
+		for _, raw := range strings.Split(v, "|") {
+			if strings.TrimSpace(raw) == "" {
+				continue
+			}
+			image, err := resolveImageURL(raw)
+			if err != nil {
+				return nil, err
+			}
+			req.Images = append(req.Images, image)
+		}
+	}
+	if req.Thumbnail == "" && len(req.Images) > 0 {
+		req.Thumbnail = req.Images[0]
+	}
+
+	return req, nil
+}
+
+// validateNewImportRow checks the fields a row must carry to create a product, as opposed to updating one.
+func validateNewImportRow(req *NewProduct) error {
+	switch {
+	case req.Name == "":
+		return fmt.Errorf("name is required for new products")
+	case req.Price <= 0:
+		return fmt.Errorf("price is required for new products")
+	case req.CategoryID == 0:
+		return fmt.Errorf("category is required for new products")
+	}
+	return nil
+}
+
+func matchCategory(categories []*Category, value string) *Category {
+	id, idErr := strconv.Atoi(value)
+	for _, c := range categories {
+		if (idErr == nil && c.ID == id) || strings.EqualFold(c.Name, value) || strings.EqualFold(c.Slug, value) {
+			return c
+		}
+	}
+	return nil
+}
+
+// resolveImageURL accepts absolute image URLs as they are and turns the names of files
+// previously sent to the upload endpoint into their download URL.
+func resolveImageURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return "", fmt.Errorf("invalid image URL %q", raw)
+		}
+		return raw, nil
+	}
+
+	name := filepath.Base(raw)
+	if _, err := os.Stat(filepath.Join(uploadDir, name)); err != nil {
+		return "", fmt.Errorf("image %q is neither a URL nor an uploaded file", raw)
+	}
+	return strings.TrimRight(os.Getenv("DOMAIN"), "/") + "/download/" + name, nil
+}
+
+func parseImportFloat(row map[string]string, column string) (float64, error) {
+	v := strings.ReplaceAll(row[column], ",", "")
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not a valid number", column, row[column])
+	}
+	return f, nil
+}
+
+func parseImportBool(row map[string]string, column string, fallback bool) (bool, error) {
+	switch strings.ToLower(row[column]) {
+	case "":
+		return fallback, nil
+	case "true", "yes", "1", "y":
+		return true, nil
+	case "false", "no", "0", "n":
+		return false, nil
+	}
+	return false, fmt.Errorf("%s %q must be true or false", column, row[column])
+}
+
+// parseImportAttributes reads attribute values written as "size=M; colour=Red".
+func parseImportAttributes(v string) (map[string]string, error) {
+	attributes := map[string]string{}
+	for _, pair := range strings.Split(v, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("attribute %q must be written as name=value", strings.TrimSpace(pair))
+		}
+		attributes[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return attributes, nil
+}
+
+func formatImportAttributes(attributes ProductAttributes) string {
+	pairs := make([]string, 0, len(attributes))
+	for name, value := range attributes {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "; ")
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCatalog writes a store's products in the import sheet layout as CSV or XLSX.
+func WriteCatalog(w io.Writer, format string, products []*Product) error {
+	records := [][]string{catalogColumns}
+	for _, p := range products {
+		records = append(records, []string{
+			p.SKU,
+			p.Slug,
+			p.Name,
+			p.Description,
+			strconv.FormatFloat(p.Price, 'f', 2, 64),
+			strconv.FormatFloat(p.Discount, 'f', 2, 64),
+			strconv.Itoa(p.Quantity),
+			strconv.FormatBool(p.AlwaysAvailbale),
+			strconv.FormatBool(p.Status),
+			p.Category,
+			p.Subcategory,
+			formatImportAttributes(p.Attributes),
+			p.Thumbnail,
+			strings.Join(p.Images, "|"),
+			p.File,
+			strconv.Itoa(p.UnitsSold),
+		})
+	}
+
+	switch format {
+	case "csv":
+		for _, record := range records[1:] {
+			for i := range record {
+				record[i] = escapeFormula(record[i])
+			}
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(records); err != nil {
+			return fmt.Errorf("failed to write CSV: %v", err)
+		}
+		return nil
+	case "xlsx":
+		f := excelize.NewFile()
+		defer f.Close()
+		sheet := f.GetSheetName(0)
+		for i, record := range records {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(sheet, cell, &record); err != nil {
+				return fmt.Errorf("failed to write XLSX row: %v", err)
+			}
+		}
+		return f.Write(w)
+	}
+	return fmt.Errorf("unsupported export format %q, use csv or xlsx", format)
+}
+
+// formulaPrefixes are the characters that make a spreadsheet app run a CSV
+// cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula quotes a CSV cell that a spreadsheet app would otherwise run
+// as a formula, like a product named "=HYPERLINK(...)". Numbers are left as
+// they are.
+func escapeFormula(v string) string {
+	if v == "" || !strings.ContainsAny(v[:1], formulaPrefixes) {
+		return v
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	return "'" + v
+}
+
+// unescapeFormula undoes escapeFormula, so an exported sheet imports as it
+// was.
+func unescapeFormula(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsAny(v[1:2], formulaPrefixes) {
+		return v[1:]
+	}
+	return v
+}
@@ -0,0 +1,66 @@
+package product
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: ""},
+		{in: "Jollof rice", want: "Jollof rice"},
+		{in: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{in: "+2348012345678 call", want: "'+2348012345678 call"},
+		{in: "-cmd|' /C calc'!A0", want: "'-cmd|' /C calc'!A0"},
+		{in: "@SUM(A1:A2)", want: "'@SUM(A1:A2)"},
+		{in: "\t=1+1", want: "'\t=1+1"},
+		{in: "-3", want: "-3"},
+		{in: "+1.50", want: "+1.50"},
+		{in: "a=b", want: "a=b"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := unescapeFormula(escapeFormula(tt.in)); got != tt.in {
+			t.Errorf("unescapeFormula(escapeFormula(%q)) = %q", tt.in, got)
+		}
+	}
+}
+
+func TestWriteCatalogCSVEscapesFormulas(t *testing.T) {
+	products := []*Product{{
+		SKU:         "SKU-1",
+		Name:        "=HYPERLINK(\"http://evil\",\"Click\")",
+		Description: "@import",
+		Price:       2500,
+		Quantity:    4,
+		Category:    "Food",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteCatalog(&buf, "csv", products); err != nil {
+		t.Fatalf("WriteCatalog: %v", err)
+	}
+	if strings.Contains(buf.String(), ",=HYPERLINK") || strings.Contains(buf.String(), ",@import") {
+		t.Errorf("CSV export has cells starting a formula:\n%s", buf.String())
+	}
+
+	rows, err := ParseImportFile("catalog.csv", &buf)
+	if err != nil {
+		t.Fatalf("ParseImportFile: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	if got := rows[0][ImportColumnName]; got != products[0].Name {
+		t.Errorf("name imported as %q, want %q", got, products[0].Name)
+	}
+	if got := rows[0][ImportColumnDescription]; got != products[0].Description {
+		t.Errorf("description imported as %q, want %q", got, products[0].Description)
+	}
+}
@@ -0,0 +1,153 @@
+package product
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// defaultBannedKeywords are always flagged; MODERATION_BANNED_KEYWORDS adds
+// more as a comma separated list.
+var defaultBannedKeywords = []string{
+	"counterfeit",
+	"replica",
+	"fake id",
+	"exam leak",
+	"weed",
+	"firearm",
+}
+
+const (
+	// suspiciousPriceMinSample is how many approved products a category needs
+	// before prices are compared against its average.
+	suspiciousPriceMinSample = 5
+	// Prices below or above these multiples of the category average are flagged.
+	suspiciousPriceLowRatio  = 0.2
+	suspiciousPriceHighRatio = 10
+	// Discounts at or above this fraction of the price are flagged.
+	suspiciousDiscountRatio = 0.9
+)
+
+type unmoderatedKey struct{}
+
+// WithUnmoderated marks ctx so that store listings include pending and rejected
+// products. It is meant for the store owner's own view of their products.
+func WithUnmoderated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unmoderatedKey{}, true)
+}
+
+func includeUnmoderated(ctx context.Context) bool {
+	v, _ := ctx.Value(unmoderatedKey{}).(bool)
+	return v
+}
+
+func bannedKeywords() []string {
+	keywords := append([]string{}, defaultBannedKeywords...)
+	for _, k := range strings.Split(os.Getenv("MODERATION_BANNED_KEYWORDS"), ",") {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			keywords = append(keywords, k)
+		}
+	}
+	return keywords
+}
+
+// matchBannedKeywords returns the banned keywords that appear as whole words in text.
+func matchBannedKeywords(text string) []string {
+	var matched []string
+	text = strings.ToLower(text)
+	for _, keyword := range bannedKeywords() {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(keyword) + `\b`).MatchString(text) {
+			matched = append(matched, keyword)
+		}
+	}
+	return matched
+}
+
+// moderationFlags runs the automatic checks on a product and returns a flag for
+// every check it fails.
+func (r *repository) moderationFlags(ctx context.Context, p *Product) []string {
+	var flags []string
+
+	for _, keyword := range matchBannedKeywords(p.Name + " " + p.Description) {
+		flags = append(flags, "banned_keyword:"+keyword)
+	}
+
+	if p.Price > 0 && p.Discount >= p.Price*suspiciousDiscountRatio {
+		flags = append(flags, "suspicious_discount")
+	}
+
+	var stats struct {
+		Average float64
+		Total   int64
+	}
+	err := r.db.WithContext(ctx).Table("products").
+		Select("COALESCE(AVG(price), 0) AS average, COUNT(*) AS total").
+		Where("category = ? AND moderation_status = ? AND deleted_at IS NULL", p.Category, ModerationApproved).
+		Where("id <> ?", p.ID).
+		Scan(&stats).Error
+	if err == nil && stats.Total >= suspiciousPriceMinSample && stats.Average > 0 {
+		if p.Price < stats.Average*suspiciousPriceLowRatio || p.Price > stats.Average*suspiciousPriceHighRatio {
+			flags = append(flags, "suspicious_price")
+		}
+	}
+
+	return flags
+}
+
+func (r *repository) isTrustedStore(ctx context.Context, storeName string) bool {
+	var trusted bool
+	err := r.db.WithContext(ctx).Table("stores").
+		Select("trusted").
+		Where("name = ? AND deleted_at IS NULL", storeName).
+		Limit(1).
+		Scan(&trusted).Error
+	return err == nil && trusted
+}
+
+// moderate decides the moderation state of a product about to be saved. Trusted
+// stores skip the queue unless an automatic check flags the product. The
+// returned flags are nil when the product goes live straight away.
+func (r *repository) moderate(ctx context.Context, p *Product) (flags []string, queued bool) {
+	flags = r.moderationFlags(ctx, p)
+	if len(flags) == 0 && r.isTrustedStore(ctx, p.Store) {
+		p.ModerationStatus = ModerationApproved
+		p.ModerationReason = ""
+		return nil, false
+	}
+	p.ModerationStatus = ModerationPending
+	p.ModerationReason = ""
+	return flags, true
+}
+
+// enqueueModeration opens a queue entry for a product, or refreshes the one
+// already waiting for review.
+func enqueueModeration(tx *gorm.DB, p *Product, trigger string, flags []string) error {
+	if flags == nil {
+		flags = []string{}
+	}
+
+	var entry ProductModeration
+	err := tx.Where("product_id = ? AND status = ?", p.ID, ModerationPending).First(&entry).Error
+	if err == nil {
+		return tx.Model(&entry).Updates(map[string]interface{}{
+			"trigger": trigger,
+			"flags":   pq.StringArray(flags),
+			"store":   p.Store,
+		}).Error
+	}
+	if err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	return tx.Create(&ProductModeration{
+		ProductID: p.ID,
+		Store:     p.Store,
+		Trigger:   trigger,
+		Flags:     flags,
+		Status:    ModerationPending,
+	}).Error
+}
@@ -0,0 +1,81 @@
+package product
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"gorm.io/gorm"
+)
+
+// priceWatchTypes are the HandledProduct lists whose owners get price drop alerts.
+var priceWatchTypes = []string{"wishlists", "savedItems", "savedForLater"}
+
+func recordPrice(tx *gorm.DB, p *Product) error {
+	return tx.Create(&ProductPriceHistory{
+		ProductID: p.ID,
+		Price:     p.Price,
+		Discount:  p.Discount,
+	}).Error
+}
+
+func (r *repository) GetPriceHistory(ctx context.Context, productId uint32) ([]*ProductPriceHistory, error) {
+	var history []*ProductPriceHistory
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productId).
+		Order("created_at ASC").
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// NotifyPriceDrops alerts users who wishlisted or saved a product when its
+// current price is below what it was when they saved it. Each drop is alerted
+// once; a user is only alerted again if the price falls further. It returns
+// the number of users alerted.
+func (r *repository) NotifyPriceDrops(ctx context.Context, productId uint32) (int, error) {
+	var p Product
+	if err := r.db.WithContext(ctx).Where("id = ?", productId).First(&p).Error; err != nil {
+		return 0, err
+	}
+	if p.ModerationStatus != ModerationApproved {
+		return 0, nil
+	}
+	price := p.EffectivePrice()
+
+	var watchers []*HandledProduct
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND type IN ? AND saved_price > ?", productId, priceWatchTypes, price).
+		Where("alerted_price = 0 OR alerted_price > ?", price).
+		Find(&watchers).Error
+	if err != nil {
+		return 0, err
+	}
+
+	notifier := notification.NewService(notification.NewRepository())
+	alerted := map[uint32]bool{}
+	for _, w := range watchers {
+		if !alerted[w.UserID] {
+			var byEmail bool
+			r.db.WithContext(ctx).Table("users").Select("price_drop_email").Where("id = ?", w.UserID).Scan(&byEmail)
+
+			_, err := notifier.Notify(ctx, &notification.Notification{
+				UserID:  w.UserID,
+				Type:    notification.TypePriceDrop,
+				Title:   fmt.Sprintf("Price drop on %s", p.Name),
+				Message: fmt.Sprintf("%s is now ₦%.2f, down from ₦%.2f when you saved it.", p.Name, price, w.SavedPrice),
+				Link:    fmt.Sprintf("/product/%d", p.ID),
+			}, byEmail)
+			if err != nil {
+				return len(alerted), err
+			}
+			alerted[w.UserID] = true
+		}
+		if err := r.db.WithContext(ctx).Model(w).Update("alerted_price", price).Error; err != nil {
+			return len(alerted), err
+		}
+	}
+	return len(alerted), nil
+}
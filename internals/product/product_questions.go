@@ -0,0 +1,369 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"gorm.io/gorm"
+)
+
+// Q&A post states. Posts that trip the banned keyword check wait in pending
+// until an admin approves them.
+const (
+	QAPublished = "published"
+	QAPending   = "pending"
+	QAHidden    = "hidden"
+)
+
+// Kinds of Q&A posts, used for votes and moderation.
+const (
+	QAKindQuestion = "question"
+	QAKindAnswer   = "answer"
+)
+
+const maxQAPostLength = 1000
+
+// ProductQuestion is a public question about a product.
+type ProductQuestion struct {
+	ID          uint32           `json:"id" gorm:"primaryKey"`
+	ProductID   uint32           `json:"product_id" gorm:"index"`
+	UserID      uint32           `json:"user_id" gorm:"index"`
+	Author      string           `json:"author"`
+	Body        string           `json:"body"`
+	Status      string           `json:"status" gorm:"index"`
+	Upvotes     int              `json:"upvotes"`
+	AnswerCount int              `json:"answer_count"` // published answers only
+	Answers     []*ProductAnswer `json:"answers" gorm:"foreignKey:QuestionID"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// ProductAnswer is an answer to a product question, from the store owner or a
+// buyer who has paid for the product.
+type ProductAnswer struct {
+	ID            uint32    `json:"id" gorm:"primaryKey"`
+	QuestionID    uint32    `json:"question_id" gorm:"index"`
+	UserID        uint32    `json:"user_id" gorm:"index"`
+	Author        string    `json:"author"`
+	Body          string    `json:"body"`
+	IsSeller      bool      `json:"is_seller"`
+	VerifiedBuyer bool      `json:"verified_buyer"`
+	Status        string    `json:"status" gorm:"index"`
+	Upvotes       int       `json:"upvotes"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// QAVote is one user's upvote on a question or answer.
+type QAVote struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	UserID    uint32    `json:"user_id" gorm:"uniqueIndex:idx_qa_votes_user_target"`
+	Kind      string    `json:"kind" gorm:"uniqueIndex:idx_qa_votes_user_target"`
+	TargetID  uint32    `json:"target_id" gorm:"uniqueIndex:idx_qa_votes_user_target"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// qaStatus publishes a post unless it contains a banned keyword.
+func qaStatus(body string) string {
+	if len(matchBannedKeywords(body)) > 0 {
+		return QAPending
+	}
+	return QAPublished
+}
+
+func validateQABody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Text cannot be empty")
+	}
+	if len(body) > maxQAPostLength {
+		return "", errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Text cannot be longer than %d characters", maxQAPostLength))
+	}
+	return body, nil
+}
+
+func (r *repository) authorName(ctx context.Context, userId uint32) string {
+	var name string
+	r.db.WithContext(ctx).Table("users").Select("fullname").Where("id = ?", userId).Scan(&name)
+	return name
+}
+
+func (r *repository) storeOwnerID(ctx context.Context, storeName string) uint32 {
+	var ownerID uint32
+	r.db.WithContext(ctx).Table("stores").Select("user_id").Where("name = ? AND deleted_at IS NULL", storeName).Limit(1).Scan(&ownerID)
+	return ownerID
+}
+
+// hasPurchased reports whether the user has a paid order containing the product.
+func (r *repository) hasPurchased(ctx context.Context, userId, productId uint32) bool {
+	var lines []string
+	err := r.db.WithContext(ctx).Table("orders").
+		Select("products").
+		Where("user_id = ? AND deleted_at IS NULL", fmt.Sprint(userId)).
+		Where("status NOT IN ?", []string{"not completed", "canceled"}).
+		Pluck("products", &lines).Error
+	if err != nil {
+		return false
+	}
+	for _, raw := range lines {
+		var items []struct {
+			ID uint32 `json:"id"`
+		}
+		if json.Unmarshal([]byte(raw), &items) != nil {
+			continue
+		}
+		for _, item := range items {
+			if item.ID == productId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *repository) AskQuestion(ctx context.Context, q *ProductQuestion) (*ProductQuestion, error) {
+	body, err := validateQABody(q.Body)
+	if err != nil {
+		return nil, err
+	}
+	var p Product
+	if err := r.db.WithContext(ctx).Where("id = ?", q.ProductID).First(&p).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+
+	q.Body = body
+	q.Author = r.authorName(ctx, q.UserID)
+	q.Status = qaStatus(body)
+	if err := r.db.WithContext(ctx).Create(q).Error; err != nil {
+		return nil, err
+	}
+	if q.Status == QAPublished {
+		r.notifyQuestionAsked(ctx, &p, q)
+	}
+	return q, nil
+}
+
+func (r *repository) AnswerQuestion(ctx context.Context, a *ProductAnswer) (*ProductAnswer, error) {
+	body, err := validateQABody(a.Body)
+	if err != nil {
+		return nil, err
+	}
+	var q ProductQuestion
+	if err := r.db.WithContext(ctx).Where("id = ? AND status = ?", a.QuestionID, QAPublished).First(&q).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Question not found")
+	}
+	var p Product
+	if err := r.db.WithContext(ctx).Where("id = ?", q.ProductID).First(&p).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+
+	a.IsSeller = r.storeOwnerID(ctx, p.Store) == a.UserID
+	a.VerifiedBuyer = !a.IsSeller && r.hasPurchased(ctx, a.UserID, p.ID)
+	if !a.IsSeller && !a.VerifiedBuyer {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "Only the seller or buyers of this product can answer")
+	}
+
+	a.Body = body
+	a.Author = r.authorName(ctx, a.UserID)
+	a.Status = qaStatus(body)
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		if a.Status == QAPublished {
+			return tx.Model(&ProductQuestion{}).Where("id = ?", q.ID).
+				UpdateColumn("answer_count", gorm.Expr("answer_count + 1")).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if a.Status == QAPublished {
+		r.notifyQuestionAnswered(ctx, &p, &q, a)
+	}
+	return a, nil
+}
+
+// GetProductQuestions lists the published questions of a product with their
+// published answers, most upvoted first.
+func (r *repository) GetProductQuestions(ctx context.Context, productId uint32, answeredOnly bool, limit, offset int) ([]*ProductQuestion, error) {
+	var questions []*ProductQuestion
+	query := r.db.WithContext(ctx).
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", QAPublished).Order("is_seller DESC, upvotes DESC, created_at ASC")
+		}).
+		Where("product_id = ? AND status = ?", productId, QAPublished)
+	if answeredOnly {
+		query = query.Where("answer_count > 0")
+	}
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+	if err := query.Order("upvotes DESC, created_at DESC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// UpvoteQA records the user's upvote on a question or answer and returns its
+// new upvote count. Voting twice has no effect.
+func (r *repository) UpvoteQA(ctx context.Context, userId uint32, kind string, id uint32) (int, error) {
+	var target interface{}
+	switch kind {
+	case QAKindQuestion:
+		target = &ProductQuestion{}
+	case QAKindAnswer:
+		target = &ProductAnswer{}
+	default:
+		return 0, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Kind must be question or answer")
+	}
+
+	var upvotes int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(target).Where("id = ? AND status = ?", id, QAPublished).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Post not found")
+		}
+
+		result := tx.Where(QAVote{UserID: userId, Kind: kind, TargetID: id}).FirstOrCreate(&QAVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(target).Where("id = ?", id).UpdateColumn("upvotes", gorm.Expr("upvotes + 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(target).Where("id = ?", id).Select("upvotes").Scan(&upvotes).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return upvotes, nil
+}
+
+// ModerateQA publishes or hides a question or answer. Publishing a held post
+// sends the notifications it skipped when it was created.
+func (r *repository) ModerateQA(ctx context.Context, kind string, id uint32, approve bool) error {
+	status := QAHidden
+	if approve {
+		status = QAPublished
+	}
+
+	switch kind {
+	case QAKindQuestion:
+		var q ProductQuestion
+		if err := r.db.WithContext(ctx).Where("id = ?", id).First(&q).Error; err != nil {
+			return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Question not found")
+		}
+		if err := r.db.WithContext(ctx).Model(&q).Update("status", status).Error; err != nil {
+			return err
+		}
+		if q.Status == QAPending && status == QAPublished {
+			var p Product
+			if err := r.db.WithContext(ctx).Where("id = ?", q.ProductID).First(&p).Error; err == nil {
+				r.notifyQuestionAsked(ctx, &p, &q)
+			}
+		}
+		return nil
+
+	case QAKindAnswer:
+		var a ProductAnswer
+		if err := r.db.WithContext(ctx).Where("id = ?", id).First(&a).Error; err != nil {
+			return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Answer not found")
+		}
+		if a.Status == status {
+			return nil
+		}
+		delta := 0
+		if status == QAPublished {
+			delta = 1
+		} else if a.Status == QAPublished {
+			delta = -1
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&a).Update("status", status).Error; err != nil {
+				return err
+			}
+			if delta != 0 {
+				return tx.Model(&ProductQuestion{}).Where("id = ?", a.QuestionID).
+					UpdateColumn("answer_count", gorm.Expr("answer_count + ?", delta)).Error
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if a.Status == QAPending && status == QAPublished {
+			var q ProductQuestion
+			var p Product
+			if r.db.WithContext(ctx).Where("id = ?", a.QuestionID).First(&q).Error == nil &&
+				r.db.WithContext(ctx).Where("id = ?", q.ProductID).First(&p).Error == nil {
+				r.notifyQuestionAnswered(ctx, &p, &q, &a)
+			}
+		}
+		return nil
+
+	default:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Kind must be question or answer")
+	}
+}
+
+// GetQAModerationQueue lists the questions and answers held for review, oldest first.
+func (r *repository) GetQAModerationQueue(ctx context.Context) ([]*ProductQuestion, []*ProductAnswer, error) {
+	var questions []*ProductQuestion
+	if err := r.db.WithContext(ctx).Where("status = ?", QAPending).Order("created_at ASC").Find(&questions).Error; err != nil {
+		return nil, nil, err
+	}
+	var answers []*ProductAnswer
+	if err := r.db.WithContext(ctx).Where("status = ?", QAPending).Order("created_at ASC").Find(&answers).Error; err != nil {
+		return nil, nil, err
+	}
+	return questions, answers, nil
+}
+
+func (r *repository) notifyQuestionAsked(ctx context.Context, p *Product, q *ProductQuestion) {
+	ownerID := r.storeOwnerID(ctx, p.Store)
+	if ownerID == 0 {
+		return
+	}
+	notifier := notification.NewService(notification.NewRepository())
+	_, err := notifier.Notify(ctx, &notification.Notification{
+		UserID:  ownerID,
+		Type:    notification.TypeProductQuestion,
+		Title:   fmt.Sprintf("New question about %s", p.Name),
+		Message: q.Body,
+		Link:    fmt.Sprintf("/product/%d#question-%d", p.ID, q.ID),
+	}, true)
+	if err != nil {
+		log.Printf("failed to notify store %q of question %d: %v", p.Store, q.ID, err)
+	}
+}
+
+func (r *repository) notifyQuestionAnswered(ctx context.Context, p *Product, q *ProductQuestion, a *ProductAnswer) {
+	if q.UserID == a.UserID {
+		return
+	}
+	notifier := notification.NewService(notification.NewRepository())
+	_, err := notifier.Notify(ctx, &notification.Notification{
+		UserID:  q.UserID,
+		Type:    notification.TypeProductQuestion,
+		Title:   fmt.Sprintf("Your question about %s was answered", p.Name),
+		Message: a.Body,
+		Link:    fmt.Sprintf("/product/%d#question-%d", p.ID, q.ID),
+	}, false)
+	if err != nil {
+		log.Printf("failed to notify user %d of answer %d: %v", q.UserID, a.ID, err)
+	}
+}
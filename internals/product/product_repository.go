@@ -8,12 +8,15 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/samstringzz/alutamarket-backend/database"
 	"github.com/samstringzz/alutamarket-backend/errors"
 	"github.com/samstringzz/alutamarket-backend/graph/model"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
 	"github.com/samstringzz/alutamarket-backend/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -27,34 +30,66 @@ func NewRepository() Repository {
 }
 
 func (r *repository) CreateCategory(ctx context.Context, req *Category) (*Category, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Category name is required")
+	}
+
+	newCategory := &Category{
+		Name:     req.Name,
+		Slug:     utils.GenerateSlug(req.Name),
+		Type:     req.Type,
+		ParentID: req.ParentID,
+	}
 
 	var count int64
-	r.db.Model(&Category{}).Where("name =?", req.Name).Count(&count)
+	siblings := r.db.Model(&Category{}).Where("LOWER(name) = LOWER(?)", req.Name)
+	if req.ParentID != nil {
+		parent, err := r.GetCategory(ctx, *req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent category: %v", err)
+		}
+		newCategory.Slug = parent.Slug + "-" + newCategory.Slug
+		newCategory.Depth = parent.Depth + 1
+		if newCategory.Type == "" {
+			newCategory.Type = parent.Type
+		}
+		siblings = siblings.Where("parent_id = ?", *req.ParentID)
+	} else {
+		siblings = siblings.Where("parent_id IS NULL")
+	}
+	siblings.Count(&count)
 	if count > 0 {
 		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Category already exist")
 	}
-	newCategory := &Category{
-		Name: req.Name,
-		Slug: utils.GenerateSlug(req.Name),
-	}
-	if err := r.db.Create(newCategory).Error; err != nil {
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newCategory).Error; err != nil {
+			return err
+		}
+		newCategory.Path = strconv.Itoa(newCategory.ID)
+		if req.ParentID != nil {
+			var parentPath string
+			if err := tx.Model(&Category{}).Select("path").Where("id = ?", *req.ParentID).Scan(&parentPath).Error; err != nil {
+				return err
+			}
+			newCategory.Path = parentPath + "/" + newCategory.Path
+		}
+		return tx.Model(newCategory).Update("path", newCategory.Path).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return newCategory, nil
+	return r.GetCategory(ctx, uint32(newCategory.ID))
 }
 
+// CreateSubCategory adds a child to a category and returns the parent.
 func (r *repository) CreateSubCategory(ctx context.Context, req SubCategory) (*Category, error) {
-
-	category, err := r.GetCategory(ctx, req.CategoryID)
-	if err != nil {
-		return nil, err
-	}
-	req.Slug = utils.GenerateSlug(req.Name)
-	category.SubCategories = append(category.SubCategories, req)
-	if err := r.db.Save(&category).Error; err != nil {
+	parentID := req.CategoryID
+	if _, err := r.CreateCategory(ctx, &Category{Name: req.Name, ParentID: &parentID}); err != nil {
 		return nil, err
 	}
-	return category, nil
+	return r.GetCategory(ctx, req.CategoryID)
 }
 
 func (r *repository) GetProduct(ctx context.Context, id, user uint32) (*Product, error) {
@@ -78,32 +113,9 @@ func (r *repository) GetProduct(ctx context.Context, id, user uint32) (*Product,
 }
 
 func (r *repository) CreateProduct(ctx context.Context, req *NewProduct) (*Product, error) {
-	category, err := r.GetCategory(ctx, uint32(req.CategoryID))
+	node, root, err := r.resolveProductCategory(ctx, req.CategoryID, req.SubCategoryName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get category: %v", err)
-	}
-
-	if category == nil {
-		return nil, fmt.Errorf("category with ID %d not found", req.CategoryID)
-	}
-
-	// Verify subcategory exists in the category (check both name and slug)
-	subcategoryExists := false
-	for _, sub := range category.SubCategories {
-		// Compare both name and slug case-insensitively
-		if strings.EqualFold(sub.Name, req.SubCategoryName) ||
-			strings.EqualFold(sub.Slug, req.SubCategoryName) {
-			subcategoryExists = true
-			// Use the correct name from the database
-			req.SubCategoryName = sub.Name
-			break
-		}
-	}
-
-	if !subcategoryExists {
-		return nil, fmt.Errorf("subcategory '%s' not found in category. Available subcategories: %v",
-			req.SubCategoryName,
-			getSubcategoryNames(category.SubCategories))
+		return nil, err
 	}
 
 	// Ensure images array is not nil
@@ -118,9 +130,12 @@ func (r *repository) CreateProduct(ctx context.Context, req *NewProduct) (*Produ
 	if req.Discount > req.Price {
 		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Product Discount cannot exceed Product Price")
 	}
+	if err := kyc.CheckListing(r.db.WithContext(ctx), req.Store); err != nil {
+		return nil, err
+	}
 	newProduct := &Product{
 		Name:            req.Name,
-		Slug:            utils.GenerateSlug(req.Name),
+		SKU:             req.SKU,
 		Description:     req.Description,
 		Images:          req.Images,
 		Thumbnail:       req.Thumbnail,
@@ -131,12 +146,27 @@ func (r *repository) CreateProduct(ctx context.Context, req *NewProduct) (*Produ
 		File:            req.File,
 		Variant:         req.Variant,
 		Store:           req.Store,
-		Category:        category.Name,
-		Subcategory:     req.SubCategoryName,
 		AlwaysAvailbale: req.AlwaysAvailbale,
 	}
-	if err := r.db.Create(newProduct).Error; err != nil {
-
+	if err := applyCategory(newProduct, node, root, req.Attributes); err != nil {
+		return nil, err
+	}
+	flags, queued := r.moderate(ctx, newProduct)
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveWithSlug(tx, newProduct, "", "", func(tx *gorm.DB) error {
+			return tx.Create(newProduct).Error
+		}); err != nil {
+			return err
+		}
+		if err := recordPrice(tx, newProduct); err != nil {
+			return err
+		}
+		if queued {
+			return enqueueModeration(tx, newProduct, "create", flags)
+		}
+		return nil
+	})
+	if err != nil {
 		log.Printf("Error creating product: %v", err)
 		return nil, err
 	}
@@ -164,10 +194,19 @@ func (r *repository) UpdateProduct(ctx context.Context, req *NewProduct) (*Produ
 		return nil, err
 	}
 
+	previousPrice, previousDiscount := existingProduct.Price, existingProduct.Discount
+	previousSlug, previousStore := existingProduct.Slug, existingProduct.Store
+	previousQuantity, previousAlwaysAvailable := existingProduct.Quantity, existingProduct.AlwaysAvailbale
+	wasSoldOut := existingProduct.IsSoldOut()
+
+	// Edits to anything a buyer sees send the product back for review
+	needsReview := req.Name != "" || req.Description != "" || len(req.Images) > 0 ||
+		req.Thumbnail != "" || req.Price != 0 || req.Discount != 0 || req.File != "" ||
+		req.CategoryID != 0 || req.SubCategoryName != "" || req.Attributes != nil
+
 	// Update all provided fields
 	if req.Name != "" {
 		existingProduct.Name = req.Name
-		existingProduct.Slug = utils.GenerateSlug(req.Name)
 	}
 	if req.Description != "" {
 		existingProduct.Description = req.Description
@@ -191,7 +230,8 @@ func (r *repository) UpdateProduct(ctx context.Context, req *NewProduct) (*Produ
 	if req.Status != nil {
 		existingProduct.Status = *req.Status
 	}
-	if req.Quantity != 0 {
+	// A bundle's stock follows its components
+	if req.Quantity != 0 && !existingProduct.IsBundle {
 		existingProduct.Quantity = req.Quantity
 	}
 	if req.File != "" {
@@ -200,38 +240,112 @@ func (r *repository) UpdateProduct(ctx context.Context, req *NewProduct) (*Produ
 	if req.Store != "" {
 		existingProduct.Store = req.Store
 	}
-	if req.CategoryID != 0 {
-		// Get category to validate and get its name
-		category, err := r.GetCategory(ctx, uint32(req.CategoryID))
+	if req.CategoryID != 0 || req.SubCategoryName != "" || req.Attributes != nil {
+		categoryID := req.CategoryID
+		if categoryID == 0 && req.SubCategoryName != "" {
+			// A new subcategory alone is looked up under the product's top level category
+			byID, _, err := r.categoryTree(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if current, ok := byID[existingProduct.CategoryID]; ok {
+				categoryID = uint32(rootOf(byID, current).ID)
+			}
+		} else if categoryID == 0 {
+			categoryID = existingProduct.CategoryID
+		}
+		if categoryID == 0 {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Product has no category, set one first")
+		}
+
+		node, root, err := r.resolveProductCategory(ctx, categoryID, req.SubCategoryName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get category: %v", err)
+			return nil, err
+		}
+		values := req.Attributes
+		if values == nil {
+			values = keepSchemaAttributes(node.Attributes, existingProduct.Attributes)
+		}
+		if err := applyCategory(&existingProduct, node, root, values); err != nil {
+			return nil, err
 		}
-		existingProduct.Category = category.Name
 	}
-	if req.SubCategoryName != "" {
-		existingProduct.Subcategory = req.SubCategoryName
+	if req.SKU != "" {
+		existingProduct.SKU = req.SKU
+	}
+
+	priceChanged := existingProduct.Price != previousPrice || existingProduct.Discount != previousDiscount
+
+	var flags []string
+	queued := false
+	if needsReview {
+		flags, queued = r.moderate(ctx, &existingProduct)
 	}
 
 	// Update the existing record with all changes
-	if err := r.db.WithContext(ctx).Model(&existingProduct).Where("id = ?", idUint32).Updates(&existingProduct).Error; err != nil {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		save := func(tx *gorm.DB) error {
+			return tx.Model(&existingProduct).Where("id = ?", idUint32).Updates(&existingProduct).Error
+		}
+		if req.Name != "" || existingProduct.Store != previousStore {
+			if err := saveWithSlug(tx, &existingProduct, previousSlug, previousStore, save); err != nil {
+				return err
+			}
+		} else if err := save(tx); err != nil {
+			return err
+		}
+		if priceChanged {
+			if err := recordPrice(tx, &existingProduct); err != nil {
+				return err
+			}
+		}
+		if existingProduct.Quantity != previousQuantity || existingProduct.AlwaysAvailbale != previousAlwaysAvailable {
+			if err := refreshBundlesContaining(tx, []uint32{existingProduct.ID}); err != nil {
+				return err
+			}
+		}
+		if !needsReview {
+			return nil
+		}
+		// Updates skips zero values, so the cleared reason is written explicitly
+		if err := tx.Model(&Product{}).Where("id = ?", idUint32).Updates(map[string]interface{}{
+			"moderation_status": existingProduct.ModerationStatus,
+			"moderation_reason": "",
+		}).Error; err != nil {
+			return err
+		}
+		if queued {
+			return enqueueModeration(tx, &existingProduct, "update", flags)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update product: %v", err)
 	}
 
-	return &existingProduct, nil
-}
-
-func (r *repository) DeleteProduct(ctx context.Context, id uint32) error {
-	existingProduct, err := r.GetProduct(ctx, id, 0)
-	if err != nil {
-		fmt.Println("Error retrieving product:", err)
-		return fmt.Errorf("error retrieving product: %v", err)
+	// Products held for moderation alert their watchers once approved instead
+	if priceChanged && existingProduct.EffectivePrice() < previousPrice-previousDiscount &&
+		existingProduct.ModerationStatus == ModerationApproved {
+		go func(id uint32) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			if _, err := r.NotifyPriceDrops(ctx, id); err != nil {
+				log.Printf("failed to send price drop alerts for product %d: %v", id, err)
+			}
+		}(existingProduct.ID)
 	}
-	if existingProduct == nil {
-		return fmt.Errorf("product not found")
+
+	if wasSoldOut && !existingProduct.IsSoldOut() && existingProduct.ModerationStatus == ModerationApproved {
+		go func(id uint32) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			if _, err := r.NotifyRestock(ctx, id); err != nil {
+				log.Printf("failed to send back in stock alerts for product %d: %v", id, err)
+			}
+		}(existingProduct.ID)
 	}
-	r.db.Unscoped().Delete(&existingProduct)
 
-	return nil
+	return &existingProduct, nil
 }
 
 func (r *repository) AddHandledProduct(ctx context.Context, userId, productId uint32, eventType string) (*HandledProduct, error) {
@@ -263,6 +377,7 @@ func (r *repository) AddHandledProduct(ctx context.Context, userId, productId ui
 	prd.Product = foundProduct
 	prd.UserID = userId
 	prd.Type = eventType
+	prd.SavedPrice = foundProduct.EffectivePrice()
 	err = r.db.Create(prd).Error
 	if err != nil {
 		return nil, err
@@ -313,10 +428,12 @@ func (r *repository) GetProducts(ctx context.Context, storeName string, category
 		query = query.Where("stores.maintenance_mode = ?", false)
 	}
 
+	if !includeUnmoderated(ctx) {
+		query = query.Where("products.moderation_status = ?", ModerationApproved)
+	}
+
 	if categorySlug != "" {
-		query = query.
-			Joins("JOIN categories ON categories.name = products.category").
-			Where("categories.slug = ?", categorySlug)
+		query = query.Where("products.category_id IN (?)", r.subtreeIDs("slug", categorySlug))
 	} else {
 		// For random ordering, use a different approach
 		query = query.Where("products.status = ?", true)
@@ -349,32 +466,30 @@ func (r *repository) GetProducts(ctx context.Context, storeName string, category
 		if p.Variant == nil {
 			p.Variant = []*VariantType{}
 		}
-		if p.Views == nil {
-			p.Views = []uint32{}
-		}
-		if p.Reviews == nil {
-			p.Reviews = []Review{}
-		}
 	}
 
 	return products, int(totalCount), nil
 }
 
+// GetCategories returns the top level categories with their descendants.
 func (r *repository) GetCategories(ctx context.Context) ([]*Category, error) {
-	var categories []*Category
-	if err := r.db.Preload("SubCategories").Find(&categories).Error; err != nil {
-		return nil, fmt.Errorf("error retrieving categories: %v", err)
+	_, roots, err := r.categoryTree(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return categories, nil
+	return roots, nil
 }
 
 func (r *repository) GetCategory(ctx context.Context, id uint32) (*Category, error) {
-	p := Category{}
-	err := r.db.Preload("SubCategories").Where("id = ?", id).First(&p).Error
+	byID, _, err := r.categoryTree(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
+	category, ok := byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return category, nil
 }
 
 // isValidQuery validates the query string for allowed characters
@@ -383,32 +498,32 @@ func isValidQuery(query string) bool {
 	return match
 }
 
-func filterReviewsBySeller(reviews []*Review, sellerId string) []*Review {
-	var filteredReviews []*Review
-	for _, review := range reviews {
-		if review.Seller == sellerId {
-			filteredReviews = append(filteredReviews, review)
-		}
-	}
-	return filteredReviews
+// sellerScore is the scorecard score of the store joined as "stores". Stores
+// not scored yet get the same neutral 50 as store.NeutralScore.
+const sellerScore = "COALESCE((SELECT sc.score FROM store_scorecards sc WHERE sc.store_id = stores.id), 50)"
+
+// rankBySeller orders the products selected by query, which must include a
+// seller_score column, so better sellers come first.
+func (r *repository) rankBySeller(query *gorm.DB) *gorm.DB {
+	return r.db.Table("(?) AS products", query).Order("seller_score DESC, id")
 }
 
 func (r *repository) SearchProducts(ctx context.Context, query string) ([]*Product, error) {
 	var products []*Product
 
 	// Create base query
-	baseQuery := r.db.Select("DISTINCT ON (products.id) products.*").
+	baseQuery := r.db.Select("DISTINCT ON (products.id) products.*, "+sellerScore+" AS seller_score").
 		Table("products").
 		Joins("LEFT JOIN categories ON LOWER(categories.name) = LOWER(products.category)").
 		Joins("LEFT JOIN stores ON stores.name = products.store").
 		Where("products.deleted_at IS NULL").
 		Where("stores.deleted_at IS NULL").
-		Where("stores.maintenance_mode = ?", false)
+		Where("stores.maintenance_mode = ?", false).
+		Where("products.moderation_status = ?", ModerationApproved)
 
 	// If query is empty, return all products (similar to GetAllProducts)
 	if strings.TrimSpace(query) == "" {
-		err := baseQuery.
-			Order("products.id, products.created_at DESC").
+		err := r.rankBySeller(baseQuery.Order("products.id, products.created_at DESC")).
 			Find(&products).Error
 		if err != nil {
 			log.Printf("Get all products error: %v", err)
@@ -417,11 +532,11 @@ func (r *repository) SearchProducts(ctx context.Context, query string) ([]*Produ
 	} else {
 		// If query is provided, perform search
 		formattedQuery := "%" + strings.ToLower(strings.TrimSpace(query)) + "%"
-		err := baseQuery.
+		baseQuery = baseQuery.
 			Where("LOWER(products.name) ILIKE ? OR LOWER(products.category) ILIKE ? OR LOWER(COALESCE(categories.slug, '')) ILIKE ?",
 				formattedQuery, formattedQuery, formattedQuery).
-			Order("products.id, products.created_at DESC").
-			Find(&products).Error
+			Order("products.id, products.created_at DESC")
+		err := r.rankBySeller(baseQuery).Find(&products).Error
 		if err != nil {
 			log.Printf("Search products error: %v", err)
 			return nil, fmt.Errorf("failed to search products: %v", err)
@@ -435,8 +550,6 @@ func (r *repository) SearchProducts(ctx context.Context, query string) ([]*Produ
 		}
 		p.Images = make([]string, 0, 5) // Preallocate with capacity
 		p.Variant = make([]*VariantType, 0, 3)
-		p.Views = make([]uint32, 0, 10)
-		p.Reviews = make([]Review, 0, 5)
 	}
 
 	return products, nil
@@ -450,7 +563,9 @@ func (r *repository) GetRecommendedProducts(ctx context.Context, query string) (
 		Where("products.deleted_at IS NULL").
 		Where("stores.deleted_at IS NULL").
 		Where("stores.maintenance_mode = ?", false).
+		Where("products.moderation_status = ?", ModerationApproved).
 		Where("category ILIKE ?", "%"+query+"%").
+		Order(sellerScore + " DESC, products.id").
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -459,52 +574,6 @@ func (r *repository) GetRecommendedProducts(ctx context.Context, query string) (
 	return products, nil
 }
 
-// AddReview adds a new review for a product.
-func (r *repository) AddReview(ctx context.Context, review *Review) (*Review, error) {
-	if err := r.db.Create(review).Error; err != nil {
-		return nil, err
-	}
-	return review, nil
-}
-
-// GetProductReviews retrieves all reviews for a specific product, or all reviews for a seller's products.
-func (r *repository) GetProductReviews(ctx context.Context, productId uint32, sellerId string) ([]*Review, error) {
-	var reviews []*Review
-
-	// If we are fetching reviews for a specific product
-	if productId > 0 {
-		err := r.db.Where("product_id = ?", productId).Find(&reviews).Error
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch product reviews: %v", err)
-		}
-		return reviews, nil
-	}
-
-	// If we are fetching reviews for all products of a seller
-	// In GetProductReviews function
-	if sellerId != "" {
-		var products []*Product
-		// Add empty string for categorySlug parameter
-		products, _, err := r.GetProducts(ctx, sellerId, "", 10000, 0)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch seller's products: %v", err)
-		}
-
-		productIDs := make([]uint32, len(products))
-		for i, prd := range products {
-			productIDs[i] = prd.ID
-		}
-
-		err = r.db.Where("product_id IN ?", productIDs).Find(&reviews).Error
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch reviews for seller's products: %v", err)
-		}
-		return reviews, nil
-	}
-
-	return reviews, nil
-}
-
 func (r *repository) AddSavedForLater(ctx context.Context, userId, productId uint32) (*HandledProduct, error) {
 	savedForLater := &HandledProduct{}
 	foundProduct, err := r.GetProduct(ctx, productId, 0)
@@ -520,6 +589,7 @@ func (r *repository) AddSavedForLater(ctx context.Context, userId, productId uin
 	savedForLater.Product = foundProduct
 	savedForLater.UserID = userId
 	savedForLater.Type = "savedForLater"
+	savedForLater.SavedPrice = foundProduct.EffectivePrice()
 	err = r.db.Create(savedForLater).Error
 	if err != nil {
 		return nil, err
@@ -585,6 +655,7 @@ func (r *repository) Products(ctx context.Context, store *string, categorySlug *
 			Category:        p.Category,
 			Subcategory:     p.Subcategory,
 			Type:            &p.Type,
+			IsBundle:        p.IsBundle,
 		}
 	}
 
@@ -618,6 +689,7 @@ func (r *repository) GetAllProducts(ctx context.Context) ([]*Product, error) {
 		Table("products").
 		Joins("LEFT JOIN stores ON products.store = stores.name").
 		Where("stores.maintenance_mode = ?", false).
+		Where("products.moderation_status = ?", ModerationApproved).
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -631,13 +703,207 @@ func (r *repository) GetAllProducts(ctx context.Context) ([]*Product, error) {
 		if p.Variant == nil {
 			p.Variant = []*VariantType{}
 		}
-		if p.Views == nil {
-			p.Views = []uint32{}
+	}
+
+	return products, nil
+}
+
+// productViewDedupWindow is how long repeat views of a product by the same viewer are ignored.
+const productViewDedupWindow = 30 * time.Minute
+
+// RecordProductView stores a view event and bumps the daily aggregate. It reports
+// false when the view was dropped as a repeat within the dedup window.
+func (r *repository) RecordProductView(ctx context.Context, view *ProductView) (bool, error) {
+	if view.UserID == 0 && view.SessionID == "" {
+		return false, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A user or session is required to record a view")
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Product{}).Where("id = ?", view.ProductID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+
+	view.Viewer = "s" + view.SessionID
+	if view.UserID != 0 {
+		view.Viewer = fmt.Sprintf("u%d", view.UserID)
+	}
+	if view.Source == "" {
+		view.Source = "direct"
+	}
+	now := time.Now().UTC()
+	view.CreatedAt = now
+	day := now.Truncate(24 * time.Hour)
+
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Repeats are measured from the viewer's last counted view
+		var last ProductView
+		err := tx.Where("product_id = ? AND viewer = ?", view.ProductID, view.Viewer).
+			Order("created_at DESC, id DESC").Take(&last).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+		case err != nil:
+			return err
+		case now.Sub(last.CreatedAt) < productViewDedupWindow:
+			return nil
+		}
+		view.PreviousID = last.ID
+
+		// A concurrent view after the same one hits the viewer's unique key
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(view)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		recorded = true
+
+		// The viewer is new to the day unless their last view was today
+		unique := 0
+		if last.CreatedAt.Before(day) {
+			unique = 1
+		}
+		return tx.Exec(`
+			INSERT INTO product_view_daily (product_id, day, views, unique_viewers)
+			VALUES (?, ?, 1, ?)
+			ON CONFLICT (product_id, day) DO UPDATE
+			SET views = product_view_daily.views + 1,
+				unique_viewers = product_view_daily.unique_viewers + EXCLUDED.unique_viewers
+		`, view.ProductID, day, unique).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record product view: %v", err)
+	}
+	return recorded, nil
+}
+
+// GetProductViewStats returns the daily view counts of a product between from and to, inclusive.
+func (r *repository) GetProductViewStats(ctx context.Context, productId uint32, from, to time.Time) ([]*ProductViewDaily, error) {
+	var stats []*ProductViewDaily
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND day BETWEEN ? AND ?", productId, from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour)).
+		Order("day ASC").
+		Find(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product view stats: %v", err)
+	}
+	return stats, nil
+}
+
+// RollupProductViews rebuilds the daily aggregates of the given day from the raw view events.
+func (r *repository) RollupProductViews(ctx context.Context, day time.Time) error {
+	start := day.UTC().Truncate(24 * time.Hour)
+	end := start.Add(24 * time.Hour)
+
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO product_view_daily (product_id, day, views, unique_viewers)
+		SELECT product_id, ?, COUNT(*),
+			COUNT(DISTINCT CASE WHEN user_id <> 0 THEN 'u' || user_id::text ELSE 's' || session_id END)
+		FROM product_views
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY product_id
+		ON CONFLICT (product_id, day) DO UPDATE
+		SET views = EXCLUDED.views, unique_viewers = EXCLUDED.unique_viewers
+	`, start, start, end).Error
+}
+
+// ImportProducts creates or updates the products of a store from parsed sheet rows.
+// Rows are matched to existing products by SKU first and then by slug; every row is
+// processed independently so one bad row does not fail the whole upload.
+func (r *repository) ImportProducts(ctx context.Context, storeName string, rows []map[string]string) (*ImportReport, error) {
+	categories, err := r.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Rows: make([]*ImportRowResult, 0, len(rows))}
+	for i, row := range rows {
+		result := &ImportRowResult{Row: i + 2, Name: row[ImportColumnName]}
+
+		req, err := newProductFromImportRow(row, categories)
+		if err == nil {
+			req.Store = storeName
+			var existing *Product
+			existing, err = r.findImportedProduct(ctx, storeName, req.SKU, row[ImportColumnSlug])
+			if err == nil {
+				var saved *Product
+				if existing != nil {
+					req.ID = strconv.FormatUint(uint64(existing.ID), 10)
+					saved, err = r.UpdateProduct(ctx, req)
+					// UpdateProduct treats a zero quantity as "unchanged", but a sheet saying 0 means
+					// sold out. The stock is taken out like a sale would, so bundles follow it.
+					if err == nil && req.Quantity == 0 && strings.TrimSpace(row[ImportColumnQuantity]) != "" &&
+						!saved.IsBundle && saved.Quantity > 0 {
+						err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+							return AdjustStock(tx, saved, -saved.Quantity)
+						})
+					}
+					result.Action = "updated"
+				} else if err = validateNewImportRow(req); err == nil {
+					saved, err = r.CreateProduct(ctx, req)
+					result.Action = "created"
+				}
+				if saved != nil {
+					result.ProductID = saved.ID
+				}
+			}
+		}
+
+		if err != nil {
+			result.Action = "failed"
+			result.Error = err.Error()
+			report.Failed++
+		} else if result.Action == "created" {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	return report, nil
+}
+
+// findImportedProduct returns the store product matching the SKU or slug, or nil if there is none.
+func (r *repository) findImportedProduct(ctx context.Context, storeName, sku, slug string) (*Product, error) {
+	if sku == "" && slug == "" {
+		return nil, nil
+	}
+
+	query := r.db.WithContext(ctx).Where("store = ?", storeName)
+	if sku != "" {
+		query = query.Where("sku = ?", sku)
+	} else {
+		query = query.Where("slug = ?", slug)
+	}
+
+	var products []*Product
+	if err := query.Limit(2).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if len(products) > 1 {
+		return nil, fmt.Errorf("more than one product in the store matches sku %q / slug %q", sku, slug)
+	}
+	if len(products) == 0 {
+		if sku != "" {
+			return nil, nil
 		}
-		if p.Reviews == nil {
-			p.Reviews = []Review{}
+		// The sheet may predate a rename
+		p, err := r.GetProductBySlug(ctx, storeName, slug)
+		if err != nil {
+			return nil, nil
 		}
+		return p, nil
 	}
+	return products[0], nil
+}
 
+// GetStoreCatalog returns every live product of a store, for exports.
+func (r *repository) GetStoreCatalog(ctx context.Context, storeName string) ([]*Product, error) {
+	var products []*Product
+	if err := r.db.WithContext(ctx).Where("store = ?", storeName).Order("id ASC").Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch store catalog: %v", err)
+	}
 	return products, nil
 }
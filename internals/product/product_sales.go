@@ -0,0 +1,314 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxSaleDuration is the longest a sale campaign may run.
+	MaxSaleDuration = 31 * 24 * time.Hour
+	// MaxSaleItems caps the number of products in one campaign.
+	MaxSaleItems = 200
+	// MaxSalePercentOff is the deepest percentage cut a sale may give.
+	MaxSalePercentOff = 90
+)
+
+// Sale campaign states, derived from the schedule.
+const (
+	SaleScheduled = "scheduled"
+	SaleLive      = "live"
+	SaleEnded     = "ended"
+	SaleCancelled = "cancelled"
+)
+
+// SaleCampaign is a time boxed sale run by a store, like a weekend sale. While
+// it is live its products sell at the sale price without the seller editing
+// their regular price or discount.
+type SaleCampaign struct {
+	ID          uint32      `json:"id" gorm:"primaryKey"`
+	Store       string      `json:"store" gorm:"not null;index"`
+	Name        string      `json:"name" gorm:"not null"`
+	StartsAt    time.Time   `json:"starts_at" gorm:"not null;index"`
+	EndsAt      time.Time   `json:"ends_at" gorm:"not null;index"`
+	CancelledAt *time.Time  `json:"cancelled_at"`
+	Items       []*SaleItem `json:"items" gorm:"foreignKey:CampaignID"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// SaleItem is a product's price during a sale: a fixed SalePrice or PercentOff
+// the price it sells at after its own discount. UnitCap limits how many units sell at that price, 0 means
+// no limit.
+type SaleItem struct {
+	ID         uint32        `json:"id" gorm:"primaryKey"`
+	CampaignID uint32        `json:"campaign_id" gorm:"not null;uniqueIndex:idx_sale_items_campaign_product"`
+	ProductID  uint32        `json:"product_id" gorm:"not null;index;uniqueIndex:idx_sale_items_campaign_product"`
+	SalePrice  float64       `json:"sale_price"`
+	PercentOff float64       `json:"percent_off"`
+	UnitCap    int           `json:"unit_cap"`
+	UnitsSold  int           `json:"units_sold"`
+	Product    *Product      `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Campaign   *SaleCampaign `json:"campaign,omitempty" gorm:"foreignKey:CampaignID"`
+}
+
+// ActiveSale is the live sale price of a product.
+type ActiveSale struct {
+	CampaignID uint32    `json:"campaign_id"`
+	ItemID     uint32    `json:"item_id"`
+	Name       string    `json:"name"`
+	EndsAt     time.Time `json:"ends_at"`
+	UnitPrice  float64   `json:"unit_price"`
+	Remaining  int       `json:"remaining"` // units left at the sale price, -1 when uncapped
+}
+
+// Status reports where a campaign is in its schedule at now.
+func (c *SaleCampaign) Status(now time.Time) string {
+	switch {
+	case c.CancelledAt != nil:
+		return SaleCancelled
+	case now.Before(c.StartsAt):
+		return SaleScheduled
+	case now.Before(c.EndsAt):
+		return SaleLive
+	}
+	return SaleEnded
+}
+
+// UnitPrice is what one unit of p costs during the sale. A percentage comes
+// off the effective price, the one deals are weighed against, so it always
+// lowers what the buyer pays.
+func (item *SaleItem) UnitPrice(p *Product) float64 {
+	price := item.SalePrice
+	if price <= 0 {
+		price = p.EffectivePrice() * (1 - item.PercentOff/100)
+	}
+	return math.Round(price*100) / 100
+}
+
+// Remaining is the number of units left at the sale price, -1 when uncapped.
+func (item *SaleItem) Remaining() int {
+	if item.UnitCap == 0 {
+		return -1
+	}
+	return max(item.UnitCap-item.UnitsSold, 0)
+}
+
+// Deal works out what the sale saves on quantity units regularly selling at
+// regularPrice. Units past the sale's cap are charged the regular price.
+func (s *ActiveSale) Deal(regularPrice float64, quantity int) *AppliedDeal {
+	units := quantity
+	if s.Remaining >= 0 {
+		units = min(units, s.Remaining)
+	}
+	if units <= 0 || s.UnitPrice >= regularPrice {
+		return nil
+	}
+	endsAt := s.EndsAt
+	return &AppliedDeal{
+		Label:      s.Name,
+		Savings:    math.Round(float64(units)*(regularPrice-s.UnitPrice)*100) / 100,
+		SaleItemID: s.ItemID,
+		SaleUnits:  units,
+		EndsAt:     &endsAt,
+	}
+}
+
+// activeSales returns the live sale of each product that still has units left
+// at the sale price, keyed by product ID.
+func activeSales(db *gorm.DB, productIDs []uint32, now time.Time) (map[uint32]*ActiveSale, error) {
+	sales := map[uint32]*ActiveSale{}
+	if len(productIDs) == 0 {
+		return sales, nil
+	}
+
+	var items []*SaleItem
+	err := db.Joins("Campaign").Preload("Product").
+		Where("sale_items.product_id IN ?", productIDs).
+		Where(`"Campaign".cancelled_at IS NULL AND "Campaign".starts_at <= ? AND "Campaign".ends_at > ?`, now, now).
+		Where("sale_items.unit_cap = 0 OR sale_items.units_sold < sale_items.unit_cap").
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sales: %v", err)
+	}
+
+	for _, item := range items {
+		if item.Product == nil || item.Campaign == nil {
+			continue
+		}
+		sale := &ActiveSale{
+			CampaignID: item.CampaignID,
+			ItemID:     item.ID,
+			Name:       item.Campaign.Name,
+			EndsAt:     item.Campaign.EndsAt,
+			UnitPrice:  item.UnitPrice(item.Product),
+			Remaining:  item.Remaining(),
+		}
+		if current, ok := sales[item.ProductID]; !ok || sale.UnitPrice < current.UnitPrice {
+			sales[item.ProductID] = sale
+		}
+	}
+	return sales, nil
+}
+
+// RecordSaleUnits counts units sold at a sale price against the sale's cap
+// once the order is paid, so payments that are abandoned or fail never use
+// up the sale. Checkout only prices units still left under the cap; the paid
+// units are counted even if orders paid meanwhile took the last of them.
+func RecordSaleUnits(tx *gorm.DB, saleItemID uint32, units int) error {
+	return tx.Model(&SaleItem{}).
+		Where("id = ?", saleItemID).
+		UpdateColumn("units_sold", gorm.Expr("units_sold + ?", units)).Error
+}
+
+func validateSaleItem(item *SaleItem, p *Product, storeName string) error {
+	if p.Store != storeName {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("%s belongs to another store", p.Name))
+	}
+	switch {
+	case item.SalePrice > 0 && item.PercentOff > 0:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Set either a sale price or a percentage for %s, not both", p.Name))
+	case item.SalePrice > 0:
+		if item.SalePrice >= p.EffectivePrice() {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("The sale price of %s must be below its current price", p.Name))
+		}
+	case item.PercentOff > 0:
+		if item.PercentOff > MaxSalePercentOff {
+			return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A sale can take at most %d%% off", MaxSalePercentOff))
+		}
+	default:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Set a sale price or a percentage for %s", p.Name))
+	}
+	if item.UnitCap < 0 {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Unit caps cannot be negative")
+	}
+	return nil
+}
+
+func (r *repository) CreateSaleCampaign(ctx context.Context, c *SaleCampaign) (*SaleCampaign, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	now := time.Now()
+	switch {
+	case c.Name == "":
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Sale name is required")
+	case !c.EndsAt.After(c.StartsAt):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A sale must end after it starts")
+	case !c.EndsAt.After(now):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A sale must end in the future")
+	case c.EndsAt.Sub(c.StartsAt) > MaxSaleDuration:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A sale can run for at most 31 days")
+	case len(c.Items) == 0:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Add at least one product to the sale")
+	case len(c.Items) > MaxSaleItems:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A sale can include at most %d products", MaxSaleItems))
+	}
+
+	seen := map[uint32]bool{}
+	for _, item := range c.Items {
+		if seen[item.ProductID] {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Each product can only appear once in a sale")
+		}
+		seen[item.ProductID] = true
+
+		var p Product
+		if err := r.db.WithContext(ctx).First(&p, item.ProductID).Error; err != nil {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", fmt.Sprintf("Product %d not found", item.ProductID))
+		}
+		if err := validateSaleItem(item, &p, c.Store); err != nil {
+			return nil, err
+		}
+
+		// A product can only be in one sale at a time
+		var overlapping int64
+		r.db.WithContext(ctx).Model(&SaleItem{}).Joins("Campaign").
+			Where("sale_items.product_id = ?", item.ProductID).
+			Where(`"Campaign".cancelled_at IS NULL AND "Campaign".starts_at < ? AND "Campaign".ends_at > ?`, c.EndsAt, c.StartsAt).
+			Count(&overlapping)
+		if overlapping > 0 {
+			return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("%s is already in a sale during this time", p.Name))
+		}
+		item.ID, item.UnitsSold, item.Product = 0, 0, nil
+	}
+
+	c.ID, c.CancelledAt = 0, nil
+	if err := r.db.WithContext(ctx).Create(c).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sale: %v", err)
+	}
+	return r.GetSaleCampaign(ctx, c.ID)
+}
+
+func (r *repository) GetSaleCampaign(ctx context.Context, id uint32) (*SaleCampaign, error) {
+	var c SaleCampaign
+	err := r.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sale_items.id")
+	}).Preload("Items.Product").First(&c, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Sale not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CancelSaleCampaign stops a scheduled or live sale.
+func (r *repository) CancelSaleCampaign(ctx context.Context, id uint32) error {
+	c, err := r.GetSaleCampaign(ctx, id)
+	if err != nil {
+		return err
+	}
+	if status := c.Status(time.Now()); status == SaleEnded || status == SaleCancelled {
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "This sale is already over")
+	}
+	return r.db.WithContext(ctx).Model(c).Update("cancelled_at", time.Now()).Error
+}
+
+// GetStoreSaleCampaigns lists a store's sales, newest first. Unless
+// includePast is set only scheduled and live ones are returned.
+func (r *repository) GetStoreSaleCampaigns(ctx context.Context, storeName string, includePast bool) ([]*SaleCampaign, error) {
+	query := r.db.WithContext(ctx).Preload("Items.Product").Where("store = ?", storeName)
+	if !includePast {
+		query = query.Where("cancelled_at IS NULL AND ends_at > ?", time.Now())
+	}
+	var campaigns []*SaleCampaign
+	if err := query.Order("starts_at DESC").Find(&campaigns).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sales: %v", err)
+	}
+	return campaigns, nil
+}
+
+// GetActiveDeals lists the products on a live sale across all stores, the
+// sales ending soonest first.
+func (r *repository) GetActiveDeals(ctx context.Context, limit, offset int) ([]*SaleItem, error) {
+	now := time.Now()
+	var items []*SaleItem
+	err := r.db.WithContext(ctx).Joins("Campaign").Preload("Product").
+		Joins("JOIN products ON products.id = sale_items.product_id AND products.deleted_at IS NULL").
+		Joins("LEFT JOIN stores ON stores.name = products.store").
+		Where(`"Campaign".cancelled_at IS NULL AND "Campaign".starts_at <= ? AND "Campaign".ends_at > ?`, now, now).
+		Where("sale_items.unit_cap = 0 OR sale_items.units_sold < sale_items.unit_cap").
+		Where("products.status = ? AND products.moderation_status = ?", true, ModerationApproved).
+		Where("stores.deleted_at IS NULL AND stores.maintenance_mode = ?", false).
+		Order(`"Campaign".ends_at, sale_items.id`).
+		Limit(limit).Offset(offset * limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deals: %v", err)
+	}
+	return items, nil
+}
+
+func (r *repository) GetActiveSale(ctx context.Context, productId uint32) (*ActiveSale, error) {
+	sales, err := activeSales(r.db.WithContext(ctx), []uint32{productId}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return sales[productId], nil
+}
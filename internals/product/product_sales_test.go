@@ -0,0 +1,58 @@
+package product
+
+import "testing"
+
+func TestSaleItemUnitPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    SaleItem
+		product Product
+		want    float64
+	}{
+		{name: "fixed sale price", item: SaleItem{SalePrice: 4500}, product: Product{Price: 6000}, want: 4500},
+		{name: "fixed price ignores discount", item: SaleItem{SalePrice: 4500}, product: Product{Price: 6000, Discount: 1000}, want: 4500},
+		{name: "percent off list price", item: SaleItem{PercentOff: 25}, product: Product{Price: 8000}, want: 6000},
+		{name: "percent off discounted price", item: SaleItem{PercentOff: 10}, product: Product{Price: 10000, Discount: 5000}, want: 4500},
+		{name: "rounded to kobo", item: SaleItem{PercentOff: 33}, product: Product{Price: 999.99}, want: 669.99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.item.UnitPrice(&tt.product); got != tt.want {
+				t.Errorf("UnitPrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveSaleDeal(t *testing.T) {
+	tests := []struct {
+		name      string
+		sale      ActiveSale
+		regular   float64
+		quantity  int
+		wantUnits int
+		savings   float64
+	}{
+		{name: "uncapped", sale: ActiveSale{UnitPrice: 800, Remaining: -1}, regular: 1000, quantity: 3, wantUnits: 3, savings: 600},
+		{name: "capped", sale: ActiveSale{UnitPrice: 800, Remaining: 2}, regular: 1000, quantity: 5, wantUnits: 2, savings: 400},
+		{name: "sold out", sale: ActiveSale{UnitPrice: 800, Remaining: 0}, regular: 1000, quantity: 1},
+		{name: "no cheaper than regular", sale: ActiveSale{UnitPrice: 1000, Remaining: -1}, regular: 1000, quantity: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deal := tt.sale.Deal(tt.regular, tt.quantity)
+			if tt.wantUnits == 0 {
+				if deal != nil {
+					t.Fatalf("Deal() = %+v, want none", deal)
+				}
+				return
+			}
+			if deal == nil {
+				t.Fatal("Deal() = nil, want a deal")
+			}
+			if deal.SaleUnits != tt.wantUnits || deal.Savings != tt.savings {
+				t.Errorf("Deal() = %d units saving %v, want %d saving %v", deal.SaleUnits, deal.Savings, tt.wantUnits, tt.savings)
+			}
+		})
+	}
+}
@@ -23,7 +23,9 @@ func (s *service) CreateCategory(c context.Context, req *Category) (*Category, e
 	defer cancel()
 
 	u := &Category{
-		Name: req.Name,
+		Name:     req.Name,
+		Type:     req.Type,
+		ParentID: req.ParentID,
 	}
 	r, err := s.Repository.CreateCategory(ctx, u)
 	if err != nil {
@@ -33,6 +35,54 @@ func (s *service) CreateCategory(c context.Context, req *Category) (*Category, e
 	return r, nil
 }
 
+func (s *service) SetCategoryAttributes(ctx context.Context, categoryId uint32, attributes []CategoryAttribute) (*Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetCategoryAttributes(ctx, categoryId, attributes)
+}
+
+func (s *service) GetCategoryFacets(ctx context.Context, categoryId uint32) ([]*Facet, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetCategoryFacets(ctx, categoryId)
+}
+
+func (s *service) GetProductsByCategory(ctx context.Context, categoryId uint32, filters map[string][]string, limit, offset int) ([]*Product, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetProductsByCategory(ctx, categoryId, filters, limit, offset)
+}
+
+func (s *service) GetProductBySlug(ctx context.Context, storeName, slug string) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetProductBySlug(ctx, storeName, slug)
+}
+
+func (s *service) GetBundleItems(ctx context.Context, bundleId uint32) ([]*BundleItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetBundleItems(ctx, bundleId)
+}
+
+func (s *service) SetBundleItems(ctx context.Context, bundleId uint32, items []*BundleItem) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetBundleItems(ctx, bundleId, items)
+}
+
+func (s *service) GetPricingRules(ctx context.Context, productId uint32) ([]*PricingRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetPricingRules(ctx, productId)
+}
+
+func (s *service) SetPricingRules(ctx context.Context, productId uint32, rules []*PricingRule) ([]*PricingRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetPricingRules(ctx, productId, rules)
+}
+
 func (s *service) CreateProduct(c context.Context, req *NewProduct) (*Product, error) {
 	ctx, cancel := context.WithTimeout(c, s.timeout)
 	defer cancel()
@@ -193,28 +243,175 @@ func (s *service) SearchProducts(ctx context.Context, query string) ([]*Product,
 // 	return prd,nil
 // }
 
-func (s *service) AddReview(ctx context.Context, input *Review) (*Review, error) {
+func (s *service) GetAllProducts(ctx context.Context) ([]*Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
-	review, err := s.Repository.AddReview(ctx, input)
-	if err != nil {
-		return nil, err
-	}
-	return review, nil
+	return s.Repository.GetAllProducts(ctx)
 }
 
-func (s *service) GetProductReviews(ctx context.Context, productId uint32, sellerId string) ([]*Review, error) {
+func (s *service) RecordProductView(ctx context.Context, view *ProductView) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
-	reviews, err := s.Repository.GetProductReviews(ctx, productId, sellerId)
-	if err != nil {
-		return nil, err
+	return s.Repository.RecordProductView(ctx, view)
+}
+
+func (s *service) GetProductViewStats(ctx context.Context, productId uint32, from, to time.Time) ([]*ProductViewDaily, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is before %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
 	}
-	return reviews, nil
+	return s.Repository.GetProductViewStats(ctx, productId, from, to)
 }
 
-func (s *service) GetAllProducts(ctx context.Context) ([]*Product, error) {
+func (s *service) RollupProductViews(ctx context.Context, day time.Time) error {
+	return s.Repository.RollupProductViews(ctx, day)
+}
+
+func (s *service) ImportProducts(ctx context.Context, storeName string, rows []map[string]string) (*ImportReport, error) {
+	// Imports touch up to MaxImportRows products, so they get more time than a single write
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.ImportProducts(ctx, storeName, rows)
+}
+
+func (s *service) GetStoreCatalog(ctx context.Context, storeName string) ([]*Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
-	return s.Repository.GetAllProducts(ctx)
+	return s.Repository.GetStoreCatalog(ctx, storeName)
+}
+
+func (s *service) GetPriceHistory(ctx context.Context, productId uint32) ([]*ProductPriceHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetPriceHistory(ctx, productId)
+}
+
+func (s *service) NotifyPriceDrops(ctx context.Context, productId uint32) (int, error) {
+	// Every watcher may be emailed, so this gets more time than a single write
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.NotifyPriceDrops(ctx, productId)
+}
+
+func (s *service) SubscribeToRestock(ctx context.Context, sub *StockSubscription) (*StockSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SubscribeToRestock(ctx, sub)
+}
+
+func (s *service) CancelRestockSubscription(ctx context.Context, userId, productId uint32, variant string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CancelRestockSubscription(ctx, userId, productId, variant)
+}
+
+func (s *service) NotifyRestock(ctx context.Context, productId uint32) (int, error) {
+	// Every subscriber in the batch is emailed, so this gets more time than a single write
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.NotifyRestock(ctx, productId)
+}
+
+func (s *service) GetRestockedProductIDs(ctx context.Context) ([]uint32, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetRestockedProductIDs(ctx)
+}
+
+func (s *service) AskQuestion(ctx context.Context, q *ProductQuestion) (*ProductQuestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.AskQuestion(ctx, q)
+}
+
+func (s *service) AnswerQuestion(ctx context.Context, a *ProductAnswer) (*ProductAnswer, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.AnswerQuestion(ctx, a)
+}
+
+func (s *service) GetProductQuestions(ctx context.Context, productId uint32, answeredOnly bool, limit, offset int) ([]*ProductQuestion, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetProductQuestions(ctx, productId, answeredOnly, limit, offset)
+}
+
+func (s *service) UpvoteQA(ctx context.Context, userId uint32, kind string, id uint32) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.UpvoteQA(ctx, userId, kind, id)
+}
+
+func (s *service) ModerateQA(ctx context.Context, kind string, id uint32, approve bool) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.ModerateQA(ctx, kind, id, approve)
+}
+
+func (s *service) GetQAModerationQueue(ctx context.Context) ([]*ProductQuestion, []*ProductAnswer, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetQAModerationQueue(ctx)
+}
+
+func (s *service) CreateSaleCampaign(ctx context.Context, c *SaleCampaign) (*SaleCampaign, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CreateSaleCampaign(ctx, c)
+}
+
+func (s *service) GetSaleCampaign(ctx context.Context, id uint32) (*SaleCampaign, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetSaleCampaign(ctx, id)
+}
+
+func (s *service) CancelSaleCampaign(ctx context.Context, id uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CancelSaleCampaign(ctx, id)
+}
+
+func (s *service) GetStoreSaleCampaigns(ctx context.Context, storeName string, includePast bool) ([]*SaleCampaign, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreSaleCampaigns(ctx, storeName, includePast)
+}
+
+func (s *service) GetActiveDeals(ctx context.Context, limit, offset int) ([]*SaleItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetActiveDeals(ctx, limit, offset)
+}
+
+func (s *service) GetActiveSale(ctx context.Context, productId uint32) (*ActiveSale, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetActiveSale(ctx, productId)
+}
+
+func (s *service) GetTrashedProduct(ctx context.Context, id uint32) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetTrashedProduct(ctx, id)
+}
+
+func (s *service) GetTrashedProducts(ctx context.Context, storeName string) ([]*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetTrashedProducts(ctx, storeName)
+}
+
+func (s *service) RestoreProduct(ctx context.Context, id uint32) (*Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.RestoreProduct(ctx, id)
+}
+
+func (s *service) PurgeTrashedProducts(ctx context.Context, before time.Time) (int, error) {
+	// Purges run as a job over the whole trash, so they get more time than a single write
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.PurgeTrashedProducts(ctx, before)
 }
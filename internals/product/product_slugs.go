@@ -0,0 +1,166 @@
+package product
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxSlugLength = 80
+	// slugIndex is the unique index on a store's product slugs
+	slugIndex = "idx_products_store_slug"
+	// maxSlugAttempts bounds how often a save is retried after another
+	// product took its slug first
+	maxSlugAttempts = 5
+)
+
+// ProductSlugHistory keeps the slugs a product had before it was renamed, so
+// links shared with the old slug still find it.
+type ProductSlugHistory struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	ProductID uint32    `json:"product_id" gorm:"not null;index"`
+	Store     string    `json:"store" gorm:"not null;uniqueIndex:idx_product_slug_history_store_slug"`
+	Slug      string    `json:"slug" gorm:"not null;uniqueIndex:idx_product_slug_history_store_slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ProductSlugHistory) TableName() string {
+	return "product_slug_history"
+}
+
+var repeatedDashes = regexp.MustCompile(`-{2,}`)
+
+// slugify turns a product name into the base of its slug.
+func slugify(name string) string {
+	slug := repeatedDashes.ReplaceAllString(utils.GenerateSlug(strings.TrimSpace(name)), "-")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	if slug = strings.Trim(slug, "-"); slug == "" {
+		slug = "product"
+	}
+	return slug
+}
+
+// uniqueSlug returns the first of base, base-2, base-3... that no other product
+// of the store uses now or used before a rename, and that is not in skip.
+// Deleted products keep their slugs so they can be restored.
+func uniqueSlug(tx *gorm.DB, storeName, base string, productID uint32, skip []string) (string, error) {
+	var taken []string
+	if err := tx.Unscoped().Model(&Product{}).
+		Where("store = ? AND id <> ?", storeName, productID).
+		Where("slug = ? OR slug LIKE ?", base, base+"-%").
+		Pluck("slug", &taken).Error; err != nil {
+		return "", err
+	}
+	var retired []string
+	if err := tx.Model(&ProductSlugHistory{}).
+		Where("store = ? AND product_id <> ?", storeName, productID).
+		Where("slug = ? OR slug LIKE ?", base, base+"-%").
+		Pluck("slug", &retired).Error; err != nil {
+		return "", err
+	}
+
+	used := make(map[string]bool, len(taken)+len(retired)+len(skip))
+	for _, s := range append(append(taken, retired...), skip...) {
+		used[s] = true
+	}
+	slug := base
+	for n := 2; used[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug, nil
+}
+
+// assignSlug gives a product a unique slug for its name and store. When that
+// replaces an earlier slug, the old one is kept in the history.
+func assignSlug(tx *gorm.DB, p *Product, previousSlug, previousStore string, skip []string) error {
+	slug, err := uniqueSlug(tx, p.Store, slugify(p.Name), p.ID, skip)
+	if err != nil {
+		return fmt.Errorf("failed to generate slug: %v", err)
+	}
+	p.Slug = slug
+
+	if p.ID == 0 || previousSlug == "" || (previousSlug == slug && previousStore == p.Store) {
+		return nil
+	}
+	// A product taking back one of its old slugs no longer needs the redirect
+	if err := tx.Where("product_id = ? AND store = ? AND slug = ?", p.ID, p.Store, slug).
+		Delete(&ProductSlugHistory{}).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store"}, {Name: "slug"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"product_id": p.ID, "created_at": time.Now()}),
+	}).Create(&ProductSlugHistory{ProductID: p.ID, Store: previousStore, Slug: previousSlug}).Error
+}
+
+// saveWithSlug assigns p a slug and then writes it with save. The slug is
+// checked before it is written, so a product saved at the same moment can take
+// it first and the unique index rejects the write. The transaction is then
+// rolled back to before the slug was assigned and the save retried with the
+// next free slug.
+func saveWithSlug(tx *gorm.DB, p *Product, previousSlug, previousStore string, save func(tx *gorm.DB) error) error {
+	var skip []string
+	for attempt := 1; ; attempt++ {
+		if err := tx.SavePoint("product_slug").Error; err != nil {
+			return err
+		}
+		err := assignSlug(tx, p, previousSlug, previousStore, skip)
+		if err == nil {
+			err = save(tx)
+		}
+		if err == nil || !isSlugConflict(err) || attempt == maxSlugAttempts {
+			return err
+		}
+		if err := tx.RollbackTo("product_slug").Error; err != nil {
+			return err
+		}
+		skip = append(skip, p.Slug)
+	}
+}
+
+// isSlugConflict reports whether err is a unique violation of slugIndex.
+func isSlugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return stderrors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == slugIndex
+}
+
+// GetProductBySlug finds a store's product by its current slug or, failing
+// that, by a slug it had before being renamed.
+func (r *repository) GetProductBySlug(ctx context.Context, storeName, slug string) (*Product, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+
+	var p Product
+	err := r.db.WithContext(ctx).Where("store = ? AND slug = ?", storeName, slug).First(&p).Error
+	if err == nil {
+		return &p, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	var history ProductSlugHistory
+	err = r.db.WithContext(ctx).Where("store = ? AND slug = ?", storeName, slug).First(&history).Error
+	if err == nil {
+		err = r.db.WithContext(ctx).Where("id = ? AND store = ?", history.ProductID, storeName).First(&p).Error
+	}
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
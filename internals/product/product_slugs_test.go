@@ -0,0 +1,54 @@
+package product
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+	"gorm.io/gorm"
+)
+
+func TestSaveWithSlugRetriesTakenSlug(t *testing.T) {
+	db := testdb.Open(t, &Product{}, &ProductSlugHistory{})
+
+	// The first save loses the slug to a product saved at the same moment
+	conflict := &pgconn.PgError{Code: "23505", ConstraintName: slugIndex}
+	attempts := 0
+	p := &Product{Name: "Lecture Notes", Store: "campus"}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return saveWithSlug(tx, p, "", "", func(tx *gorm.DB) error {
+			attempts++
+			if err := tx.Create(p).Error; err != nil {
+				return err
+			}
+			if attempts == 1 {
+				return fmt.Errorf("failed to create product: %w", conflict)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("saveWithSlug: %v", err)
+	}
+	if attempts != 2 || p.Slug != "lecture-notes-2" {
+		t.Errorf("attempts = %d, slug = %q, want 2 and %q", attempts, p.Slug, "lecture-notes-2")
+	}
+	var count int64
+	if err := db.Model(&Product{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count products: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("products = %d, want the first attempt rolled back", count)
+	}
+
+	other := &pgconn.PgError{Code: "23505", ConstraintName: "products_pkey"}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return saveWithSlug(tx, &Product{Name: "Past Questions", Store: "campus"}, "", "", func(tx *gorm.DB) error {
+			return other
+		})
+	})
+	if err != other {
+		t.Errorf("other unique violation: err = %v, want it returned as is", err)
+	}
+}
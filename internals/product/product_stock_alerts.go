@@ -0,0 +1,197 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+)
+
+const (
+	// restockNotifyPerUnit is how many subscribers are notified for each unit
+	// back in stock, so a small restock does not alert everyone at once.
+	restockNotifyPerUnit = 3
+	// restockBatchSize caps the subscribers notified for a product in one batch.
+	restockBatchSize = 50
+	// restockBatchInterval is the minimum time between batches for a product.
+	restockBatchInterval = 30 * time.Minute
+)
+
+// findVariant returns the canonical "name:value" key of a product variant.
+func findVariant(p *Product, key string) (string, bool) {
+	name, value, ok := strings.Cut(key, ":")
+	if !ok {
+		return "", false
+	}
+	for _, v := range p.Variant {
+		if v == nil || !strings.EqualFold(v.Name, strings.TrimSpace(name)) {
+			continue
+		}
+		for _, val := range v.Value {
+			if val != nil && strings.EqualFold(val.Value, strings.TrimSpace(value)) {
+				return v.Name + ":" + val.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// SubscribeToRestock adds the user to the waiting list of a sold-out product.
+// Subscribing twice returns the existing subscription.
+func (r *repository) SubscribeToRestock(ctx context.Context, sub *StockSubscription) (*StockSubscription, error) {
+	var p Product
+	if err := r.db.WithContext(ctx).Where("id = ?", sub.ProductID).First(&p).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+	}
+	if !p.IsSoldOut() {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Product is in stock")
+	}
+	if sub.Variant != "" {
+		variant, ok := findVariant(&p, sub.Variant)
+		if !ok {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Product has no variant %q", sub.Variant))
+		}
+		sub.Variant = variant
+	}
+
+	existing := &StockSubscription{}
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND variant = ? AND user_id = ? AND status = ?", sub.ProductID, sub.Variant, sub.UserID, StockAlertWaiting).
+		First(existing).Error
+	if err == nil {
+		return existing, nil
+	}
+
+	sub.Status = StockAlertWaiting
+	if err := r.db.WithContext(ctx).Create(sub).Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (r *repository) CancelRestockSubscription(ctx context.Context, userId, productId uint32, variant string) error {
+	query := r.db.WithContext(ctx).Model(&StockSubscription{}).
+		Where("product_id = ? AND user_id = ? AND status = ?", productId, userId, StockAlertWaiting)
+	if variant != "" {
+		query = query.Where("LOWER(variant) = LOWER(?)", variant)
+	} else {
+		query = query.Where("variant = ''")
+	}
+	result := query.Update("status", StockAlertCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Subscription not found")
+	}
+	return nil
+}
+
+// NotifyRestock notifies the next batch of subscribers of a product that is back
+// in stock, first come first served. The batch is sized by the units available
+// and batches are spaced by restockBatchInterval; the rest of the waiting list is
+// picked up by later calls while stock lasts. Variants do not track their own
+// stock yet, so variant subscribers are notified when the product is restocked.
+// It returns the number of subscribers notified.
+func (r *repository) NotifyRestock(ctx context.Context, productId uint32) (int, error) {
+	var p Product
+	if err := r.db.WithContext(ctx).Where("id = ?", productId).First(&p).Error; err != nil {
+		return 0, err
+	}
+	if p.IsSoldOut() || p.ModerationStatus != ModerationApproved {
+		return 0, nil
+	}
+
+	var lastBatch sql.NullTime
+	err := r.db.WithContext(ctx).Model(&StockSubscription{}).
+		Select("MAX(notified_at)").
+		Where("product_id = ?", productId).
+		Scan(&lastBatch).Error
+	if err != nil {
+		return 0, err
+	}
+	if lastBatch.Valid && time.Since(lastBatch.Time) < restockBatchInterval {
+		return 0, nil
+	}
+
+	batch := restockBatchSize
+	if !p.AlwaysAvailbale && p.Quantity*restockNotifyPerUnit < batch {
+		batch = p.Quantity * restockNotifyPerUnit
+	}
+
+	var subs []*StockSubscription
+	err = r.db.WithContext(ctx).
+		Where("product_id = ? AND status = ?", productId, StockAlertWaiting).
+		Order("created_at ASC, id ASC").
+		Limit(batch).
+		Find(&subs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	notifier := notification.NewService(notification.NewRepository())
+	notified := 0
+	for _, sub := range subs {
+		// Claim the subscription first so concurrent batches never notify anyone twice
+		now := time.Now()
+		result := r.db.WithContext(ctx).Model(&StockSubscription{}).
+			Where("id = ? AND status = ?", sub.ID, StockAlertWaiting).
+			Updates(map[string]interface{}{"status": StockAlertNotified, "notified_at": now})
+		if result.Error != nil {
+			return notified, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		name := p.Name
+		if sub.Variant != "" {
+			name = fmt.Sprintf("%s (%s)", p.Name, sub.Variant)
+		}
+		_, err := notifier.Notify(ctx, &notification.Notification{
+			UserID:  sub.UserID,
+			Type:    notification.TypeBackInStock,
+			Title:   fmt.Sprintf("%s is back in stock", p.Name),
+			Message: fmt.Sprintf("%s is available again. Stock is limited, so get yours before it sells out.", name),
+			Link:    fmt.Sprintf("/product/%d", p.ID),
+		}, true)
+		if err != nil {
+			return notified, err
+		}
+		notified++
+	}
+	return notified, nil
+}
+
+// GetRestockedProductIDs lists products that are in stock and still have people
+// waiting to be notified.
+func (r *repository) GetRestockedProductIDs(ctx context.Context) ([]uint32, error) {
+	var waiting []uint32
+	err := r.db.WithContext(ctx).Model(&StockSubscription{}).
+		Distinct("product_id").
+		Where("status = ?", StockAlertWaiting).
+		Pluck("product_id", &waiting).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(waiting) == 0 {
+		return nil, nil
+	}
+
+	var products []*Product
+	if err := r.db.WithContext(ctx).Where("id IN ?", waiting).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint32, 0, len(products))
+	for _, p := range products {
+		if !p.IsSoldOut() && p.ModerationStatus == ModerationApproved {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids, nil
+}
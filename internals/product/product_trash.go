@@ -0,0 +1,129 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+// TrashRetention is how long deleted products and stores can be restored
+// before they are purged for good.
+const TrashRetention = 30 * 24 * time.Hour
+
+// DeleteProduct moves a product to its store's trash. Bundles using it run
+// out of stock until it is restored.
+func (r *repository) DeleteProduct(ctx context.Context, id uint32) error {
+	existingProduct, err := r.GetProduct(ctx, id, 0)
+	if err != nil {
+		return fmt.Errorf("error retrieving product: %v", err)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(existingProduct).Error; err != nil {
+			return err
+		}
+		return refreshBundlesContaining(tx, []uint32{existingProduct.ID})
+	})
+}
+
+// GetTrashedProduct loads a product in the trash.
+func (r *repository) GetTrashedProduct(ctx context.Context, id uint32) (*Product, error) {
+	var p Product
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&p).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product is not in the trash")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetTrashedProducts lists a store's deleted products, most recently deleted
+// first.
+func (r *repository) GetTrashedProducts(ctx context.Context, storeName string) ([]*Product, error) {
+	var products []*Product
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("store = ? AND deleted_at IS NOT NULL", storeName).
+		Order("deleted_at DESC").
+		Find(&products).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %v", err)
+	}
+	return products, nil
+}
+
+// RestoreProduct takes a product out of the trash. Products of a deleted store
+// come back with the store instead.
+func (r *repository) RestoreProduct(ctx context.Context, id uint32) (*Product, error) {
+	p, err := r.GetTrashedProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var liveStores int64
+	r.db.WithContext(ctx).Table("stores").Where("name = ? AND deleted_at IS NULL", p.Store).Count(&liveStores)
+	if liveStores == 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Restore the store this product belongs to first")
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&Product{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if p.IsBundle {
+			if err := refreshBundleStock(tx, []uint32{id}); err != nil {
+				return err
+			}
+		}
+		return refreshBundlesContaining(tx, []uint32{id})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore product: %v", err)
+	}
+	return r.GetProduct(ctx, id, 0)
+}
+
+// PurgeTrashedProducts permanently deletes products that have been in the
+// trash since before the cutoff. Orders and reviews keep their own copy of
+// the product's name and picture. Listings of closed stores still keeping
+// their records are left alone.
+func (r *repository) PurgeTrashedProducts(ctx context.Context, before time.Time) (int, error) {
+	var ids []uint32
+	if err := r.db.WithContext(ctx).Unscoped().Model(&Product{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("store NOT IN (SELECT store_name FROM store_closeouts WHERE status = 'closed' AND retain_until > ?)", time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bundles lose the purged components, so their stock is worked out again
+		var bundleIDs []uint32
+		if err := tx.Model(&BundleItem{}).Where("component_id IN ?", ids).Distinct().Pluck("bundle_id", &bundleIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("component_id IN ? OR bundle_id IN ?", ids, ids).Delete(&BundleItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("product_id IN ?", ids).Delete(&HandledProduct{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN ?", ids).Delete(&ProductSlugHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id IN ?", ids).Delete(&Product{}).Error; err != nil {
+			return err
+		}
+		return refreshBundleStock(tx, bundleIDs)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge products: %v", err)
+	}
+	return len(ids), nil
+}
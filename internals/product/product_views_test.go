@@ -0,0 +1,81 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+)
+
+func TestRecordProductViewDedup(t *testing.T) {
+	db := testdb.Open(t, &Product{}, &ProductView{}, &ProductViewDaily{})
+	if err := db.Create(&Product{ID: 1}).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	r := &repository{db: db}
+	ctx := context.Background()
+
+	views := []struct {
+		name    string
+		view    ProductView
+		counted bool
+	}{
+		{name: "first view", view: ProductView{ProductID: 1, UserID: 5, SessionID: "a"}, counted: true},
+		{name: "same user, other session", view: ProductView{ProductID: 1, UserID: 5, SessionID: "b"}},
+		{name: "anonymous session", view: ProductView{ProductID: 1, SessionID: "a"}, counted: true},
+		{name: "same anonymous session", view: ProductView{ProductID: 1, SessionID: "a"}},
+		{name: "another user", view: ProductView{ProductID: 1, UserID: 6}, counted: true},
+	}
+	for _, tt := range views {
+		counted, err := r.RecordProductView(ctx, &tt.view)
+		if err != nil {
+			t.Fatalf("%s: RecordProductView: %v", tt.name, err)
+		}
+		if counted != tt.counted {
+			t.Errorf("%s: counted = %v, want %v", tt.name, counted, tt.counted)
+		}
+	}
+
+	var daily ProductViewDaily
+	if err := db.Where("product_id = ?", 1).First(&daily).Error; err != nil {
+		t.Fatalf("failed to load daily views: %v", err)
+	}
+	if daily.Views != 3 || daily.UniqueViewers != 3 {
+		t.Errorf("daily = %d views, %d unique, want 3 and 3", daily.Views, daily.UniqueViewers)
+	}
+}
+
+func TestRecordProductViewSlidingWindow(t *testing.T) {
+	db := testdb.Open(t, &Product{}, &ProductView{}, &ProductViewDaily{})
+	if err := db.Create(&Product{ID: 1}).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+	r := &repository{db: db}
+
+	tests := []struct {
+		name    string
+		ago     time.Duration
+		counted bool
+	}{
+		{name: "just inside the window", ago: productViewDedupWindow - time.Minute},
+		{name: "just outside the window", ago: productViewDedupWindow + time.Minute, counted: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer := uint32(10 + i)
+			earlier := &ProductView{ProductID: 1, UserID: viewer, Viewer: fmt.Sprintf("u%d", viewer), CreatedAt: time.Now().UTC().Add(-tt.ago)}
+			if err := db.Create(earlier).Error; err != nil {
+				t.Fatalf("failed to create earlier view: %v", err)
+			}
+			counted, err := r.RecordProductView(context.Background(), &ProductView{ProductID: 1, UserID: viewer})
+			if err != nil {
+				t.Fatalf("RecordProductView: %v", err)
+			}
+			if counted != tt.counted {
+				t.Errorf("counted = %v, want %v", counted, tt.counted)
+			}
+		})
+	}
+}
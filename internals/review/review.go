@@ -0,0 +1,77 @@
+package review
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Review is a buyer's review of one delivered order line. A line is identified by
+// the order UUID and the product ID, and can be reviewed once. Reviews written
+// before purchases were checked are kept with Verified set to false.
+type Review struct {
+	ID               uint32         `json:"id" gorm:"primaryKey"`
+	OrderID          string         `json:"order_id" gorm:"index"` // order UUID
+	ProductID        uint32         `json:"product_id" gorm:"index"`
+	ProductName      string         `json:"product_name"` // as ordered, kept if the product is purged
+	ProductThumbnail string         `json:"product_thumbnail"`
+	StoreID          uint32         `json:"store_id" gorm:"index"`
+	SellerID         uint32         `json:"seller_id"`
+	BuyerID          uint32         `json:"buyer_id" gorm:"index"`
+	Nickname         string         `json:"nickname"`
+	Avatar           string         `json:"avatar"`
+	Rating           float64        `json:"rating"`
+	Message          string         `json:"message"`
+	Photos           pq.StringArray `json:"photos" gorm:"type:text[]"`
+	SellerReply      string         `json:"seller_reply"`
+	SellerRepliedAt  *time.Time     `json:"seller_replied_at"`
+	HelpfulCount     int            `json:"helpful_count"`
+	Verified         bool           `json:"verified"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+// HelpfulVote is one user's "helpful" vote on a review.
+type HelpfulVote struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	ReviewID  uint32    `json:"review_id" gorm:"uniqueIndex:idx_review_helpful_votes_review_user"`
+	UserID    uint32    `json:"user_id" gorm:"uniqueIndex:idx_review_helpful_votes_review_user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (HelpfulVote) TableName() string {
+	return "review_helpful_votes"
+}
+
+// Filter selects reviews by product, store or order. Zero values are ignored.
+type Filter struct {
+	ProductID uint32
+	StoreID   uint32
+	OrderID   string
+}
+
+const (
+	MinRating = 1
+	MaxRating = 5
+	// MaxPhotos is how many photos a review can carry.
+	MaxPhotos = 5
+)
+
+type Repository interface {
+	CreateReview(ctx context.Context, review *Review) (*Review, error)
+	GetReview(ctx context.Context, id uint32) (*Review, error)
+	GetReviews(ctx context.Context, filter Filter, limit, offset int) ([]*Review, error)
+	ReplyToReview(ctx context.Context, id, sellerID uint32, reply string) (*Review, error)
+	MarkHelpful(ctx context.Context, id, userID uint32) (int, error)
+}
+
+type Service interface {
+	CreateReview(ctx context.Context, review *Review) (*Review, error)
+	GetReview(ctx context.Context, id uint32) (*Review, error)
+	GetReviews(ctx context.Context, filter Filter, limit, offset int) ([]*Review, error)
+	ReplyToReview(ctx context.Context, id, sellerID uint32, reply string) (*Review, error)
+	MarkHelpful(ctx context.Context, id, userID uint32) (int, error)
+}
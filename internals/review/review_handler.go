@@ -0,0 +1,31 @@
+package review
+
+import (
+	"context"
+)
+
+type Handler struct {
+	Service
+}
+
+func NewHandler(s Service) *Handler {
+	return &Handler{
+		Service: s,
+	}
+}
+
+func (h *Handler) CreateReview(ctx context.Context, review *Review) (*Review, error) {
+	return h.Service.CreateReview(ctx, review)
+}
+
+func (h *Handler) GetReviews(ctx context.Context, filter Filter, limit, offset int) ([]*Review, error) {
+	return h.Service.GetReviews(ctx, filter, limit, offset)
+}
+
+func (h *Handler) ReplyToReview(ctx context.Context, id, sellerID uint32, reply string) (*Review, error) {
+	return h.Service.ReplyToReview(ctx, id, sellerID, reply)
+}
+
+func (h *Handler) MarkHelpful(ctx context.Context, id, userID uint32) (int, error) {
+	return h.Service.MarkHelpful(ctx, id, userID)
+}
@@ -0,0 +1,203 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/database"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateReview checks that the buyer received the order line being reviewed and
+// has not reviewed it yet, then saves the review and refreshes the product and
+// store ratings.
+func (r *repository) CreateReview(ctx context.Context, review *Review) (*Review, error) {
+	if review.Rating < MinRating || review.Rating > MaxRating {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Rating must be between %d and %d", MinRating, MaxRating))
+	}
+	if len(review.Photos) > MaxPhotos {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A review can have at most %d photos", MaxPhotos))
+	}
+	review.Message = strings.TrimSpace(review.Message)
+
+	var order store.Order
+	if err := r.db.WithContext(ctx).Where("uuid = ?", review.OrderID).First(&order).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Order not found")
+	}
+	if order.UserID != fmt.Sprint(review.BuyerID) {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "You can only review your own orders")
+	}
+
+	var line *store.TrackedProduct
+	for i := range order.Products {
+		if order.Products[i].ID == review.ProductID {
+			line = &order.Products[i]
+			break
+		}
+	}
+	if line == nil {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Product is not part of this order")
+	}
+	if order.Status != "delivered" && line.Status != "delivered" {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Only delivered items can be reviewed")
+	}
+
+	var count int64
+	r.db.WithContext(ctx).Model(&Review{}).Where("order_id = ? AND product_id = ?", review.OrderID, review.ProductID).Count(&count)
+	if count > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "You have already reviewed this item")
+	}
+
+	var seller struct {
+		ID     uint32
+		UserID uint32
+	}
+	if err := r.db.WithContext(ctx).Table("stores").Select("id, user_id").Where("name = ? AND deleted_at IS NULL", line.Store).Take(&seller).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Store not found")
+	}
+	review.StoreID = seller.ID
+	review.SellerID = seller.UserID
+	review.ProductName = line.Name
+	review.ProductThumbnail = line.Thumbnail
+
+	if review.Nickname == "" {
+		r.db.WithContext(ctx).Table("users").Select("fullname").Where("id = ?", review.BuyerID).Scan(&review.Nickname)
+	}
+	if review.Photos == nil {
+		review.Photos = []string{}
+	}
+	review.Verified = true
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		return refreshRatings(tx, review.ProductID, review.StoreID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// refreshRatings recomputes the average rating and review count of a product and store
+// from its verified reviews. Legacy reviews stay listed but do not count.
+func refreshRatings(tx *gorm.DB, productID, storeID uint32) error {
+	err := tx.Exec(`
+		UPDATE products SET
+			rating_average = COALESCE((SELECT AVG(rating) FROM reviews WHERE product_id = ? AND verified AND deleted_at IS NULL), 0),
+			review_count = (SELECT COUNT(*) FROM reviews WHERE product_id = ? AND verified AND deleted_at IS NULL)
+		WHERE id = ?
+	`, productID, productID, productID).Error
+	if err != nil {
+		return err
+	}
+	return tx.Exec(`
+		UPDATE stores SET
+			rating_average = COALESCE((SELECT AVG(rating) FROM reviews WHERE store_id = ? AND verified AND deleted_at IS NULL), 0),
+			review_count = (SELECT COUNT(*) FROM reviews WHERE store_id = ? AND verified AND deleted_at IS NULL)
+		WHERE id = ?
+	`, storeID, storeID, storeID).Error
+}
+
+func (r *repository) GetReview(ctx context.Context, id uint32) (*Review, error) {
+	var review Review
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&review).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Review not found")
+	}
+	return &review, nil
+}
+
+// GetReviews lists reviews matching the filter, most helpful first.
+func (r *repository) GetReviews(ctx context.Context, filter Filter, limit, offset int) ([]*Review, error) {
+	var reviews []*Review
+	query := r.db.WithContext(ctx)
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.StoreID != 0 {
+		query = query.Where("store_id = ?", filter.StoreID)
+	}
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+	if err := query.Order("helpful_count DESC, created_at DESC").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ReplyToReview sets the seller's public reply to a review, replacing any earlier one.
+func (r *repository) ReplyToReview(ctx context.Context, id, sellerID uint32, reply string) (*Review, error) {
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Reply cannot be empty")
+	}
+	review, err := r.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.SellerID != sellerID {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "Only the seller can reply to this review")
+	}
+
+	now := time.Now()
+	err = r.db.WithContext(ctx).Model(review).Updates(map[string]interface{}{
+		"seller_reply":      reply,
+		"seller_replied_at": now,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	review.SellerReply = reply
+	review.SellerRepliedAt = &now
+	return review, nil
+}
+
+// MarkHelpful records the user's "helpful" vote and returns the review's new
+// count. Voting twice has no effect.
+func (r *repository) MarkHelpful(ctx context.Context, id, userID uint32) (int, error) {
+	review, err := r.GetReview(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if review.BuyerID == userID {
+		return 0, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "You cannot vote on your own review")
+	}
+
+	var helpful int
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where(HelpfulVote{ReviewID: id, UserID: userID}).FirstOrCreate(&HelpfulVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&Review{}).Where("id = ?", id).UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&Review{}).Where("id = ?", id).Select("helpful_count").Scan(&helpful).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return helpful, nil
+}
@@ -0,0 +1,48 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+)
+
+func TestRefreshRatingsCountsVerifiedReviews(t *testing.T) {
+	db := testdb.Open(t, &product.Product{}, &store.Store{}, &Review{})
+	deleted := &Review{ProductID: 1, StoreID: 2, Rating: 1, Verified: true}
+	for _, row := range []interface{}{
+		&product.Product{ID: 1},
+		&store.Store{ID: 2},
+		&[]*Review{
+			{ProductID: 1, StoreID: 2, Rating: 4, Verified: true},
+			{ProductID: 1, StoreID: 2, Rating: 5, Verified: true},
+			{ProductID: 1, StoreID: 2, Rating: 1},
+		},
+		deleted,
+	} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("failed to set up test database: %v", err)
+		}
+	}
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatalf("failed to delete review: %v", err)
+	}
+
+	if err := refreshRatings(db, 1, 2); err != nil {
+		t.Fatalf("refreshRatings: %v", err)
+	}
+
+	for _, table := range []string{"products", "stores"} {
+		var got struct {
+			RatingAverage float64
+			ReviewCount   int
+		}
+		if err := db.Table(table).Select("rating_average, review_count").Take(&got).Error; err != nil {
+			t.Fatalf("failed to load %s: %v", table, err)
+		}
+		if got.RatingAverage != 4.5 || got.ReviewCount != 2 {
+			t.Errorf("%s rating = %v over %d reviews, want 4.5 over 2", table, got.RatingAverage, got.ReviewCount)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package review
+
+import (
+	"context"
+	"time"
+)
+
+type service struct {
+	Repository
+	timeout time.Duration
+}
+
+func NewService(repository Repository) Service {
+	return &service{
+		repository,
+		time.Duration(5) * time.Second,
+	}
+}
+
+func (s *service) CreateReview(c context.Context, review *Review) (*Review, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.CreateReview(ctx, review)
+}
+
+func (s *service) GetReview(c context.Context, id uint32) (*Review, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetReview(ctx, id)
+}
+
+func (s *service) GetReviews(c context.Context, filter Filter, limit, offset int) ([]*Review, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.GetReviews(ctx, filter, limit, offset)
+}
+
+func (s *service) ReplyToReview(c context.Context, id, sellerID uint32, reply string) (*Review, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.ReplyToReview(ctx, id, sellerID, reply)
+}
+
+func (s *service) MarkHelpful(c context.Context, id, userID uint32) (int, error) {
+	ctx, cancel := context.WithTimeout(c, s.timeout)
+	defer cancel()
+	return s.Repository.MarkHelpful(ctx, id, userID)
+}
@@ -65,18 +65,24 @@ type DVADetails struct {
 
 type Downloads struct {
 	gorm.Model
-	ID        string    `json:"id" db:"id"`
-	Thumbnail string    `json:"thumbnail" db:"thumbnail"`
-	Name      string    `json:"name" db:"name"`
-	Price     float64   `json:"price" db:"price"`
-	Discount  int       `json:"discount" db:"discount"`
-	UUID      string    `json:"uuid" db:"uuid"`
-	File      string    `json:"file" db:"file"`
-	Users     []string  `gorm:"serializer:json" json:"paid_users" db:"paid_users"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID            string    `json:"id" db:"id"`
+	Thumbnail     string    `json:"thumbnail" db:"thumbnail"`
+	Name          string    `json:"name" db:"name"`
+	Price         float64   `json:"price" db:"price"`
+	Discount      int       `json:"discount" db:"discount"`
+	UUID          string    `json:"uuid" db:"uuid"`
+	ProductID     uint32    `json:"product_id" db:"product_id"`
+	File          string    `json:"file" db:"file"`
+	Users         []string  `gorm:"serializer:json" json:"paid_users" db:"paid_users"`
+	DownloadCount int       `json:"download_count" db:"download_count"`
+	MaxDownloads  int       `json:"max_downloads" db:"max_downloads" gorm:"default:5"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// DefaultDownloadLimit is how many times a purchased file can be downloaded.
+const DefaultDownloadLimit = 5
+
 type WithdrawalAccount struct {
 	BankID        int    `json:"bank_id"`
 	BankName      string `json:"bank_name"`
@@ -109,6 +115,9 @@ type Store struct {
 	Accounts           []*WithdrawalAccount `gorm:"serializer:json" json:"accounts" db:"accounts"`
 	Orders             []*StoreOrder        `gorm:"serializer:json"`
 	MaintenanceMode    bool                 `json:"maintenance_mode" db:"maintenance_mode"`
+	Trusted            bool                 `json:"trusted" db:"trusted"` // products skip the moderation queue
+	RatingAverage      float64              `json:"rating_average" db:"rating_average"`
+	ReviewCount        int                  `json:"review_count" db:"review_count"`
 }
 
 type UpdateStore struct {
@@ -123,7 +132,6 @@ type UpdateStore struct {
 	Transactions       []*Transactions    `gorm:"serializer:json"`
 	Followers          []Follower         `gorm:"serializer:json"`
 	Orders             []*StoreOrder      `gorm:"serializer:json"`
-	Reviews            []*Review          `gorm:"serializer:json"`
 	Account            *WithdrawalAccount `gorm:"serializer:json"`
 	Products           []Product          `gorm:"serializer:json"`
 	Wallet             float64            `json:"wallet" db:"wallet"`
@@ -150,6 +158,20 @@ type TrackedProduct struct {
 	Status    string    `json:"status" db:"status"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// Multi-buy deal applied to the line at checkout
+	DealLabel   string  `json:"deal_label,omitempty" db:"deal_label"`
+	DealSavings float64 `json:"deal_savings,omitempty" db:"deal_savings"`
+	// Sale the line was priced by and the units it sold at the sale price,
+	// counted against the sale's cap once the order is paid
+	SaleItemID uint32 `json:"sale_item_id,omitempty" db:"sale_item_id"`
+	SaleUnits  int    `json:"sale_units,omitempty" db:"sale_units"`
+	// Booking made for a service line and the slot it is for
+	BookingID uint32     `json:"booking_id,omitempty" db:"booking_id"`
+	SlotStart *time.Time `json:"slot_start,omitempty" db:"slot_start"`
+	// Set when the store was closed at checkout; the line ships once it reopens
+	ShipsAfter *time.Time `json:"ships_after,omitempty" db:"ships_after"`
+	// How the store gets the line to the buyer, one of the Fulfilment methods
+	Fulfilment string `json:"fulfilment,omitempty" db:"fulfilment"`
 }
 type DeliveryDetails struct {
 	Method  string  `json:"method,omitempty" db:"method"`
@@ -180,6 +202,9 @@ type Order struct {
 	CreatedAt           time.Time        `json:"created_at"`
 	UpdatedAt           time.Time        `json:"updated_at"`
 	Products            []TrackedProduct `gorm:"serializer:json" json:"products" db:"products"`
+	// Fulfilment method the buyer picked for each store, by store name, when
+	// checking out. Stores left out get their policy's default.
+	FulfilmentChoices map[string]string `json:"-" gorm:"-"`
 }
 
 type Customer struct {
@@ -211,24 +236,6 @@ type StoreOrder struct {
 	CreatedAt time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
 }
-type Buyer struct {
-	Nickname string `json:"nickname" db:"nickname"`
-	Avatar   string `json:"avatar" db:"avatar"`
-	Comment  string `json:"comment" db:"comment"`
-}
-
-type Review struct {
-	gorm.Model
-	StoreID   uint32    `json:"store_id" db:"store_id"`
-	ProductID uint32    `json:"product_id" db:"product_id"`
-	OrderID   string    `json:"uuid" db:"uuid"`
-	Buyer     *Buyer    `gorm:"serializer:json" json:"buyer" db:"buyer"`
-	SellerID  uint32    `json:"seller_id" db:"seller_id"`
-	Rating    float64   `json:"rating" db:"rating"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-}
-
 type Fund struct {
 	StoreID       uint32  `json:"store_id" db:"store_id"`
 	UserID        uint32  `json:"user_id" db:"user_id"`
@@ -339,14 +346,72 @@ type Repository interface {
 	WithdrawFund(ctx context.Context, req *Fund) error
 	GetInvoices(ctx context.Context, storeID uint32) ([]*Invoice, error)
 	GetFollowedStores(ctx context.Context, userID uint32) ([]*Store, error)
-	AddReview(ctx context.Context, review *Review) error
-	GetReviews(ctx context.Context, filter string, value interface{}) ([]*Review, error)
 	GetOrderByUUID(ctx context.Context, uuid string) (*Order, error)
 	UpdateProductUnitsSold(ctx context.Context, productID uint32) error
 	GetAllStores(ctx context.Context, limit, offset int) ([]*Store, error)
 	UpdateStoreBankDetails(ctx context.Context, storeID uint32, account *WithdrawalAccount) error
 	AddStoreEarnings(ctx context.Context, earnings *StoreEarnings) error
 	GetStoreEarnings(ctx context.Context, storeID uint32) ([]*StoreEarnings, error)
+	CheckStoreHandle(ctx context.Context, handle string, storeID uint32) error
+	SetStoreHandle(ctx context.Context, store *Store, handle string) (*Store, error)
+	GetStoreByHandle(ctx context.Context, handle string) (*Store, error)
+	GetQRTarget(ctx context.Context, kind string, id uint32) (*QRTarget, error)
+	RecordQRScan(ctx context.Context, target *QRTarget, ip string) error
+	GetStoreCloseout(ctx context.Context, storeID uint32) (*StoreCloseout, error)
+	GetCloseoutChecklist(ctx context.Context, store *Store) (*CloseoutChecklist, error)
+	StartStoreCloseout(ctx context.Context, store *Store, requestedBy uint32, reason string) (*StoreCloseout, error)
+	CancelStoreCloseout(ctx context.Context, storeID uint32) error
+	CloseStore(ctx context.Context, id, closedBy uint32) (*StoreCloseout, error)
+	GetStorePolicy(ctx context.Context, store *Store) (*StorePolicy, error)
+	SetStorePolicy(ctx context.Context, policy *StorePolicy) (*StorePolicy, error)
+	GetStoreScorecard(ctx context.Context, storeID uint32) (*StoreScorecard, error)
+	RollupStoreScorecards(ctx context.Context) (int, error)
+	OpenDispute(ctx context.Context, dispute *OrderDispute) (*OrderDispute, error)
+	GetDispute(ctx context.Context, id uint32) (*OrderDispute, error)
+	GetBuyerDisputes(ctx context.Context, buyerID uint32) ([]*OrderDispute, error)
+	GetStoreDisputes(ctx context.Context, storeID uint32) ([]*OrderDispute, error)
+	GetDisputeQueue(ctx context.Context, status string) ([]*OrderDispute, error)
+	ResolveDispute(ctx context.Context, id, adminID uint32, upheld bool, resolution string) (*OrderDispute, error)
+	GetStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) (*CustomerList, error)
+	ExportStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) ([]*CustomerProfile, error)
+	GetStoreCustomer(ctx context.Context, store *Store, userID uint32) (*CustomerProfile, error)
+	SetCustomerTags(ctx context.Context, store *Store, userID uint32, tags []string) (*CustomerProfile, error)
+	AddCustomerNote(ctx context.Context, store *Store, note *StoreCustomerNote) (*StoreCustomerNote, error)
+	GetCustomerNotes(ctx context.Context, storeID, userID uint32) ([]*StoreCustomerNote, error)
+	GetCustomerNote(ctx context.Context, id uint32) (*StoreCustomerNote, error)
+	DeleteCustomerNote(ctx context.Context, id uint32) error
+	SendPromo(ctx context.Context, store *Store, campaign *StorePromoCampaign) (*StorePromoCampaign, error)
+	GetPromoCampaigns(ctx context.Context, storeID uint32) ([]*StorePromoCampaign, error)
+	PostAnnouncement(ctx context.Context, a *StoreAnnouncement) (*StoreAnnouncement, error)
+	GetAnnouncement(ctx context.Context, id uint32) (*StoreAnnouncement, error)
+	GetStoreAnnouncements(ctx context.Context, storeID uint32, limit, offset int) ([]*StoreAnnouncement, error)
+	DeleteAnnouncement(ctx context.Context, id uint32) error
+	GetFollowerFeed(ctx context.Context, userID uint32, limit int, after string) (*FeedPage, error)
+	SendFollowDigests(ctx context.Context) (int, error)
+	GetStoreHours(ctx context.Context, storeID uint32) (*StoreHours, error)
+	SetStoreHours(ctx context.Context, hours *StoreHours) (*StoreHours, error)
+	GetStoreClosures(ctx context.Context, storeID uint32, includePast bool) ([]*StoreClosure, error)
+	GetStoreClosure(ctx context.Context, id uint32) (*StoreClosure, error)
+	ScheduleClosure(ctx context.Context, closure *StoreClosure) (*StoreClosure, error)
+	EndClosure(ctx context.Context, id uint32) (*StoreClosure, error)
+	GetStoreAvailability(ctx context.Context, store *Store, at time.Time) (*StoreAvailability, error)
+	NotifyReopenedStores(ctx context.Context) (int, error)
+	GetStoreRole(ctx context.Context, store *Store, userID uint32) (string, error)
+	InviteStaff(ctx context.Context, member *StoreMember) (*StoreMember, error)
+	GetStaffMember(ctx context.Context, id uint32) (*StoreMember, error)
+	GetStoreStaff(ctx context.Context, storeID uint32) ([]*StoreMember, error)
+	GetUserStaffInvites(ctx context.Context, userID uint32) ([]*StoreMember, error)
+	GetUserMemberships(ctx context.Context, userID uint32) ([]*StoreMember, error)
+	AcceptStaffInvite(ctx context.Context, id, userID uint32) (*StoreMember, error)
+	UpdateStaffRole(ctx context.Context, id uint32, role string) (*StoreMember, error)
+	RemoveStaff(ctx context.Context, id uint32) error
+	GetStoreAnalytics(ctx context.Context, storeName string, from, to time.Time, granularity string) (*StoreAnalytics, error)
+	RollupStoreAnalytics(ctx context.Context, day time.Time) error
+	GetTrashedStore(ctx context.Context, id uint32) (*Store, error)
+	GetTrashedStores(ctx context.Context, userID uint32) ([]*Store, error)
+	CountTrashedStoreProducts(ctx context.Context, store *Store) (int, error)
+	RestoreStore(ctx context.Context, id uint32) (*Store, error)
+	PurgeTrashedStores(ctx context.Context, before time.Time) (int, error)
 	GetAllOrders(ctx context.Context) ([]*Order, error)
 	CheckStoreEarningsDiscrepancy(ctx context.Context, storeID uint32) (int, float64, error)
 	CreatePaystackDVAAccount(ctx context.Context, storeID uint32, account *PaystackDVAResponse, email string) error
@@ -377,8 +442,6 @@ type Service interface {
 	UpdateStoreFollowership(ctx context.Context, storeID uint32, follower *Follower, action string) (*Store, error)
 	WithdrawFund(ctx context.Context, req *Fund) error
 	GetInvoices(ctx context.Context, storeID uint32) ([]*Invoice, error)
-	AddReview(ctx context.Context, review *Review) error
-	GetReviews(ctx context.Context, filter string, value interface{}) ([]*Review, error)
 	GetDVAAccount(ctx context.Context, email string) (*DVAAccount, error)
 	GetDVABalance(ctx context.Context, id string) (float64, error)
 	GetFollowedStores(ctx context.Context, userID uint32) ([]*Store, error)
@@ -391,4 +454,64 @@ type Service interface {
 	GetPaystackDVAAccount(ctx context.Context, storeID uint32) (*PaystackDVAResponse, error)
 	SyncExistingPaystackDVAAccounts(ctx context.Context) error
 	GetStoreEarnings(ctx context.Context, storeID uint32) ([]*StoreEarnings, error)
+	CheckStoreHandle(ctx context.Context, handle string, storeID uint32) error
+	SetStoreHandle(ctx context.Context, store *Store, handle string) (*Store, error)
+	GetStoreByHandle(ctx context.Context, handle string) (*Store, error)
+	GetQRTarget(ctx context.Context, kind string, id uint32) (*QRTarget, error)
+	RecordQRScan(ctx context.Context, target *QRTarget, ip string) error
+	GetStoreCloseout(ctx context.Context, storeID uint32) (*StoreCloseout, error)
+	GetCloseoutChecklist(ctx context.Context, store *Store) (*CloseoutChecklist, error)
+	StartStoreCloseout(ctx context.Context, store *Store, requestedBy uint32, reason string) (*StoreCloseout, error)
+	CancelStoreCloseout(ctx context.Context, storeID uint32) error
+	CloseStore(ctx context.Context, id, closedBy uint32) (*StoreCloseout, error)
+	GetStorePolicy(ctx context.Context, store *Store) (*StorePolicy, error)
+	SetStorePolicy(ctx context.Context, policy *StorePolicy) (*StorePolicy, error)
+	GetStoreScorecard(ctx context.Context, storeID uint32) (*StoreScorecard, error)
+	RollupStoreScorecards(ctx context.Context) (int, error)
+	OpenDispute(ctx context.Context, dispute *OrderDispute) (*OrderDispute, error)
+	GetDispute(ctx context.Context, id uint32) (*OrderDispute, error)
+	GetBuyerDisputes(ctx context.Context, buyerID uint32) ([]*OrderDispute, error)
+	GetStoreDisputes(ctx context.Context, storeID uint32) ([]*OrderDispute, error)
+	GetDisputeQueue(ctx context.Context, status string) ([]*OrderDispute, error)
+	ResolveDispute(ctx context.Context, id, adminID uint32, upheld bool, resolution string) (*OrderDispute, error)
+	GetStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) (*CustomerList, error)
+	ExportStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) ([]*CustomerProfile, error)
+	GetStoreCustomer(ctx context.Context, store *Store, userID uint32) (*CustomerProfile, error)
+	SetCustomerTags(ctx context.Context, store *Store, userID uint32, tags []string) (*CustomerProfile, error)
+	AddCustomerNote(ctx context.Context, store *Store, note *StoreCustomerNote) (*StoreCustomerNote, error)
+	GetCustomerNotes(ctx context.Context, storeID, userID uint32) ([]*StoreCustomerNote, error)
+	GetCustomerNote(ctx context.Context, id uint32) (*StoreCustomerNote, error)
+	DeleteCustomerNote(ctx context.Context, id uint32) error
+	SendPromo(ctx context.Context, store *Store, campaign *StorePromoCampaign) (*StorePromoCampaign, error)
+	GetPromoCampaigns(ctx context.Context, storeID uint32) ([]*StorePromoCampaign, error)
+	PostAnnouncement(ctx context.Context, a *StoreAnnouncement) (*StoreAnnouncement, error)
+	GetAnnouncement(ctx context.Context, id uint32) (*StoreAnnouncement, error)
+	GetStoreAnnouncements(ctx context.Context, storeID uint32, limit, offset int) ([]*StoreAnnouncement, error)
+	DeleteAnnouncement(ctx context.Context, id uint32) error
+	GetFollowerFeed(ctx context.Context, userID uint32, limit int, after string) (*FeedPage, error)
+	SendFollowDigests(ctx context.Context) (int, error)
+	GetStoreHours(ctx context.Context, storeID uint32) (*StoreHours, error)
+	SetStoreHours(ctx context.Context, hours *StoreHours) (*StoreHours, error)
+	GetStoreClosures(ctx context.Context, storeID uint32, includePast bool) ([]*StoreClosure, error)
+	GetStoreClosure(ctx context.Context, id uint32) (*StoreClosure, error)
+	ScheduleClosure(ctx context.Context, closure *StoreClosure) (*StoreClosure, error)
+	EndClosure(ctx context.Context, id uint32) (*StoreClosure, error)
+	GetStoreAvailability(ctx context.Context, store *Store, at time.Time) (*StoreAvailability, error)
+	NotifyReopenedStores(ctx context.Context) (int, error)
+	GetStoreRole(ctx context.Context, store *Store, userID uint32) (string, error)
+	InviteStaff(ctx context.Context, member *StoreMember) (*StoreMember, error)
+	GetStaffMember(ctx context.Context, id uint32) (*StoreMember, error)
+	GetStoreStaff(ctx context.Context, storeID uint32) ([]*StoreMember, error)
+	GetUserStaffInvites(ctx context.Context, userID uint32) ([]*StoreMember, error)
+	GetUserMemberships(ctx context.Context, userID uint32) ([]*StoreMember, error)
+	AcceptStaffInvite(ctx context.Context, id, userID uint32) (*StoreMember, error)
+	UpdateStaffRole(ctx context.Context, id uint32, role string) (*StoreMember, error)
+	RemoveStaff(ctx context.Context, id uint32) error
+	GetStoreAnalytics(ctx context.Context, storeName string, from, to time.Time, granularity string) (*StoreAnalytics, error)
+	RollupStoreAnalytics(ctx context.Context, day time.Time) error
+	GetTrashedStore(ctx context.Context, id uint32) (*Store, error)
+	GetTrashedStores(ctx context.Context, userID uint32) ([]*Store, error)
+	CountTrashedStoreProducts(ctx context.Context, store *Store) (int, error)
+	RestoreStore(ctx context.Context, id uint32) (*Store, error)
+	PurgeTrashedStores(ctx context.Context, before time.Time) (int, error)
 }
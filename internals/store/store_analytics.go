@@ -0,0 +1,374 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+)
+
+// Analytics granularities
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+const (
+	// MaxAnalyticsRange is the longest period a single analytics query covers.
+	MaxAnalyticsRange = 366 * 24 * time.Hour
+	// TopProductsLimit is how many products each top products list holds.
+	TopProductsLimit = 10
+)
+
+// StoreSalesDaily holds a store's paid sales of one day. Orders holding
+// products of several stores count once for each of them, with only that
+// store's lines in the revenue.
+type StoreSalesDaily struct {
+	Store     string    `json:"store" gorm:"primaryKey"`
+	Day       time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Revenue   float64   `json:"revenue"`
+	Orders    int       `json:"orders"`
+	Units     int       `json:"units"`
+	Customers int       `json:"customers"`
+}
+
+func (StoreSalesDaily) TableName() string {
+	return "store_sales_daily"
+}
+
+// StoreProductSalesDaily holds the paid sales of one product of a store in a day.
+type StoreProductSalesDaily struct {
+	Store       string    `json:"store" gorm:"primaryKey"`
+	ProductID   uint32    `json:"product_id" gorm:"primaryKey"`
+	Day         time.Time `json:"day" gorm:"primaryKey;type:date"`
+	ProductName string    `json:"product_name"`
+	Revenue     float64   `json:"revenue"`
+	Units       int       `json:"units"`
+	Orders      int       `json:"orders"`
+}
+
+func (StoreProductSalesDaily) TableName() string {
+	return "store_product_sales_daily"
+}
+
+// StoreCustomerDaily holds what one customer bought from a store in a day.
+type StoreCustomerDaily struct {
+	Store   string    `json:"store" gorm:"primaryKey"`
+	UserID  string    `json:"user_id" gorm:"primaryKey"`
+	Day     time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Orders  int       `json:"orders"`
+	Revenue float64   `json:"revenue"`
+}
+
+func (StoreCustomerDaily) TableName() string {
+	return "store_customer_daily"
+}
+
+// StoreVisitorDaily holds the product page views of a store in a day.
+type StoreVisitorDaily struct {
+	Store          string    `json:"store" gorm:"primaryKey"`
+	Day            time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Views          int       `json:"views"`
+	UniqueVisitors int       `json:"unique_visitors"`
+}
+
+func (StoreVisitorDaily) TableName() string {
+	return "store_visitor_daily"
+}
+
+// AnalyticsBucket is one point of the analytics time series.
+type AnalyticsBucket struct {
+	Start          time.Time `json:"start"`
+	Revenue        float64   `json:"revenue"`
+	Orders         int       `json:"orders"`
+	Units          int       `json:"units"`
+	Views          int       `json:"views"`
+	UniqueVisitors int       `json:"unique_visitors"`
+}
+
+// ProductSales is a product's share of a store's sales over a period.
+type ProductSales struct {
+	ProductID   uint32  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Revenue     float64 `json:"revenue"`
+	Units       int     `json:"units"`
+	Orders      int     `json:"orders"`
+}
+
+// TrafficSource counts the product page views of a store that came from one
+// source, such as "direct" or QRSource.
+type TrafficSource struct {
+	Source string `json:"source"`
+	Views  int    `json:"views"`
+}
+
+// StoreAnalytics summarises a store's sales and traffic between From and To.
+// Unique visitors are counted once over the period, and once in each bucket
+// they came in. ConversionRate is orders per unique visitor and
+// RepeatCustomerRate the share of the period's customers that had ordered from
+// the store more than once by its end.
+type StoreAnalytics struct {
+	From               time.Time          `json:"from"`
+	To                 time.Time          `json:"to"`
+	Granularity        string             `json:"granularity"`
+	Revenue            float64            `json:"revenue"`
+	Orders             int                `json:"orders"`
+	Units              int                `json:"units"`
+	AverageOrderValue  float64            `json:"average_order_value"`
+	Views              int                `json:"views"`
+	UniqueVisitors     int                `json:"unique_visitors"`
+	ConversionRate     float64            `json:"conversion_rate"`
+	Customers          int                `json:"customers"`
+	RepeatCustomers    int                `json:"repeat_customers"`
+	RepeatCustomerRate float64            `json:"repeat_customer_rate"`
+	Series             []*AnalyticsBucket `json:"series"`
+	TopByRevenue       []*ProductSales    `json:"top_by_revenue"`
+	TopByUnits         []*ProductSales    `json:"top_by_units"`
+	TrafficSources     []*TrafficSource   `json:"traffic_sources"`
+	QRScans            int                `json:"qr_scans"` // of the store's, its products' and its invoices' codes
+}
+
+// paidOrderLines selects the lines of the paid, uncancelled orders placed in
+// [start, end), with what each line earned its store after discounts and deals.
+const paidOrderLines = `
+	SELECT o.uuid, o.user_id, l->>'store' AS store,
+		(l->>'id')::int AS product_id, l->>'name' AS product_name,
+		COALESCE((l->>'quantity')::int, 0) AS units,
+		(COALESCE((l->>'price')::numeric, 0) - COALESCE((l->>'discount')::numeric, 0)) * COALESCE((l->>'quantity')::int, 0)
+			- COALESCE((l->>'deal_savings')::numeric, 0) AS revenue
+	FROM orders o, jsonb_array_elements(o.products::jsonb) AS l
+	WHERE o.trans_status = 'paid' AND o.status <> 'canceled' AND o.deleted_at IS NULL
+		AND o.created_at >= @start AND o.created_at < @end`
+
+// RollupStoreAnalytics rebuilds the daily sales, customer and visitor
+// aggregates of every store for the given day.
+func (r *repository) RollupStoreAnalytics(ctx context.Context, day time.Time) error {
+	start := day.UTC().Truncate(24 * time.Hour)
+	args := map[string]interface{}{"day": start, "start": start, "end": start.Add(24 * time.Hour)}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Orders cancelled since the last run drop out, so the day is rebuilt from scratch
+		for _, table := range []string{"store_sales_daily", "store_product_sales_daily", "store_customer_daily", "store_visitor_daily"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE day = ?", start).Error; err != nil {
+				return err
+			}
+		}
+
+		statements := []string{`
+			INSERT INTO store_sales_daily (store, day, revenue, orders, units, customers)
+			SELECT store, @day, SUM(revenue), COUNT(DISTINCT uuid), SUM(units), COUNT(DISTINCT user_id)
+			FROM (` + paidOrderLines + `) lines
+			GROUP BY store`, `
+			INSERT INTO store_product_sales_daily (store, product_id, day, product_name, revenue, units, orders)
+			SELECT store, product_id, @day, MAX(product_name), SUM(revenue), SUM(units), COUNT(DISTINCT uuid)
+			FROM (` + paidOrderLines + `) lines
+			GROUP BY store, product_id`, `
+			INSERT INTO store_customer_daily (store, user_id, day, orders, revenue)
+			SELECT store, user_id, @day, COUNT(DISTINCT uuid), SUM(revenue)
+			FROM (` + paidOrderLines + `) lines
+			GROUP BY store, user_id`, `
+			INSERT INTO store_visitor_daily (store, day, views, unique_visitors)
+			SELECT p.store, @day, COUNT(*),
+				COUNT(DISTINCT CASE WHEN v.user_id <> 0 THEN 'u' || v.user_id::text ELSE 's' || v.session_id END)
+			FROM product_views v
+			JOIN products p ON p.id = v.product_id
+			WHERE v.created_at >= @start AND v.created_at < @end
+			GROUP BY p.store`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement, args).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetStoreAnalytics reads a store's analytics between from and to, inclusive,
+// from the daily aggregates, and its unique visitors and traffic sources from
+// the raw views.
+func (r *repository) GetStoreAnalytics(ctx context.Context, storeName string, from, to time.Time, granularity string) (*StoreAnalytics, error) {
+	from, to = from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour)
+	if to.Before(from) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "The end of the period must not be before its start")
+	}
+	if to.Sub(from) > MaxAnalyticsRange {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Analytics cover at most a year at a time")
+	}
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	if granularity != GranularityDay && granularity != GranularityWeek && granularity != GranularityMonth {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Granularity must be day, week or month")
+	}
+
+	db := r.db.WithContext(ctx)
+	var sales []*StoreSalesDaily
+	if err := db.Where("store = ? AND day BETWEEN ? AND ?", storeName, from, to).Order("day ASC").Find(&sales).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch store sales: %v", err)
+	}
+	var visits []*StoreVisitorDaily
+	if err := db.Where("store = ? AND day BETWEEN ? AND ?", storeName, from, to).Order("day ASC").Find(&visits).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch store visitors: %v", err)
+	}
+
+	analytics := &StoreAnalytics{From: from, To: to, Granularity: granularity}
+
+	// Every bucket of the period is returned, including the empty ones
+	buckets := map[time.Time]*AnalyticsBucket{}
+	for start := bucketStart(from, granularity); !start.After(to); start = nextBucket(start, granularity) {
+		bucket := &AnalyticsBucket{Start: start}
+		buckets[start] = bucket
+		analytics.Series = append(analytics.Series, bucket)
+	}
+	for _, s := range sales {
+		bucket, ok := buckets[bucketStart(s.Day, granularity)]
+		if !ok {
+			continue
+		}
+		bucket.Revenue += s.Revenue
+		bucket.Orders += s.Orders
+		bucket.Units += s.Units
+		analytics.Revenue += s.Revenue
+		analytics.Orders += s.Orders
+		analytics.Units += s.Units
+	}
+	for _, v := range visits {
+		bucket, ok := buckets[bucketStart(v.Day, granularity)]
+		if !ok {
+			continue
+		}
+		bucket.Views += v.Views
+		analytics.Views += v.Views
+	}
+
+	// Daily unique visitors can't be added up without counting a visitor once
+	// per day they came, so visitors are counted from the raw views
+	end := to.Add(24 * time.Hour)
+	var visitors []struct {
+		Start    time.Time
+		Visitors int
+	}
+	err := db.Raw(`
+		SELECT date_trunc(?, v.created_at AT TIME ZONE 'UTC') AS start, COUNT(DISTINCT v.viewer) AS visitors
+		FROM product_views v
+		JOIN products p ON p.id = v.product_id
+		WHERE p.store = ? AND v.created_at >= ? AND v.created_at < ?
+		GROUP BY 1
+	`, granularity, storeName, from, end).Scan(&visitors).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count store visitors: %v", err)
+	}
+	for _, v := range visitors {
+		if bucket, ok := buckets[bucketStart(v.Start, granularity)]; ok {
+			bucket.UniqueVisitors = v.Visitors
+		}
+	}
+	err = db.Raw(`
+		SELECT COUNT(DISTINCT v.viewer)
+		FROM product_views v
+		JOIN products p ON p.id = v.product_id
+		WHERE p.store = ? AND v.created_at >= ? AND v.created_at < ?
+	`, storeName, from, end).Scan(&analytics.UniqueVisitors).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count store visitors: %v", err)
+	}
+	if analytics.Orders > 0 {
+		analytics.AverageOrderValue = analytics.Revenue / float64(analytics.Orders)
+	}
+	if analytics.UniqueVisitors > 0 {
+		analytics.ConversionRate = float64(analytics.Orders) / float64(analytics.UniqueVisitors)
+	}
+
+	var customers struct {
+		Customers       int
+		RepeatCustomers int
+	}
+	err = db.Raw(`
+		SELECT COUNT(*) AS customers, COUNT(*) FILTER (WHERE lifetime_orders > 1) AS repeat_customers
+		FROM (
+			SELECT user_id, SUM(orders) AS lifetime_orders
+			FROM store_customer_daily
+			WHERE store = ? AND day <= ? AND user_id IN (
+				SELECT user_id FROM store_customer_daily WHERE store = ? AND day BETWEEN ? AND ?
+			)
+			GROUP BY user_id
+		) c
+	`, storeName, to, storeName, from, to).Scan(&customers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch store customers: %v", err)
+	}
+	analytics.Customers = customers.Customers
+	analytics.RepeatCustomers = customers.RepeatCustomers
+	if customers.Customers > 0 {
+		analytics.RepeatCustomerRate = float64(customers.RepeatCustomers) / float64(customers.Customers)
+	}
+
+	for _, top := range []struct {
+		order string
+		into  *[]*ProductSales
+	}{
+		{"revenue DESC", &analytics.TopByRevenue},
+		{"units DESC", &analytics.TopByUnits},
+	} {
+		err := db.Model(&StoreProductSalesDaily{}).
+			Select("product_id, MAX(product_name) AS product_name, SUM(revenue) AS revenue, SUM(units) AS units, SUM(orders) AS orders").
+			Where("store = ? AND day BETWEEN ? AND ?", storeName, from, to).
+			Group("product_id").
+			Order(top.order).
+			Limit(TopProductsLimit).
+			Scan(top.into).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch top products: %v", err)
+		}
+	}
+
+	// Sources are not rolled up, so they are counted from the raw events
+	err = db.Raw(`
+		SELECT v.source, COUNT(*) AS views
+		FROM product_views v
+		JOIN products p ON p.id = v.product_id
+		WHERE p.store = ? AND v.created_at >= ? AND v.created_at < ?
+		GROUP BY v.source
+		ORDER BY views DESC
+	`, storeName, from, end).Scan(&analytics.TrafficSources).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch traffic sources: %v", err)
+	}
+	var scans int64
+	err = db.Model(&QRScan{}).
+		Where("store_id IN (SELECT id FROM stores WHERE name = ?) AND created_at >= ? AND created_at < ?", storeName, from, end).
+		Count(&scans).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count QR scans: %v", err)
+	}
+	analytics.QRScans = int(scans)
+	return analytics, nil
+}
+
+// bucketStart returns the first day of the bucket holding day. Weeks start on
+// Monday.
+func bucketStart(day time.Time, granularity string) time.Time {
+	day = day.UTC().Truncate(24 * time.Hour)
+	switch granularity {
+	case GranularityWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+func nextBucket(start time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
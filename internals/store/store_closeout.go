@@ -0,0 +1,295 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Closeout statuses
+const (
+	CloseoutPending  = "pending" // closing, settling what the store still owes
+	CloseoutClosed   = "closed"
+	CloseoutReopened = "reopened" // restored from the trash after closing
+)
+
+// RecordRetention is how long a closed store's orders and earnings are kept
+// before the store can be purged. Nigerian company and tax law asks for six
+// years of accounting records.
+const RecordRetention = 6 * 365 * 24 * time.Hour
+
+// StoreCloseout tracks a store being closed. Closing starts by putting the
+// store in maintenance mode so it takes no new orders, and finishes once
+// nothing is left to settle.
+type StoreCloseout struct {
+	ID               uint32     `json:"id" gorm:"primaryKey"`
+	StoreID          uint32     `json:"store_id" gorm:"not null;uniqueIndex"`
+	StoreName        string     `json:"store_name" gorm:"not null"`
+	Reason           string     `json:"reason"`
+	Status           string     `json:"status" gorm:"not null;index"`
+	WasInMaintenance bool       `json:"was_in_maintenance"` // restored if closing is cancelled
+	RequestedBy      uint32     `json:"requested_by"`
+	ClosedBy         uint32     `json:"closed_by"`
+	ProductsArchived int        `json:"products_archived"`
+	FinalPayout      float64    `json:"final_payout"` // withdrawn while closing
+	RetainUntil      *time.Time `json:"retain_until"`
+	ClosedAt         *time.Time `json:"closed_at"`
+	ReopenedAt       *time.Time `json:"reopened_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (StoreCloseout) TableName() string {
+	return "store_closeouts"
+}
+
+// CloseoutChecklist is what a store still has to settle before it can close.
+//
+// Everything on it has to be settled by this store; none of it can be
+// transferred to another store. Open orders, bookings and disputes are between
+// the buyer and this store, for products no other store sells. Earnings are
+// recorded against the orders that earned them and are kept for
+// RecordRetention, and the balance is paid out through this store's own
+// withdrawals, so moving either would rewrite the closed store's accounts.
+type CloseoutChecklist struct {
+	StoreID            uint32
+	OpenOrders         []string // UUIDs of paid orders the store has not delivered or cancelled
+	UpcomingBookings   int
+	PendingEarnings    float64
+	PendingWithdrawals int
+	OpenDisputes       int
+	Balance            float64 // wallet and Paystack balance not yet paid out
+	Closeout           *StoreCloseout
+}
+
+// Blockers describes each obligation that stops the store closing.
+func (c *CloseoutChecklist) Blockers() []string {
+	blockers := []string{}
+	if n := len(c.OpenOrders); n > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d open order(s) must be delivered or cancelled", n))
+	}
+	if c.UpcomingBookings > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d upcoming booking(s) must be completed or cancelled", c.UpcomingBookings))
+	}
+	if c.PendingEarnings > 0 {
+		blockers = append(blockers, fmt.Sprintf("%.2f in earnings has not been released yet", c.PendingEarnings))
+	}
+	if c.PendingWithdrawals > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d withdrawal(s) are still being processed", c.PendingWithdrawals))
+	}
+	if c.OpenDisputes > 0 {
+		blockers = append(blockers, fmt.Sprintf("%d order dispute(s) are still open", c.OpenDisputes))
+	}
+	if c.Balance > 0 {
+		blockers = append(blockers, fmt.Sprintf("%.2f is still in the store balance; request a final payout first", c.Balance))
+	}
+	return blockers
+}
+
+// CanClose reports whether nothing is left to settle.
+func (c *CloseoutChecklist) CanClose() bool {
+	return len(c.Blockers()) == 0
+}
+
+// GetStoreCloseout returns the closeout of a store, whether or not the store
+// is in the trash.
+func (r *repository) GetStoreCloseout(ctx context.Context, storeID uint32) (*StoreCloseout, error) {
+	var closeout StoreCloseout
+	err := r.db.WithContext(ctx).Where("store_id = ?", storeID).First(&closeout).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "This store is not being closed")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closeout: %v", err)
+	}
+	return &closeout, nil
+}
+
+func (r *repository) GetCloseoutChecklist(ctx context.Context, store *Store) (*CloseoutChecklist, error) {
+	db := r.db.WithContext(ctx)
+	checklist := &CloseoutChecklist{StoreID: store.ID, Balance: store.Wallet + store.PaystackBalance}
+
+	err := db.Model(&Order{}).
+		Where("trans_status = 'paid' AND ? = ANY(stores_id) AND status NOT IN ('delivered', 'canceled', 'completed')", store.Name).
+		Where("NOT EXISTS (SELECT 1 FROM store_order_events e WHERE e.order_uuid = orders.uuid AND e.store = ? AND e.status IN ('delivered', 'canceled'))", store.Name).
+		Order("created_at").
+		Pluck("uuid", &checklist.OpenOrders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open orders: %v", err)
+	}
+
+	var count int64
+	err = db.Model(&booking.Booking{}).
+		Where("store = ? AND status IN ? AND starts_at > ?", store.Name, []string{booking.StatusHeld, booking.StatusConfirmed}, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bookings: %v", err)
+	}
+	checklist.UpcomingBookings = int(count)
+
+	err = db.Model(&StoreEarnings{}).
+		Where("store_id = ? AND status = 'pending'", store.ID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&checklist.PendingEarnings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check earnings: %v", err)
+	}
+
+	err = db.Table("withdrawals").
+		Where("store_id = ? AND status IN ?", store.ID, []string{string(StatusPending), string(StatusApproved)}).
+		Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check withdrawals: %v", err)
+	}
+	checklist.PendingWithdrawals = int(count)
+
+	err = db.Model(&OrderDispute{}).Where("store_id = ? AND status = ?", store.ID, DisputeOpen).Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check disputes: %v", err)
+	}
+	checklist.OpenDisputes = int(count)
+
+	if closeout, err := r.GetStoreCloseout(ctx, store.ID); err == nil && closeout.Status == CloseoutPending {
+		checklist.Closeout = closeout
+	}
+	return checklist, nil
+}
+
+// StartStoreCloseout puts a store in maintenance mode so it takes no new
+// orders while the owner settles what is left.
+func (r *repository) StartStoreCloseout(ctx context.Context, store *Store, requestedBy uint32, reason string) (*StoreCloseout, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 1000 {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Reason must be 1000 characters or fewer")
+	}
+	if existing, err := r.GetStoreCloseout(ctx, store.ID); err == nil && existing.Status == CloseoutPending {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "This store is already being closed")
+	}
+
+	closeout := &StoreCloseout{
+		StoreID:          store.ID,
+		StoreName:        store.Name,
+		Reason:           reason,
+		Status:           CloseoutPending,
+		WasInMaintenance: store.MaintenanceMode,
+		RequestedBy:      requestedBy,
+		CreatedAt:        time.Now(),
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A store reopened from the trash may be closed again, reusing its row
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "store_id"}},
+			UpdateAll: true,
+		}).Create(closeout).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Store{}).Where("id = ?", store.ID).Update("maintenance_mode", true).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start closing store: %v", err)
+	}
+	return closeout, nil
+}
+
+// CancelStoreCloseout stops closing a store and puts its maintenance mode back
+// to how it was.
+func (r *repository) CancelStoreCloseout(ctx context.Context, storeID uint32) error {
+	closeout, err := r.GetStoreCloseout(ctx, storeID)
+	if err != nil {
+		return err
+	}
+	if closeout.Status != CloseoutPending {
+		return errors.NewAppError(http.StatusConflict, "CONFLICT", "This store is not being closed")
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(closeout).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Store{}).Where("id = ?", storeID).Update("maintenance_mode", closeout.WasInMaintenance).Error
+	})
+}
+
+// CloseStore closes a store once its checklist is clear. The store and its
+// products go to the trash together, the products are unlisted, and a store
+// with orders or earnings is kept for RecordRetention instead of being purged
+// with the rest of the trash.
+func (r *repository) CloseStore(ctx context.Context, id, closedBy uint32) (*StoreCloseout, error) {
+	existingStore, err := r.GetStore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	checklist, err := r.GetCloseoutChecklist(ctx, existingStore)
+	if err != nil {
+		return nil, err
+	}
+	if blockers := checklist.Blockers(); len(blockers) > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "The store cannot close yet: "+strings.Join(blockers, "; "))
+	}
+
+	closeout := checklist.Closeout
+	if closeout == nil {
+		closeout = &StoreCloseout{
+			StoreID:          existingStore.ID,
+			StoreName:        existingStore.Name,
+			WasInMaintenance: existingStore.MaintenanceMode,
+			RequestedBy:      closedBy,
+			CreatedAt:        time.Now(),
+		}
+	}
+
+	var records int64
+	r.db.WithContext(ctx).Model(&Order{}).Where("? = ANY(stores_id)", existingStore.Name).Count(&records)
+	if records == 0 {
+		r.db.WithContext(ctx).Model(&StoreEarnings{}).Where("store_id = ?", existingStore.ID).Count(&records)
+	}
+	var payout float64
+	r.db.WithContext(ctx).Table("withdrawals").
+		Where("store_id = ? AND status = ? AND created_at >= ?", existingStore.ID, string(StatusCompleted), closeout.CreatedAt).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&payout)
+
+	now := time.Now().Truncate(time.Microsecond)
+	closeout.Status = CloseoutClosed
+	closeout.ClosedBy = closedBy
+	closeout.ClosedAt = &now
+	closeout.ReopenedAt = nil
+	closeout.FinalPayout = payout
+	closeout.RetainUntil = nil
+	if records > 0 {
+		retainUntil := now.Add(RecordRetention)
+		closeout.RetainUntil = &retainUntil
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Unlisted as well as trashed, so nothing sells if the store is restored
+		// before the owner is ready
+		archived := tx.Model(&product.Product{}).Where("store = ?", existingStore.Name).
+			Updates(map[string]interface{}{"status": false, "deleted_at": now})
+		if archived.Error != nil {
+			return archived.Error
+		}
+		closeout.ProductsArchived = int(archived.RowsAffected)
+
+		if err := tx.Model(&Store{}).Where("id = ?", existingStore.ID).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "store_id"}},
+			UpdateAll: true,
+		}).Create(closeout).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to close store: %v", err)
+	}
+	log.Printf("Store %d (%s) closed by user %d, %d products archived", existingStore.ID, existingStore.Name, closedBy, closeout.ProductsArchived)
+	return closeout, nil
+}
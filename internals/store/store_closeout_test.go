@@ -0,0 +1,57 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloseoutChecklistBlockers(t *testing.T) {
+	tests := []struct {
+		name      string
+		checklist CloseoutChecklist
+		want      []string
+	}{
+		{name: "nothing to settle", want: []string{}},
+		{
+			name:      "open orders",
+			checklist: CloseoutChecklist{OpenOrders: []string{"a", "b"}},
+			want:      []string{"2 open order(s) must be delivered or cancelled"},
+		},
+		{
+			name:      "balance",
+			checklist: CloseoutChecklist{Balance: 1500.5},
+			want:      []string{"1500.50 is still in the store balance; request a final payout first"},
+		},
+		{
+			name: "everything",
+			checklist: CloseoutChecklist{
+				OpenOrders:         []string{"a"},
+				UpcomingBookings:   3,
+				PendingEarnings:    200,
+				PendingWithdrawals: 1,
+				OpenDisputes:       2,
+				Balance:            10,
+			},
+			want: []string{
+				"1 open order(s) must be delivered or cancelled",
+				"3 upcoming booking(s) must be completed or cancelled",
+				"200.00 in earnings has not been released yet",
+				"1 withdrawal(s) are still being processed",
+				"2 order dispute(s) are still open",
+				"10.00 is still in the store balance; request a final payout first",
+			},
+		},
+		{name: "negative balance", checklist: CloseoutChecklist{Balance: -5}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.checklist.Blockers()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Blockers() = %q, want %q", got, tt.want)
+			}
+			if can := tt.checklist.CanClose(); can != (len(tt.want) == 0) {
+				t.Errorf("CanClose() = %v with blockers %q", can, got)
+			}
+		})
+	}
+}
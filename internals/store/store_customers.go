@@ -0,0 +1,441 @@
+package store
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Customer segments. Customers can be in several at once.
+const (
+	SegmentRepeat     = "repeat_buyers"    // two or more orders
+	SegmentNew        = "new_customers"    // first order in the last 30 days
+	SegmentInactive30 = "inactive_30_days" // no order in the last 30 days
+	SegmentInactive90 = "inactive_90_days" // no order in the last 90 days
+)
+
+// Customer list sort orders
+const (
+	CustomerSortSpend        = "spend"
+	CustomerSortOrders       = "orders"
+	CustomerSortLastPurchase = "last_purchase"
+	CustomerSortName         = "name"
+)
+
+const (
+	// MaxCustomerPage caps how many customers one page lists.
+	MaxCustomerPage = 100
+	// MaxCustomerTags caps the tags on one customer.
+	MaxCustomerTags = 10
+	// PromoCooldown is how long a store waits between promo campaigns.
+	PromoCooldown = 24 * time.Hour
+)
+
+var customerSorts = map[string]string{
+	CustomerSortSpend:        "s.lifetime_spend DESC",
+	CustomerSortOrders:       "s.orders DESC",
+	CustomerSortLastPurchase: "s.last_purchase DESC",
+	CustomerSortName:         "name ASC",
+}
+
+// CustomerProfile is someone who bought from a store, with what they spent
+// there and the seller's tags. Figures come from the daily store customer
+// aggregates, so purchase dates are days.
+type CustomerProfile struct {
+	UserID        uint32         `json:"user_id"`
+	Name          string         `json:"name"`
+	Email         string         `json:"email"`
+	Phone         string         `json:"phone"`
+	Orders        int            `json:"orders"`
+	LifetimeSpend float64        `json:"lifetime_spend"`
+	FirstPurchase time.Time      `json:"first_purchase"`
+	LastPurchase  time.Time      `json:"last_purchase"`
+	Tags          pq.StringArray `json:"tags" gorm:"type:text[]"`
+	Segments      []string       `json:"segments" gorm:"-"`
+}
+
+// CustomerFilter narrows a store's customer list. Empty fields match all.
+type CustomerFilter struct {
+	Segment string `json:"segment"`
+	Tag     string `json:"tag"`
+	Search  string `json:"search"` // name, email or phone
+	Sort    string `json:"sort"`
+	Limit   int    `json:"limit"`
+	Offset  int    `json:"offset"`
+}
+
+type CustomerList struct {
+	Total     int                `json:"total"`
+	Customers []*CustomerProfile `json:"customers"`
+}
+
+// StoreCustomerTags holds the tags a store gave a customer.
+type StoreCustomerTags struct {
+	StoreID   uint32         `json:"store_id" gorm:"primaryKey"`
+	UserID    uint32         `json:"user_id" gorm:"primaryKey"`
+	Tags      pq.StringArray `json:"tags" gorm:"type:text[]"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (StoreCustomerTags) TableName() string {
+	return "store_customer_tags"
+}
+
+// StoreCustomerNote is a seller's private note about a customer.
+type StoreCustomerNote struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	StoreID   uint32    `json:"store_id" gorm:"not null;index"`
+	UserID    uint32    `json:"user_id" gorm:"not null;index"`
+	Body      string    `json:"body" gorm:"not null"`
+	AuthorID  uint32    `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (StoreCustomerNote) TableName() string {
+	return "store_customer_notes"
+}
+
+// StorePromoCampaign is a message a store sent to a segment of its
+// customers, optionally pointing at one of its products.
+type StorePromoCampaign struct {
+	ID         uint32    `json:"id" gorm:"primaryKey"`
+	StoreID    uint32    `json:"store_id" gorm:"not null;index"`
+	Segment    string    `json:"segment"`
+	Tag        string    `json:"tag"`
+	Title      string    `json:"title" gorm:"not null"`
+	Message    string    `json:"message" gorm:"not null"`
+	ProductID  uint32    `json:"product_id"`
+	Email      bool      `json:"email"`
+	Recipients int       `json:"recipients"`
+	SentBy     uint32    `json:"sent_by"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (StorePromoCampaign) TableName() string {
+	return "store_promo_campaigns"
+}
+
+// ValidSegment reports whether a segment name is known. The empty segment is
+// every customer.
+func ValidSegment(segment string) bool {
+	switch segment {
+	case "", SegmentRepeat, SegmentNew, SegmentInactive30, SegmentInactive90:
+		return true
+	}
+	return false
+}
+
+// customerSegments lists the segments a customer is in at now.
+func customerSegments(c *CustomerProfile, now time.Time) []string {
+	segments := []string{}
+	if c.Orders >= 2 {
+		segments = append(segments, SegmentRepeat)
+	}
+	if !c.FirstPurchase.Before(now.AddDate(0, 0, -30)) {
+		segments = append(segments, SegmentNew)
+	}
+	if c.LastPurchase.Before(now.AddDate(0, 0, -30)) {
+		segments = append(segments, SegmentInactive30)
+	}
+	if c.LastPurchase.Before(now.AddDate(0, 0, -90)) {
+		segments = append(segments, SegmentInactive90)
+	}
+	return segments
+}
+
+// customerQuery selects a store's customers matching filter, unsorted and
+// unpaginated.
+func (r *repository) customerQuery(ctx context.Context, store *Store, filter *CustomerFilter, now time.Time) (*gorm.DB, error) {
+	if !ValidSegment(filter.Segment) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Unknown segment %q", filter.Segment))
+	}
+
+	// Guest checkouts have no account to follow up with
+	stats := r.db.Table("store_customer_daily").
+		Select("user_id, SUM(orders) AS orders, SUM(revenue) AS lifetime_spend, MIN(day) AS first_purchase, MAX(day) AS last_purchase").
+		Where("store = ? AND user_id ~ '^[0-9]+$' AND user_id <> '0'", store.Name).
+		Group("user_id")
+
+	query := r.db.WithContext(ctx).Table("(?) AS s", stats).
+		Select(`s.user_id::int AS user_id, s.orders, s.lifetime_spend, s.first_purchase, s.last_purchase,
+			COALESCE(u.fullname, '') AS name, COALESCE(u.email, '') AS email, COALESCE(u.phone, '') AS phone,
+			COALESCE(t.tags, '{}') AS tags`).
+		Joins("LEFT JOIN users u ON u.id::text = s.user_id").
+		Joins("LEFT JOIN store_customer_tags t ON t.store_id = ? AND t.user_id::text = s.user_id", store.ID)
+
+	switch filter.Segment {
+	case SegmentRepeat:
+		query = query.Where("s.orders >= 2")
+	case SegmentNew:
+		query = query.Where("s.first_purchase >= ?", now.AddDate(0, 0, -30))
+	case SegmentInactive30:
+		query = query.Where("s.last_purchase < ?", now.AddDate(0, 0, -30))
+	case SegmentInactive90:
+		query = query.Where("s.last_purchase < ?", now.AddDate(0, 0, -90))
+	}
+	if tag := normalizeTag(filter.Tag); tag != "" {
+		query = query.Where("? = ANY(t.tags)", tag)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("(u.fullname ILIKE ? OR u.email ILIKE ? OR u.phone ILIKE ?)", like, like, like)
+	}
+	return query, nil
+}
+
+func (r *repository) listCustomers(ctx context.Context, store *Store, filter *CustomerFilter, limit int) (*CustomerList, error) {
+	now := time.Now()
+	query, err := r.customerQuery(ctx, store, filter, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := r.db.WithContext(ctx).Table("(?) AS c", query).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count customers: %v", err)
+	}
+
+	order, ok := customerSorts[filter.Sort]
+	if !ok {
+		order = customerSorts[CustomerSortSpend]
+	}
+	query = query.Order(order + ", s.user_id")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(filter.Offset)
+	}
+	var customers []*CustomerProfile
+	if err := query.Scan(&customers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get customers: %v", err)
+	}
+	for _, c := range customers {
+		c.Segments = customerSegments(c, now)
+	}
+	return &CustomerList{Total: int(total), Customers: customers}, nil
+}
+
+func (r *repository) GetStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) (*CustomerList, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > MaxCustomerPage {
+		limit = MaxCustomerPage
+	}
+	return r.listCustomers(ctx, store, filter, limit)
+}
+
+// ExportStoreCustomers lists every customer matching filter, ignoring its
+// pagination.
+func (r *repository) ExportStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) ([]*CustomerProfile, error) {
+	list, err := r.listCustomers(ctx, store, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	return list.Customers, nil
+}
+
+func (r *repository) GetStoreCustomer(ctx context.Context, store *Store, userID uint32) (*CustomerProfile, error) {
+	now := time.Now()
+	query, err := r.customerQuery(ctx, store, &CustomerFilter{}, now)
+	if err != nil {
+		return nil, err
+	}
+	var customers []*CustomerProfile
+	if err := query.Where("s.user_id = ?", strconv.Itoa(int(userID))).Scan(&customers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get customer: %v", err)
+	}
+	if len(customers) == 0 {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "This user has not bought from the store")
+	}
+	customers[0].Segments = customerSegments(customers[0], now)
+	return customers[0], nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func (r *repository) SetCustomerTags(ctx context.Context, store *Store, userID uint32, tags []string) (*CustomerProfile, error) {
+	if _, err := r.GetStoreCustomer(ctx, store, userID); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	cleaned := pq.StringArray{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > 30 {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Tags must be 30 characters or fewer")
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+	}
+	if len(cleaned) > MaxCustomerTags {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("A customer can have up to %d tags", MaxCustomerTags))
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tags", "updated_at"}),
+	}).Create(&StoreCustomerTags{StoreID: store.ID, UserID: userID, Tags: cleaned, UpdatedAt: time.Now()}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save customer tags: %v", err)
+	}
+	return r.GetStoreCustomer(ctx, store, userID)
+}
+
+func (r *repository) AddCustomerNote(ctx context.Context, store *Store, note *StoreCustomerNote) (*StoreCustomerNote, error) {
+	note.Body = strings.TrimSpace(note.Body)
+	if note.Body == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Note cannot be empty")
+	}
+	if len(note.Body) > 2000 {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Note must be 2000 characters or fewer")
+	}
+	if _, err := r.GetStoreCustomer(ctx, store, note.UserID); err != nil {
+		return nil, err
+	}
+	note.StoreID = store.ID
+	if err := r.db.WithContext(ctx).Create(note).Error; err != nil {
+		return nil, fmt.Errorf("failed to add note: %v", err)
+	}
+	return note, nil
+}
+
+func (r *repository) GetCustomerNotes(ctx context.Context, storeID, userID uint32) ([]*StoreCustomerNote, error) {
+	var notes []*StoreCustomerNote
+	err := r.db.WithContext(ctx).
+		Where("store_id = ? AND user_id = ?", storeID, userID).
+		Order("created_at DESC").
+		Find(&notes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notes: %v", err)
+	}
+	return notes, nil
+}
+
+func (r *repository) GetCustomerNote(ctx context.Context, id uint32) (*StoreCustomerNote, error) {
+	var note StoreCustomerNote
+	if err := r.db.WithContext(ctx).First(&note, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Note not found")
+		}
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *repository) DeleteCustomerNote(ctx context.Context, id uint32) error {
+	return r.db.WithContext(ctx).Delete(&StoreCustomerNote{}, id).Error
+}
+
+// SendPromo notifies the customers in a campaign's segment and tag. Stores
+// can send one campaign a day so customers are not spammed.
+func (r *repository) SendPromo(ctx context.Context, store *Store, campaign *StorePromoCampaign) (*StorePromoCampaign, error) {
+	campaign.Title = strings.TrimSpace(campaign.Title)
+	campaign.Message = strings.TrimSpace(campaign.Message)
+	campaign.Tag = normalizeTag(campaign.Tag)
+	switch {
+	case campaign.Title == "" || campaign.Message == "":
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Promo title and message are required")
+	case len(campaign.Title) > 120:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Promo title must be 120 characters or fewer")
+	case len(campaign.Message) > 1000:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Promo message must be 1000 characters or fewer")
+	}
+
+	link := fmt.Sprintf("/store/%s", store.Link)
+	if campaign.ProductID != 0 {
+		var count int64
+		r.db.WithContext(ctx).Table("products").
+			Where("id = ? AND store = ? AND deleted_at IS NULL", campaign.ProductID, store.Name).
+			Count(&count)
+		if count == 0 {
+			return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "The promoted product must be one of the store's")
+		}
+		link = fmt.Sprintf("/product/%d", campaign.ProductID)
+	}
+
+	var last StorePromoCampaign
+	err := r.db.WithContext(ctx).Where("store_id = ?", store.ID).Order("created_at DESC").First(&last).Error
+	if err == nil && time.Since(last.CreatedAt) < PromoCooldown {
+		return nil, errors.NewAppError(http.StatusTooManyRequests, "TOO MANY REQUESTS", fmt.Sprintf("The store can send its next promo after %s", last.CreatedAt.Add(PromoCooldown).Format("2 Jan 3:04 PM")))
+	}
+
+	customers, err := r.ExportStoreCustomers(ctx, store, &CustomerFilter{Segment: campaign.Segment, Tag: campaign.Tag})
+	if err != nil {
+		return nil, err
+	}
+	if len(customers) == 0 {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "No customers match this segment")
+	}
+
+	campaign.StoreID = store.ID
+	campaign.Recipients = len(customers)
+	if err := r.db.WithContext(ctx).Create(campaign).Error; err != nil {
+		return nil, fmt.Errorf("failed to save promo: %v", err)
+	}
+
+	notifier := notification.NewService(notification.NewRepository())
+	for _, c := range customers {
+		_, err := notifier.Notify(ctx, &notification.Notification{
+			UserID:  c.UserID,
+			Type:    notification.TypeStorePromo,
+			Title:   fmt.Sprintf("%s: %s", store.Name, campaign.Title),
+			Message: campaign.Message,
+			Link:    link,
+		}, campaign.Email)
+		if err != nil {
+			log.Printf("failed to send promo %d to user %d: %v", campaign.ID, c.UserID, err)
+		}
+	}
+	return campaign, nil
+}
+
+func (r *repository) GetPromoCampaigns(ctx context.Context, storeID uint32) ([]*StorePromoCampaign, error) {
+	var campaigns []*StorePromoCampaign
+	if err := r.db.WithContext(ctx).Where("store_id = ?", storeID).Order("created_at DESC").Find(&campaigns).Error; err != nil {
+		return nil, fmt.Errorf("failed to get promos: %v", err)
+	}
+	return campaigns, nil
+}
+
+// WriteCustomers writes a customer list as CSV.
+func WriteCustomers(w io.Writer, customers []*CustomerProfile) error {
+	writer := csv.NewWriter(w)
+	header := []string{"user_id", "name", "email", "phone", "orders", "lifetime_spend", "first_purchase", "last_purchase", "tags", "segments"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, c := range customers {
+		row := []string{
+			strconv.Itoa(int(c.UserID)),
+			c.Name,
+			c.Email,
+			c.Phone,
+			strconv.Itoa(c.Orders),
+			strconv.FormatFloat(c.LifetimeSpend, 'f', 2, 64),
+			c.FirstPurchase.Format("2006-01-02"),
+			c.LastPurchase.Format("2006-01-02"),
+			strings.Join(c.Tags, ";"),
+			strings.Join(c.Segments, ";"),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
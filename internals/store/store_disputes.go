@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"gorm.io/gorm"
+)
+
+// Dispute statuses
+const (
+	DisputeOpen      = "open"
+	DisputeUpheld    = "upheld"    // resolved in the buyer's favour
+	DisputeDismissed = "dismissed" // the store was not at fault
+)
+
+// Dispute reasons
+const (
+	DisputeNotReceived    = "not_received"
+	DisputeNotAsDescribed = "not_as_described"
+	DisputeDamaged        = "damaged"
+	DisputeOtherReason    = "other"
+)
+
+const maxDisputeDetailLength = 2000
+
+// OrderDispute is a buyer's complaint about one store's part of an order.
+// Admins resolve it; disputes that are not dismissed count against the
+// store's scorecard.
+type OrderDispute struct {
+	ID         uint32     `json:"id" gorm:"primaryKey"`
+	OrderUUID  string     `json:"order_uuid" gorm:"not null;index"`
+	StoreID    uint32     `json:"store_id" gorm:"not null;index"`
+	BuyerID    uint32     `json:"buyer_id" gorm:"not null;index"`
+	Reason     string     `json:"reason" gorm:"not null"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status" gorm:"not null;default:open;index"`
+	Resolution string     `json:"resolution"`
+	ResolvedBy uint32     `json:"resolved_by"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (OrderDispute) TableName() string {
+	return "order_disputes"
+}
+
+func validDisputeReason(reason string) bool {
+	switch reason {
+	case DisputeNotReceived, DisputeNotAsDescribed, DisputeDamaged, DisputeOtherReason:
+		return true
+	}
+	return false
+}
+
+func (r *repository) OpenDispute(ctx context.Context, dispute *OrderDispute) (*OrderDispute, error) {
+	dispute.Details = strings.TrimSpace(dispute.Details)
+	if !validDisputeReason(dispute.Reason) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Unknown dispute reason %q", dispute.Reason))
+	}
+	if dispute.Reason == DisputeOtherReason && dispute.Details == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Please describe the problem")
+	}
+	if len(dispute.Details) > maxDisputeDetailLength {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Details must be %d characters or fewer", maxDisputeDetailLength))
+	}
+
+	var order Order
+	if err := r.db.WithContext(ctx).Where("uuid = ?", dispute.OrderUUID).First(&order).Error; err != nil {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Order not found")
+	}
+	if order.UserID != strconv.Itoa(int(dispute.BuyerID)) {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "You can only dispute your own orders")
+	}
+	if order.TransStatus != "paid" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Only paid orders can be disputed")
+	}
+	store, err := r.GetStore(ctx, dispute.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	inOrder := false
+	for _, name := range order.StoresID {
+		if name == store.Name {
+			inOrder = true
+			break
+		}
+	}
+	if !inOrder {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "The store is not part of this order")
+	}
+
+	var count int64
+	r.db.WithContext(ctx).Model(&OrderDispute{}).
+		Where("order_uuid = ? AND store_id = ?", dispute.OrderUUID, dispute.StoreID).
+		Count(&count)
+	if count > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "You have already disputed this order with the store")
+	}
+
+	dispute.Status = DisputeOpen
+	if err := r.db.WithContext(ctx).Create(dispute).Error; err != nil {
+		return nil, fmt.Errorf("failed to open dispute: %v", err)
+	}
+
+	_, err = notification.NewService(notification.NewRepository()).Notify(ctx, &notification.Notification{
+		UserID:  store.UserID,
+		Type:    notification.TypeOrderDispute,
+		Title:   "An order was disputed",
+		Message: fmt.Sprintf("A buyer disputed order %s (%s). Our team will review it and may contact you.", order.UUID, strings.ReplaceAll(dispute.Reason, "_", " ")),
+		Link:    "/store/disputes",
+	}, true)
+	if err != nil {
+		log.Printf("Failed to notify store %d of dispute %d: %v", store.ID, dispute.ID, err)
+	}
+	return dispute, nil
+}
+
+func (r *repository) GetDispute(ctx context.Context, id uint32) (*OrderDispute, error) {
+	var dispute OrderDispute
+	if err := r.db.WithContext(ctx).First(&dispute, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Dispute not found")
+		}
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+func (r *repository) GetBuyerDisputes(ctx context.Context, buyerID uint32) ([]*OrderDispute, error) {
+	var disputes []*OrderDispute
+	if err := r.db.WithContext(ctx).Where("buyer_id = ?", buyerID).Order("created_at DESC").Find(&disputes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get disputes: %v", err)
+	}
+	return disputes, nil
+}
+
+func (r *repository) GetStoreDisputes(ctx context.Context, storeID uint32) ([]*OrderDispute, error) {
+	var disputes []*OrderDispute
+	if err := r.db.WithContext(ctx).Where("store_id = ?", storeID).Order("created_at DESC").Find(&disputes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get disputes: %v", err)
+	}
+	return disputes, nil
+}
+
+// GetDisputeQueue lists disputes with the given status, oldest first, for
+// admins to work through.
+func (r *repository) GetDisputeQueue(ctx context.Context, status string) ([]*OrderDispute, error) {
+	var disputes []*OrderDispute
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at ASC").Find(&disputes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get disputes: %v", err)
+	}
+	return disputes, nil
+}
+
+func (r *repository) ResolveDispute(ctx context.Context, id, adminID uint32, upheld bool, resolution string) (*OrderDispute, error) {
+	dispute, err := r.GetDispute(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != DisputeOpen {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "This dispute has already been resolved")
+	}
+	resolution = strings.TrimSpace(resolution)
+	if resolution == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Please explain the resolution")
+	}
+
+	now := time.Now()
+	dispute.Status = DisputeDismissed
+	if upheld {
+		dispute.Status = DisputeUpheld
+	}
+	dispute.Resolution = resolution
+	dispute.ResolvedBy = adminID
+	dispute.ResolvedAt = &now
+	if err := r.db.WithContext(ctx).Save(dispute).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve dispute: %v", err)
+	}
+
+	recipients := []uint32{dispute.BuyerID}
+	if store, err := r.GetStore(ctx, dispute.StoreID); err == nil {
+		recipients = append(recipients, store.UserID)
+	}
+	notifier := notification.NewService(notification.NewRepository())
+	for _, userID := range recipients {
+		_, err := notifier.Notify(ctx, &notification.Notification{
+			UserID:  userID,
+			Type:    notification.TypeOrderDispute,
+			Title:   fmt.Sprintf("Dispute on order %s %s", dispute.OrderUUID, dispute.Status),
+			Message: resolution,
+			Link:    fmt.Sprintf("/disputes/%d", dispute.ID),
+		}, true)
+		if err != nil {
+			log.Printf("Failed to notify user %d of dispute %d: %v", userID, dispute.ID, err)
+		}
+	}
+	return dispute, nil
+}
@@ -0,0 +1,457 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Feed item kinds
+const (
+	FeedNewProduct   = "new_product"
+	FeedPriceDrop    = "price_drop"
+	FeedAnnouncement = "announcement"
+)
+
+const (
+	// MaxFeedPage caps how many feed items one page returns.
+	MaxFeedPage = 50
+	// DigestLookback is how far back a user's first digest reaches.
+	DigestLookback = 7 * 24 * time.Hour
+	// digestLines caps how many items a digest email lists.
+	digestLines = 10
+)
+
+// StoreAnnouncement is a post from a store to its followers.
+type StoreAnnouncement struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	StoreID   uint32    `json:"store_id" gorm:"not null;index"`
+	Store     *Store    `json:"store,omitempty" gorm:"foreignKey:StoreID"`
+	Title     string    `json:"title" gorm:"not null"`
+	Body      string    `json:"body"`
+	Image     string    `json:"image"`
+	PostedBy  uint32    `json:"posted_by"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+func (StoreAnnouncement) TableName() string {
+	return "store_announcements"
+}
+
+// FeedItem is a new listing, a price drop or an announcement from a followed
+// store. Product is set for listings and price drops.
+type FeedItem struct {
+	Kind          string             `json:"kind"`
+	ID            uint32             `json:"id"` // product, price history or announcement ID
+	Store         string             `json:"store"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Product       *product.Product   `json:"product,omitempty"`
+	PreviousPrice float64            `json:"previous_price,omitempty"`
+	Price         float64            `json:"price,omitempty"`
+	Announcement  *StoreAnnouncement `json:"announcement,omitempty"`
+}
+
+// Cursor identifies the item to continue a feed after.
+func (f *FeedItem) Cursor() string {
+	raw := fmt.Sprintf("%d:%s:%d", f.CreatedAt.UnixMicro(), f.Kind, f.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// FeedPage is one page of a follower feed. NextCursor is empty on the last
+// page.
+type FeedPage struct {
+	Items      []*FeedItem `json:"items"`
+	NextCursor string      `json:"next_cursor"`
+}
+
+type feedCursor struct {
+	CreatedAt time.Time
+	Kind      string
+	ID        uint32
+}
+
+func parseFeedCursor(cursor string) (*feedCursor, error) {
+	invalid := errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Invalid feed cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return nil, invalid
+	}
+	return &feedCursor{CreatedAt: time.UnixMicro(micros), Kind: parts[1], ID: uint32(id)}, nil
+}
+
+// after keeps the rows of one feed kind that come after the cursor. The feed
+// is ordered newest first, then by kind and ID, all descending.
+func (c *feedCursor) after(q *gorm.DB, kind, createdCol, idCol string) *gorm.DB {
+	if c == nil {
+		return q
+	}
+	switch {
+	case kind < c.Kind:
+		return q.Where(createdCol+" <= ?", c.CreatedAt)
+	case kind > c.Kind:
+		return q.Where(createdCol+" < ?", c.CreatedAt)
+	default:
+		return q.Where("("+createdCol+" < ? OR ("+createdCol+" = ? AND "+idCol+" < ?))", c.CreatedAt, c.CreatedAt, c.ID)
+	}
+}
+
+func (r *repository) PostAnnouncement(ctx context.Context, a *StoreAnnouncement) (*StoreAnnouncement, error) {
+	a.Title = strings.TrimSpace(a.Title)
+	a.Body = strings.TrimSpace(a.Body)
+	switch {
+	case a.Title == "":
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Announcement title is required")
+	case len(a.Title) > 120:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Announcement title must be 120 characters or fewer")
+	case len(a.Body) > 2000:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Announcement must be 2000 characters or fewer")
+	}
+	if err := r.db.WithContext(ctx).Create(a).Error; err != nil {
+		return nil, fmt.Errorf("failed to post announcement: %v", err)
+	}
+	return r.GetAnnouncement(ctx, a.ID)
+}
+
+func (r *repository) GetAnnouncement(ctx context.Context, id uint32) (*StoreAnnouncement, error) {
+	var a StoreAnnouncement
+	err := r.db.WithContext(ctx).Preload("Store").Where("id = ?", id).First(&a).Error
+	if err == gorm.ErrRecordNotFound || (err == nil && a.Store == nil) {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Announcement not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *repository) GetStoreAnnouncements(ctx context.Context, storeID uint32, limit, offset int) ([]*StoreAnnouncement, error) {
+	if limit <= 0 || limit > MaxFeedPage {
+		limit = MaxFeedPage
+	}
+	var announcements []*StoreAnnouncement
+	err := r.db.WithContext(ctx).Preload("Store").
+		Where("store_id = ?", storeID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).Offset(offset).
+		Find(&announcements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcements: %v", err)
+	}
+	return announcements, nil
+}
+
+func (r *repository) DeleteAnnouncement(ctx context.Context, id uint32) error {
+	return r.db.WithContext(ctx).Delete(&StoreAnnouncement{}, id).Error
+}
+
+// followedStores returns the live stores a user follows, by ID.
+func (r *repository) followedStores(ctx context.Context, userID uint32) (map[uint32]string, error) {
+	filter, err := json.Marshal([]map[string]uint32{{"follower_id": userID}})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID   uint32
+		Name string
+	}
+	err = r.db.WithContext(ctx).Table("stores").
+		Select("id, name").
+		Where("deleted_at IS NULL AND followers::jsonb @> ?::jsonb", string(filter)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed stores: %v", err)
+	}
+	stores := make(map[uint32]string, len(rows))
+	for _, row := range rows {
+		stores[row.ID] = row.Name
+	}
+	return stores, nil
+}
+
+// GetFollowerFeed merges new listings, price drops and announcements from the
+// stores a user follows, newest first. Pass the previous page's NextCursor as
+// after to continue.
+func (r *repository) GetFollowerFeed(ctx context.Context, userID uint32, limit int, after string) (*FeedPage, error) {
+	if limit <= 0 || limit > MaxFeedPage {
+		limit = MaxFeedPage
+	}
+	var cursor *feedCursor
+	if after != "" {
+		var err error
+		if cursor, err = parseFeedCursor(after); err != nil {
+			return nil, err
+		}
+	}
+
+	stores, err := r.followedStores(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.feedItems(ctx, stores, cursor, time.Time{}, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &FeedPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = page.Items[limit-1].Cursor()
+	}
+	return page, nil
+}
+
+// feedItems reads up to limit items of every kind from the given stores,
+// after the cursor and newer than since, and merges them.
+func (r *repository) feedItems(ctx context.Context, stores map[uint32]string, cursor *feedCursor, since time.Time, limit int) ([]*FeedItem, error) {
+	if len(stores) == 0 {
+		return []*FeedItem{}, nil
+	}
+	storeIDs := make([]uint32, 0, len(stores))
+	storeNames := make([]string, 0, len(stores))
+	for id, name := range stores {
+		storeIDs = append(storeIDs, id)
+		storeNames = append(storeNames, name)
+	}
+
+	var items []*FeedItem
+	listings, err := r.feedNewProducts(ctx, storeNames, cursor, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, listings...)
+	drops, err := r.feedPriceDrops(ctx, storeNames, cursor, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, drops...)
+	announcements, err := r.feedAnnouncements(ctx, storeIDs, cursor, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	items = append(items, announcements...)
+
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.ID > b.ID
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *repository) feedNewProducts(ctx context.Context, storeNames []string, cursor *feedCursor, since time.Time, limit int) ([]*FeedItem, error) {
+	query := r.db.WithContext(ctx).
+		Where("store IN ? AND moderation_status = ?", storeNames, product.ModerationApproved).
+		Where("created_at > ?", since)
+	query = cursor.after(query, FeedNewProduct, "created_at", "id")
+
+	var products []*product.Product
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to get new listings: %v", err)
+	}
+	items := make([]*FeedItem, 0, len(products))
+	for _, p := range products {
+		items = append(items, &FeedItem{
+			Kind:      FeedNewProduct,
+			ID:        p.ID,
+			Store:     p.Store,
+			CreatedAt: p.CreatedAt,
+			Product:   p,
+			Price:     p.Price - p.Discount,
+		})
+	}
+	return items, nil
+}
+
+// feedPriceDrops finds price history entries where a product got cheaper
+// than its previous entry.
+func (r *repository) feedPriceDrops(ctx context.Context, storeNames []string, cursor *feedCursor, since time.Time, limit int) ([]*FeedItem, error) {
+	changes := r.db.Table("product_price_history AS h").
+		Select(`h.id, h.product_id, h.created_at, h.price - h.discount AS price,
+			LAG(h.price - h.discount) OVER (PARTITION BY h.product_id ORDER BY h.created_at, h.id) AS previous_price`).
+		Joins("JOIN products p ON p.id = h.product_id").
+		Where("p.store IN ? AND p.deleted_at IS NULL AND p.moderation_status = ?", storeNames, product.ModerationApproved)
+
+	query := r.db.WithContext(ctx).Table("(?) AS d", changes).
+		Where("d.previous_price IS NOT NULL AND d.price < d.previous_price").
+		Where("d.created_at > ?", since)
+	query = cursor.after(query, FeedPriceDrop, "d.created_at", "d.id")
+
+	var drops []struct {
+		ID            uint32
+		ProductID     uint32
+		CreatedAt     time.Time
+		Price         float64
+		PreviousPrice float64
+	}
+	if err := query.Order("d.created_at DESC, d.id DESC").Limit(limit).Scan(&drops).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price drops: %v", err)
+	}
+	if len(drops) == 0 {
+		return nil, nil
+	}
+
+	productIDs := make([]uint32, 0, len(drops))
+	for _, d := range drops {
+		productIDs = append(productIDs, d.ProductID)
+	}
+	var products []*product.Product
+	if err := r.db.WithContext(ctx).Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price drop products: %v", err)
+	}
+	byID := make(map[uint32]*product.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	items := make([]*FeedItem, 0, len(drops))
+	for _, d := range drops {
+		p, ok := byID[d.ProductID]
+		if !ok {
+			continue
+		}
+		items = append(items, &FeedItem{
+			Kind:          FeedPriceDrop,
+			ID:            d.ID,
+			Store:         p.Store,
+			CreatedAt:     d.CreatedAt,
+			Product:       p,
+			PreviousPrice: d.PreviousPrice,
+			Price:         d.Price,
+		})
+	}
+	return items, nil
+}
+
+func (r *repository) feedAnnouncements(ctx context.Context, storeIDs []uint32, cursor *feedCursor, since time.Time, limit int) ([]*FeedItem, error) {
+	query := r.db.WithContext(ctx).Preload("Store").
+		Where("store_id IN ?", storeIDs).
+		Where("created_at > ?", since)
+	query = cursor.after(query, FeedAnnouncement, "created_at", "id")
+
+	var announcements []*StoreAnnouncement
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&announcements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get announcements: %v", err)
+	}
+	items := make([]*FeedItem, 0, len(announcements))
+	for _, a := range announcements {
+		if a.Store == nil {
+			continue
+		}
+		items = append(items, &FeedItem{
+			Kind:         FeedAnnouncement,
+			ID:           a.ID,
+			Store:        a.Store.Name,
+			CreatedAt:    a.CreatedAt,
+			Announcement: a,
+		})
+	}
+	return items, nil
+}
+
+// SendFollowDigests emails users who opted in a summary of what their
+// followed stores posted since their last digest. It returns how many
+// digests were sent.
+func (r *repository) SendFollowDigests(ctx context.Context) (int, error) {
+	var recipients []struct {
+		ID                 uint32
+		Fullname           string
+		Email              string
+		FollowDigestSentAt *time.Time
+	}
+	err := r.db.WithContext(ctx).Table("users").
+		Select("id, fullname, email, follow_digest_sent_at").
+		Where("follow_digest_email = true AND email <> ''").
+		Scan(&recipients).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to get digest recipients: %v", err)
+	}
+
+	now := time.Now()
+	sent := 0
+	for _, u := range recipients {
+		since := now.Add(-DigestLookback)
+		if u.FollowDigestSentAt != nil && u.FollowDigestSentAt.After(since) {
+			since = *u.FollowDigestSentAt
+		}
+		stores, err := r.followedStores(ctx, u.ID)
+		if err != nil {
+			return sent, err
+		}
+		// One more than is listed, to tell whether there are others
+		items, err := r.feedItems(ctx, stores, nil, since, digestLines+1)
+		if err != nil {
+			return sent, err
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		lines := make([]string, 0, len(items))
+		for i, item := range items {
+			if i == digestLines {
+				lines = append(lines, "...and more in your feed")
+				break
+			}
+			lines = append(lines, digestLine(item))
+		}
+		contents := map[string]string{
+			"name":    u.Fullname,
+			"title":   "New from stores you follow",
+			"message": strings.Join(lines, "\n"),
+			"link":    "/feed",
+		}
+		if err := utils.SendEmail(os.Getenv("ONE_SIGNAL_NOTIFICATION_TEMPLATE_ID"), contents["title"], []string{u.Email}, contents); err != nil {
+			log.Printf("failed to send follow digest to user %d: %v", u.ID, err)
+			continue
+		}
+		if err := r.db.WithContext(ctx).Table("users").Where("id = ?", u.ID).Update("follow_digest_sent_at", now).Error; err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func digestLine(item *FeedItem) string {
+	switch item.Kind {
+	case FeedNewProduct:
+		return fmt.Sprintf("%s listed %s for ₦%.2f", item.Store, item.Product.Name, item.Price)
+	case FeedPriceDrop:
+		return fmt.Sprintf("%s dropped %s from ₦%.2f to ₦%.2f", item.Store, item.Product.Name, item.PreviousPrice, item.Price)
+	default:
+		return fmt.Sprintf("%s: %s", item.Store, item.Announcement.Title)
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 type Handler struct {
@@ -141,22 +142,6 @@ func (h *Handler) WithdrawFund(ctx context.Context, req *Fund) error {
 	return nil
 }
 
-func (h *Handler) AddReview(ctx context.Context, req *Review) error {
-	err := h.Service.AddReview(ctx, req)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (h *Handler) GetReviews(ctx context.Context, filterType string, value interface{}) ([]*Review, error) {
-	result, err := h.Service.GetReviews(ctx, filterType, value)
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
-
 func (h *Handler) GetDVAAccount(ctx context.Context, email string) (*DVAAccount, error) {
 	account, err := h.Service.GetDVAAccount(ctx, email)
 	if err != nil {
@@ -212,3 +197,243 @@ func (h *Handler) SyncExistingPaystackDVAAccounts(ctx context.Context) error {
 func (h *Handler) GetStoreEarnings(ctx context.Context, storeID uint32) ([]*StoreEarnings, error) {
 	return h.Service.GetStoreEarnings(ctx, storeID)
 }
+
+func (h *Handler) GetTrashedStore(ctx context.Context, id uint32) (*Store, error) {
+	return h.Service.GetTrashedStore(ctx, id)
+}
+
+func (h *Handler) GetTrashedStores(ctx context.Context, userID uint32) ([]*Store, error) {
+	return h.Service.GetTrashedStores(ctx, userID)
+}
+
+func (h *Handler) CountTrashedStoreProducts(ctx context.Context, store *Store) (int, error) {
+	return h.Service.CountTrashedStoreProducts(ctx, store)
+}
+
+func (h *Handler) RestoreStore(ctx context.Context, id uint32) (*Store, error) {
+	return h.Service.RestoreStore(ctx, id)
+}
+
+func (h *Handler) PurgeTrashedStores(ctx context.Context, before time.Time) (int, error) {
+	return h.Service.PurgeTrashedStores(ctx, before)
+}
+
+func (h *Handler) GetStoreAnalytics(ctx context.Context, storeName string, from, to time.Time, granularity string) (*StoreAnalytics, error) {
+	return h.Service.GetStoreAnalytics(ctx, storeName, from, to, granularity)
+}
+
+func (h *Handler) RollupStoreAnalytics(ctx context.Context, day time.Time) error {
+	return h.Service.RollupStoreAnalytics(ctx, day)
+}
+
+func (h *Handler) GetStoreRole(ctx context.Context, store *Store, userID uint32) (string, error) {
+	return h.Service.GetStoreRole(ctx, store, userID)
+}
+
+func (h *Handler) InviteStaff(ctx context.Context, member *StoreMember) (*StoreMember, error) {
+	return h.Service.InviteStaff(ctx, member)
+}
+
+func (h *Handler) GetStaffMember(ctx context.Context, id uint32) (*StoreMember, error) {
+	return h.Service.GetStaffMember(ctx, id)
+}
+
+func (h *Handler) GetStoreStaff(ctx context.Context, storeID uint32) ([]*StoreMember, error) {
+	return h.Service.GetStoreStaff(ctx, storeID)
+}
+
+func (h *Handler) GetUserStaffInvites(ctx context.Context, userID uint32) ([]*StoreMember, error) {
+	return h.Service.GetUserStaffInvites(ctx, userID)
+}
+
+func (h *Handler) GetUserMemberships(ctx context.Context, userID uint32) ([]*StoreMember, error) {
+	return h.Service.GetUserMemberships(ctx, userID)
+}
+
+func (h *Handler) AcceptStaffInvite(ctx context.Context, id, userID uint32) (*StoreMember, error) {
+	return h.Service.AcceptStaffInvite(ctx, id, userID)
+}
+
+func (h *Handler) UpdateStaffRole(ctx context.Context, id uint32, role string) (*StoreMember, error) {
+	return h.Service.UpdateStaffRole(ctx, id, role)
+}
+
+func (h *Handler) RemoveStaff(ctx context.Context, id uint32) error {
+	return h.Service.RemoveStaff(ctx, id)
+}
+
+func (h *Handler) GetStoreHours(ctx context.Context, storeID uint32) (*StoreHours, error) {
+	return h.Service.GetStoreHours(ctx, storeID)
+}
+
+func (h *Handler) SetStoreHours(ctx context.Context, hours *StoreHours) (*StoreHours, error) {
+	return h.Service.SetStoreHours(ctx, hours)
+}
+
+func (h *Handler) GetStoreClosures(ctx context.Context, storeID uint32, includePast bool) ([]*StoreClosure, error) {
+	return h.Service.GetStoreClosures(ctx, storeID, includePast)
+}
+
+func (h *Handler) GetStoreClosure(ctx context.Context, id uint32) (*StoreClosure, error) {
+	return h.Service.GetStoreClosure(ctx, id)
+}
+
+func (h *Handler) ScheduleClosure(ctx context.Context, closure *StoreClosure) (*StoreClosure, error) {
+	return h.Service.ScheduleClosure(ctx, closure)
+}
+
+func (h *Handler) EndClosure(ctx context.Context, id uint32) (*StoreClosure, error) {
+	return h.Service.EndClosure(ctx, id)
+}
+
+func (h *Handler) GetStoreAvailability(ctx context.Context, store *Store, at time.Time) (*StoreAvailability, error) {
+	return h.Service.GetStoreAvailability(ctx, store, at)
+}
+
+func (h *Handler) NotifyReopenedStores(ctx context.Context) (int, error) {
+	return h.Service.NotifyReopenedStores(ctx)
+}
+
+func (h *Handler) PostAnnouncement(ctx context.Context, a *StoreAnnouncement) (*StoreAnnouncement, error) {
+	return h.Service.PostAnnouncement(ctx, a)
+}
+
+func (h *Handler) GetAnnouncement(ctx context.Context, id uint32) (*StoreAnnouncement, error) {
+	return h.Service.GetAnnouncement(ctx, id)
+}
+
+func (h *Handler) GetStoreAnnouncements(ctx context.Context, storeID uint32, limit, offset int) ([]*StoreAnnouncement, error) {
+	return h.Service.GetStoreAnnouncements(ctx, storeID, limit, offset)
+}
+
+func (h *Handler) DeleteAnnouncement(ctx context.Context, id uint32) error {
+	return h.Service.DeleteAnnouncement(ctx, id)
+}
+
+func (h *Handler) GetFollowerFeed(ctx context.Context, userID uint32, limit int, after string) (*FeedPage, error) {
+	return h.Service.GetFollowerFeed(ctx, userID, limit, after)
+}
+
+func (h *Handler) SendFollowDigests(ctx context.Context) (int, error) {
+	return h.Service.SendFollowDigests(ctx)
+}
+
+func (h *Handler) GetStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) (*CustomerList, error) {
+	return h.Service.GetStoreCustomers(ctx, store, filter)
+}
+
+func (h *Handler) ExportStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) ([]*CustomerProfile, error) {
+	return h.Service.ExportStoreCustomers(ctx, store, filter)
+}
+
+func (h *Handler) GetStoreCustomer(ctx context.Context, store *Store, userID uint32) (*CustomerProfile, error) {
+	return h.Service.GetStoreCustomer(ctx, store, userID)
+}
+
+func (h *Handler) SetCustomerTags(ctx context.Context, store *Store, userID uint32, tags []string) (*CustomerProfile, error) {
+	return h.Service.SetCustomerTags(ctx, store, userID, tags)
+}
+
+func (h *Handler) AddCustomerNote(ctx context.Context, store *Store, note *StoreCustomerNote) (*StoreCustomerNote, error) {
+	return h.Service.AddCustomerNote(ctx, store, note)
+}
+
+func (h *Handler) GetCustomerNotes(ctx context.Context, storeID, userID uint32) ([]*StoreCustomerNote, error) {
+	return h.Service.GetCustomerNotes(ctx, storeID, userID)
+}
+
+func (h *Handler) GetCustomerNote(ctx context.Context, id uint32) (*StoreCustomerNote, error) {
+	return h.Service.GetCustomerNote(ctx, id)
+}
+
+func (h *Handler) DeleteCustomerNote(ctx context.Context, id uint32) error {
+	return h.Service.DeleteCustomerNote(ctx, id)
+}
+
+func (h *Handler) SendPromo(ctx context.Context, store *Store, campaign *StorePromoCampaign) (*StorePromoCampaign, error) {
+	return h.Service.SendPromo(ctx, store, campaign)
+}
+
+func (h *Handler) GetPromoCampaigns(ctx context.Context, storeID uint32) ([]*StorePromoCampaign, error) {
+	return h.Service.GetPromoCampaigns(ctx, storeID)
+}
+
+func (h *Handler) GetStoreScorecard(ctx context.Context, storeID uint32) (*StoreScorecard, error) {
+	return h.Service.GetStoreScorecard(ctx, storeID)
+}
+
+func (h *Handler) RollupStoreScorecards(ctx context.Context) (int, error) {
+	return h.Service.RollupStoreScorecards(ctx)
+}
+
+func (h *Handler) OpenDispute(ctx context.Context, dispute *OrderDispute) (*OrderDispute, error) {
+	return h.Service.OpenDispute(ctx, dispute)
+}
+
+func (h *Handler) GetDispute(ctx context.Context, id uint32) (*OrderDispute, error) {
+	return h.Service.GetDispute(ctx, id)
+}
+
+func (h *Handler) GetBuyerDisputes(ctx context.Context, buyerID uint32) ([]*OrderDispute, error) {
+	return h.Service.GetBuyerDisputes(ctx, buyerID)
+}
+
+func (h *Handler) GetStoreDisputes(ctx context.Context, storeID uint32) ([]*OrderDispute, error) {
+	return h.Service.GetStoreDisputes(ctx, storeID)
+}
+
+func (h *Handler) GetDisputeQueue(ctx context.Context, status string) ([]*OrderDispute, error) {
+	return h.Service.GetDisputeQueue(ctx, status)
+}
+
+func (h *Handler) ResolveDispute(ctx context.Context, id, adminID uint32, upheld bool, resolution string) (*OrderDispute, error) {
+	return h.Service.ResolveDispute(ctx, id, adminID, upheld, resolution)
+}
+
+func (h *Handler) GetStorePolicy(ctx context.Context, store *Store) (*StorePolicy, error) {
+	return h.Service.GetStorePolicy(ctx, store)
+}
+
+func (h *Handler) SetStorePolicy(ctx context.Context, policy *StorePolicy) (*StorePolicy, error) {
+	return h.Service.SetStorePolicy(ctx, policy)
+}
+
+func (h *Handler) GetStoreCloseout(ctx context.Context, storeID uint32) (*StoreCloseout, error) {
+	return h.Service.GetStoreCloseout(ctx, storeID)
+}
+
+func (h *Handler) GetCloseoutChecklist(ctx context.Context, store *Store) (*CloseoutChecklist, error) {
+	return h.Service.GetCloseoutChecklist(ctx, store)
+}
+
+func (h *Handler) StartStoreCloseout(ctx context.Context, store *Store, requestedBy uint32, reason string) (*StoreCloseout, error) {
+	return h.Service.StartStoreCloseout(ctx, store, requestedBy, reason)
+}
+
+func (h *Handler) CancelStoreCloseout(ctx context.Context, storeID uint32) error {
+	return h.Service.CancelStoreCloseout(ctx, storeID)
+}
+
+func (h *Handler) CloseStore(ctx context.Context, id, closedBy uint32) (*StoreCloseout, error) {
+	return h.Service.CloseStore(ctx, id, closedBy)
+}
+
+func (h *Handler) CheckStoreHandle(ctx context.Context, handle string, storeID uint32) error {
+	return h.Service.CheckStoreHandle(ctx, handle, storeID)
+}
+
+func (h *Handler) SetStoreHandle(ctx context.Context, store *Store, handle string) (*Store, error) {
+	return h.Service.SetStoreHandle(ctx, store, handle)
+}
+
+func (h *Handler) GetStoreByHandle(ctx context.Context, handle string) (*Store, error) {
+	return h.Service.GetStoreByHandle(ctx, handle)
+}
+
+func (h *Handler) GetQRTarget(ctx context.Context, kind string, id uint32) (*QRTarget, error) {
+	return h.Service.GetQRTarget(ctx, kind, id)
+}
+
+func (h *Handler) RecordQRScan(ctx context.Context, target *QRTarget, ip string) error {
+	return h.Service.RecordQRScan(ctx, target, ip)
+}
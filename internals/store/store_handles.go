@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 30
+)
+
+var handlePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedHandles are kept for the site's own pages and for names buyers
+// could mistake for the marketplace itself.
+var reservedHandles = map[string]bool{
+	"about": true, "account": true, "admin": true, "aluta": true, "alutamarket": true,
+	"api": true, "auth": true, "billing": true, "blog": true, "bookings": true,
+	"cart": true, "categories": true, "category": true, "checkout": true, "contact": true,
+	"dashboard": true, "disputes": true, "download": true, "downloads": true, "explore": true,
+	"faq": true, "feed": true, "graphql": true, "health": true, "help": true,
+	"invoice": true, "invoices": true, "login": true, "logout": true, "media": true,
+	"messages": true, "notifications": true, "official": true, "orders": true, "privacy": true,
+	"product": true, "products": true, "purchased": true, "qr": true, "register": true,
+	"reset-password": true, "search": true, "sell": true, "seller": true, "settings": true,
+	"signup": true, "skynet": true, "store": true, "stores": true, "support": true,
+	"terms": true, "uploads": true, "user": true, "users": true, "wallet": true,
+	"ws": true,
+}
+
+// StoreHandleHistory keeps the handles a store used before changing it, so
+// links and flyers printed with the old handle still find the store. No
+// other store can take them.
+type StoreHandleHistory struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	StoreID   uint32    `json:"store_id" gorm:"not null;index"`
+	Handle    string    `json:"handle" gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (StoreHandleHistory) TableName() string {
+	return "store_handle_history"
+}
+
+// NormalizeHandle lowercases a handle and drops surrounding spaces and a
+// leading "@".
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ValidateHandle checks a normalized handle against the handle rules.
+func ValidateHandle(handle string) error {
+	switch {
+	case len(handle) < MinHandleLength || len(handle) > MaxHandleLength:
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Store handles must be %d to %d characters long", MinHandleLength, MaxHandleLength))
+	case !handlePattern.MatchString(handle):
+		return errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Store handles can only use letters, numbers and single hyphens between them")
+	case reservedHandles[handle]:
+		return errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("The handle %q is reserved", handle))
+	}
+	return nil
+}
+
+// checkHandle validates a handle and makes sure no other store uses it now,
+// used it before, or holds it in the trash. storeID is the store taking the
+// handle, or 0 for a new one.
+func checkHandle(db *gorm.DB, handle string, storeID uint32) error {
+	if err := ValidateHandle(handle); err != nil {
+		return err
+	}
+	var count int64
+	err := db.Unscoped().Model(&Store{}).Where("LOWER(link) = ? AND id <> ?", handle, storeID).Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check handle: %v", err)
+	}
+	if count == 0 {
+		err = db.Model(&StoreHandleHistory{}).Where("handle = ? AND store_id <> ?", handle, storeID).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed to check handle: %v", err)
+		}
+	}
+	if count > 0 {
+		return handleTaken(handle)
+	}
+	return nil
+}
+
+func handleTaken(handle string) error {
+	return errors.NewAppError(http.StatusConflict, "CONFLICT", fmt.Sprintf("The handle %q is already taken", handle))
+}
+
+// handleIndex is the unique index on LOWER(stores.link). It catches a store
+// taking a handle between checkHandle and the write.
+const handleIndex = "idx_stores_link_lower"
+
+// handleWriteError turns a write of handle that lost to the unique index into
+// the usual conflict error.
+func handleWriteError(err error, handle string) error {
+	if err != nil && strings.Contains(err.Error(), handleIndex) {
+		return handleTaken(handle)
+	}
+	return err
+}
+
+// assignHandle sets a store's handle, keeping the one it replaces in the
+// history.
+func assignHandle(tx *gorm.DB, store *Store, handle string) error {
+	previous := NormalizeHandle(store.Link)
+	if previous == handle {
+		store.Link = handle
+		return nil
+	}
+	if err := checkHandle(tx, handle, store.ID); err != nil {
+		return err
+	}
+	// Taking back an old handle makes its redirect unnecessary
+	if err := tx.Where("store_id = ? AND handle = ?", store.ID, handle).Delete(&StoreHandleHistory{}).Error; err != nil {
+		return err
+	}
+	if previous != "" {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&StoreHandleHistory{StoreID: store.ID, Handle: previous}).Error
+		if err != nil {
+			return err
+		}
+	}
+	store.Link = handle
+	return handleWriteError(tx.Model(&Store{}).Where("id = ?", store.ID).Update("link", handle).Error, handle)
+}
+
+// CheckStoreHandle reports why a store could not take a handle, or nil if it
+// can.
+func (r *repository) CheckStoreHandle(ctx context.Context, handle string, storeID uint32) error {
+	return checkHandle(r.db.WithContext(ctx), NormalizeHandle(handle), storeID)
+}
+
+func (r *repository) SetStoreHandle(ctx context.Context, store *Store, handle string) (*Store, error) {
+	handle = NormalizeHandle(handle)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return assignHandle(tx, store, handle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetStoreByHandle finds a store by its handle or, failing that, by a handle
+// it used before.
+func (r *repository) GetStoreByHandle(ctx context.Context, handle string) (*Store, error) {
+	handle = NormalizeHandle(handle)
+
+	var store Store
+	err := r.db.WithContext(ctx).Where("LOWER(link) = ?", handle).First(&store).Error
+	if err == nil {
+		return &store, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	var history StoreHandleHistory
+	err = r.db.WithContext(ctx).Where("handle = ?", handle).First(&history).Error
+	if err == nil {
+		err = r.db.WithContext(ctx).Where("id = ?", history.StoreID).First(&store).Error
+	}
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Store not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
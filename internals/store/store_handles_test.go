@@ -0,0 +1,50 @@
+package store
+
+import "testing"
+
+func TestValidateHandle(t *testing.T) {
+	tests := []struct {
+		name    string
+		handle  string
+		wantErr bool
+	}{
+		{name: "letters", handle: "campuscuts"},
+		{name: "letters and numbers", handle: "store24"},
+		{name: "single hyphens", handle: "campus-cuts-ui"},
+		{name: "shortest", handle: "abc"},
+		{name: "longest", handle: "abcdefghijklmnopqrstuvwxyz1234"},
+		{name: "too short", handle: "ab", wantErr: true},
+		{name: "too long", handle: "abcdefghijklmnopqrstuvwxyz12345", wantErr: true},
+		{name: "uppercase", handle: "CampusCuts", wantErr: true},
+		{name: "space", handle: "campus cuts", wantErr: true},
+		{name: "underscore", handle: "campus_cuts", wantErr: true},
+		{name: "leading hyphen", handle: "-campus", wantErr: true},
+		{name: "trailing hyphen", handle: "campus-", wantErr: true},
+		{name: "double hyphen", handle: "campus--cuts", wantErr: true},
+		{name: "reserved", handle: "admin", wantErr: true},
+		{name: "reserved with hyphen", handle: "reset-password", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateHandle(tt.handle); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHandle(%q) error = %v, wantErr %v", tt.handle, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeHandle(t *testing.T) {
+	tests := []struct {
+		handle string
+		want   string
+	}{
+		{handle: "CampusCuts", want: "campuscuts"},
+		{handle: "  @campus-cuts ", want: "campus-cuts"},
+		{handle: "@@campus", want: "@campus"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHandle(tt.handle); got != tt.want {
+			t.Errorf("NormalizeHandle(%q) = %q, want %q", tt.handle, got, tt.want)
+		}
+	}
+}
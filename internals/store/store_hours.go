@@ -0,0 +1,380 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Closure kinds
+const (
+	ClosureHoliday  = "holiday"
+	ClosureVacation = "vacation"
+)
+
+// Why a store is closed. Closures give their kind as the reason.
+const (
+	ClosedMaintenance = "maintenance"
+	ClosedHours       = "hours"
+)
+
+// MaxClosureLength caps how long one scheduled closure can run.
+const MaxClosureLength = 90 * 24 * time.Hour
+
+// StoreHours is when a store is open every week, in campus time. A store
+// without hours is always open.
+type StoreHours struct {
+	StoreID           uint32                `json:"store_id" gorm:"primaryKey"`
+	Hours             []booking.WeeklyHours `json:"hours" gorm:"type:jsonb;serializer:json"`
+	AwayMessage       string                `json:"away_message"`
+	AcceptLaterOrders bool                  `json:"accept_later_orders"` // take orders while closed and ship them on reopening
+	UpdatedAt         time.Time             `json:"updated_at"`
+}
+
+func (StoreHours) TableName() string {
+	return "store_hours"
+}
+
+// StoreClosure is a holiday or vacation that closes a store whatever its
+// weekly hours say.
+type StoreClosure struct {
+	ID               uint32     `json:"id" gorm:"primaryKey"`
+	StoreID          uint32     `json:"store_id" gorm:"not null;index"`
+	Kind             string     `json:"kind" gorm:"not null"`
+	StartsAt         time.Time  `json:"starts_at" gorm:"not null"`
+	EndsAt           time.Time  `json:"ends_at" gorm:"not null;index"`
+	Message          string     `json:"message"`
+	ReopenNotifiedAt *time.Time `json:"reopen_notified_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func (StoreClosure) TableName() string {
+	return "store_closures"
+}
+
+// StoreAvailability is whether a store is open at a given time and, if not,
+// why and until when.
+type StoreAvailability struct {
+	Store         string     `json:"store"`
+	Open          bool       `json:"open"`
+	Reason        string     `json:"reason"`
+	Message       string     `json:"message"`
+	ReopensAt     *time.Time `json:"reopens_at"`
+	AcceptsOrders bool       `json:"accepts_orders"`
+}
+
+func (r *repository) GetStoreHours(ctx context.Context, storeID uint32) (*StoreHours, error) {
+	hours := &StoreHours{StoreID: storeID}
+	err := r.db.WithContext(ctx).Where("store_id = ?", storeID).First(hours).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get store hours: %v", err)
+	}
+	if hours.Hours == nil {
+		hours.Hours = []booking.WeeklyHours{}
+	}
+	return hours, nil
+}
+
+func (r *repository) SetStoreHours(ctx context.Context, hours *StoreHours) (*StoreHours, error) {
+	if err := booking.ValidateWeeklyHours(hours.Hours); err != nil {
+		return nil, err
+	}
+	hours.AwayMessage = strings.TrimSpace(hours.AwayMessage)
+	if len(hours.AwayMessage) > 500 {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Away message must be 500 characters or fewer")
+	}
+	if hours.Hours == nil {
+		hours.Hours = []booking.WeeklyHours{}
+	}
+	hours.UpdatedAt = time.Now()
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hours", "away_message", "accept_later_orders", "updated_at"}),
+	}).Create(hours).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save store hours: %v", err)
+	}
+	return hours, nil
+}
+
+func (r *repository) GetStoreClosures(ctx context.Context, storeID uint32, includePast bool) ([]*StoreClosure, error) {
+	query := r.db.WithContext(ctx).Where("store_id = ?", storeID)
+	if !includePast {
+		query = query.Where("ends_at > ?", time.Now())
+	}
+	var closures []*StoreClosure
+	if err := query.Order("starts_at ASC").Find(&closures).Error; err != nil {
+		return nil, fmt.Errorf("failed to get store closures: %v", err)
+	}
+	return closures, nil
+}
+
+func (r *repository) GetStoreClosure(ctx context.Context, id uint32) (*StoreClosure, error) {
+	var closure StoreClosure
+	if err := r.db.WithContext(ctx).First(&closure, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Closure not found")
+		}
+		return nil, err
+	}
+	return &closure, nil
+}
+
+func (r *repository) ScheduleClosure(ctx context.Context, closure *StoreClosure) (*StoreClosure, error) {
+	closure.Message = strings.TrimSpace(closure.Message)
+	switch {
+	case closure.Kind != ClosureHoliday && closure.Kind != ClosureVacation:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Closures are either a holiday or a vacation")
+	case !closure.EndsAt.After(closure.StartsAt):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A closure must end after it starts")
+	case !closure.EndsAt.After(time.Now()):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A closure cannot end in the past")
+	case closure.EndsAt.Sub(closure.StartsAt) > MaxClosureLength:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "A closure can last up to 90 days")
+	case len(closure.Message) > 500:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Closure message must be 500 characters or fewer")
+	}
+
+	var overlapping int64
+	err := r.db.WithContext(ctx).Model(&StoreClosure{}).
+		Where("store_id = ? AND starts_at < ? AND ends_at > ?", closure.StoreID, closure.EndsAt, closure.StartsAt).
+		Count(&overlapping).Error
+	if err != nil {
+		return nil, err
+	}
+	if overlapping > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "The store already has a closure during that time")
+	}
+
+	if err := r.db.WithContext(ctx).Create(closure).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule closure: %v", err)
+	}
+	return closure, nil
+}
+
+// EndClosure reopens a store early. Closures that have not started yet are
+// cancelled instead.
+func (r *repository) EndClosure(ctx context.Context, id uint32) (*StoreClosure, error) {
+	closure, err := r.GetStoreClosure(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !closure.EndsAt.After(now) {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "This closure has already ended")
+	}
+	if closure.StartsAt.After(now) {
+		if err := r.db.WithContext(ctx).Delete(closure).Error; err != nil {
+			return nil, err
+		}
+		return closure, nil
+	}
+	closure.EndsAt = now
+	if err := r.db.WithContext(ctx).Save(closure).Error; err != nil {
+		return nil, err
+	}
+	return closure, nil
+}
+
+func (r *repository) GetStoreAvailability(ctx context.Context, store *Store, at time.Time) (*StoreAvailability, error) {
+	hours, err := r.GetStoreHours(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+	var closures []*StoreClosure
+	err = r.db.WithContext(ctx).
+		Where("store_id = ? AND ends_at > ?", store.ID, at).
+		Order("starts_at ASC").
+		Find(&closures).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store closures: %v", err)
+	}
+	return storeAvailability(store, hours, closures, at), nil
+}
+
+// storeAvailability works out whether a store is open at a time from its
+// maintenance switch, closures and weekly hours, in that order.
+func storeAvailability(store *Store, hours *StoreHours, closures []*StoreClosure, at time.Time) *StoreAvailability {
+	a := &StoreAvailability{Store: store.Name, Open: true, AcceptsOrders: true}
+	if store.MaintenanceMode {
+		// Maintenance has no end date and the store is hidden meanwhile
+		a.Open, a.AcceptsOrders, a.Reason = false, false, ClosedMaintenance
+		return a
+	}
+
+	if c := closureAt(closures, at); c != nil {
+		a.Open, a.Reason, a.Message = false, c.Kind, c.Message
+	} else if !openAt(hours.Hours, at) {
+		a.Open, a.Reason = false, ClosedHours
+	}
+	if a.Open {
+		return a
+	}
+
+	if a.Message == "" {
+		a.Message = hours.AwayMessage
+	}
+	a.AcceptsOrders = hours.AcceptLaterOrders
+	a.ReopensAt = reopening(hours.Hours, closures, at)
+	return a
+}
+
+func closureAt(closures []*StoreClosure, t time.Time) *StoreClosure {
+	for _, c := range closures {
+		if !t.Before(c.StartsAt) && t.Before(c.EndsAt) {
+			return c
+		}
+	}
+	return nil
+}
+
+// reopening finds the first time from t when no closure is running and the
+// weekly hours have the store open.
+func reopening(hours []booking.WeeklyHours, closures []*StoreClosure, t time.Time) *time.Time {
+	// Each step skips a whole closure or gap between opening windows, so a
+	// handful is plenty for back-to-back closures
+	for i := 0; i < 20; i++ {
+		if c := closureAt(closures, t); c != nil {
+			t = c.EndsAt
+			continue
+		}
+		if openAt(hours, t) {
+			return &t
+		}
+		next, ok := nextOpening(hours, t)
+		if !ok {
+			return nil
+		}
+		t = next
+	}
+	return nil
+}
+
+// openAt reports whether weekly hours have a store open at t. No hours means
+// always open.
+func openAt(hours []booking.WeeklyHours, t time.Time) bool {
+	if len(hours) == 0 {
+		return true
+	}
+	local := t.In(booking.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, booking.Location)
+	for _, h := range hours {
+		if h.Weekday != local.Weekday() {
+			continue
+		}
+		opens, closes, err := windowOffsets(h)
+		if err != nil {
+			continue
+		}
+		if !local.Before(midnight.Add(opens)) && local.Before(midnight.Add(closes)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextOpening returns the first opening time after t within the next week.
+func nextOpening(hours []booking.WeeklyHours, t time.Time) (time.Time, bool) {
+	local := t.In(booking.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, booking.Location)
+	for d := 0; d <= 7; d++ {
+		day := midnight.AddDate(0, 0, d)
+		var earliest time.Time
+		for _, h := range hours {
+			if h.Weekday != day.Weekday() {
+				continue
+			}
+			opens, _, err := windowOffsets(h)
+			if err != nil {
+				continue
+			}
+			start := day.Add(opens)
+			if start.After(t) && (earliest.IsZero() || start.Before(earliest)) {
+				earliest = start
+			}
+		}
+		if !earliest.IsZero() {
+			return earliest, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func windowOffsets(h booking.WeeklyHours) (time.Duration, time.Duration, error) {
+	opens, err := booking.ClockOffset(h.Opens)
+	if err != nil {
+		return 0, 0, err
+	}
+	closes, err := booking.ClockOffset(h.Closes)
+	if err != nil {
+		return 0, 0, err
+	}
+	return opens, closes, nil
+}
+
+// NotifyReopenedStores tells followers when a store that was away on holiday
+// or vacation is open again. Stores whose closure ended outside their opening
+// hours are picked up once they open.
+func (r *repository) NotifyReopenedStores(ctx context.Context) (int, error) {
+	now := time.Now()
+	var ended []*StoreClosure
+	if err := r.db.WithContext(ctx).
+		Where("ends_at <= ? AND reopen_notified_at IS NULL", now).
+		Find(&ended).Error; err != nil {
+		return 0, fmt.Errorf("failed to find ended closures: %v", err)
+	}
+
+	byStore := map[uint32][]uint32{}
+	for _, c := range ended {
+		byStore[c.StoreID] = append(byStore[c.StoreID], c.ID)
+	}
+
+	notifier := notification.NewService(notification.NewRepository())
+	reopened := 0
+	for storeID, closureIDs := range byStore {
+		store, err := r.GetStore(ctx, storeID)
+		if err != nil {
+			// The store is gone, so there is no one to tell
+			r.db.WithContext(ctx).Model(&StoreClosure{}).Where("id IN ?", closureIDs).Update("reopen_notified_at", now)
+			continue
+		}
+		availability, err := r.GetStoreAvailability(ctx, store, now)
+		if err != nil {
+			return reopened, err
+		}
+		if !availability.Open {
+			continue
+		}
+
+		for _, f := range store.Followers {
+			if f == nil || f.FollowerID == 0 {
+				continue
+			}
+			_, err := notifier.Notify(ctx, &notification.Notification{
+				UserID:  f.FollowerID,
+				Type:    notification.TypeStoreReopened,
+				Title:   fmt.Sprintf("%s is open again", store.Name),
+				Message: fmt.Sprintf("%s is back and taking orders.", store.Name),
+				Link:    fmt.Sprintf("/store/%s", store.Link),
+			}, false)
+			if err != nil {
+				log.Printf("failed to notify follower %d of store %d reopening: %v", f.FollowerID, store.ID, err)
+			}
+		}
+		if err := r.db.WithContext(ctx).Model(&StoreClosure{}).Where("id IN ?", closureIDs).Update("reopen_notified_at", now).Error; err != nil {
+			return reopened, err
+		}
+		reopened++
+	}
+	return reopened, nil
+}
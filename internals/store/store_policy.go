@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fulfilment methods a store can offer
+const (
+	FulfilmentPickup     = "pickup"
+	FulfilmentCampus     = "campus_delivery"
+	FulfilmentNationwide = "nationwide_shipping"
+)
+
+const (
+	// MaxProcessingDays caps how long a store can take to prepare an order.
+	MaxProcessingDays = 30
+	// MaxReturnWindowDays caps how long a store can accept returns for.
+	MaxReturnWindowDays = 90
+)
+
+// StorePolicy is how a store fulfils orders and handles returns. Stores that
+// have not set one get DefaultStorePolicy.
+type StorePolicy struct {
+	StoreID            uint32    `json:"store_id" gorm:"primaryKey"`
+	Pickup             bool      `json:"pickup"`
+	PickupLocation     string    `json:"pickup_location"`
+	CampusDelivery     bool      `json:"campus_delivery"`
+	NationwideShipping bool      `json:"nationwide_shipping"`
+	ProcessingDays     int       `json:"processing_days"` // to get an order ready, 0 if same day
+	MinOrderValue      float64   `json:"min_order_value"`
+	ReturnsAccepted    bool      `json:"returns_accepted"`
+	ReturnWindowDays   int       `json:"return_window_days"`
+	ReturnPolicy       string    `json:"return_policy"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func (StorePolicy) TableName() string {
+	return "store_policies"
+}
+
+// DefaultStorePolicy is what a store offers before setting a policy: campus
+// delivery, plus pickup from its address if it has a physical one.
+func DefaultStorePolicy(store *Store) *StorePolicy {
+	policy := &StorePolicy{StoreID: store.ID, CampusDelivery: true}
+	if store.HasPhysicalAddress && strings.TrimSpace(store.Address) != "" {
+		policy.Pickup = true
+		policy.PickupLocation = store.Address
+	}
+	return policy
+}
+
+// Methods lists the fulfilment methods the policy offers, the default first.
+func (p *StorePolicy) Methods() []string {
+	methods := []string{}
+	if p.CampusDelivery {
+		methods = append(methods, FulfilmentCampus)
+	}
+	if p.NationwideShipping {
+		methods = append(methods, FulfilmentNationwide)
+	}
+	if p.Pickup {
+		methods = append(methods, FulfilmentPickup)
+	}
+	return methods
+}
+
+// Offers reports whether the policy offers a fulfilment method.
+func (p *StorePolicy) Offers(method string) bool {
+	for _, m := range p.Methods() {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderProblem says why a store's part of an order, worth subtotal and
+// fulfilled by method, breaks the policy. It returns "" if it does not.
+func (p *StorePolicy) OrderProblem(storeName, method string, subtotal float64) string {
+	if !p.Offers(method) {
+		return fmt.Sprintf("%s does not offer %s", storeName, strings.ReplaceAll(method, "_", " "))
+	}
+	if subtotal < p.MinOrderValue {
+		return fmt.Sprintf("%s has a minimum order of %.2f; add %.2f more from the store", storeName, p.MinOrderValue, p.MinOrderValue-subtotal)
+	}
+	return ""
+}
+
+func (r *repository) GetStorePolicy(ctx context.Context, store *Store) (*StorePolicy, error) {
+	var policy StorePolicy
+	err := r.db.WithContext(ctx).Where("store_id = ?", store.ID).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return DefaultStorePolicy(store), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store policy: %v", err)
+	}
+	return &policy, nil
+}
+
+func (r *repository) SetStorePolicy(ctx context.Context, policy *StorePolicy) (*StorePolicy, error) {
+	policy.PickupLocation = strings.TrimSpace(policy.PickupLocation)
+	policy.ReturnPolicy = strings.TrimSpace(policy.ReturnPolicy)
+	switch {
+	case len(policy.Methods()) == 0:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Offer at least one way to get orders to buyers")
+	case policy.Pickup && policy.PickupLocation == "":
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Pickup needs a pickup location")
+	case policy.ProcessingDays < 0 || policy.ProcessingDays > MaxProcessingDays:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Processing time must be between 0 and %d days", MaxProcessingDays))
+	case policy.MinOrderValue < 0:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Minimum order value cannot be negative")
+	case policy.ReturnsAccepted && (policy.ReturnWindowDays < 1 || policy.ReturnWindowDays > MaxReturnWindowDays):
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("Return window must be between 1 and %d days", MaxReturnWindowDays))
+	case len(policy.ReturnPolicy) > 2000:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Return policy must be 2000 characters or fewer")
+	}
+	if !policy.Pickup {
+		policy.PickupLocation = ""
+	}
+	if !policy.ReturnsAccepted {
+		policy.ReturnWindowDays = 0
+	}
+	policy.UpdatedAt = time.Now()
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "store_id"}},
+		UpdateAll: true,
+	}).Create(policy).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save store policy: %v", err)
+	}
+	return policy, nil
+}
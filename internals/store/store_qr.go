@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// What a QR code can point to
+const (
+	QRStore   = "store"
+	QRProduct = "product"
+	QRInvoice = "invoice"
+)
+
+// QRSource is the traffic source of visits that came from scanning a QR
+// code. Scans redirect with it in the "src" parameter, for the site to pass
+// on when recording views.
+const QRSource = "qr"
+
+// QR code image formats
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// QR codes point at the scan redirect rather than the page itself, so every
+// scan is counted and printed codes survive a store changing its handle.
+const qrScanPath = "/q/"
+
+// qrScanWindow is how long after a counted scan repeat scans of the code from
+// the same visitor are ignored.
+const qrScanWindow = 30 * time.Minute
+
+// QRScan is one scan of a store's QR code. Each scan links to the visitor's
+// scan of the code before it, so two requests counting a scan after the same
+// one collide on the unique key.
+type QRScan struct {
+	ID         uint32    `json:"id" gorm:"primaryKey"`
+	Kind       string    `json:"kind" gorm:"not null;uniqueIndex:idx_qr_scans_visitor_previous"`
+	TargetID   uint32    `json:"target_id" gorm:"not null;uniqueIndex:idx_qr_scans_visitor_previous"`
+	StoreID    uint32    `json:"store_id" gorm:"not null;index"`
+	Visitor    string    `json:"-" gorm:"not null;default:'';uniqueIndex:idx_qr_scans_visitor_previous"` // hash of the scanner's IP
+	PreviousID uint32    `json:"-" gorm:"not null;default:0;uniqueIndex:idx_qr_scans_visitor_previous"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (QRScan) TableName() string {
+	return "qr_scans"
+}
+
+// QRTarget is the page a QR code leads to.
+type QRTarget struct {
+	Kind     string
+	TargetID uint32
+	Store    *Store
+	Path     string // on the site, relative to CLIENT_URL
+}
+
+// ScanURL is what the QR code encodes.
+func (t *QRTarget) ScanURL() string {
+	return fmt.Sprintf("%s%s%s/%d", strings.TrimRight(os.Getenv("DOMAIN"), "/"), qrScanPath, t.Kind, t.TargetID)
+}
+
+// ImageURL is where the QR code can be downloaded in format.
+func (t *QRTarget) ImageURL(format string) string {
+	return fmt.Sprintf("%s/qr/%s/%d.%s", strings.TrimRight(os.Getenv("DOMAIN"), "/"), t.Kind, t.TargetID, format)
+}
+
+// PageURL is where a scan is redirected, tagged with QRSource.
+func (t *QRTarget) PageURL() string {
+	return fmt.Sprintf("%s%s?src=%s", strings.TrimRight(os.Getenv("CLIENT_URL"), "/"), t.Path, url.QueryEscape(QRSource))
+}
+
+// GetQRTarget finds the store, product or invoice a QR code is for. Codes of
+// deleted stores and products, and of products not approved by moderation,
+// stop resolving.
+func (r *repository) GetQRTarget(ctx context.Context, kind string, id uint32) (*QRTarget, error) {
+	target := &QRTarget{Kind: kind, TargetID: id}
+	var storeID uint32
+	switch kind {
+	case QRStore:
+		storeID = id
+	case QRProduct:
+		var p product.Product
+		if err := r.db.WithContext(ctx).Select("id, store").
+			Where("id = ? AND moderation_status = ?", id, product.ModerationApproved).First(&p).Error; err != nil {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+		}
+		store, err := r.GetStoreByName(ctx, p.Store)
+		if err != nil {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Product not found")
+		}
+		target.Store = store
+		target.Path = fmt.Sprintf("/product/%d", p.ID)
+	case QRInvoice:
+		var invoice Invoice
+		if err := r.db.WithContext(ctx).Select("id, store_id").Where("id = ?", id).First(&invoice).Error; err != nil {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Invoice not found")
+		}
+		storeID = invoice.StoreID
+		target.Path = fmt.Sprintf("/invoice/%d", invoice.ID)
+	default:
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", fmt.Sprintf("QR codes can be made for a %s, %s or %s, not %q", QRStore, QRProduct, QRInvoice, kind))
+	}
+
+	if target.Store == nil {
+		store, err := r.GetStore(ctx, storeID)
+		if err != nil {
+			return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Store not found")
+		}
+		target.Store = store
+	}
+	if kind == QRStore {
+		target.Path = "/store/" + url.PathEscape(target.Store.Link)
+	}
+	return target, nil
+}
+
+// RecordQRScan counts a scan of target from ip. Scans of the same code from
+// the same IP within qrScanWindow of the last counted one are dropped.
+func (r *repository) RecordQRScan(ctx context.Context, target *QRTarget, ip string) error {
+	visitor := sha256.Sum256([]byte(ip))
+	scan := &QRScan{
+		Kind:      target.Kind,
+		TargetID:  target.TargetID,
+		StoreID:   target.Store.ID,
+		Visitor:   hex.EncodeToString(visitor[:]),
+		CreatedAt: time.Now(),
+	}
+	var last QRScan
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND target_id = ? AND visitor = ?", scan.Kind, scan.TargetID, scan.Visitor).
+		Order("created_at DESC, id DESC").Take(&last).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+	case err != nil:
+		return fmt.Errorf("failed to record QR scan: %v", err)
+	case scan.CreatedAt.Sub(last.CreatedAt) < qrScanWindow:
+		return nil
+	}
+	scan.PreviousID = last.ID
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(scan).Error; err != nil {
+		return fmt.Errorf("failed to record QR scan: %v", err)
+	}
+	return nil
+}
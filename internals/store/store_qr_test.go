@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samstringzz/alutamarket-backend/internals/testdb"
+)
+
+func TestRecordQRScanCountsVisitorOnce(t *testing.T) {
+	db := testdb.Open(t, &QRScan{})
+	r := &repository{db: db}
+	ctx := context.Background()
+	storeTarget := &QRTarget{Kind: QRStore, TargetID: 4, Store: &Store{ID: 4}}
+	productTarget := &QRTarget{Kind: QRProduct, TargetID: 9, Store: &Store{ID: 4}}
+
+	scans := []struct {
+		target *QRTarget
+		ip     string
+	}{
+		{storeTarget, "10.0.0.1"},
+		{storeTarget, "10.0.0.1"},
+		{storeTarget, "10.0.0.2"},
+		{productTarget, "10.0.0.1"},
+	}
+	for _, scan := range scans {
+		if err := r.RecordQRScan(ctx, scan.target, scan.ip); err != nil {
+			t.Fatalf("RecordQRScan: %v", err)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&QRScan{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count scans: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("recorded %d scans, want 3", count)
+	}
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/lib/pq"
 	"github.com/samstringzz/alutamarket-backend/database"
 	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/booking"
 	"github.com/samstringzz/alutamarket-backend/internals/paystack"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
 	"github.com/samstringzz/alutamarket-backend/utils"
 	"gorm.io/gorm"
 )
@@ -49,11 +51,15 @@ func (r *repository) CreateStore(ctx context.Context, req *Store) (*Store, error
 	if count > 0 {
 		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Store already exists")
 	}
+	link := NormalizeHandle(req.Link)
+	if err := checkHandle(r.db.WithContext(ctx), link, 0); err != nil {
+		return nil, err
+	}
 
 	resp := &Store{
 		Name:               req.Name,
 		Email:              req.Email,
-		Link:               req.Link,
+		Link:               link,
 		UserID:             req.UserID,
 		Description:        req.Description,
 		HasPhysicalAddress: req.HasPhysicalAddress,
@@ -65,7 +71,7 @@ func (r *repository) CreateStore(ctx context.Context, req *Store) (*Store, error
 	}
 	if err := r.db.Create(resp).Error; err != nil {
 		r.db.Rollback()
-		return nil, err
+		return nil, handleWriteError(err, link)
 	}
 
 	// Create Paystack DVA account
@@ -273,19 +279,6 @@ func (r *repository) GetInvoices(ctx context.Context, storeId uint32) ([]*Invoic
 	return invoice, nil
 }
 
-func (r *repository) DeleteStore(ctx context.Context, id uint32) error {
-	existingStore, err := r.GetStore(ctx, id)
-	if err != nil {
-		return err
-	}
-	err = r.db.Where("id = ?", id).First(&Store{}).Error
-	if err != nil {
-		return err
-	}
-	err = r.db.Delete(existingStore).Error
-	return err
-}
-
 func (r *repository) UpdateStore(ctx context.Context, req *UpdateStore) (*Store, error) {
 	// First, check if the Store exists by its ID or another unique identifier
 	existingStore, err := r.GetStore(ctx, req.ID)
@@ -318,7 +311,10 @@ func (r *repository) UpdateStore(ctx context.Context, req *UpdateStore) (*Store,
 		sort.Strings(existingStore.Visitors)
 	}
 	if req.Link != "" {
-		existingStore.Link = req.Link
+		// Old handles stay with the store so links to them keep working
+		if err := assignHandle(r.db.WithContext(ctx), existingStore, NormalizeHandle(req.Link)); err != nil {
+			return nil, err
+		}
 	}
 	if req.Phone != "" {
 		existingStore.Phone = req.Phone
@@ -510,6 +506,20 @@ func (r *repository) GetPurchasedOrders(ctx context.Context, userID string) ([]*
 	return orders, nil
 }
 
+// RecordSaleUnits counts the lines of a newly paid order priced by a sale
+// against the sale's cap.
+func RecordSaleUnits(tx *gorm.DB, order *Order) error {
+	for _, line := range order.Products {
+		if line.SaleItemID == 0 || line.SaleUnits <= 0 {
+			continue
+		}
+		if err := product.RecordSaleUnits(tx, line.SaleItemID, line.SaleUnits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *repository) UpdateOrderStatus(ctx context.Context, uuid string, status, transStatus string) error {
 	// Get the order first
 	order, err := r.GetOrderByUUID(ctx, uuid)
@@ -530,15 +540,35 @@ func (r *repository) UpdateOrderStatus(ctx context.Context, uuid string, status,
 		}
 	}
 
-	// Update the order status
-	if err := r.db.Model(&Order{}).
-		Where("uuid = ?", uuid).
-		Updates(map[string]interface{}{
-			"status":       status,
-			"trans_status": transStatus,
-		}).Error; err != nil {
+	// Update the order status. Bookings held for the order are confirmed with
+	// the payment, or their holds would lapse and the slots be sold again.
+	var confirmed []*booking.Booking
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Order{}).
+			Where("uuid = ?", uuid).
+			Updates(map[string]interface{}{
+				"status":       status,
+				"trans_status": transStatus,
+			}).Error; err != nil {
+			return err
+		}
+		if transStatus != "paid" {
+			return nil
+		}
+		// Sale units are counted once, when the order first becomes paid
+		if order.TransStatus != "paid" {
+			if err := RecordSaleUnits(tx, order); err != nil {
+				return err
+			}
+		}
+		confirmed, err = booking.ConfirmOrder(ctx, tx, uuid)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update order status: %v", err)
 	}
+	booking.NotifyConfirmed(ctx, r.db, confirmed)
+	r.recordOrderEvent(ctx, uuid, order.StoresID, status)
 
 	// Send email notifications concurrently
 	go func() {
@@ -727,6 +757,7 @@ func (r *repository) UpdateOrder(ctx context.Context, req *UpdateStoreOrderInput
 			return nil, err // Return the first error encountered
 		}
 	}
+	r.recordOrderEvent(ctx, existingOrder.UUID, []string{existingStore.Name}, req.Status)
 
 	return existingOrder, nil
 }
@@ -822,54 +853,6 @@ func (r *repository) WithdrawFund(ctx context.Context, req *Fund) error {
 	return service.WithdrawFund(ctx, req)
 }
 
-// AddReview adds a new review to the database
-func (r *repository) AddReview(ctx context.Context, review *Review) error {
-	if err := r.db.WithContext(ctx).Create(review).Error; err != nil {
-		return err
-	}
-	return nil
-}
-
-// storeReviews, err := repo.GetReviews(ctx, "storeID", uint32(1))
-// orderReviews, err := repo.GetReviews(ctx, "orderID", "order1
-// GetReviews fetches reviews by either storeID or orderID based on the filter type.
-func (r *repository) GetReviews(ctx context.Context, filterType string, value interface{}) ([]*Review, error) {
-	var reviews []*Review
-
-	// Determine the filter type and apply the query
-	switch filterType {
-	case "storeID":
-		storeID, ok := value.(uint32)
-		if !ok {
-			return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "invalid storeID type")
-		}
-		if err := r.db.WithContext(ctx).Where("store_id = ?", storeID).Find(&reviews).Error; err != nil {
-			return nil, err
-		}
-	case "productID":
-		productID, ok := value.(uint32)
-		if !ok {
-			return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "invalid storeID type")
-		}
-		if err := r.db.WithContext(ctx).Where("product_id = ?", productID).Find(&reviews).Error; err != nil {
-			return nil, err
-		}
-	case "orderID":
-		orderID, ok := value.(string)
-		if !ok {
-			return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "invalid orderID type")
-		}
-		if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&reviews).Error; err != nil {
-			return nil, err
-		}
-
-	default:
-		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "invalid filter type")
-	}
-
-	return reviews, nil
-}
-
 func filterProductsByStore(products []TrackedProduct, storeName string) []*StoreProduct {
 	var filteredProducts []*StoreProduct
 
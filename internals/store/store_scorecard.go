@@ -0,0 +1,267 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Seller badges, from least to most trusted
+const (
+	BadgeNew      = "new" // too few recent orders to judge
+	BadgeStandard = "standard"
+	BadgeTrusted  = "trusted"
+	BadgeTop      = "top_seller"
+)
+
+const (
+	// ScorecardWindow is how far back order based metrics look.
+	ScorecardWindow = 90 * 24 * time.Hour
+	// MinScoredOrders is how many orders in the window a store needs before
+	// it earns a badge above BadgeNew.
+	MinScoredOrders = 5
+	// NeutralScore is given to stores with nothing to score yet, so new
+	// sellers rank between good and poor ones.
+	NeutralScore = 50.0
+)
+
+// StoreOrderEvent records a store moving its part of an order to a new
+// status. Scorecards read shipping times and cancellations from it.
+type StoreOrderEvent struct {
+	ID        uint32    `json:"id" gorm:"primaryKey"`
+	OrderUUID string    `json:"order_uuid" gorm:"not null;index"`
+	Store     string    `json:"store" gorm:"not null;index"`
+	Status    string    `json:"status" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (StoreOrderEvent) TableName() string {
+	return "store_order_events"
+}
+
+// StoreScorecard is a store's seller performance, rebuilt daily. Metrics are
+// nil when the store has no data for them.
+type StoreScorecard struct {
+	StoreID            uint32    `json:"store_id" gorm:"primaryKey"`
+	Orders             int       `json:"orders"` // paid orders in the window
+	AvgShipHours       *float64  `json:"avg_ship_hours"`
+	CancellationRate   float64   `json:"cancellation_rate"`
+	DisputeRate        float64   `json:"dispute_rate"`
+	AvgResponseMinutes *float64  `json:"avg_response_minutes"`
+	Rating             *float64  `json:"rating"`
+	Reviews            int       `json:"reviews"`
+	Score              float64   `json:"score"` // 0 to 100
+	Badge              string    `json:"badge"`
+	ComputedAt         time.Time `json:"computed_at"`
+}
+
+func (StoreScorecard) TableName() string {
+	return "store_scorecards"
+}
+
+// recordOrderEvent logs a status change for each store's part of an order.
+// Failures only cost scorecard accuracy, so they are logged and not returned.
+func (r *repository) recordOrderEvent(ctx context.Context, orderUUID string, stores []string, status string) {
+	for _, name := range stores {
+		event := &StoreOrderEvent{OrderUUID: orderUUID, Store: name, Status: status}
+		if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+			log.Printf("Failed to record %s event for order %s of store %s: %v", status, orderUUID, name, err)
+		}
+	}
+}
+
+// GetStoreScorecard returns a store's last computed scorecard, or a neutral
+// one if it has not been scored yet.
+func (r *repository) GetStoreScorecard(ctx context.Context, storeID uint32) (*StoreScorecard, error) {
+	var scorecard StoreScorecard
+	err := r.db.WithContext(ctx).Where("store_id = ?", storeID).First(&scorecard).Error
+	if err == gorm.ErrRecordNotFound {
+		return &StoreScorecard{StoreID: storeID, Score: NeutralScore, Badge: BadgeNew}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scorecard: %v", err)
+	}
+	return &scorecard, nil
+}
+
+type scorecardStat struct {
+	Key   string
+	Count int
+	Value float64
+}
+
+// scorecardStats runs a query returning key, count and value columns and
+// indexes the rows by key.
+func (r *repository) scorecardStats(ctx context.Context, query string, args map[string]interface{}) (map[string]scorecardStat, error) {
+	var rows []scorecardStat
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	stats := make(map[string]scorecardStat, len(rows))
+	for _, row := range rows {
+		stats[row.Key] = row
+	}
+	return stats, nil
+}
+
+// RollupStoreScorecards recomputes every store's scorecard from its recent
+// orders, disputes, chat replies and reviews. It returns how many stores were
+// scored.
+func (r *repository) RollupStoreScorecards(ctx context.Context) (int, error) {
+	now := time.Now()
+	args := map[string]interface{}{"since": now.Add(-ScorecardWindow)}
+
+	// Paid orders per store, and how many the store or buyer cancelled
+	orders, err := r.scorecardStats(ctx, `
+		SELECT l->>'store' AS key, COUNT(DISTINCT o.uuid) AS count,
+			COUNT(DISTINCT o.uuid) FILTER (WHERE o.status = 'canceled' OR e.id IS NOT NULL) AS value
+		FROM orders o
+		CROSS JOIN jsonb_array_elements(o.products::jsonb) AS l
+		LEFT JOIN store_order_events e ON e.order_uuid = o.uuid AND e.store = l->>'store' AND e.status = 'canceled'
+		WHERE o.trans_status = 'paid' AND o.deleted_at IS NULL AND o.created_at >= @since
+		GROUP BY 1`, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count orders: %v", err)
+	}
+
+	// Hours from checkout until the store first marked its part shipped
+	shipping, err := r.scorecardStats(ctx, `
+		SELECT e.store AS key, COUNT(*) AS count,
+			AVG(EXTRACT(EPOCH FROM e.shipped_at - o.created_at) / 3600) AS value
+		FROM (
+			SELECT store, order_uuid, MIN(created_at) AS shipped_at
+			FROM store_order_events
+			WHERE status IN ('shipped', 'delivered')
+			GROUP BY store, order_uuid
+		) e
+		JOIN orders o ON o.uuid = e.order_uuid
+		WHERE o.created_at >= @since
+		GROUP BY 1`, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure shipping times: %v", err)
+	}
+
+	disputes, err := r.scorecardStats(ctx, `
+		SELECT s.name AS key, COUNT(*) AS count, 0 AS value
+		FROM order_disputes d
+		JOIN stores s ON s.id = d.store_id
+		WHERE d.created_at >= @since AND d.status <> '`+DisputeDismissed+`'
+		GROUP BY 1`, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count disputes: %v", err)
+	}
+
+	// Minutes a seller took to answer when someone else wrote last in a chat.
+	// Keyed by the seller's user ID, so stores with one owner share it.
+	responses, err := r.scorecardStats(ctx, `
+		SELECT sender::text AS key, COUNT(*) AS count, AVG(EXTRACT(EPOCH FROM delay) / 60) AS value
+		FROM (
+			SELECT m.sender, m.created_at,
+				m.created_at - LAG(m.created_at) OVER w AS delay,
+				LAG(m.sender) OVER w AS previous_sender
+			FROM messages m
+			WHERE m.deleted_at IS NULL
+			WINDOW w AS (PARTITION BY m.chat_id ORDER BY m.created_at)
+		) replies
+		WHERE previous_sender <> sender AND created_at >= @since
+			AND sender IN (SELECT user_id FROM stores WHERE deleted_at IS NULL)
+		GROUP BY 1`, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure response times: %v", err)
+	}
+
+	ratings, err := r.scorecardStats(ctx, `
+		SELECT s.name AS key, COUNT(*) AS count, AVG(rv.rating) AS value
+		FROM reviews rv
+		JOIN stores s ON s.id = rv.store_id
+		WHERE rv.verified AND rv.deleted_at IS NULL
+		GROUP BY 1`, args)
+	if err != nil {
+		return 0, fmt.Errorf("failed to average ratings: %v", err)
+	}
+
+	var stores []*Store
+	if err := r.db.WithContext(ctx).Select("id, name, user_id").Find(&stores).Error; err != nil {
+		return 0, fmt.Errorf("failed to get stores: %v", err)
+	}
+
+	for _, store := range stores {
+		scorecard := &StoreScorecard{StoreID: store.ID, ComputedAt: now}
+		if o, ok := orders[store.Name]; ok && o.Count > 0 {
+			scorecard.Orders = o.Count
+			scorecard.CancellationRate = o.Value / float64(o.Count)
+			scorecard.DisputeRate = float64(disputes[store.Name].Count) / float64(o.Count)
+		}
+		if s, ok := shipping[store.Name]; ok {
+			scorecard.AvgShipHours = &s.Value
+		}
+		if rs, ok := responses[strconv.Itoa(int(store.UserID))]; ok {
+			scorecard.AvgResponseMinutes = &rs.Value
+		}
+		if rt, ok := ratings[store.Name]; ok {
+			scorecard.Rating = &rt.Value
+			scorecard.Reviews = rt.Count
+		}
+		scorecard.Score = scorecardScore(scorecard)
+		scorecard.Badge = scorecardBadge(scorecard)
+
+		err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "store_id"}},
+			UpdateAll: true,
+		}).Create(scorecard).Error
+		if err != nil {
+			return 0, fmt.Errorf("failed to save scorecard of store %d: %v", store.ID, err)
+		}
+	}
+	return len(stores), nil
+}
+
+// linearScore maps value to 1 at or below best and 0 at or beyond worst.
+func linearScore(value, best, worst float64) float64 {
+	return math.Max(0, math.Min(1, (worst-value)/(worst-best)))
+}
+
+// scorecardScore weighs the metrics a store has into a 0 to 100 score.
+// Missing metrics are left out rather than counted as bad, and a store with
+// no orders gets NeutralScore.
+func scorecardScore(s *StoreScorecard) float64 {
+	if s.Orders == 0 {
+		return NeutralScore
+	}
+	var total, weights float64
+	add := func(weight, score float64) {
+		total += weight * score
+		weights += weight
+	}
+	if s.Rating != nil {
+		add(30, linearScore(*s.Rating, 5, 1))
+	}
+	if s.AvgShipHours != nil {
+		add(25, linearScore(*s.AvgShipHours, 24, 7*24))
+	}
+	add(20, linearScore(s.CancellationRate, 0, 0.2))
+	add(15, linearScore(s.DisputeRate, 0, 0.1))
+	if s.AvgResponseMinutes != nil {
+		add(10, linearScore(*s.AvgResponseMinutes, 60, 24*60))
+	}
+	return math.Round(total/weights*1000) / 10
+}
+
+func scorecardBadge(s *StoreScorecard) string {
+	switch {
+	case s.Orders < MinScoredOrders:
+		return BadgeNew
+	case s.Score >= 85 && s.Orders >= 20 && s.Rating != nil && *s.Rating >= 4.5:
+		return BadgeTop
+	case s.Score >= 70:
+		return BadgeTrusted
+	default:
+		return BadgeStandard
+	}
+}
@@ -210,8 +210,12 @@ func (s *service) WithdrawFund(ctx context.Context, req *Fund) error {
 		return fmt.Errorf("failed to get store: %v", err)
 	}
 
-	// Check if user is store owner
-	if req.UserID != store.UserID {
+	// Check that the user's role lets them pay the wallet out
+	role, err := s.Repository.GetStoreRole(ctx, store, req.UserID)
+	if err != nil {
+		return err
+	}
+	if !RoleAllows(role, PermWithdrawals) {
 		return errors.NewAppError(http.StatusNotFound, "NOT_FOUND", "Oops, An error occurred in transaction")
 	}
 
@@ -230,26 +234,6 @@ func (s *service) WithdrawFund(ctx context.Context, req *Fund) error {
 	return nil
 }
 
-func (s *service) AddReview(ctx context.Context, review *Review) error {
-	ctx, cancel := context.WithTimeout(ctx, s.timeout)
-	defer cancel()
-	if err := s.Repository.AddReview(ctx, review); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (s *service) GetReviews(ctx context.Context, filterType string, value interface{}) ([]*Review, error) {
-	ctx, cancel := context.WithTimeout(ctx, s.timeout)
-	defer cancel()
-
-	result, err := s.Repository.GetReviews(ctx, filterType, value)
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
-}
-
 func (s *service) GetDVAAccount(ctx context.Context, email string) (*DVAAccount, error) {
 	return s.Repository.GetDVAAccount(ctx, email)
 }
@@ -351,3 +335,370 @@ func (s *service) SyncExistingPaystackDVAAccounts(ctx context.Context) error {
 
 	return s.Repository.SyncExistingPaystackDVAAccounts(ctx)
 }
+
+func (s *service) GetTrashedStore(ctx context.Context, id uint32) (*Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetTrashedStore(ctx, id)
+}
+
+func (s *service) GetTrashedStores(ctx context.Context, userID uint32) ([]*Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetTrashedStores(ctx, userID)
+}
+
+func (s *service) CountTrashedStoreProducts(ctx context.Context, store *Store) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CountTrashedStoreProducts(ctx, store)
+}
+
+func (s *service) RestoreStore(ctx context.Context, id uint32) (*Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.RestoreStore(ctx, id)
+}
+
+func (s *service) PurgeTrashedStores(ctx context.Context, before time.Time) (int, error) {
+	// Purging can touch many stores, so it gets longer than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.PurgeTrashedStores(ctx, before)
+}
+
+func (s *service) GetStoreAnalytics(ctx context.Context, storeName string, from, to time.Time, granularity string) (*StoreAnalytics, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreAnalytics(ctx, storeName, from, to, granularity)
+}
+
+func (s *service) RollupStoreAnalytics(ctx context.Context, day time.Time) error {
+	// A rollup scans a whole day of orders and views, so it gets more time than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.RollupStoreAnalytics(ctx, day)
+}
+
+func (s *service) GetStoreRole(ctx context.Context, store *Store, userID uint32) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreRole(ctx, store, userID)
+}
+
+func (s *service) InviteStaff(ctx context.Context, member *StoreMember) (*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.InviteStaff(ctx, member)
+}
+
+func (s *service) GetStaffMember(ctx context.Context, id uint32) (*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStaffMember(ctx, id)
+}
+
+func (s *service) GetStoreStaff(ctx context.Context, storeID uint32) ([]*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreStaff(ctx, storeID)
+}
+
+func (s *service) GetUserStaffInvites(ctx context.Context, userID uint32) ([]*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetUserStaffInvites(ctx, userID)
+}
+
+func (s *service) GetUserMemberships(ctx context.Context, userID uint32) ([]*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetUserMemberships(ctx, userID)
+}
+
+func (s *service) AcceptStaffInvite(ctx context.Context, id, userID uint32) (*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.AcceptStaffInvite(ctx, id, userID)
+}
+
+func (s *service) UpdateStaffRole(ctx context.Context, id uint32, role string) (*StoreMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.UpdateStaffRole(ctx, id, role)
+}
+
+func (s *service) RemoveStaff(ctx context.Context, id uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.RemoveStaff(ctx, id)
+}
+
+func (s *service) GetStoreHours(ctx context.Context, storeID uint32) (*StoreHours, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreHours(ctx, storeID)
+}
+
+func (s *service) SetStoreHours(ctx context.Context, hours *StoreHours) (*StoreHours, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetStoreHours(ctx, hours)
+}
+
+func (s *service) GetStoreClosures(ctx context.Context, storeID uint32, includePast bool) ([]*StoreClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreClosures(ctx, storeID, includePast)
+}
+
+func (s *service) GetStoreClosure(ctx context.Context, id uint32) (*StoreClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreClosure(ctx, id)
+}
+
+func (s *service) ScheduleClosure(ctx context.Context, closure *StoreClosure) (*StoreClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.ScheduleClosure(ctx, closure)
+}
+
+func (s *service) EndClosure(ctx context.Context, id uint32) (*StoreClosure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.EndClosure(ctx, id)
+}
+
+func (s *service) GetStoreAvailability(ctx context.Context, store *Store, at time.Time) (*StoreAvailability, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreAvailability(ctx, store, at)
+}
+
+func (s *service) NotifyReopenedStores(ctx context.Context) (int, error) {
+	// Runs over every ended closure, so give it longer than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.NotifyReopenedStores(ctx)
+}
+
+func (s *service) PostAnnouncement(ctx context.Context, a *StoreAnnouncement) (*StoreAnnouncement, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.PostAnnouncement(ctx, a)
+}
+
+func (s *service) GetAnnouncement(ctx context.Context, id uint32) (*StoreAnnouncement, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetAnnouncement(ctx, id)
+}
+
+func (s *service) GetStoreAnnouncements(ctx context.Context, storeID uint32, limit, offset int) ([]*StoreAnnouncement, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreAnnouncements(ctx, storeID, limit, offset)
+}
+
+func (s *service) DeleteAnnouncement(ctx context.Context, id uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.DeleteAnnouncement(ctx, id)
+}
+
+func (s *service) GetFollowerFeed(ctx context.Context, userID uint32, limit int, after string) (*FeedPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetFollowerFeed(ctx, userID, limit, after)
+}
+
+func (s *service) SendFollowDigests(ctx context.Context) (int, error) {
+	// Runs over every opted-in user, so give it longer than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.SendFollowDigests(ctx)
+}
+
+func (s *service) GetStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) (*CustomerList, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreCustomers(ctx, store, filter)
+}
+
+func (s *service) ExportStoreCustomers(ctx context.Context, store *Store, filter *CustomerFilter) ([]*CustomerProfile, error) {
+	// Lists every matching customer, so give it longer than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.ExportStoreCustomers(ctx, store, filter)
+}
+
+func (s *service) GetStoreCustomer(ctx context.Context, store *Store, userID uint32) (*CustomerProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreCustomer(ctx, store, userID)
+}
+
+func (s *service) SetCustomerTags(ctx context.Context, store *Store, userID uint32, tags []string) (*CustomerProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetCustomerTags(ctx, store, userID, tags)
+}
+
+func (s *service) AddCustomerNote(ctx context.Context, store *Store, note *StoreCustomerNote) (*StoreCustomerNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.AddCustomerNote(ctx, store, note)
+}
+
+func (s *service) GetCustomerNotes(ctx context.Context, storeID, userID uint32) ([]*StoreCustomerNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetCustomerNotes(ctx, storeID, userID)
+}
+
+func (s *service) GetCustomerNote(ctx context.Context, id uint32) (*StoreCustomerNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetCustomerNote(ctx, id)
+}
+
+func (s *service) DeleteCustomerNote(ctx context.Context, id uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.DeleteCustomerNote(ctx, id)
+}
+
+func (s *service) SendPromo(ctx context.Context, store *Store, campaign *StorePromoCampaign) (*StorePromoCampaign, error) {
+	// Notifies every matching customer, so give it longer than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.SendPromo(ctx, store, campaign)
+}
+
+func (s *service) GetPromoCampaigns(ctx context.Context, storeID uint32) ([]*StorePromoCampaign, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetPromoCampaigns(ctx, storeID)
+}
+
+func (s *service) GetStoreScorecard(ctx context.Context, storeID uint32) (*StoreScorecard, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreScorecard(ctx, storeID)
+}
+
+func (s *service) RollupStoreScorecards(ctx context.Context) (int, error) {
+	// Runs over every store, so give it longer than a request
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	return s.Repository.RollupStoreScorecards(ctx)
+}
+
+func (s *service) OpenDispute(ctx context.Context, dispute *OrderDispute) (*OrderDispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.OpenDispute(ctx, dispute)
+}
+
+func (s *service) GetDispute(ctx context.Context, id uint32) (*OrderDispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetDispute(ctx, id)
+}
+
+func (s *service) GetBuyerDisputes(ctx context.Context, buyerID uint32) ([]*OrderDispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetBuyerDisputes(ctx, buyerID)
+}
+
+func (s *service) GetStoreDisputes(ctx context.Context, storeID uint32) ([]*OrderDispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreDisputes(ctx, storeID)
+}
+
+func (s *service) GetDisputeQueue(ctx context.Context, status string) ([]*OrderDispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetDisputeQueue(ctx, status)
+}
+
+func (s *service) ResolveDispute(ctx context.Context, id, adminID uint32, upheld bool, resolution string) (*OrderDispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.ResolveDispute(ctx, id, adminID, upheld, resolution)
+}
+
+func (s *service) GetStorePolicy(ctx context.Context, store *Store) (*StorePolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStorePolicy(ctx, store)
+}
+
+func (s *service) SetStorePolicy(ctx context.Context, policy *StorePolicy) (*StorePolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetStorePolicy(ctx, policy)
+}
+
+func (s *service) GetStoreCloseout(ctx context.Context, storeID uint32) (*StoreCloseout, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreCloseout(ctx, storeID)
+}
+
+func (s *service) GetCloseoutChecklist(ctx context.Context, store *Store) (*CloseoutChecklist, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetCloseoutChecklist(ctx, store)
+}
+
+func (s *service) StartStoreCloseout(ctx context.Context, store *Store, requestedBy uint32, reason string) (*StoreCloseout, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.StartStoreCloseout(ctx, store, requestedBy, reason)
+}
+
+func (s *service) CancelStoreCloseout(ctx context.Context, storeID uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CancelStoreCloseout(ctx, storeID)
+}
+
+func (s *service) CloseStore(ctx context.Context, id, closedBy uint32) (*StoreCloseout, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CloseStore(ctx, id, closedBy)
+}
+
+func (s *service) CheckStoreHandle(ctx context.Context, handle string, storeID uint32) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.CheckStoreHandle(ctx, handle, storeID)
+}
+
+func (s *service) SetStoreHandle(ctx context.Context, store *Store, handle string) (*Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.SetStoreHandle(ctx, store, handle)
+}
+
+func (s *service) GetStoreByHandle(ctx context.Context, handle string) (*Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetStoreByHandle(ctx, handle)
+}
+
+func (s *service) GetQRTarget(ctx context.Context, kind string, id uint32) (*QRTarget, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.GetQRTarget(ctx, kind, id)
+}
+
+func (s *service) RecordQRScan(ctx context.Context, target *QRTarget, ip string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.Repository.RecordQRScan(ctx, target, ip)
+}
@@ -0,0 +1,333 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/notification"
+	"github.com/samstringzz/alutamarket-backend/utils"
+	"gorm.io/gorm"
+)
+
+// Store roles. The owner is the store's UserID and is never stored as a member.
+const (
+	RoleOwner          = "owner"
+	RoleManager        = "manager"
+	RoleOrderHandler   = "order_handler"
+	RoleInventoryClerk = "inventory_clerk"
+)
+
+// Permissions checked against a caller's store role
+const (
+	PermProducts    = "products"    // catalogue, stock, prices and sales
+	PermOrders      = "orders"      // order status and bookings
+	PermInvoices    = "invoices"    // invoices sent to customers
+	PermAnalytics   = "analytics"   // sales and traffic figures
+	PermSettings    = "settings"    // store profile and hours
+	PermStaff       = "staff"       // inviting and removing members
+	PermWithdrawals = "withdrawals" // paying the wallet out
+	PermCustomers   = "customers"   // customer list, notes and promos
+)
+
+var rolePermissions = map[string][]string{
+	RoleOwner:          {PermProducts, PermOrders, PermInvoices, PermAnalytics, PermSettings, PermStaff, PermWithdrawals, PermCustomers},
+	RoleManager:        {PermProducts, PermOrders, PermInvoices, PermAnalytics, PermSettings, PermCustomers},
+	RoleOrderHandler:   {PermOrders, PermInvoices},
+	RoleInventoryClerk: {PermProducts},
+}
+
+// Member statuses
+const (
+	MemberInvited = "invited"
+	MemberActive  = "active"
+)
+
+// InviteTTL is how long a staff invite can be accepted.
+const InviteTTL = 7 * 24 * time.Hour
+
+// RoleAllows reports whether a store role grants a permission.
+func RoleAllows(role, perm string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidStaffRole reports whether a role can be given to a member. Ownership
+// cannot be handed out through an invite.
+func ValidStaffRole(role string) bool {
+	return role != RoleOwner && rolePermissions[role] != nil
+}
+
+// StoreMember is a person working for a store. Invites are matched to an
+// account by email or phone and get a UserID once accepted.
+type StoreMember struct {
+	ID              uint32     `json:"id" gorm:"primaryKey"`
+	StoreID         uint32     `json:"store_id" gorm:"index"`
+	Store           *Store     `json:"store,omitempty" gorm:"foreignKey:StoreID"`
+	UserID          uint32     `json:"user_id" gorm:"index"` // 0 until the invite is accepted
+	Role            string     `json:"role"`
+	Email           string     `json:"email" gorm:"index"`
+	Phone           string     `json:"phone" gorm:"index"`
+	Status          string     `json:"status"`
+	InvitedBy       uint32     `json:"invited_by"`
+	InviteExpiresAt time.Time  `json:"invite_expires_at"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// staffContact is the part of a user account invites are matched against.
+type staffContact struct {
+	ID       uint32
+	Fullname string
+	Email    string
+	Phone    string
+}
+
+// GetStoreRole returns the caller's role in a store, or "" if they have none.
+func (r *repository) GetStoreRole(ctx context.Context, store *Store, userID uint32) (string, error) {
+	if userID == 0 {
+		return "", nil
+	}
+	if store.UserID == userID {
+		return RoleOwner, nil
+	}
+	var role string
+	err := r.db.WithContext(ctx).Model(&StoreMember{}).
+		Where("store_id = ? AND user_id = ? AND status = ?", store.ID, userID, MemberActive).
+		Limit(1).Pluck("role", &role).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to get store role: %v", err)
+	}
+	return role, nil
+}
+
+// InviteStaff invites someone to a store by email or phone. People who
+// already have an account are notified in the app as well.
+func (r *repository) InviteStaff(ctx context.Context, member *StoreMember) (*StoreMember, error) {
+	member.Email = strings.ToLower(strings.TrimSpace(member.Email))
+	member.Phone = strings.TrimSpace(member.Phone)
+	if member.Email == "" && member.Phone == "" {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "An email address or phone number is required")
+	}
+	if !ValidStaffRole(member.Role) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Role must be manager, order_handler or inventory_clerk")
+	}
+	store, err := r.GetStore(ctx, member.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitee := r.findStaffContact(ctx, member.Email, member.Phone)
+	if invitee != nil && invitee.ID == store.UserID {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "The owner is already part of this store")
+	}
+
+	var existing int64
+	query := r.db.WithContext(ctx).Model(&StoreMember{}).Where("store_id = ?", store.ID)
+	if invitee != nil {
+		query = query.Where("(user_id = ? OR (status = ? AND ((email <> '' AND email = ?) OR (phone <> '' AND phone = ?))))",
+			invitee.ID, MemberInvited, invitee.Email, invitee.Phone)
+	} else {
+		query = query.Where("((email <> '' AND email = ?) OR (phone <> '' AND phone = ?))", member.Email, member.Phone)
+	}
+	query.Count(&existing)
+	if existing > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "This person is already a member or has a pending invite")
+	}
+
+	member.Status = MemberInvited
+	member.UserID = 0
+	member.InviteExpiresAt = time.Now().Add(InviteTTL)
+	if err := r.db.WithContext(ctx).Create(member).Error; err != nil {
+		return nil, fmt.Errorf("failed to invite staff: %v", err)
+	}
+
+	r.sendStaffInvite(ctx, store, member, invitee)
+	return member, nil
+}
+
+// sendStaffInvite tells the invitee about the invite. Failures are logged so
+// the invite still stands.
+func (r *repository) sendStaffInvite(ctx context.Context, store *Store, member *StoreMember, invitee *staffContact) {
+	contact := "phone number"
+	if member.Email != "" {
+		contact = "email address"
+	}
+	title := fmt.Sprintf("Join %s on Alutamarket", store.Name)
+	message := fmt.Sprintf("You have been invited to help run %s as %s. Sign in with this %s to accept.",
+		store.Name, strings.ReplaceAll(member.Role, "_", " "), contact)
+	if invitee != nil {
+		_, err := notification.NewService(notification.NewRepository()).Notify(ctx, &notification.Notification{
+			UserID:  invitee.ID,
+			Type:    notification.TypeStoreInvite,
+			Title:   title,
+			Message: message,
+			Link:    "/store-invites",
+		}, true)
+		if err != nil {
+			log.Printf("Failed to notify user %d of store invite %d: %v", invitee.ID, member.ID, err)
+		}
+		return
+	}
+
+	if member.Email != "" {
+		contents := map[string]string{
+			"name":    member.Email,
+			"title":   title,
+			"message": message,
+			"link":    "/store-invites",
+		}
+		if err := utils.SendEmail(os.Getenv("ONE_SIGNAL_NOTIFICATION_TEMPLATE_ID"), title, []string{member.Email}, contents); err != nil {
+			log.Printf("Failed to email store invite %d: %v", member.ID, err)
+		}
+		return
+	}
+	if _, err := utils.SendSMS(strings.TrimPrefix(member.Phone, "+"), "N-Alert", message); err != nil {
+		log.Printf("Failed to text store invite %d: %v", member.ID, err)
+	}
+}
+
+// findStaffContact looks up the account using an email address or phone number.
+func (r *repository) findStaffContact(ctx context.Context, email, phone string) *staffContact {
+	var contact staffContact
+	query := r.db.WithContext(ctx).Table("users").Select("id, fullname, email, phone").Where("deleted_at IS NULL")
+	switch {
+	case email != "" && phone != "":
+		query = query.Where("(LOWER(email) = ? OR phone = ?)", email, phone)
+	case email != "":
+		query = query.Where("LOWER(email) = ?", email)
+	default:
+		query = query.Where("phone = ?", phone)
+	}
+	if err := query.Limit(1).Scan(&contact).Error; err != nil || contact.ID == 0 {
+		return nil
+	}
+	contact.Email = strings.ToLower(contact.Email)
+	return &contact
+}
+
+// GetStaffMember loads a member or invite with its store.
+func (r *repository) GetStaffMember(ctx context.Context, id uint32) (*StoreMember, error) {
+	var member StoreMember
+	err := r.db.WithContext(ctx).Preload("Store").Where("id = ?", id).First(&member).Error
+	if err == gorm.ErrRecordNotFound || (err == nil && member.Store == nil) {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Staff member not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetStoreStaff lists a store's members and pending invites.
+func (r *repository) GetStoreStaff(ctx context.Context, storeID uint32) ([]*StoreMember, error) {
+	var members []*StoreMember
+	err := r.db.WithContext(ctx).Where("store_id = ?", storeID).Order("created_at ASC").Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store staff: %v", err)
+	}
+	return members, nil
+}
+
+// GetUserStaffInvites lists the pending invites sent to a user's email or phone.
+func (r *repository) GetUserStaffInvites(ctx context.Context, userID uint32) ([]*StoreMember, error) {
+	var contact staffContact
+	if err := r.db.WithContext(ctx).Table("users").Select("id, fullname, email, phone").Where("id = ?", userID).Scan(&contact).Error; err != nil {
+		return nil, err
+	}
+	var invites []*StoreMember
+	err := r.db.WithContext(ctx).Preload("Store").
+		Where("status = ? AND invite_expires_at > ?", MemberInvited, time.Now()).
+		Where("((email <> '' AND email = ?) OR (phone <> '' AND phone = ?))", strings.ToLower(contact.Email), contact.Phone).
+		Order("created_at DESC").
+		Find(&invites).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store invites: %v", err)
+	}
+	return invites, nil
+}
+
+// GetUserMemberships lists the stores a user works for.
+func (r *repository) GetUserMemberships(ctx context.Context, userID uint32) ([]*StoreMember, error) {
+	var members []*StoreMember
+	err := r.db.WithContext(ctx).Preload("Store").
+		Where("user_id = ? AND status = ?", userID, MemberActive).
+		Order("accepted_at DESC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store memberships: %v", err)
+	}
+	return members, nil
+}
+
+// AcceptStaffInvite makes the user a member of the inviting store. The invite
+// must have been sent to the user's email or phone.
+func (r *repository) AcceptStaffInvite(ctx context.Context, id, userID uint32) (*StoreMember, error) {
+	member, err := r.GetStaffMember(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if member.Status != MemberInvited {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "This invite has already been accepted")
+	}
+	if time.Now().After(member.InviteExpiresAt) {
+		return nil, errors.NewAppError(http.StatusGone, "GONE", "This invite has expired; ask the store owner for a new one")
+	}
+
+	var contact staffContact
+	r.db.WithContext(ctx).Table("users").Select("id, fullname, email, phone").Where("id = ?", userID).Scan(&contact)
+	emailMatches := member.Email != "" && strings.EqualFold(member.Email, contact.Email)
+	phoneMatches := member.Phone != "" && member.Phone == contact.Phone
+	if !emailMatches && !phoneMatches {
+		return nil, errors.NewAppError(http.StatusForbidden, "FORBIDDEN", "This invite was sent to someone else")
+	}
+	if member.Store.UserID == userID {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "The owner is already part of this store")
+	}
+
+	var existing int64
+	r.db.WithContext(ctx).Model(&StoreMember{}).Where("store_id = ? AND user_id = ?", member.StoreID, userID).Count(&existing)
+	if existing > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "You are already a member of this store")
+	}
+
+	now := time.Now()
+	err = r.db.WithContext(ctx).Model(&StoreMember{}).Where("id = ?", member.ID).Updates(map[string]interface{}{
+		"user_id":     userID,
+		"status":      MemberActive,
+		"accepted_at": now,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept invite: %v", err)
+	}
+	return r.GetStaffMember(ctx, member.ID)
+}
+
+// UpdateStaffRole changes a member's role.
+func (r *repository) UpdateStaffRole(ctx context.Context, id uint32, role string) (*StoreMember, error) {
+	if !ValidStaffRole(role) {
+		return nil, errors.NewAppError(http.StatusBadRequest, "BAD REQUEST", "Role must be manager, order_handler or inventory_clerk")
+	}
+	if err := r.db.WithContext(ctx).Model(&StoreMember{}).Where("id = ?", id).Update("role", role).Error; err != nil {
+		return nil, fmt.Errorf("failed to update staff role: %v", err)
+	}
+	return r.GetStaffMember(ctx, id)
+}
+
+// RemoveStaff removes a member from a store or withdraws an invite.
+func (r *repository) RemoveStaff(ctx context.Context, id uint32) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&StoreMember{}).Error; err != nil {
+		return fmt.Errorf("failed to remove staff: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"gorm.io/gorm"
+)
+
+// DeleteStore closes a store through CloseStore, so a store cannot be deleted
+// while it still has orders, earnings or money to settle.
+func (r *repository) DeleteStore(ctx context.Context, id uint32) error {
+	_, err := r.CloseStore(ctx, id, 0)
+	return err
+}
+
+// GetTrashedStore loads a store in the trash.
+func (r *repository) GetTrashedStore(ctx context.Context, id uint32) (*Store, error) {
+	var s Store
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&s).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, errors.NewAppError(http.StatusNotFound, "NOT FOUND", "Store is not in the trash")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetTrashedStores lists a user's deleted stores, most recently deleted first.
+func (r *repository) GetTrashedStores(ctx context.Context, userID uint32) ([]*Store, error) {
+	var stores []*Store
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&stores).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %v", err)
+	}
+	return stores, nil
+}
+
+// CountTrashedStoreProducts counts the products that went to the trash with a
+// store and come back when it is restored.
+func (r *repository) CountTrashedStoreProducts(ctx context.Context, store *Store) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&product.Product{}).
+		Where("store = ? AND deleted_at = ?", store.Name, store.DeletedAt.Time).
+		Count(&count).Error
+	return int(count), err
+}
+
+// RestoreStore takes a store and the products deleted with it out of the trash.
+// A closed store comes back in maintenance mode with its products unlisted.
+func (r *repository) RestoreStore(ctx context.Context, id uint32) (*Store, error) {
+	s, err := r.GetTrashedStore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var taken int64
+	r.db.WithContext(ctx).Model(&Store{}).Where("(name = ? OR link = ?) AND id <> ?", s.Name, s.Link, s.ID).Count(&taken)
+	if taken > 0 {
+		return nil, errors.NewAppError(http.StatusConflict, "CONFLICT", "Another store is now using this store's name")
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&product.Product{}).
+			Where("store = ? AND deleted_at = ?", s.Name, s.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&StoreCloseout{}).Where("store_id = ? AND status = ?", s.ID, CloseoutClosed).
+			Updates(map[string]interface{}{"status": CloseoutReopened, "reopened_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&Store{}).Where("id = ?", s.ID).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore store: %v", err)
+	}
+	return r.GetStore(ctx, id)
+}
+
+// PurgeTrashedStores permanently deletes stores that have been in the trash
+// since before the cutoff. Stores still holding a wallet balance are kept so
+// the money can be paid out, and closed stores are kept until their records
+// are past RecordRetention.
+func (r *repository) PurgeTrashedStores(ctx context.Context, before time.Time) (int, error) {
+	var stores []*Store
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("id NOT IN (SELECT store_id FROM store_closeouts WHERE status = ? AND retain_until > ?)", CloseoutClosed, time.Now()).
+		Find(&stores).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, s := range stores {
+		if s.Wallet > 0 {
+			log.Printf("Keeping deleted store %d (%s): wallet still holds %.2f", s.ID, s.Name, s.Wallet)
+			continue
+		}
+		if err := r.db.WithContext(ctx).Unscoped().Where("id = ?", s.ID).Delete(&Store{}).Error; err != nil {
+			return purged, fmt.Errorf("failed to purge store %d: %v", s.ID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
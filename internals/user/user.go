@@ -68,8 +68,12 @@ type User struct {
 	Code           string           `json:"code,omitempty" db:"code"` // otp code for verifications
 	Online         bool             `json:"online,omitempty" db:"online"`
 	PaymentDetails PaymentDetails   `gorm:"serializer:json"`
-	Codeexpiry     time.Time        `json:"codeexpiry,omitempty" db:"codeexpiry"` // Expiry time for otpCode
-	CreatedAt      time.Time        // Set to current time if it is zero on creating
+	Codeexpiry     time.Time        `json:"codeexpiry,omitempty" db:"codeexpiry"`   // Expiry time for otpCode
+	PriceDropEmail bool             `json:"price_drop_email" db:"price_drop_email"` // Email price drop alerts as well as showing them in-app
+	// Email a digest of what followed stores posted
+	FollowDigestEmail  bool       `json:"follow_digest_email" db:"follow_digest_email"`
+	FollowDigestSentAt *time.Time `json:"follow_digest_sent_at" db:"follow_digest_sent_at"`
+	CreatedAt          time.Time  // Set to current time if it is zero on creating
 }
 
 // Add this type definition after the User struct
@@ -19,6 +19,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/samstringzz/alutamarket-backend/database"
 	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
 	"github.com/samstringzz/alutamarket-backend/internals/models"
 	"github.com/samstringzz/alutamarket-backend/utils"
 	"gorm.io/gorm"
@@ -379,6 +380,9 @@ func (r *repository) VerifyOTP(ctx context.Context, req *VerifyOTPReq) (*LoginUs
 		if err := r.db.Model(foundUser).Update("active", true).Error; err != nil {
 			return nil, err
 		}
+		if err := kyc.MarkPhoneVerified(r.db.WithContext(ctx), foundUser.ID); err != nil {
+			return nil, err
+		}
 		return &LoginUserRes{AccessToken: accessSS, RefreshToken: refreshSS, ID: foundUser.ID}, nil
 	}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/samstringzz/alutamarket-backend/database"
 	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/kyc"
 	"github.com/samstringzz/alutamarket-backend/internals/shared"
 	"github.com/samstringzz/alutamarket-backend/internals/store"
 	"github.com/samstringzz/alutamarket-backend/utils"
@@ -43,6 +44,11 @@ func (r *repository) CreateWithdrawal(ctx context.Context, req *shared.NewWithdr
 		return nil, fmt.Errorf("failed to get store: %v", err)
 	}
 
+	// The owner's verification tier caps how much one withdrawal can take
+	if err := kyc.CheckWithdrawal(r.db.WithContext(ctx), req.StoreID, req.Amount); err != nil {
+		return nil, err
+	}
+
 	// Create withdrawal record
 	withdrawal := &shared.Withdrawal{
 		StoreID:       req.StoreID,
@@ -21,6 +21,7 @@ import (
 	"github.com/samstringzz/alutamarket-backend/internals/messages"
 	"github.com/samstringzz/alutamarket-backend/internals/product"
 	"github.com/samstringzz/alutamarket-backend/internals/user"
+	"github.com/samstringzz/alutamarket-backend/services"
 	// "github.com/samstringzz/alutamarket-backend/app"
 )
 
@@ -177,6 +178,25 @@ func InitServer() error {
 	// GraphQL endpoint for queries/mutations
 	router.POST("/graphql", gin.WrapH(ExtractTokenMiddleware(srv)))
 
+	// Bulk catalog import/export for sellers
+	router.POST("/products/import", gin.WrapH(ExtractTokenMiddleware(http.HandlerFunc(services.ProductImportHandler))))
+	router.GET("/products/export", gin.WrapH(ExtractTokenMiddleware(http.HandlerFunc(services.ProductExportHandler))))
+
+	// Store customer list export for sellers
+	router.GET("/stores/customers/export", gin.WrapH(ExtractTokenMiddleware(http.HandlerFunc(services.StoreCustomerExportHandler))))
+
+	// Product and store pictures go through the image pipeline
+	router.POST("/media/images", gin.WrapH(ExtractTokenMiddleware(http.HandlerFunc(services.ImageUploadHandler))))
+
+	// Public uploads, and purchased files behind signed links
+	router.GET("/download/*name", gin.WrapH(http.HandlerFunc(services.DownloadHandler)))
+	router.GET("/downloads/:id", gin.WrapH(http.HandlerFunc(services.SignedDownloadHandler)))
+	router.HEAD("/downloads/:id", gin.WrapH(http.HandlerFunc(services.SignedDownloadHandler)))
+
+	// Printable QR codes, and the redirect their scans go through
+	router.GET("/qr/:kind/:file", gin.WrapH(http.HandlerFunc(services.QRCodeHandler)))
+	router.GET("/q/:kind/:id", gin.WrapH(http.HandlerFunc(services.QRScanHandler)))
+
 	// WebSocket endpoint
 	router.GET("/ws", func(c *gin.Context) {
 		if messageHandler == nil {
@@ -1,13 +1,19 @@
 package services
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/download"
 )
 
 const (
@@ -55,29 +61,162 @@ func UploadHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(response))
 }
 
-// Handler for file downloads
+// Handler for public uploads such as product and store pictures. Files sold as
+// digital products are refused here and must be fetched through a signed link.
 func DownloadHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract filename from URL path
-	filename := strings.TrimPrefix(r.URL.Path, "/download/")
-	if filename == "" {
+	filename := filepath.Base(strings.TrimPrefix(r.URL.Path, "/download/"))
+	if filename == "" || filename == "." || filename == "/" {
 		http.Error(w, "Filename is required", http.StatusBadRequest)
 		return
 	}
 
-	filePath := filepath.Join(uploadPath, filename)
+	downloadService := download.NewService(download.NewRepository())
+	protected, err := downloadService.IsProtectedFile(r.Context(), filename)
+	if err != nil || protected {
+		http.Error(w, "This file is only available to buyers", http.StatusForbidden)
+		return
+	}
 
-	file, err := os.Open(filePath)
+	file, err := os.Open(filepath.Join(uploadPath, filename))
 	if err != nil {
 		http.Error(w, "Error opening file", http.StatusNotFound)
 		return
 	}
 	defer file.Close()
 
-	// Set headers and serve the file
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	w.Header().Set("Content-Type", "application/octet-stream")
-	if _, err := io.Copy(w, file); err != nil {
-		http.Error(w, "Error writing file", http.StatusInternalServerError)
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Error opening file", http.StatusNotFound)
+		return
+	}
+	http.ServeContent(w, r, filename, info.ModTime(), file)
+}
+
+// SignedDownloadHandler streams a purchased file to a buyer holding a valid signed
+// link. Range requests are supported; each link counts once against the
+// purchase's download limit, whatever requests are made with it.
+func SignedDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	downloadID := strings.TrimPrefix(r.URL.Path, "/downloads/")
+	query := r.URL.Query()
+	userID := query.Get("user")
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+
+	downloadService := download.NewService(download.NewRepository())
+	event := &download.Event{
+		DownloadID: downloadID,
+		UserID:     userID,
+		Action:     download.ActionServed,
+		IP:         clientIP(r),
+		UserAgent:  r.UserAgent(),
+	}
+	deny := func(err error) {
+		event.Action = download.ActionDenied
+		event.Reason = err.Error()
+		downloadService.LogEvent(r.Context(), event)
+		status := http.StatusForbidden
+		if appErr, ok := err.(*errors.AppError); ok {
+			status = appErr.StatusCode
+		}
+		http.Error(w, err.Error(), status)
+	}
+
+	entitlement, err := downloadService.Authorize(r.Context(), downloadID, userID, expires, query.Get("signature"))
+	if err != nil {
+		deny(err)
+		return
+	}
+
+	if err := downloadService.ClaimDownload(r.Context(), downloadID, query.Get("signature")); err != nil {
+		deny(err)
+		return
+	}
+
+	file := entitlement.Download.File
+	name := entitlement.Download.Name + filepath.Ext(file)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	if strings.HasPrefix(file, "http") && !strings.Contains(file, "/download/") {
+		if err := proxyDownload(w, r, file); err != nil {
+			deny(err)
+			return
+		}
+		downloadService.LogEvent(r.Context(), event)
+		return
+	}
+
+	f, err := os.Open(filepath.Join(uploadPath, filepath.Base(file)))
+	if err != nil {
+		http.Error(w, "Error opening file", http.StatusNotFound)
 		return
 	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Error opening file", http.StatusNotFound)
+		return
+	}
+
+	if entitlement.Watermark && entitlement.BuyerEmail != "" {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, "Error reading file", http.StatusInternalServerError)
+			return
+		}
+		if marked, ok := download.Watermark(data, entitlement.BuyerEmail); ok {
+			downloadService.LogEvent(r.Context(), event)
+			http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(marked))
+			return
+		}
+		f.Seek(0, io.SeekStart)
+	}
+
+	downloadService.LogEvent(r.Context(), event)
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// proxyDownload streams a file kept with an external storage provider, passing
+// the client's range request along.
+func proxyDownload(w http.ResponseWriter, r *http.Request, fileURL string) error {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, fileURL, nil)
+	if err != nil {
+		return err
+	}
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch file: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return errors.NewAppError(http.StatusBadGateway, "BAD GATEWAY", "File is not available")
+	}
+
+	for _, header := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges", "Last-Modified"} {
+		if v := resp.Header.Get(header); v != "" {
+			w.Header().Set(header, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+	return nil
+}
+
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
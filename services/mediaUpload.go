@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/media"
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+// authorizeMediaOwner checks that the bearer of the request token may manage the
+// product or store an image is uploaded for.
+func authorizeMediaOwner(r *http.Request, ownerType string, ownerID uint32) (uint32, int, error) {
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		return 0, http.StatusUnauthorized, err
+	}
+
+	storeRepo := store.NewRepository()
+	var storeObj *store.Store
+	perm := store.PermProducts
+	switch ownerType {
+	case media.OwnerProduct:
+		p, err := product.NewRepository().GetProduct(r.Context(), ownerID, 0)
+		if err != nil {
+			return 0, http.StatusNotFound, err
+		}
+		storeObj, err = storeRepo.GetStoreByName(r.Context(), p.Store)
+		if err != nil {
+			return 0, http.StatusNotFound, err
+		}
+	case media.OwnerStore:
+		perm = store.PermSettings
+		storeObj, err = storeRepo.GetStore(r.Context(), ownerID)
+		if err != nil {
+			return 0, http.StatusNotFound, err
+		}
+	default:
+		return 0, http.StatusBadRequest, fmt.Errorf("owner_type must be product or store")
+	}
+
+	role, err := storeRepo.GetStoreRole(r.Context(), storeObj, userID)
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	if !store.RoleAllows(role, perm) {
+		return 0, http.StatusForbidden, fmt.Errorf("your role in this store does not allow uploading these images")
+	}
+	return userID, http.StatusOK, nil
+}
+
+// ImageUploadHandler runs an uploaded picture through the image pipeline and
+// attaches the result to a product or store. The form takes the file, the
+// owner_type and owner_id, and the role the picture plays for its owner.
+// JPEG, PNG and WebP files are accepted; the original and renditions are
+// always saved as JPEG.
+func ImageUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, media.MaxUploadSize+(1<<20))
+	if err := r.ParseMultipartForm(media.MaxUploadSize); err != nil {
+		http.Error(w, "File is too large or the form is invalid", http.StatusBadRequest)
+		return
+	}
+
+	ownerType := r.FormValue("owner_type")
+	ownerID, err := strconv.ParseUint(r.FormValue("owner_id"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid owner ID", http.StatusBadRequest)
+		return
+	}
+	userID, status, err := authorizeMediaOwner(r, ownerType, uint32(ownerID))
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+
+	role := r.FormValue("role")
+	if role == "" {
+		role = media.RoleImage
+	}
+
+	mediaHandler := media.NewHandler(media.NewService(media.NewRepository()))
+	image, err := mediaHandler.UploadImage(r.Context(), &media.Image{
+		OwnerType:  ownerType,
+		OwnerID:    uint32(ownerID),
+		Role:       role,
+		UploadedBy: userID,
+	}, data)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if appErr, ok := err.(*errors.AppError); ok {
+			status = appErr.StatusCode
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(image)
+}
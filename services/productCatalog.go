@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/product"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+// maxImportFileSize is the largest catalog sheet accepted by ProductImportHandler
+const maxImportFileSize = 10 << 20
+
+// authorizeStoreRequest loads the store named by the "store" parameter and checks
+// that the bearer of the request token holds perm in it.
+func authorizeStoreRequest(r *http.Request, perm string) (*store.Store, int, error) {
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+
+	storeID, err := strconv.ParseUint(r.FormValue("store"), 10, 32)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid store ID")
+	}
+
+	storeRepo := store.NewRepository()
+	storeObj, err := storeRepo.GetStore(r.Context(), uint32(storeID))
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	role, err := storeRepo.GetStoreRole(r.Context(), storeObj, userID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if !store.RoleAllows(role, perm) {
+		return nil, http.StatusForbidden, fmt.Errorf("your role in this store does not allow managing its %s", perm)
+	}
+	return storeObj, http.StatusOK, nil
+}
+
+// ProductImportHandler creates or updates a store's products from an uploaded CSV or
+// XLSX sheet and responds with a per-row report.
+func ProductImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		http.Error(w, "File is too large or the form is invalid", http.StatusBadRequest)
+		return
+	}
+
+	storeObj, status, err := authorizeStoreRequest(r, store.PermProducts)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Error retrieving file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	rows, err := product.ParseImportFile(fileHeader.Filename, file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	productService := product.NewService(product.NewRepository())
+	report, err := productService.ImportProducts(r.Context(), storeObj.Name, rows)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error importing products: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ProductExportHandler downloads a store's catalog and stock levels in the import sheet layout.
+func ProductExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storeObj, status, err := authorizeStoreRequest(r, store.PermProducts)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	contentType := "text/csv"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else if format != "csv" {
+		http.Error(w, "Format must be csv or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	productService := product.NewService(product.NewRepository())
+	products, err := productService.GetStoreCatalog(r.Context(), storeObj.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-catalog-%s.%s", utils.GenerateSlug(storeObj.Name), time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set("Content-Type", contentType)
+	if err := product.WriteCatalog(w, format, products); err != nil {
+		http.Error(w, "Error writing export", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/samstringzz/alutamarket-backend/errors"
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+const (
+	defaultQRSize = 512
+	minQRSize     = 128
+	maxQRSize     = 2048
+)
+
+// qrTargetFromPath reads the kind and ID of a QR code from a path ending in
+// "/<kind>/<id>", with an optional extension on the ID.
+func qrTargetFromPath(r *http.Request, prefix string) (*store.QRTarget, string, int, error) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	if len(parts) != 2 {
+		return nil, "", http.StatusNotFound, fmt.Errorf("QR code not found")
+	}
+	ext := path.Ext(parts[1])
+	id, err := strconv.ParseUint(strings.TrimSuffix(parts[1], ext), 10, 32)
+	if err != nil {
+		return nil, "", http.StatusNotFound, fmt.Errorf("QR code not found")
+	}
+
+	storeService := store.NewService(store.NewRepository())
+	target, err := storeService.GetQRTarget(r.Context(), parts[0], uint32(id))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if appErr, ok := err.(*errors.AppError); ok {
+			status = appErr.StatusCode
+		}
+		return nil, "", status, err
+	}
+	return target, strings.TrimPrefix(ext, "."), http.StatusOK, nil
+}
+
+// QRCodeHandler serves the QR code of a store, product or invoice as
+// /qr/<kind>/<id>.png or .svg, sized by the "size" parameter in pixels.
+func QRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	target, format, status, err := qrTargetFromPath(r, "/qr/")
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	size := defaultQRSize
+	if s := r.URL.Query().Get("size"); s != "" {
+		size, err = strconv.Atoi(s)
+		if err != nil || size < minQRSize || size > maxQRSize {
+			http.Error(w, fmt.Sprintf("Size must be between %d and %d pixels", minQRSize, maxQRSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var image []byte
+	switch format {
+	case store.QRFormatPNG, "":
+		image, err = utils.QRCodePNG(target.ScanURL(), size)
+		w.Header().Set("Content-Type", "image/png")
+	case store.QRFormatSVG:
+		image, err = utils.QRCodeSVG(target.ScanURL(), size)
+		w.Header().Set("Content-Type", "image/svg+xml")
+	default:
+		http.Error(w, "QR codes come as png or svg", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error generating QR code", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("download") != "" {
+		filename := fmt.Sprintf("%s-%s-%d-qr.%s", utils.GenerateSlug(target.Store.Name), target.Kind, target.TargetID, format)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(image)
+}
+
+// QRScanHandler counts a scan of a QR code and redirects to the page it is
+// for, tagged as QR traffic.
+func QRScanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	target, _, status, err := qrTargetFromPath(r, "/q/")
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// A lost scan should not stop the buyer reaching the page
+	storeService := store.NewService(store.NewRepository())
+	if err := storeService.RecordQRScan(r.Context(), target, clientIP(r)); err != nil {
+		log.Printf("Failed to record scan of %s %d: %v", target.Kind, target.TargetID, err)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target.PageURL(), http.StatusFound)
+}
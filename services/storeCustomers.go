@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/samstringzz/alutamarket-backend/internals/store"
+	"github.com/samstringzz/alutamarket-backend/utils"
+)
+
+// StoreCustomerExportHandler downloads a store's customers as CSV, optionally
+// narrowed by the "segment", "tag" and "search" parameters.
+func StoreCustomerExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storeObj, status, err := authorizeStoreRequest(r, store.PermCustomers)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &store.CustomerFilter{
+		Segment: query.Get("segment"),
+		Tag:     query.Get("tag"),
+		Search:  query.Get("search"),
+		Sort:    query.Get("sort"),
+	}
+	if !store.ValidSegment(filter.Segment) {
+		http.Error(w, fmt.Sprintf("Unknown segment %q", filter.Segment), http.StatusBadRequest)
+		return
+	}
+
+	storeService := store.NewService(store.NewRepository())
+	customers, err := storeService.ExportStoreCustomers(r.Context(), storeObj, filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-customers-%s.csv", utils.GenerateSlug(storeObj.Name), time.Now().Format("20060102"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set("Content-Type", "text/csv")
+	if err := store.WriteCustomers(w, customers); err != nil {
+		http.Error(w, "Error writing export", http.StatusInternalServerError)
+	}
+}
@@ -280,14 +280,16 @@ func (repo *repository) FWWebhookHandler(w http.ResponseWriter, r *http.Request)
 					// Process each product with a file
 					for _, product := range productsWithFiles {
 						download := &store.Downloads{
-							Thumbnail: product.Thumbnail,
-							Price:     product.Price,
-							Name:      product.Name,
-							Discount:  int(product.Discount),
-							UUID:      order.UUID,
-							ID:        utils.GenerateUUID(),
-							File:      *product.File,          // Dereference the file pointer safely
-							Users:     []string{order.UserID}, // Initialize with the current user
+							Thumbnail:    product.Thumbnail,
+							Price:        product.Price,
+							Name:         product.Name,
+							Discount:     int(product.Discount),
+							UUID:         order.UUID,
+							ID:           utils.GenerateUUID(),
+							ProductID:    product.ID,
+							File:         *product.File,          // Dereference the file pointer safely
+							Users:        []string{order.UserID}, // Initialize with the current user
+							MaxDownloads: store.DefaultDownloadLimit,
 						}
 
 						// Save the download record to the database
@@ -499,14 +501,16 @@ func (repo *repository) PaystackWebhookHandler(w http.ResponseWriter, r *http.Re
 					// Process each product with a file
 					for _, product := range productsWithFiles {
 						download := &store.Downloads{
-							Thumbnail: product.Thumbnail,
-							Price:     product.Price,
-							Name:      product.Name,
-							Discount:  int(product.Discount),
-							UUID:      order.UUID,
-							ID:        utils.GenerateUUID(),
-							File:      *product.File,          // Dereference the file pointer safely
-							Users:     []string{order.UserID}, // Initialize with the current user
+							Thumbnail:    product.Thumbnail,
+							Price:        product.Price,
+							Name:         product.Name,
+							Discount:     int(product.Discount),
+							UUID:         order.UUID,
+							ID:           utils.GenerateUUID(),
+							ProductID:    product.ID,
+							File:         *product.File,          // Dereference the file pointer safely
+							Users:        []string{order.UserID}, // Initialize with the current user
+							MaxDownloads: store.DefaultDownloadLimit,
 						}
 
 						// Save the download record to the database
@@ -691,14 +695,16 @@ func (repo *repository) SquadWebhookHandler(w http.ResponseWriter, r *http.Reque
 					// Process each product with a file
 					for _, product := range productsWithFiles {
 						download := &store.Downloads{
-							Thumbnail: product.Thumbnail,
-							Price:     product.Price,
-							Name:      product.Name,
-							Discount:  int(product.Discount),
-							UUID:      order.UUID,
-							ID:        utils.GenerateUUID(),
-							File:      *product.File,          // Dereference the file pointer safely
-							Users:     []string{order.UserID}, // Initialize with the current user
+							Thumbnail:    product.Thumbnail,
+							Price:        product.Price,
+							Name:         product.Name,
+							Discount:     int(product.Discount),
+							UUID:         order.UUID,
+							ID:           utils.GenerateUUID(),
+							ProductID:    product.ID,
+							File:         *product.File,          // Dereference the file pointer safely
+							Users:        []string{order.UserID}, // Initialize with the current user
+							MaxDownloads: store.DefaultDownloadLimit,
 						}
 
 						// Save the download record to the database